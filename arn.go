@@ -0,0 +1,43 @@
+package fakedynamo
+
+import (
+	"fmt"
+	"strings"
+)
+
+func (d *DB) tableArn(tableName string) string {
+	return fmt.Sprintf("arn:aws:dynamodb:%s:%s:table/%s", d.region, d.accountID, tableName)
+}
+
+// tableNameFromArn extracts the table name from a table ARN, or any ARN
+// nested beneath it (e.g. an export or stream ARN). Returns false if the ARN
+// doesn't refer to a table in this DB's region and account.
+func (d *DB) tableNameFromArn(arn string) (string, bool) {
+	prefix := fmt.Sprintf("arn:aws:dynamodb:%s:%s:table/", d.region, d.accountID)
+	rest, found := strings.CutPrefix(arn, prefix)
+	if !found || rest == "" {
+		return "", false
+	}
+	name, _, _ := strings.Cut(rest, "/")
+	return name, true
+}
+
+// streamLabel identifies a table's stream. DynamoDB uses the stream's
+// creation time; since we don't support re-enabling streams, we use the
+// table's creation time.
+func (t *table) streamLabel() (string, bool) {
+	spec := t.spec.StreamSpecification
+	if spec == nil || !val(spec.StreamEnabled) {
+		return "", false
+	}
+	return t.createdAt.Format("2006-01-02T15:04:05.000"), true
+}
+
+// streamArn returns the ARN of the table's stream, if it has one.
+func (d *DB) streamArn(t *table) (string, bool) {
+	label, ok := t.streamLabel()
+	if !ok {
+		return "", false
+	}
+	return d.tableArn(*t.spec.TableName) + "/stream/" + label, true
+}
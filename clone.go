@@ -0,0 +1,204 @@
+package fakedynamo
+
+import (
+	"errors"
+	"maps"
+	"slices"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/google/btree"
+)
+
+// dbState holds the parts of a DB which are copied by [DB.Clone] and saved by
+// [DB.Checkpoint].
+type dbState struct {
+	tables  *btree.BTreeG[*table]
+	exports []*dynamodb.ExportDescription
+	imports []*dynamodb.ImportTableDescription
+}
+
+// state returns the DB's current state, without copying it.
+func (d *DB) state() dbState {
+	return dbState{tables: d.tables, exports: d.exports, imports: d.imports}
+}
+
+// clone copies the state. Tables in memory share their items with the copy
+// until either is written to, so the copy takes time proportional to the
+// number of tables, not items. Tables which are being deleted are left out,
+// as they are from snapshots: only the DB which deleted them would remove
+// them. The caller MUST hold the write lock of the DB the state belongs to.
+func (s dbState) clone() (dbState, error) {
+	copied := dbState{
+		tables:  btree.NewG(2, tableLess),
+		exports: slices.Clip(s.exports),
+		imports: slices.Clip(s.imports),
+	}
+	var err error
+	s.tables.Ascend(func(t *table) bool {
+		if t.deleting {
+			return true
+		}
+		var c *table
+		if c, err = t.clone(); err == nil {
+			copied.tables.ReplaceOrInsert(c)
+		}
+		return err == nil
+	})
+	if err != nil {
+		return dbState{}, errors.Join(err, copied.close()) //nolint:exhaustruct
+	}
+	return copied, nil
+}
+
+// close releases the storage of the state's tables.
+func (s dbState) close() error {
+	var errs []error
+	s.tables.Ascend(func(t *table) bool {
+		errs = append(errs, t.items.Close())
+		return true
+	})
+	return errors.Join(errs...)
+}
+
+// clone returns a copy of the table. See [dbState.clone].
+func (t *table) clone() (*table, error) {
+	items, err := t.items.Snapshot()
+	if err != nil {
+		return nil, err
+	}
+	c := &table{
+		spec:                t.spec,
+		schema:              t.schema,
+		createdAt:           t.createdAt,
+		items:               items,
+		pitr:                nil,
+		ttlAttribute:        t.ttlAttribute,
+		tags:                maps.Clone(t.tags),
+		policy:              t.policy,
+		streamPolicy:        t.streamPolicy,
+		replication:         maps.Clone(t.replication),
+		kinesisDestinations: make([]*kinesisDestination, 0, len(t.kinesisDestinations)),
+		insights:            make(map[string]*contributorInsights, len(t.insights)),
+		throughput:          t.throughput.clone(),
+		deleting:            false,
+	}
+	if t.pitr != nil {
+		// Clip the history, so that appending to either copy doesn't write
+		// over the other's.
+		c.pitr = &pitrState{enabledAt: t.pitr.enabledAt, changes: slices.Clip(t.pitr.changes)}
+	}
+	for _, dest := range t.kinesisDestinations {
+		c.kinesisDestinations = append(c.kinesisDestinations, shallowCopy(dest))
+	}
+	for name, ci := range t.insights {
+		c.insights[name] = ci.clone()
+	}
+	return c, nil
+}
+
+// Clone returns an independent copy of the DB, with the same tables, items
+// and configuration. Later changes to either DB don't affect the other.
+//
+// Cloning is cheap for DBs which keep their items in memory: the copy shares
+// its items with the original until either writes to them, so Clone takes
+// time proportional to the number of tables, not items. A test suite can load
+// a large fixture once, then give each subtest a clone to change as it
+// likes, even if the subtests run in parallel. The tables of a DB configured
+// with [WithFileStorage] are copied to new files, in the same directory.
+//
+// Tables which are being deleted aren't copied. The clone isn't durable, even
+// if the DB was opened with [OpenDB], and isn't linked to the DB's [Regions],
+// so its global tables aren't replicated. It streams to the same Kinesis as
+// the DB, if configured with [WithKinesis], and has no faults.
+func (d *DB) Clone() (*DB, error) {
+	// Copying the tables' storage writes to it, so needs the write lock.
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	state, err := d.state().clone()
+	if err != nil {
+		return nil, err
+	}
+	return &DB{
+		mu:        sync.RWMutex{},
+		tables:    state.tables,
+		region:    d.region,
+		accountID: d.accountID,
+		s3Dir:     d.s3Dir,
+		exports:   state.exports,
+		imports:   state.imports,
+		regions:   nil,
+		kinesis:   d.kinesis,
+
+		transientStatusDelay: d.transientStatusDelay,
+		clock:                d.clock,
+		adaptiveCapacity:     d.adaptiveCapacity,
+		storageDir:           d.storageDir,
+		wal:                  nil,
+		compactEvery:         d.compactEvery,
+		faults:               newFaults(),
+	}, nil
+}
+
+// Checkpoint is a saved state of a DB, made by [DB.Checkpoint].
+type Checkpoint struct {
+	// db is the DB which the checkpoint was made of.
+	db *DB
+	// mu guards state, whose storage is written to when it's copied.
+	mu    sync.Mutex
+	state dbState
+}
+
+// Checkpoint saves the DB's tables, with their items and settings, and the
+// history of exports and imports, so that they can be restored by
+// [DB.Rollback]. Like [DB.Clone], it's cheap for DBs which keep their items in
+// memory, and leaves out tables which are being deleted.
+func (d *DB) Checkpoint() (*Checkpoint, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	state, err := d.state().clone()
+	if err != nil {
+		return nil, err
+	}
+	return &Checkpoint{db: d, mu: sync.Mutex{}, state: state}, nil
+}
+
+// Rollback restores the state saved by cp, which must be a checkpoint of d:
+// tables created since are deleted, tables deleted since are restored, and
+// every table's items and settings are put back as they were. The DB's
+// configuration, such as its faults, isn't affected. A DB can be rolled back
+// to the same checkpoint many times.
+//
+// Only this region's tables are rolled back, so the replicas of a global table
+// may diverge. Rolling back a DB opened with [OpenDB] compacts its
+// write-ahead log, so the rollback is durable.
+func (d *DB) Rollback(cp *Checkpoint) error {
+	if cp.db != d {
+		return errors.New("can't roll back to a checkpoint of another DB")
+	}
+	cp.mu.Lock()
+	state, err := cp.state.clone()
+	cp.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	previous := d.state()
+	d.tables, d.exports, d.imports = state.tables, state.exports, state.imports
+	errs := []error{previous.close()}
+	if d.wal != nil {
+		errs = append(errs, d.compactWAL())
+	}
+	return errors.Join(errs...)
+}
+
+// Close releases the files holding the items of a checkpoint of a DB
+// configured with [WithFileStorage]. The checkpoint can't be rolled back to
+// afterwards. Close has nothing to do for other checkpoints.
+func (cp *Checkpoint) Close() error {
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+	return cp.state.close()
+}
@@ -0,0 +1,247 @@
+package fakedynamo_test
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DMRobertson/fakedynamo"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDB_Clone(t *testing.T) {
+	t.Parallel()
+	db := fakedynamo.NewDB()
+	input := exampleCreateTableInputSimplePrimaryKey()
+	input.BillingMode = ptr(dynamodb.BillingModePayPerRequest)
+	input.ProvisionedThroughput = nil
+	created, err := db.CreateTable(input)
+	require.NoError(t, err)
+	for i := range 1000 {
+		putFoo(t, db, input.TableName, fmt.Sprint(i))
+	}
+
+	// Each subtest changes its own clone, without affecting the others.
+	for i := range 10 {
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			t.Parallel()
+			clone, err := db.Clone()
+			require.NoError(t, err)
+			putFoo(t, clone, input.TableName, fmt.Sprintf("clone-%d", i))
+			_, err = clone.DeleteItem(&dynamodb.DeleteItemInput{ //nolint:exhaustruct
+				Key:       map[string]*dynamodb.AttributeValue{"Foo": {S: ptr(fmt.Sprint(i))}},
+				TableName: input.TableName,
+			})
+			require.NoError(t, err)
+			_, err = clone.TagResource(&dynamodb.TagResourceInput{
+				ResourceArn: created.TableDescription.TableArn,
+				Tags:        []*dynamodb.Tag{{Key: ptr("clone"), Value: ptr(fmt.Sprint(i))}},
+			})
+			require.NoError(t, err)
+
+			assert.True(t, hasFoo(t, clone, input.TableName, fmt.Sprintf("clone-%d", i)))
+			assert.False(t, hasFoo(t, clone, input.TableName, fmt.Sprint(i)))
+			assert.False(t, hasFoo(t, db, input.TableName, fmt.Sprintf("clone-%d", i)))
+			assert.True(t, hasFoo(t, db, input.TableName, fmt.Sprint(i)))
+			assert.Equal(t, map[string]string{"clone": fmt.Sprint(i)}, listAllTags(t, clone, created.TableDescription.TableArn))
+		})
+	}
+
+	t.Run("original", func(t *testing.T) {
+		t.Parallel()
+		clone, err := db.Clone()
+		require.NoError(t, err)
+		_, err = clone.DeleteTable(&dynamodb.DeleteTableInput{TableName: input.TableName})
+		require.NoError(t, err)
+		diff, err := db.Diff(clone)
+		require.NoError(t, err)
+		require.Len(t, diff.Tables, 1)
+		assert.True(t, diff.Tables[0].Removed)
+		assert.Len(t, diff.Tables[0].Items, 1000)
+		assert.Empty(t, listAllTags(t, db, created.TableDescription.TableArn))
+	})
+}
+
+func TestDB_Clone_FileStorage(t *testing.T) {
+	t.Parallel()
+	db := fakedynamo.NewDB(fakedynamo.WithFileStorage(t.TempDir()))
+	defer db.Close()
+	input := exampleCreateTableInputSimplePrimaryKey()
+	_, err := db.CreateTable(input)
+	require.NoError(t, err)
+	putFoo(t, db, input.TableName, "original")
+
+	clone, err := db.Clone()
+	require.NoError(t, err)
+	defer clone.Close()
+	putFoo(t, clone, input.TableName, "clone")
+	assert.True(t, hasFoo(t, clone, input.TableName, "original"))
+	assert.False(t, hasFoo(t, db, input.TableName, "clone"))
+}
+
+func TestDB_Rollback(t *testing.T) {
+	t.Parallel()
+	db := fakedynamo.NewDB()
+	kept := exampleCreateTableInputSimplePrimaryKey()
+	_, err := db.CreateTable(kept)
+	require.NoError(t, err)
+	putFoo(t, db, kept.TableName, "a")
+	dropped := exampleCreateTableInputSimplePrimaryKey()
+	_, err = db.CreateTable(dropped)
+	require.NoError(t, err)
+	var before bytes.Buffer
+	require.NoError(t, db.SaveSnapshot(&before))
+
+	cp, err := db.Checkpoint()
+	require.NoError(t, err)
+	for range 2 {
+		putFoo(t, db, kept.TableName, "b")
+		_, err = db.DeleteItem(&dynamodb.DeleteItemInput{ //nolint:exhaustruct
+			Key:       map[string]*dynamodb.AttributeValue{"Foo": {S: ptr("a")}},
+			TableName: kept.TableName,
+		})
+		require.NoError(t, err)
+		_, err = db.DeleteTable(&dynamodb.DeleteTableInput{TableName: dropped.TableName})
+		require.NoError(t, err)
+		_, err = db.CreateTable(exampleCreateTableInputCompositePrimaryKey())
+		require.NoError(t, err)
+
+		require.NoError(t, db.Rollback(cp))
+		diff, err := db.DiffSnapshot(bytes.NewReader(before.Bytes()))
+		require.NoError(t, err)
+		assert.True(t, diff.Empty(), diff.String())
+		listed, err := db.ListTables(&dynamodb.ListTablesInput{}) //nolint:exhaustruct
+		require.NoError(t, err)
+		assert.Len(t, listed.TableNames, 2)
+	}
+
+	other, err := fakedynamo.NewDB().Checkpoint()
+	require.NoError(t, err)
+	assertErrorContains(t, db.Rollback(other), "another DB")
+}
+
+func TestDB_Rollback_OpenDB(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	db, err := fakedynamo.OpenDB(dir)
+	require.NoError(t, err)
+	input := exampleCreateTableInputSimplePrimaryKey()
+	_, err = db.CreateTable(input)
+	require.NoError(t, err)
+	putFoo(t, db, input.TableName, "kept")
+	cp, err := db.Checkpoint()
+	require.NoError(t, err)
+	putFoo(t, db, input.TableName, "rolled back")
+	require.NoError(t, db.Rollback(cp))
+
+	reopened, err := fakedynamo.OpenDB(dir)
+	require.NoError(t, err)
+	defer reopened.Close()
+	assert.True(t, hasFoo(t, reopened, input.TableName, "kept"))
+	assert.False(t, hasFoo(t, reopened, input.TableName, "rolled back"))
+}
+
+func TestDB_Clone_ParallelWritesToSharedPartition(t *testing.T) {
+	t.Parallel()
+	db := fakedynamo.NewDB()
+	input := exampleCreateTableInputCompositePrimaryKey()
+	_, err := db.CreateTable(input)
+	require.NoError(t, err)
+	put := func(db *fakedynamo.DB, bar string) error {
+		_, err := db.PutItem(&dynamodb.PutItemInput{ //nolint:exhaustruct
+			Item:      map[string]*dynamodb.AttributeValue{"Foo": {S: ptr("shared")}, "Bar": {S: ptr(bar)}},
+			TableName: input.TableName,
+		})
+		return err
+	}
+	for i := range 100 {
+		require.NoError(t, put(db, fmt.Sprint(i)))
+	}
+
+	// The clones share the partition until they write to it, when each takes
+	// its own copy. Write from goroutines, rather than parallel subtests, so
+	// that -race sees the writes as concurrent even with -parallel 1.
+	clones := make([]*fakedynamo.DB, 10)
+	for i := range clones {
+		clones[i], err = db.Clone()
+		require.NoError(t, err)
+	}
+	var wg sync.WaitGroup
+	for i, clone := range clones {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, put(clone, fmt.Sprintf("clone-%d", i)))
+		}()
+	}
+	wg.Wait()
+
+	for _, clone := range clones {
+		output, err := clone.Query(&dynamodb.QueryInput{ //nolint:exhaustruct
+			ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{":foo": {S: ptr("shared")}},
+			KeyConditionExpression:    ptr("Foo = :foo"),
+			Select:                    ptr(dynamodb.SelectCount),
+			TableName:                 input.TableName,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, int64(101), val(output.Count))
+	}
+}
+
+func TestDB_Clone_LeavesOutDeletingTables(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []struct {
+		name  string
+		clone func(t *testing.T, db *fakedynamo.DB) *fakedynamo.DB
+	}{
+		{
+			name: "Clone",
+			clone: func(t *testing.T, db *fakedynamo.DB) *fakedynamo.DB {
+				clone, err := db.Clone()
+				require.NoError(t, err)
+				return clone
+			},
+		},
+		{
+			name: "Rollback",
+			clone: func(t *testing.T, db *fakedynamo.DB) *fakedynamo.DB {
+				cp, err := db.Checkpoint()
+				require.NoError(t, err)
+				require.NoError(t, db.Rollback(cp))
+				return db
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			db := fakedynamo.NewDB(fakedynamo.WithTransientStatusDelay(50 * time.Millisecond))
+			input := exampleCreateTableInputSimplePrimaryKey()
+			_, err := db.CreateTable(input)
+			require.NoError(t, err)
+			require.Eventually(t, func() bool {
+				described, err := db.DescribeTable(&dynamodb.DescribeTableInput{TableName: input.TableName})
+				return err == nil && val(described.Table.TableStatus) == dynamodb.TableStatusActive
+			}, time.Second, 10*time.Millisecond)
+			_, err = db.DeleteTable(&dynamodb.DeleteTableInput{TableName: input.TableName})
+			require.NoError(t, err)
+
+			clone := tc.clone(t, db)
+			_, err = clone.DescribeTable(&dynamodb.DescribeTableInput{TableName: input.TableName})
+			var notFound *dynamodb.ResourceNotFoundException
+			require.ErrorAs(t, err, &notFound)
+
+			// The name can be reused, and the original deletion doesn't
+			// remove the new table when it completes.
+			_, err = clone.CreateTable(input)
+			require.NoError(t, err)
+			time.Sleep(100 * time.Millisecond)
+			described, err := clone.DescribeTable(&dynamodb.DescribeTableInput{TableName: input.TableName})
+			require.NoError(t, err)
+			assert.Equal(t, dynamodb.TableStatusActive, val(described.Table.TableStatus))
+		})
+	}
+}
@@ -0,0 +1,993 @@
+package fakedynamo
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"gopkg.in/yaml.v3"
+)
+
+// CloudFormation templates
+//
+// LoadCloudFormationTemplate creates the tables declared by a CloudFormation
+// template's resources of these types:
+//
+//   - AWS::DynamoDB::Table,
+//   - AWS::DynamoDB::GlobalTable, and
+//   - AWS::Serverless::SimpleTable, from SAM templates.
+//
+// The template may be JSON, as synthesised by the CDK, or YAML, using either
+// the long or short forms of intrinsic functions. Ref, Condition, Fn::Base64,
+// Fn::Equals, Fn::FindInMap, Fn::If, Fn::Join, Fn::Not, Fn::And, Fn::Or,
+// Fn::Select, Fn::Split and Fn::Sub are evaluated; Ref and Fn::Sub can refer
+// to parameters, pseudo parameters and tables. Templates using other
+// functions, like Fn::GetAtt, in the tables' properties can't be loaded.
+//
+// These properties are translated into calls to the DB's API: the key
+// schema, attribute definitions, billing mode and throughput, global and
+// local secondary indexes, stream specification, server-side encryption,
+// table class, deletion protection, tags, resource policy, Time to Live and
+// point-in-time recovery. Other properties are ignored.
+
+// CloudFormationOptions configures [DB.LoadCloudFormationTemplate].
+type CloudFormationOptions struct {
+	// Parameters overrides the defaults of the template's parameters.
+	Parameters map[string]string
+	// StackName is the value of the AWS::StackName pseudo parameter. It
+	// defaults to "stack".
+	StackName string
+}
+
+// LoadCloudFormationTemplateFile loads the template in the file at path. See
+// [DB.LoadCloudFormationTemplate].
+func (d *DB) LoadCloudFormationTemplateFile(path string, opts CloudFormationOptions) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return d.LoadCloudFormationTemplate(path, f, opts)
+}
+
+// LoadCloudFormationTemplate reads a CloudFormation template from r, creating
+// the tables it declares, in the order they're declared. It returns a map
+// from the tables' logical IDs to their names. Tables without a TableName
+// property are named "<StackName>-<logical ID>".
+//
+// name identifies the template in errors, which are *[FixtureError]s unless
+// the template can't be parsed at all. Loading stops at the first error,
+// keeping the tables created before it.
+//
+// For an AWS::DynamoDB::GlobalTable, the table is created in this DB's
+// region, which must be one of its replicas' regions. If the DB belongs to
+// [Regions], the other replicas are created too; otherwise they are ignored.
+func (d *DB) LoadCloudFormationTemplate(name string, r io.Reader, opts CloudFormationOptions) (map[string]string, error) {
+	var doc yaml.Node
+	if err := yaml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	if opts.StackName == "" {
+		opts.StackName = "stack"
+	}
+	c := &cfnLoader{
+		fixtureLoader: fixtureLoader{d: d, name: name},
+		opts:          opts,
+		parameters:    map[string]*yaml.Node{},
+		mappings:      nil,
+		conditions:    map[string]*yaml.Node{},
+		evaluated:     map[string]bool{},
+		evaluating:    map[string]bool{},
+		tables:        map[string]string{},
+		resources:     map[string]bool{},
+	}
+	if err := c.load(resolveAlias(doc.Content[0])); err != nil {
+		return nil, err
+	}
+	return c.tables, nil
+}
+
+const (
+	cfnTable       = "AWS::DynamoDB::Table"
+	cfnGlobalTable = "AWS::DynamoDB::GlobalTable"
+	cfnSimpleTable = "AWS::Serverless::SimpleTable"
+)
+
+// cfnNoValue is the value of the AWS::NoValue pseudo parameter, which removes
+// the property or list element holding it.
+type cfnNoValue struct{}
+
+type cfnLoader struct {
+	fixtureLoader
+	opts CloudFormationOptions
+	// parameters, mappings and conditions are the template's sections.
+	parameters map[string]*yaml.Node
+	mappings   *yaml.Node
+	conditions map[string]*yaml.Node
+	// evaluated caches the values of conditions; evaluating holds those
+	// being evaluated, to detect cycles.
+	evaluated  map[string]bool
+	evaluating map[string]bool
+	// tables maps the logical IDs of table resources to their names.
+	tables map[string]string
+	// resources holds the logical IDs of every resource.
+	resources map[string]bool
+}
+
+type cfnResource struct {
+	id         string
+	node       *yaml.Node
+	typ        string
+	properties *yaml.Node
+}
+
+func (c *cfnLoader) load(doc *yaml.Node) error {
+	if doc.Kind != yaml.MappingNode {
+		return c.errorf(doc, "expected a template")
+	}
+	var resources []cfnResource
+	for i := 0; i < len(doc.Content); i += 2 {
+		section := resolveAlias(doc.Content[i+1])
+		switch doc.Content[i].Value {
+		case "Parameters":
+			if err := c.eachEntry(section, func(key string, value *yaml.Node) error {
+				c.parameters[key] = value
+				return nil
+			}); err != nil {
+				return err
+			}
+		case "Mappings":
+			c.mappings = section
+		case "Conditions":
+			if err := c.eachEntry(section, func(key string, value *yaml.Node) error {
+				c.conditions[key] = value
+				return nil
+			}); err != nil {
+				return err
+			}
+		case "Resources":
+			if err := c.eachEntry(section, func(key string, value *yaml.Node) error {
+				resource, err := c.resource(key, value)
+				resources = append(resources, resource)
+				c.resources[key] = true
+				return err
+			}); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Name every table first, so that tables can refer to each other.
+	var included []cfnResource
+	for _, resource := range resources {
+		if resource.typ != cfnTable && resource.typ != cfnGlobalTable && resource.typ != cfnSimpleTable {
+			continue
+		}
+		condition := yamlField(resource.node, "Condition")
+		if condition != nil {
+			ok, err := c.condition(condition, condition.Value)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				continue
+			}
+		}
+		name := c.opts.StackName + "-" + resource.id
+		if tableName := yamlField(resource.properties, "TableName"); tableName != nil {
+			v, err := c.resolve(tableName)
+			if err != nil {
+				return err
+			}
+			if s, ok := cfnString(v); ok {
+				name = s
+			}
+		}
+		c.tables[resource.id] = name
+		included = append(included, resource)
+	}
+
+	for _, resource := range included {
+		if err := c.createTable(resource); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *cfnLoader) eachEntry(n *yaml.Node, f func(key string, value *yaml.Node) error) error {
+	if n.Kind != yaml.MappingNode {
+		return c.errorf(n, "expected a mapping")
+	}
+	for i := 0; i < len(n.Content); i += 2 {
+		if err := f(n.Content[i].Value, resolveAlias(n.Content[i+1])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *cfnLoader) resource(id string, n *yaml.Node) (cfnResource, error) {
+	resource := cfnResource{id: id, node: n, typ: "", properties: nil}
+	if n.Kind != yaml.MappingNode {
+		return resource, c.errorf(n, "expected a resource")
+	}
+	if typ := yamlField(n, "Type"); typ != nil {
+		resource.typ = typ.Value
+	}
+	resource.properties = yamlField(n, "Properties")
+	if resource.properties == nil {
+		resource.properties = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Line: n.Line, Column: n.Column} //nolint:exhaustruct
+	}
+	return resource, nil
+}
+
+// intrinsic returns the name and argument of the intrinsic function called by
+// n, if any.
+func (c *cfnLoader) intrinsic(n *yaml.Node) (string, *yaml.Node, bool) {
+	if strings.HasPrefix(n.Tag, "!") && !strings.HasPrefix(n.Tag, "!!") {
+		fn := strings.TrimPrefix(n.Tag, "!")
+		if fn != "Ref" && fn != "Condition" {
+			fn = "Fn::" + fn
+		}
+		arg := *n
+		arg.Tag = ""
+		if arg.Kind == yaml.ScalarNode {
+			arg.Tag = "!!str"
+		}
+		return fn, &arg, true
+	}
+	if n.Kind == yaml.MappingNode && len(n.Content) == 2 {
+		key := n.Content[0].Value
+		if key == "Ref" || key == "Condition" || strings.HasPrefix(key, "Fn::") {
+			return key, resolveAlias(n.Content[1]), true
+		}
+	}
+	return "", nil, false
+}
+
+// resolve evaluates the intrinsic functions in n, returning a value which
+// encodes as the equivalent JSON, or cfnNoValue.
+func (c *cfnLoader) resolve(n *yaml.Node) (any, error) {
+	n = resolveAlias(n)
+	if fn, arg, ok := c.intrinsic(n); ok {
+		return c.call(n, fn, arg)
+	}
+	switch n.Kind {
+	case yaml.MappingNode:
+		m := make(map[string]any, len(n.Content)/2)
+		for i := 0; i < len(n.Content); i += 2 {
+			v, err := c.resolve(n.Content[i+1])
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := v.(cfnNoValue); !ok {
+				m[n.Content[i].Value] = v
+			}
+		}
+		return m, nil
+	case yaml.SequenceNode:
+		return c.resolveList(n)
+	}
+	return c.plainValue(n)
+}
+
+func (c *cfnLoader) resolveList(n *yaml.Node) ([]any, error) {
+	if n.Kind != yaml.SequenceNode {
+		v, err := c.resolve(n)
+		if err != nil {
+			return nil, err
+		}
+		list, ok := v.([]any)
+		if !ok {
+			return nil, c.errorf(n, "expected a list")
+		}
+		return list, nil
+	}
+	list := make([]any, 0, len(n.Content))
+	for _, child := range n.Content {
+		v, err := c.resolve(child)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := v.(cfnNoValue); !ok {
+			list = append(list, v)
+		}
+	}
+	return list, nil
+}
+
+func (c *cfnLoader) resolveString(n *yaml.Node) (string, error) {
+	v, err := c.resolve(n)
+	if err != nil {
+		return "", err
+	}
+	s, ok := cfnString(v)
+	if !ok {
+		return "", c.errorf(n, "expected a string")
+	}
+	return s, nil
+}
+
+func cfnString(v any) (string, bool) {
+	switch v := v.(type) {
+	case string:
+		return v, true
+	case json.Number:
+		return v.String(), true
+	case bool:
+		return strconv.FormatBool(v), true
+	}
+	return "", false
+}
+
+// args checks that arg is a list of the given length.
+func (c *cfnLoader) args(fn string, arg *yaml.Node, n int) ([]*yaml.Node, error) {
+	if arg.Kind != yaml.SequenceNode || len(arg.Content) != n {
+		return nil, c.errorf(arg, "%s expects a list of %d arguments", fn, n)
+	}
+	args := make([]*yaml.Node, n)
+	for i, child := range arg.Content {
+		args[i] = resolveAlias(child)
+	}
+	return args, nil
+}
+
+func (c *cfnLoader) call(n *yaml.Node, fn string, arg *yaml.Node) (any, error) {
+	switch fn {
+	case "Ref":
+		return c.ref(arg, arg.Value)
+	case "Condition":
+		return c.condition(arg, arg.Value)
+	case "Fn::Base64":
+		s, err := c.resolveString(arg)
+		return base64.StdEncoding.EncodeToString([]byte(s)), err
+	case "Fn::Sub":
+		return c.sub(arg)
+	case "Fn::Join":
+		args, err := c.args(fn, arg, 2)
+		if err != nil {
+			return nil, err
+		}
+		list, err := c.resolveList(args[1])
+		if err != nil {
+			return nil, err
+		}
+		parts := make([]string, len(list))
+		for i, v := range list {
+			var ok bool
+			if parts[i], ok = cfnString(v); !ok {
+				return nil, c.errorf(args[1], "Fn::Join can only join strings")
+			}
+		}
+		return strings.Join(parts, args[0].Value), nil
+	case "Fn::Select":
+		args, err := c.args(fn, arg, 2)
+		if err != nil {
+			return nil, err
+		}
+		index, err := c.resolveString(args[0])
+		if err != nil {
+			return nil, err
+		}
+		list, err := c.resolveList(args[1])
+		if err != nil {
+			return nil, err
+		}
+		i, err := strconv.Atoi(index)
+		if err != nil || i < 0 || i >= len(list) {
+			return nil, c.errorf(args[0], "Fn::Select index %s is out of range", index)
+		}
+		return list[i], nil
+	case "Fn::Split":
+		args, err := c.args(fn, arg, 2)
+		if err != nil {
+			return nil, err
+		}
+		s, err := c.resolveString(args[1])
+		if err != nil {
+			return nil, err
+		}
+		var list []any
+		for _, part := range strings.Split(s, args[0].Value) {
+			list = append(list, part)
+		}
+		return list, nil
+	case "Fn::FindInMap":
+		args, err := c.args(fn, arg, 3)
+		if err != nil {
+			return nil, err
+		}
+		value := c.mappings
+		for _, key := range args {
+			name, err := c.resolveString(key)
+			if err != nil {
+				return nil, err
+			}
+			if value = yamlField(value, name); value == nil {
+				return nil, c.errorf(key, "mapping has no key %s", name)
+			}
+		}
+		return c.resolve(value)
+	case "Fn::If":
+		args, err := c.args(fn, arg, 3)
+		if err != nil {
+			return nil, err
+		}
+		ok, err := c.condition(args[0], args[0].Value)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return c.resolve(args[1])
+		}
+		return c.resolve(args[2])
+	case "Fn::Equals":
+		args, err := c.args(fn, arg, 2)
+		if err != nil {
+			return nil, err
+		}
+		a, err := c.resolveString(args[0])
+		if err != nil {
+			return nil, err
+		}
+		b, err := c.resolveString(args[1])
+		return a == b, err
+	case "Fn::Not", "Fn::And", "Fn::Or":
+		if arg.Kind != yaml.SequenceNode {
+			return nil, c.errorf(arg, "%s expects a list of conditions", fn)
+		}
+		result := fn == "Fn::And"
+		for _, child := range arg.Content {
+			v, err := c.resolve(child)
+			if err != nil {
+				return nil, err
+			}
+			b, ok := v.(bool)
+			if !ok {
+				return nil, c.errorf(child, "expected a condition")
+			}
+			switch fn {
+			case "Fn::Not":
+				return !b, nil
+			case "Fn::And":
+				result = result && b
+			case "Fn::Or":
+				result = result || b
+			}
+		}
+		return result, nil
+	}
+	return nil, c.errorf(n, "unsupported intrinsic function %s", fn)
+}
+
+// ref evaluates a Ref to a parameter, pseudo parameter or table.
+func (c *cfnLoader) ref(n *yaml.Node, name string) (any, error) {
+	switch name {
+	case "AWS::Region":
+		return c.d.region, nil
+	case "AWS::AccountId":
+		return c.d.accountID, nil
+	case "AWS::StackName":
+		return c.opts.StackName, nil
+	case "AWS::Partition":
+		return "aws", nil
+	case "AWS::URLSuffix":
+		return "amazonaws.com", nil
+	case "AWS::NoValue":
+		return cfnNoValue{}, nil
+	}
+	if tableName, ok := c.tables[name]; ok {
+		return tableName, nil
+	}
+	if c.resources[name] {
+		return nil, c.errorf(n, "Ref to resource %s, which isn't a table, is unsupported", name)
+	}
+
+	parameter, ok := c.parameters[name]
+	if !ok {
+		return nil, c.errorf(n, "Ref to unknown parameter %s", name)
+	}
+	value, ok := c.opts.Parameters[name]
+	if !ok {
+		def := yamlField(parameter, "Default")
+		if def == nil {
+			return nil, c.errorf(n, "parameter %s has no value", name)
+		}
+		v, err := c.resolve(def)
+		if err != nil {
+			return nil, err
+		}
+		if value, ok = cfnString(v); !ok {
+			return v, nil
+		}
+	}
+	if typ := yamlField(parameter, "Type"); typ != nil && (typ.Value == "CommaDelimitedList" || strings.HasPrefix(typ.Value, "List<")) {
+		var list []any
+		for _, part := range strings.Split(value, ",") {
+			list = append(list, strings.TrimSpace(part))
+		}
+		return list, nil
+	}
+	return value, nil
+}
+
+// condition evaluates the named condition.
+func (c *cfnLoader) condition(n *yaml.Node, name string) (bool, error) {
+	if value, ok := c.evaluated[name]; ok {
+		return value, nil
+	}
+	definition, ok := c.conditions[name]
+	if !ok {
+		return false, c.errorf(n, "unknown condition %s", name)
+	}
+	if c.evaluating[name] {
+		return false, c.errorf(n, "condition %s depends on itself", name)
+	}
+	c.evaluating[name] = true
+	v, err := c.resolve(definition)
+	delete(c.evaluating, name)
+	if err != nil {
+		return false, err
+	}
+	value, ok := v.(bool)
+	if !ok {
+		return false, c.errorf(definition, "condition %s isn't a condition function", name)
+	}
+	c.evaluated[name] = value
+	return value, nil
+}
+
+// cfnSubVariable matches the variables substituted by Fn::Sub.
+var cfnSubVariable = regexp.MustCompile(`\$\{([^}]*)\}`)
+
+func (c *cfnLoader) sub(arg *yaml.Node) (any, error) {
+	template := arg
+	variables := map[string]any{}
+	if arg.Kind == yaml.SequenceNode {
+		args, err := c.args("Fn::Sub", arg, 2)
+		if err != nil {
+			return nil, err
+		}
+		template = args[0]
+		v, err := c.resolve(args[1])
+		if err != nil {
+			return nil, err
+		}
+		var ok bool
+		if variables, ok = v.(map[string]any); !ok {
+			return nil, c.errorf(args[1], "Fn::Sub variables must be a mapping")
+		}
+	}
+	if template.Kind != yaml.ScalarNode {
+		return nil, c.errorf(template, "Fn::Sub expects a string")
+	}
+
+	var errs []error
+	result := cfnSubVariable.ReplaceAllStringFunc(template.Value, func(match string) string {
+		name := match[2 : len(match)-1]
+		if literal, ok := strings.CutPrefix(name, "!"); ok {
+			return "${" + literal + "}"
+		}
+		v, ok := variables[name]
+		if !ok {
+			var err error
+			if v, err = c.ref(template, name); err != nil {
+				errs = append(errs, err)
+				return match
+			}
+		}
+		s, ok := cfnString(v)
+		if !ok {
+			errs = append(errs, c.errorf(template, "can't substitute %s into a string", name))
+		}
+		return s
+	})
+	if len(errs) > 0 {
+		return nil, errs[0]
+	}
+	return result, nil
+}
+
+// cfnInt is an integer, which templates may write as a string.
+type cfnInt int64
+
+func (i *cfnInt) UnmarshalJSON(data []byte) error {
+	n, err := strconv.ParseInt(strings.Trim(string(data), `"`), 10, 64)
+	*i = cfnInt(n)
+	return err
+}
+
+func (i *cfnInt) ptr() *int64 {
+	if i == nil {
+		return nil
+	}
+	return ptr(int64(*i))
+}
+
+// cfnBool is a boolean, which templates may write as a string.
+type cfnBool bool
+
+func (b *cfnBool) UnmarshalJSON(data []byte) error {
+	v, err := strconv.ParseBool(strings.Trim(string(data), `"`))
+	*b = cfnBool(v)
+	return err
+}
+
+func (b *cfnBool) ptr() *bool {
+	if b == nil {
+		return nil
+	}
+	return ptr(bool(*b))
+}
+
+// cfnTableProperties holds the properties of AWS::DynamoDB::Table and
+// AWS::DynamoDB::GlobalTable resources which we translate.
+type cfnTableProperties struct {
+	TableName               *string
+	KeySchema               []*dynamodb.KeySchemaElement
+	AttributeDefinitions    []*dynamodb.AttributeDefinition
+	BillingMode             *string
+	ProvisionedThroughput   *cfnThroughput
+	OnDemandThroughput      *cfnOnDemandThroughput
+	GlobalSecondaryIndexes  []cfnIndex
+	LocalSecondaryIndexes   []cfnIndex
+	StreamSpecification     *struct{ StreamViewType *string }
+	SSESpecification        *cfnSSESpecification
+	TimeToLiveSpecification *cfnTimeToLiveSpecification
+	cfnReplicaProperties
+	Replicas                           []cfnReplica
+	WriteProvisionedThroughputSettings *cfnWriteSettings
+}
+
+// cfnReplicaProperties are set per table, or for a global table per replica.
+type cfnReplicaProperties struct {
+	TableClass                       *string
+	DeletionProtectionEnabled        *cfnBool
+	Tags                             []*dynamodb.Tag
+	ResourcePolicy                   *struct{ PolicyDocument json.RawMessage }
+	PointInTimeRecoverySpecification *struct{ PointInTimeRecoveryEnabled *cfnBool }
+}
+
+type cfnReplica struct {
+	Region string
+	cfnReplicaProperties
+	ReadProvisionedThroughputSettings *cfnReadSettings
+	GlobalSecondaryIndexes            []struct {
+		IndexName                         string
+		ReadProvisionedThroughputSettings *cfnReadSettings
+	}
+}
+
+type cfnThroughput struct {
+	ReadCapacityUnits  cfnInt
+	WriteCapacityUnits cfnInt
+}
+
+func (t *cfnThroughput) sdk() *dynamodb.ProvisionedThroughput {
+	if t == nil {
+		return nil
+	}
+	return &dynamodb.ProvisionedThroughput{
+		ReadCapacityUnits:  ptr(int64(t.ReadCapacityUnits)),
+		WriteCapacityUnits: ptr(int64(t.WriteCapacityUnits)),
+	}
+}
+
+type cfnOnDemandThroughput struct {
+	MaxReadRequestUnits  *cfnInt
+	MaxWriteRequestUnits *cfnInt
+}
+
+func (t *cfnOnDemandThroughput) sdk() *dynamodb.OnDemandThroughput {
+	if t == nil {
+		return nil
+	}
+	return &dynamodb.OnDemandThroughput{
+		MaxReadRequestUnits:  t.MaxReadRequestUnits.ptr(),
+		MaxWriteRequestUnits: t.MaxWriteRequestUnits.ptr(),
+	}
+}
+
+// cfnReadSettings and cfnWriteSettings hold a global table's provisioned
+// throughput. We use the autoscaling minimum as the fixed capacity.
+type cfnReadSettings struct {
+	ReadCapacityUnits               *cfnInt
+	ReadCapacityAutoScalingSettings *struct{ MinCapacity cfnInt }
+}
+
+func (s *cfnReadSettings) units() int64 {
+	switch {
+	case s == nil:
+		return 1
+	case s.ReadCapacityUnits != nil:
+		return int64(*s.ReadCapacityUnits)
+	case s.ReadCapacityAutoScalingSettings != nil:
+		return int64(s.ReadCapacityAutoScalingSettings.MinCapacity)
+	}
+	return 1
+}
+
+type cfnWriteSettings struct {
+	WriteCapacityAutoScalingSettings *struct{ MinCapacity cfnInt }
+}
+
+func (s *cfnWriteSettings) units() int64 {
+	if s == nil || s.WriteCapacityAutoScalingSettings == nil {
+		return 1
+	}
+	return int64(s.WriteCapacityAutoScalingSettings.MinCapacity)
+}
+
+type cfnIndex struct {
+	IndexName                          *string
+	KeySchema                          []*dynamodb.KeySchemaElement
+	Projection                         *dynamodb.Projection
+	ProvisionedThroughput              *cfnThroughput
+	OnDemandThroughput                 *cfnOnDemandThroughput
+	WriteProvisionedThroughputSettings *cfnWriteSettings
+}
+
+type cfnSSESpecification struct {
+	SSEEnabled     *cfnBool
+	SSEType        *string
+	KMSMasterKeyId *string
+}
+
+type cfnTimeToLiveSpecification struct {
+	AttributeName *string
+	Enabled       cfnBool
+}
+
+// cfnSimpleTableProperties holds the properties of an
+// AWS::Serverless::SimpleTable.
+type cfnSimpleTableProperties struct {
+	TableName  *string
+	PrimaryKey *struct {
+		Name string
+		Type string
+	}
+	ProvisionedThroughput *cfnThroughput
+	SSESpecification      *cfnSSESpecification
+	Tags                  map[string]string
+}
+
+func (c *cfnLoader) createTable(resource cfnResource) error {
+	v, err := c.resolve(resource.properties)
+	if err != nil {
+		return err
+	}
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		return c.wrap(resource.properties, err)
+	}
+	name := c.tables[resource.id]
+
+	var input *dynamodb.CreateTableInput
+	var replica *cfnReplica
+	var props cfnTableProperties
+	switch resource.typ {
+	case cfnSimpleTable:
+		var simple cfnSimpleTableProperties
+		if err := json.Unmarshal(encoded, &simple); err != nil {
+			return c.wrap(resource.properties, err)
+		}
+		input = simpleTableInput(name, &simple)
+	default:
+		if err := json.Unmarshal(encoded, &props); err != nil {
+			return c.wrap(resource.properties, err)
+		}
+		replica = &cfnReplica{cfnReplicaProperties: props.cfnReplicaProperties} //nolint:exhaustruct
+		if resource.typ == cfnGlobalTable {
+			if replica = props.replica(c.d.region); replica == nil {
+				return c.errorf(resource.node, "global table %s has no replica in region %s", resource.id, c.d.region)
+			}
+		}
+		input = props.createTableInput(name, resource.typ == cfnGlobalTable, replica)
+	}
+
+	if _, err := c.d.CreateTable(input); err != nil {
+		return c.wrap(resource.node, err)
+	}
+	if replica == nil {
+		return nil
+	}
+	if err := c.applyReplicaSettings(c.d, name, props.TimeToLiveSpecification, replica); err != nil {
+		return c.wrap(resource.node, err)
+	}
+
+	if resource.typ != cfnGlobalTable || c.d.regions == nil {
+		return nil
+	}
+	for i := range props.Replicas {
+		other := &props.Replicas[i]
+		if other.Region == c.d.region {
+			continue
+		}
+		_, err := c.d.UpdateTable(&dynamodb.UpdateTableInput{ //nolint:exhaustruct
+			TableName: &name,
+			ReplicaUpdates: []*dynamodb.ReplicationGroupUpdate{{ //nolint:exhaustruct
+				Create: &dynamodb.CreateReplicationGroupMemberAction{RegionName: ptr(other.Region)}, //nolint:exhaustruct
+			}},
+		})
+		if err == nil {
+			err = c.applyReplicaSettings(c.d.regions.DB(other.Region), name, props.TimeToLiveSpecification, other)
+		}
+		if err != nil {
+			return c.wrap(resource.node, err)
+		}
+	}
+	return nil
+}
+
+func (p *cfnTableProperties) replica(region string) *cfnReplica {
+	for i := range p.Replicas {
+		if p.Replicas[i].Region == region {
+			return &p.Replicas[i]
+		}
+	}
+	return nil
+}
+
+func (p *cfnTableProperties) createTableInput(name string, global bool, replica *cfnReplica) *dynamodb.CreateTableInput {
+	input := &dynamodb.CreateTableInput{ //nolint:exhaustruct
+		AttributeDefinitions:      p.AttributeDefinitions,
+		BillingMode:               p.BillingMode,
+		DeletionProtectionEnabled: replica.DeletionProtectionEnabled.ptr(),
+		KeySchema:                 p.KeySchema,
+		OnDemandThroughput:        p.OnDemandThroughput.sdk(),
+		ProvisionedThroughput:     p.ProvisionedThroughput.sdk(),
+		SSESpecification:          p.SSESpecification.sdk(),
+		TableClass:                replica.TableClass,
+		TableName:                 &name,
+		Tags:                      replica.Tags,
+	}
+	provisioned := global && valOr(p.BillingMode, dynamodb.BillingModeProvisioned) == dynamodb.BillingModeProvisioned
+	if global {
+		input.BillingMode = ptr(valOr(p.BillingMode, dynamodb.BillingModeProvisioned))
+	}
+	if provisioned {
+		input.ProvisionedThroughput = &dynamodb.ProvisionedThroughput{
+			ReadCapacityUnits:  ptr(replica.ReadProvisionedThroughputSettings.units()),
+			WriteCapacityUnits: ptr(p.WriteProvisionedThroughputSettings.units()),
+		}
+	}
+	if p.StreamSpecification != nil {
+		input.StreamSpecification = &dynamodb.StreamSpecification{
+			StreamEnabled:  ptr(true),
+			StreamViewType: p.StreamSpecification.StreamViewType,
+		}
+	}
+	if replica.ResourcePolicy != nil {
+		input.ResourcePolicy = ptr(string(replica.ResourcePolicy.PolicyDocument))
+	}
+
+	for _, index := range p.GlobalSecondaryIndexes {
+		gsi := &dynamodb.GlobalSecondaryIndex{
+			IndexName:             index.IndexName,
+			KeySchema:             index.KeySchema,
+			OnDemandThroughput:    index.OnDemandThroughput.sdk(),
+			Projection:            index.Projection,
+			ProvisionedThroughput: index.ProvisionedThroughput.sdk(),
+		}
+		if provisioned {
+			var read *cfnReadSettings
+			for _, replicaIndex := range replica.GlobalSecondaryIndexes {
+				if replicaIndex.IndexName == val(index.IndexName) {
+					read = replicaIndex.ReadProvisionedThroughputSettings
+				}
+			}
+			gsi.ProvisionedThroughput = &dynamodb.ProvisionedThroughput{
+				ReadCapacityUnits:  ptr(read.units()),
+				WriteCapacityUnits: ptr(index.WriteProvisionedThroughputSettings.units()),
+			}
+		}
+		input.GlobalSecondaryIndexes = append(input.GlobalSecondaryIndexes, gsi)
+	}
+	for _, index := range p.LocalSecondaryIndexes {
+		input.LocalSecondaryIndexes = append(input.LocalSecondaryIndexes, &dynamodb.LocalSecondaryIndex{
+			IndexName:  index.IndexName,
+			KeySchema:  index.KeySchema,
+			Projection: index.Projection,
+		})
+	}
+	return input
+}
+
+func (s *cfnSSESpecification) sdk() *dynamodb.SSESpecification {
+	if s == nil {
+		return nil
+	}
+	return &dynamodb.SSESpecification{
+		Enabled:        s.SSEEnabled.ptr(),
+		KMSMasterKeyId: s.KMSMasterKeyId,
+		SSEType:        s.SSEType,
+	}
+}
+
+func simpleTableInput(name string, p *cfnSimpleTableProperties) *dynamodb.CreateTableInput {
+	key, typ := "id", "String"
+	if p.PrimaryKey != nil {
+		key, typ = p.PrimaryKey.Name, p.PrimaryKey.Type
+	}
+	attributeType := map[string]string{
+		"String": dynamodb.ScalarAttributeTypeS,
+		"Number": dynamodb.ScalarAttributeTypeN,
+		"Binary": dynamodb.ScalarAttributeTypeB,
+	}[typ]
+	input := &dynamodb.CreateTableInput{ //nolint:exhaustruct
+		AttributeDefinitions: []*dynamodb.AttributeDefinition{{
+			AttributeName: &key,
+			AttributeType: &attributeType,
+		}},
+		BillingMode: ptr(dynamodb.BillingModePayPerRequest),
+		KeySchema: []*dynamodb.KeySchemaElement{{
+			AttributeName: &key,
+			KeyType:       ptr(dynamodb.KeyTypeHash),
+		}},
+		SSESpecification: p.SSESpecification.sdk(),
+		TableName:        &name,
+	}
+	if p.ProvisionedThroughput != nil {
+		input.BillingMode = ptr(dynamodb.BillingModeProvisioned)
+		input.ProvisionedThroughput = p.ProvisionedThroughput.sdk()
+	}
+	for key, value := range p.Tags {
+		input.Tags = append(input.Tags, &dynamodb.Tag{Key: ptr(key), Value: ptr(value)})
+	}
+	return input
+}
+
+// applyReplicaSettings enables Time to Live and point-in-time recovery for a
+// table, if configured. If the table is a replica in another DB, its tags are
+// applied too.
+func (c *cfnLoader) applyReplicaSettings(db *DB, name string, ttl *cfnTimeToLiveSpecification, replica *cfnReplica) error {
+	var ttlAttribute string
+	if ttl != nil && ttl.Enabled {
+		ttlAttribute = val(ttl.AttributeName)
+	}
+	pitr := replica.PointInTimeRecoverySpecification
+	err := db.enableTableSettings(name, ttlAttribute, pitr != nil && val(pitr.PointInTimeRecoveryEnabled.ptr()))
+	if err != nil || len(replica.Tags) == 0 || db == c.d {
+		return err
+	}
+	_, err = db.TagResource(&dynamodb.TagResourceInput{
+		ResourceArn: ptr(db.tableArn(name)),
+		Tags:        replica.Tags,
+	})
+	return err
+}
+
+// enableTableSettings enables Time to Live on the given attribute, unless it
+// is empty, and point-in-time recovery if pitr is set, for a table created by
+// one of the loaders.
+func (d *DB) enableTableSettings(name, ttlAttribute string, pitr bool) error {
+	if ttlAttribute != "" {
+		_, err := d.UpdateTimeToLive(&dynamodb.UpdateTimeToLiveInput{
+			TableName: &name,
+			TimeToLiveSpecification: &dynamodb.TimeToLiveSpecification{
+				AttributeName: &ttlAttribute,
+				Enabled:       ptr(true),
+			},
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if pitr {
+		_, err := d.UpdateContinuousBackups(&dynamodb.UpdateContinuousBackupsInput{
+			PointInTimeRecoverySpecification: &dynamodb.PointInTimeRecoverySpecification{
+				PointInTimeRecoveryEnabled: ptr(true),
+			},
+			TableName: &name,
+		})
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,206 @@
+package fakedynamo_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/DMRobertson/fakedynamo"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const exampleTemplate = `
+AWSTemplateFormatVersion: "2010-09-09"
+Transform: AWS::Serverless-2016-10-31
+Parameters:
+  Env:
+    Type: String
+    Default: dev
+  Capacity:
+    Type: Number
+    Default: 5
+Conditions:
+  IsProd: !Equals [!Ref Env, prod]
+Resources:
+  Orders:
+    Type: AWS::DynamoDB::Table
+    Properties:
+      TableName: !Sub "orders-${Env}"
+      BillingMode: !If [IsProd, PAY_PER_REQUEST, PROVISIONED]
+      ProvisionedThroughput: !If
+        - IsProd
+        - !Ref AWS::NoValue
+        - {ReadCapacityUnits: !Ref Capacity, WriteCapacityUnits: 2}
+      KeySchema:
+        - {AttributeName: pk, KeyType: HASH}
+        - {AttributeName: sk, KeyType: RANGE}
+      AttributeDefinitions:
+        - {AttributeName: pk, AttributeType: S}
+        - {AttributeName: sk, AttributeType: S}
+        - {AttributeName: customer, AttributeType: S}
+      GlobalSecondaryIndexes:
+        - IndexName: ByCustomer
+          KeySchema: [{AttributeName: customer, KeyType: HASH}]
+          Projection: {ProjectionType: KEYS_ONLY}
+          ProvisionedThroughput: {ReadCapacityUnits: "1", WriteCapacityUnits: "1"}
+      StreamSpecification: {StreamViewType: NEW_AND_OLD_IMAGES}
+      TimeToLiveSpecification: {AttributeName: expires, Enabled: true}
+      PointInTimeRecoverySpecification: {PointInTimeRecoveryEnabled: "true"}
+      Tags:
+        - {Key: env, Value: !Ref Env}
+  Audit:
+    Type: AWS::DynamoDB::Table
+    Condition: IsProd
+    Properties:
+      KeySchema: [{AttributeName: id, KeyType: HASH}]
+      AttributeDefinitions: [{AttributeName: id, AttributeType: S}]
+  Sessions:
+    Type: AWS::Serverless::SimpleTable
+    Properties:
+      PrimaryKey: {Name: token, Type: String}
+      Tags: {env: !Ref Env}
+  Queue:
+    Type: AWS::SQS::Queue
+`
+
+func TestDB_LoadCloudFormationTemplate(t *testing.T) {
+	t.Parallel()
+	db := fakedynamo.NewDB()
+	tables, err := db.LoadCloudFormationTemplate("template.yaml", strings.NewReader(exampleTemplate), fakedynamo.CloudFormationOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"Orders": "orders-dev", "Sessions": "stack-Sessions"}, tables)
+
+	described, err := db.DescribeTable(&dynamodb.DescribeTableInput{TableName: ptr("orders-dev")})
+	require.NoError(t, err)
+	table := described.Table
+	assert.Equal(t, int64(5), val(table.ProvisionedThroughput.ReadCapacityUnits))
+	assert.Equal(t, int64(2), val(table.ProvisionedThroughput.WriteCapacityUnits))
+	assert.Len(t, table.KeySchema, 2)
+	require.Len(t, table.GlobalSecondaryIndexes, 1)
+	assert.Equal(t, "ByCustomer", val(table.GlobalSecondaryIndexes[0].IndexName))
+	assert.Equal(t, dynamodb.StreamViewTypeNewAndOldImages, val(table.StreamSpecification.StreamViewType))
+	assert.Equal(t, map[string]string{"env": "dev"}, listAllTags(t, db, table.TableArn))
+
+	ttl, err := db.DescribeTimeToLive(&dynamodb.DescribeTimeToLiveInput{TableName: ptr("orders-dev")})
+	require.NoError(t, err)
+	assert.Equal(t, "expires", val(ttl.TimeToLiveDescription.AttributeName))
+	backups, err := db.DescribeContinuousBackups(&dynamodb.DescribeContinuousBackupsInput{TableName: ptr("orders-dev")})
+	require.NoError(t, err)
+	assert.Equal(t, dynamodb.PointInTimeRecoveryStatusEnabled,
+		val(backups.ContinuousBackupsDescription.PointInTimeRecoveryDescription.PointInTimeRecoveryStatus))
+
+	sessions, err := db.DescribeTable(&dynamodb.DescribeTableInput{TableName: ptr("stack-Sessions")})
+	require.NoError(t, err)
+	assert.Equal(t, "token", val(sessions.Table.KeySchema[0].AttributeName))
+}
+
+func TestDB_LoadCloudFormationTemplate_ParameterOverrides(t *testing.T) {
+	t.Parallel()
+	db := fakedynamo.NewDB()
+	tables, err := db.LoadCloudFormationTemplate("template.yaml", strings.NewReader(exampleTemplate), fakedynamo.CloudFormationOptions{
+		Parameters: map[string]string{"Env": "prod"},
+		StackName:  "app",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"Orders":   "orders-prod",
+		"Audit":    "app-Audit",
+		"Sessions": "app-Sessions",
+	}, tables)
+
+	described, err := db.DescribeTable(&dynamodb.DescribeTableInput{TableName: ptr("orders-prod")})
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), val(described.Table.ProvisionedThroughput.ReadCapacityUnits))
+}
+
+func TestDB_LoadCloudFormationTemplate_CDKOutput(t *testing.T) {
+	t.Parallel()
+	db := fakedynamo.NewDB(fakedynamo.WithRegion("eu-west-1"))
+	tables, err := db.LoadCloudFormationTemplate("template.json", strings.NewReader(`{
+  "Resources": {
+    "Table8235A42E": {
+      "Type": "AWS::DynamoDB::Table",
+      "Properties": {
+        "TableName": {"Fn::Join": ["-", ["things", {"Ref": "AWS::Region"}]]},
+        "KeySchema": [{"AttributeName": "id", "KeyType": "HASH"}],
+        "AttributeDefinitions": [{"AttributeName": "id", "AttributeType": "S"}],
+        "BillingMode": "PAY_PER_REQUEST"
+      },
+      "UpdateReplacePolicy": "Retain",
+      "DeletionPolicy": "Retain"
+    }
+  }
+}`), fakedynamo.CloudFormationOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"Table8235A42E": "things-eu-west-1"}, tables)
+}
+
+func TestDB_LoadCloudFormationTemplate_GlobalTable(t *testing.T) {
+	t.Parallel()
+	regions := fakedynamo.NewRegions([]string{"us-east-1", "eu-west-1"})
+	db := regions.DB("us-east-1")
+	_, err := db.LoadCloudFormationTemplate("template.yaml", strings.NewReader(`
+Resources:
+  Global:
+    Type: AWS::DynamoDB::GlobalTable
+    Properties:
+      TableName: global
+      BillingMode: PAY_PER_REQUEST
+      KeySchema: [{AttributeName: id, KeyType: HASH}]
+      AttributeDefinitions: [{AttributeName: id, AttributeType: S}]
+      StreamSpecification: {StreamViewType: NEW_AND_OLD_IMAGES}
+      Replicas:
+        - Region: us-east-1
+          Tags: [{Key: home, Value: "yes"}]
+        - Region: eu-west-1
+          Tags: [{Key: home, Value: "no"}]
+`), fakedynamo.CloudFormationOptions{})
+	require.NoError(t, err)
+
+	for region, home := range map[string]string{"us-east-1": "yes", "eu-west-1": "no"} {
+		described, err := regions.DB(region).DescribeTable(&dynamodb.DescribeTableInput{TableName: ptr("global")})
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"home": home}, listAllTags(t, regions.DB(region), described.Table.TableArn))
+	}
+}
+
+func TestDB_LoadCloudFormationTemplate_Errors(t *testing.T) {
+	t.Parallel()
+	for _, test := range []struct {
+		name     string
+		template string
+		line     int
+		message  string
+	}{
+		{
+			"unknown parameter",
+			"Resources:\n  T:\n    Type: AWS::DynamoDB::Table\n    Properties:\n      TableName: !Ref Missing",
+			5, "unknown parameter Missing",
+		},
+		{
+			"unsupported function",
+			"Resources:\n  T:\n    Type: AWS::DynamoDB::Table\n    Properties:\n      TableName: x\n      Tags:\n        - {Key: a, Value: !GetAtt Other.Arn}",
+			7, "unsupported intrinsic function Fn::GetAtt",
+		},
+		{
+			"invalid table",
+			"Resources:\n  T:\n    Type: AWS::DynamoDB::Table\n    Properties:\n      TableName: x",
+			3, "KeySchema",
+		},
+		{
+			"no local replica",
+			"Resources:\n  T:\n    Type: AWS::DynamoDB::GlobalTable\n    Properties:\n      Replicas: [{Region: mars-1}]",
+			3, "no replica in region",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := fakedynamo.NewDB().LoadCloudFormationTemplate("template.yaml", strings.NewReader(test.template), fakedynamo.CloudFormationOptions{})
+			var fixtureErr *fakedynamo.FixtureError
+			require.ErrorAs(t, err, &fixtureErr)
+			assert.Equal(t, test.line, fixtureErr.Line)
+			assertErrorContains(t, err, test.message)
+		})
+	}
+}
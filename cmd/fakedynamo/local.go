@@ -0,0 +1,126 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DMRobertson/fakedynamo"
+)
+
+// sharedInstance names the database used by all clients with -sharedDb,
+// matching DynamoDB Local's shared-local-instance.db.
+const sharedInstance = "shared-local-instance"
+
+// localServer routes requests to a database per access key ID and region, or
+// to a single shared database, like DynamoDB Local.
+type localServer struct {
+	shared    bool
+	dbPath    string // empty when running in memory
+	region    string
+	accountID string
+	s3Dir     string
+	delay     time.Duration
+
+	mu        sync.Mutex
+	instances map[string]*instance
+}
+
+// instance is one database.
+type instance struct {
+	db      *fakedynamo.DB
+	handler http.Handler
+}
+
+func (s *localServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	inst, err := s.instance(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	inst.handler.ServeHTTP(w, r)
+}
+
+// instance returns the database for the request, creating it if necessary.
+func (s *localServer) instance(r *http.Request) (*instance, error) {
+	name, region := sharedInstance, s.region
+	if !s.shared {
+		accessKeyID, requestRegion := credentialScope(r.Header.Get("Authorization"))
+		if requestRegion != "" {
+			region = requestRegion
+		}
+		name = unsafeFileChars.ReplaceAllString(accessKeyID+"_"+region, "")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if inst, ok := s.instances[name]; ok {
+		return inst, nil
+	}
+	inst, err := s.open(name, region)
+	if err != nil {
+		return nil, err
+	}
+	s.instances[name] = inst
+	return inst, nil
+}
+
+// open creates a database, loading its state from dbPath if there is one.
+func (s *localServer) open(name, region string) (*instance, error) {
+	opts := []fakedynamo.Option{
+		fakedynamo.WithRegion(region),
+		fakedynamo.WithAccountID(s.accountID),
+		fakedynamo.WithTransientStatusDelay(s.delay),
+	}
+	if s.s3Dir != "" {
+		opts = append(opts, fakedynamo.WithS3Directory(s.s3Dir))
+	}
+
+	var db *fakedynamo.DB
+	if s.dbPath == "" {
+		db = fakedynamo.NewDB(opts...)
+	} else {
+		dir := filepath.Join(s.dbPath, name)
+		var err error
+		if db, err = fakedynamo.OpenDB(dir, opts...); err != nil {
+			return nil, fmt.Errorf("opening %s: %w", dir, err)
+		}
+	}
+	return &instance{db: db, handler: fakedynamo.NewHandler(db)}, nil
+}
+
+// close closes every database, compacting their logs.
+func (s *localServer) close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var errs []error
+	for name, inst := range s.instances {
+		errs = append(errs, inst.db.Close())
+		delete(s.instances, name)
+	}
+	return errors.Join(errs...)
+}
+
+// credentialScope extracts the access key ID and region from a SigV4
+// Authorization header, which looks like
+//
+//	AWS4-HMAC-SHA256 Credential=AKID/20240101/us-east-1/dynamodb/aws4_request, ...
+func credentialScope(authorization string) (accessKeyID, region string) {
+	_, credential, found := strings.Cut(authorization, "Credential=")
+	if !found {
+		return "", ""
+	}
+	credential, _, _ = strings.Cut(credential, ",")
+	parts := strings.Split(strings.TrimSpace(credential), "/")
+	if len(parts) < 3 {
+		return parts[0], ""
+	}
+	return parts[0], parts[2]
+}
+
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9_-]`)
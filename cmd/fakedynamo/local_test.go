@@ -0,0 +1,106 @@
+package main
+
+import (
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newLocalServer(t *testing.T, dbPath string, shared bool) *httptest.Server {
+	t.Helper()
+	local := &localServer{
+		shared:    shared,
+		dbPath:    dbPath,
+		region:    "us-east-1",
+		accountID: "000000000000",
+		s3Dir:     "",
+		delay:     0,
+		mu:        sync.Mutex{},
+		instances: map[string]*instance{},
+	}
+	server := httptest.NewServer(local)
+	t.Cleanup(func() {
+		server.Close()
+		assert.NoError(t, local.close())
+	})
+	return server
+}
+
+func newClient(t *testing.T, server *httptest.Server, accessKeyID, region string) *dynamodb.DynamoDB {
+	t.Helper()
+	sess, err := session.NewSession(&aws.Config{
+		Credentials: credentials.NewStaticCredentials(accessKeyID, "secret", ""),
+		Endpoint:    aws.String(server.URL),
+		Region:      aws.String(region),
+	})
+	require.NoError(t, err)
+	return dynamodb.New(sess)
+}
+
+func createTable(t *testing.T, client *dynamodb.DynamoDB, name string) {
+	t.Helper()
+	_, err := client.CreateTable(&dynamodb.CreateTableInput{
+		AttributeDefinitions: []*dynamodb.AttributeDefinition{{
+			AttributeName: aws.String("Foo"),
+			AttributeType: aws.String(dynamodb.ScalarAttributeTypeS),
+		}},
+		BillingMode: aws.String(dynamodb.BillingModePayPerRequest),
+		KeySchema: []*dynamodb.KeySchemaElement{{
+			AttributeName: aws.String("Foo"),
+			KeyType:       aws.String(dynamodb.KeyTypeHash),
+		}},
+		TableName: aws.String(name),
+	})
+	require.NoError(t, err)
+}
+
+func listTables(t *testing.T, client *dynamodb.DynamoDB) []string {
+	t.Helper()
+	out, err := client.ListTables(&dynamodb.ListTablesInput{})
+	require.NoError(t, err)
+	return aws.StringValueSlice(out.TableNames)
+}
+
+func TestLocalServer_SeparatesDatabasesByAccessKeyAndRegion(t *testing.T) {
+	t.Parallel()
+	server := newLocalServer(t, "", false)
+	alice := newClient(t, server, "alice", "us-east-1")
+	createTable(t, alice, "alices-table")
+
+	assert.Equal(t, []string{"alices-table"}, listTables(t, newClient(t, server, "alice", "us-east-1")))
+	assert.Empty(t, listTables(t, newClient(t, server, "alice", "eu-west-1")))
+	assert.Empty(t, listTables(t, newClient(t, server, "bob", "us-east-1")))
+}
+
+func TestLocalServer_SharedDb(t *testing.T) {
+	t.Parallel()
+	server := newLocalServer(t, "", true)
+	createTable(t, newClient(t, server, "alice", "us-east-1"), "table")
+	assert.Equal(t, []string{"table"}, listTables(t, newClient(t, server, "bob", "eu-west-1")))
+}
+
+func TestLocalServer_PersistsToDbPath(t *testing.T) {
+	t.Parallel()
+	dbPath := t.TempDir()
+	server := newLocalServer(t, dbPath, false)
+	client := newClient(t, server, "alice", "us-east-1")
+	createTable(t, client, "table")
+	item := map[string]*dynamodb.AttributeValue{"Foo": {S: aws.String("foo")}}
+	_, err := client.PutItem(&dynamodb.PutItemInput{Item: item, TableName: aws.String("table")})
+	require.NoError(t, err)
+	server.Close()
+	require.NoError(t, server.Config.Handler.(*localServer).close())
+
+	restarted := newClient(t, newLocalServer(t, dbPath, false), "alice", "us-east-1")
+	got, err := restarted.GetItem(&dynamodb.GetItemInput{Key: item, TableName: aws.String("table")})
+	require.NoError(t, err)
+	assert.Equal(t, item, got.Item)
+	assert.DirExists(t, dbPath+"/alice_us-east-1")
+}
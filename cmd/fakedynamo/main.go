@@ -0,0 +1,104 @@
+// Command fakedynamo serves an in-memory fake of DynamoDB over HTTP, speaking
+// the DynamoDB JSON wire protocol. Point any AWS SDK or the AWS CLI at it with
+// an endpoint override, e.g.
+//
+//	aws dynamodb list-tables --endpoint-url http://localhost:8000
+//
+// It accepts the same command-line flags as DynamoDB Local, so that it can
+// replace DynamoDB Local's jar in scripts and docker-compose files:
+//
+//   - -port: the port to listen on. Defaults to 8000.
+//   - -inMemory: don't persist anything to disk.
+//   - -dbPath: the directory to persist databases to. Defaults to the current
+//     directory, unless -inMemory is given.
+//   - -sharedDb: use a single database for all clients. Otherwise each
+//     combination of access key ID and region gets its own database.
+//   - -delayTransientStatuses: keep tables CREATING and DELETING for a few
+//     seconds, as DynamoDB does.
+//   - -optimizeDbBeforeStartup: accepted for compatibility, but does nothing.
+//
+// Unlike DynamoDB Local, databases are persisted with fakedynamo.OpenDB, each
+// in a directory named like DynamoDB Local's database files. Only tables, with
+// their tags, resource policies and Time to Live settings, and items are
+// persisted.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// transientStatusDelay is how long tables spend CREATING and DELETING with
+// -delayTransientStatuses.
+const transientStatusDelay = 5 * time.Second
+
+func main() {
+	port := flag.Int("port", 8000, "port to listen on")
+	inMemory := flag.Bool("inMemory", false, "don't persist databases to disk")
+	dbPath := flag.String("dbPath", "", "directory to persist databases to (default: the current directory)")
+	sharedDb := flag.Bool("sharedDb", false, "use a single database, regardless of access key ID and region")
+	delayTransientStatuses := flag.Bool("delayTransientStatuses", false, "keep tables CREATING and DELETING for a while")
+	optimizeDbBeforeStartup := flag.Bool("optimizeDbBeforeStartup", false, "accepted for compatibility with DynamoDB Local; does nothing")
+	region := flag.String("region", "us-east-1", "AWS region used in ARNs, with -sharedDb")
+	accountID := flag.String("account-id", "000000000000", "AWS account ID used in ARNs")
+	s3Dir := flag.String("s3-dir", "", "local directory standing in for S3, for imports and exports")
+	flag.Parse()
+
+	if *inMemory && *dbPath != "" {
+		fail("-inMemory and -dbPath cannot be used together")
+	}
+	if *optimizeDbBeforeStartup && *dbPath == "" {
+		fail("-optimizeDbBeforeStartup requires -dbPath")
+	}
+	if !*inMemory && *dbPath == "" {
+		*dbPath = "."
+	}
+	if *inMemory {
+		*dbPath = ""
+	}
+
+	server := &localServer{
+		shared:    *sharedDb,
+		dbPath:    *dbPath,
+		region:    *region,
+		accountID: *accountID,
+		s3Dir:     *s3Dir,
+		delay:     0,
+		instances: map[string]*instance{},
+	}
+	if *delayTransientStatuses {
+		server.delay = transientStatusDelay
+	}
+
+	// Stop serving on SIGINT or SIGTERM, then close the databases so that
+	// their logs are compacted.
+	httpServer := &http.Server{Addr: fmt.Sprintf(":%d", *port), Handler: server} //nolint:exhaustruct,gosec
+	go func() {
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+		<-signals
+		_ = httpServer.Shutdown(context.Background())
+	}()
+
+	log.Printf("fakedynamo listening on %s", httpServer.Addr)
+	if err := httpServer.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		log.Fatal(err)
+	}
+	if err := server.close(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func fail(message string) {
+	fmt.Fprintln(os.Stderr, message)
+	flag.Usage()
+	os.Exit(2)
+}
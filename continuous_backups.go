@@ -0,0 +1,182 @@
+package fakedynamo
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// recoveryPeriodInDays is the length of time we keep PITR history for.
+// DynamoDB allows this to be configured, but the v1 SDK predates that option.
+const recoveryPeriodInDays = 35
+
+// pitrState tracks point-in-time recovery for a single table.
+type pitrState struct {
+	enabledAt time.Time
+
+	// changes records every write to the table since PITR was enabled, in
+	// chronological order. Entries older than the recovery period are pruned
+	// as new writes arrive.
+	changes []itemChange
+}
+
+// itemChange records a single write to an item. oldImage is nil if the write
+// created the item; newImage is nil if the write deleted the item.
+type itemChange struct {
+	at       time.Time
+	keys     avmap
+	oldImage avmap
+	newImage avmap
+}
+
+// earliestRestorable returns the earliest time we hold a full change history
+// for.
+func (p *pitrState) earliestRestorable(now time.Time) time.Time {
+	horizon := now.AddDate(0, 0, -recoveryPeriodInDays)
+	if p.enabledAt.After(horizon) {
+		return p.enabledAt
+	}
+	return horizon
+}
+
+// recordWrite notes a change to an item in the table's PITR history, if PITR
+// is enabled. Callers MUST hold the DB's write lock.
+func (t *table) recordWrite(oldImage, newImage avmap) {
+	if t.pitr == nil || (oldImage == nil && newImage == nil) {
+		return
+	}
+	now := time.Now().UTC()
+	image := newImage
+	if image == nil {
+		image = oldImage
+	}
+	t.pitr.changes = append(t.pitr.changes, itemChange{
+		at:       now,
+		keys:     t.extractKeys(image),
+		oldImage: oldImage,
+		newImage: newImage,
+	})
+
+	horizon := t.pitr.earliestRestorable(now)
+	drop := 0
+	for drop < len(t.pitr.changes) && t.pitr.changes[drop].at.Before(horizon) {
+		drop++
+	}
+	t.pitr.changes = t.pitr.changes[drop:]
+}
+
+// extractKeys returns the primary key attributes of the given item.
+func (t *table) extractKeys(item avmap) avmap {
+	keys := avmap{t.schema.partition: item[t.schema.partition]}
+	if t.schema.sort != "" {
+		keys[t.schema.sort] = item[t.schema.sort]
+	}
+	return keys
+}
+
+func (d *DB) DescribeContinuousBackups(input *dynamodb.DescribeContinuousBackupsInput) (*dynamodb.DescribeContinuousBackupsOutput, error) {
+	return d.describeContinuousBackupsContext(aws.BackgroundContext(), input)
+}
+
+func (d *DB) describeContinuousBackupsContext(ctx aws.Context, input *dynamodb.DescribeContinuousBackupsInput) (*dynamodb.DescribeContinuousBackupsOutput, error) {
+	if err := d.injectFault(ctx, "DescribeContinuousBackups", input); err != nil {
+		return nil, err
+	}
+
+	if input.TableName == nil {
+		return nil, newValidationError("TableName is a required field")
+	}
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	t, exists := d.tables.Get(tableKey(*input.TableName))
+	if !exists {
+		return nil, &dynamodb.TableNotFoundException{}
+	}
+	return &dynamodb.DescribeContinuousBackupsOutput{
+		ContinuousBackupsDescription: t.describeContinuousBackups(),
+	}, nil
+}
+
+func (t *table) describeContinuousBackups() *dynamodb.ContinuousBackupsDescription {
+	desc := &dynamodb.ContinuousBackupsDescription{
+		ContinuousBackupsStatus: ptr(dynamodb.ContinuousBackupsStatusEnabled),
+		PointInTimeRecoveryDescription: &dynamodb.PointInTimeRecoveryDescription{
+			PointInTimeRecoveryStatus: ptr(dynamodb.PointInTimeRecoveryStatusDisabled),
+		},
+	}
+	if t.pitr != nil {
+		now := time.Now().UTC()
+		desc.PointInTimeRecoveryDescription = &dynamodb.PointInTimeRecoveryDescription{
+			EarliestRestorableDateTime: ptr(t.pitr.earliestRestorable(now)),
+			LatestRestorableDateTime:   &now,
+			PointInTimeRecoveryStatus:  ptr(dynamodb.PointInTimeRecoveryStatusEnabled),
+		}
+	}
+	return desc
+}
+
+func (d *DB) DescribeContinuousBackupsWithContext(ctx aws.Context, input *dynamodb.DescribeContinuousBackupsInput, opts ...request.Option) (*dynamodb.DescribeContinuousBackupsOutput, error) {
+	req, output := d.DescribeContinuousBackupsRequest(input)
+	req.SetContext(ctx)
+	req.ApplyOptions(opts...)
+	return output, req.Send()
+}
+
+func (d *DB) DescribeContinuousBackupsRequest(input *dynamodb.DescribeContinuousBackupsInput) (*request.Request, *dynamodb.DescribeContinuousBackupsOutput) {
+	return newContextRequest(d, "DescribeContinuousBackups", input, d.describeContinuousBackupsContext)
+}
+
+func (d *DB) UpdateContinuousBackups(input *dynamodb.UpdateContinuousBackupsInput) (*dynamodb.UpdateContinuousBackupsOutput, error) {
+	return d.updateContinuousBackupsContext(aws.BackgroundContext(), input)
+}
+
+func (d *DB) updateContinuousBackupsContext(ctx aws.Context, input *dynamodb.UpdateContinuousBackupsInput) (*dynamodb.UpdateContinuousBackupsOutput, error) {
+	if err := d.injectFault(ctx, "UpdateContinuousBackups", input); err != nil {
+		return nil, err
+	}
+
+	if input.TableName == nil {
+		return nil, newValidationError("TableName is a required field")
+	}
+	spec := input.PointInTimeRecoverySpecification
+	if spec == nil {
+		return nil, newValidationError("PointInTimeRecoverySpecification is a required field")
+	} else if spec.PointInTimeRecoveryEnabled == nil {
+		return nil, newValidationError("PointInTimeRecoverySpecification.PointInTimeRecoveryEnabled is a required field")
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	t, exists := d.tables.Get(tableKey(*input.TableName))
+	if !exists {
+		return nil, &dynamodb.TableNotFoundException{}
+	}
+
+	switch {
+	case !*spec.PointInTimeRecoveryEnabled:
+		t.pitr = nil
+	case t.pitr == nil:
+		t.pitr = &pitrState{
+			enabledAt: time.Now().UTC(),
+			changes:   nil,
+		}
+	}
+
+	return &dynamodb.UpdateContinuousBackupsOutput{
+		ContinuousBackupsDescription: t.describeContinuousBackups(),
+	}, nil
+}
+
+func (d *DB) UpdateContinuousBackupsWithContext(ctx aws.Context, input *dynamodb.UpdateContinuousBackupsInput, opts ...request.Option) (*dynamodb.UpdateContinuousBackupsOutput, error) {
+	req, output := d.UpdateContinuousBackupsRequest(input)
+	req.SetContext(ctx)
+	req.ApplyOptions(opts...)
+	return output, req.Send()
+}
+
+func (d *DB) UpdateContinuousBackupsRequest(input *dynamodb.UpdateContinuousBackupsInput) (*request.Request, *dynamodb.UpdateContinuousBackupsOutput) {
+	return newContextRequest(d, "UpdateContinuousBackups", input, d.updateContinuousBackupsContext)
+}
@@ -0,0 +1,58 @@
+package fakedynamo_test
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDB_UpdateContinuousBackups_ErrorsIfTableMissing(t *testing.T) {
+	t.Parallel()
+	db := makeTestDB(t)
+	_, err := db.UpdateContinuousBackups(&dynamodb.UpdateContinuousBackupsInput{
+		PointInTimeRecoverySpecification: &dynamodb.PointInTimeRecoverySpecification{
+			PointInTimeRecoveryEnabled: ptr(true),
+		},
+		TableName: ptr("does-not-exist"),
+	})
+	var expectedErr *dynamodb.TableNotFoundException
+	assert.ErrorAs(t, err, &expectedErr)
+}
+
+func TestDB_UpdateContinuousBackups_TogglesPointInTimeRecovery(t *testing.T) {
+	t.Parallel()
+	db := makeTestDB(t)
+	input := exampleCreateTableInputSimplePrimaryKey()
+	_, err := db.CreateTable(input)
+	require.NoError(t, err)
+
+	described, err := db.DescribeContinuousBackups(&dynamodb.DescribeContinuousBackupsInput{
+		TableName: input.TableName,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, dynamodb.PointInTimeRecoveryStatusDisabled,
+		val(described.ContinuousBackupsDescription.PointInTimeRecoveryDescription.PointInTimeRecoveryStatus))
+
+	updated, err := db.UpdateContinuousBackups(&dynamodb.UpdateContinuousBackupsInput{
+		PointInTimeRecoverySpecification: &dynamodb.PointInTimeRecoverySpecification{
+			PointInTimeRecoveryEnabled: ptr(true),
+		},
+		TableName: input.TableName,
+	})
+	require.NoError(t, err)
+	pitr := updated.ContinuousBackupsDescription.PointInTimeRecoveryDescription
+	assert.Equal(t, dynamodb.PointInTimeRecoveryStatusEnabled, val(pitr.PointInTimeRecoveryStatus))
+	assert.NotNil(t, pitr.EarliestRestorableDateTime)
+
+	updated, err = db.UpdateContinuousBackups(&dynamodb.UpdateContinuousBackupsInput{
+		PointInTimeRecoverySpecification: &dynamodb.PointInTimeRecoverySpecification{
+			PointInTimeRecoveryEnabled: ptr(false),
+		},
+		TableName: input.TableName,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, dynamodb.PointInTimeRecoveryStatusDisabled,
+		val(updated.ContinuousBackupsDescription.PointInTimeRecoveryDescription.PointInTimeRecoveryStatus))
+}
@@ -0,0 +1,550 @@
+package fakedynamo
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+const (
+	// contributorInsightsWindow is the granularity at which contributors are
+	// counted.
+	contributorInsightsWindow = time.Minute
+	// contributorInsightsRetention is how long we keep counts for.
+	contributorInsightsRetention = 24 * time.Hour
+	// contributorInsightsTopN is the number of contributors we keep for each
+	// rule in each window, and the most that TopContributors returns.
+	contributorInsightsTopN = 10
+)
+
+// ContributorInsightsRule selects which contributors [DB.TopContributors]
+// ranks. Each corresponds to one of the CloudWatch Contributor Insights rules
+// that DynamoDB creates.
+type ContributorInsightsRule int
+
+const (
+	// MostAccessedPartitionKeys ranks partition keys by reads and writes.
+	MostAccessedPartitionKeys ContributorInsightsRule = iota
+	// MostThrottledPartitionKeys ranks partition keys by throttled requests.
+	MostThrottledPartitionKeys
+	// MostAccessedItems ranks partition and sort key pairs by reads and
+	// writes. Only available for tables and indexes with a sort key.
+	MostAccessedItems
+	// MostThrottledItems ranks partition and sort key pairs by throttled
+	// requests. Only available for tables and indexes with a sort key.
+	MostThrottledItems
+
+	numContributorInsightsRules = iota
+)
+
+// ruleAbbreviations are used in rule names, e.g. PKC for
+// MostAccessedPartitionKeys.
+var ruleAbbreviations = [numContributorInsightsRules]string{"PKC", "PKT", "SKC", "SKT"}
+
+// Contributor is a key ranked by [DB.TopContributors].
+type Contributor struct {
+	// Key holds the partition key attribute, and the sort key attribute for
+	// the item rules.
+	Key   map[string]*dynamodb.AttributeValue
+	Count int64
+}
+
+// contributorInsights tracks the most accessed and throttled keys for a
+// table or global secondary index.
+type contributorInsights struct {
+	// mu guards windows, which are updated by reads holding the DB's read
+	// lock. status and updatedAt are guarded by the DB's lock.
+	mu        sync.Mutex
+	windows   []*insightsWindow
+	status    string
+	updatedAt time.Time
+
+	partition string
+	sort      string
+}
+
+// insightsWindow counts contributors for each rule during one
+// contributorInsightsWindow, keyed by [contributorID].
+type insightsWindow struct {
+	start  time.Time
+	counts [numContributorInsightsRules]map[string]*Contributor
+}
+
+func newContributorInsights(partition, sort string) *contributorInsights {
+	return &contributorInsights{
+		mu:        sync.Mutex{},
+		windows:   nil,
+		status:    dynamodb.ContributorInsightsStatusDisabled,
+		updatedAt: time.Time{},
+		partition: partition,
+		sort:      sort,
+	}
+}
+
+// clone returns a copy of the Contributor Insights state.
+func (ci *contributorInsights) clone() *contributorInsights {
+	ci.mu.Lock()
+	defer ci.mu.Unlock()
+	copied := &contributorInsights{
+		mu:        sync.Mutex{},
+		windows:   make([]*insightsWindow, 0, len(ci.windows)),
+		status:    ci.status,
+		updatedAt: ci.updatedAt,
+		partition: ci.partition,
+		sort:      ci.sort,
+	}
+	for _, window := range ci.windows {
+		copiedWindow := &insightsWindow{start: window.start, counts: window.counts}
+		for rule, counts := range window.counts {
+			copiedWindow.counts[rule] = make(map[string]*Contributor, len(counts))
+			for id, contributor := range counts {
+				copiedWindow.counts[rule][id] = shallowCopy(contributor)
+			}
+		}
+		copied.windows = append(copied.windows, copiedWindow)
+	}
+	return copied
+}
+
+// insightsFor returns the Contributor Insights state for the table, or for
+// one of its global secondary indexes if indexName is non-empty. The state
+// is created on demand. Returns nil if there is no such index.
+func (t *table) insightsFor(indexName string) *contributorInsights {
+	if ci := t.insights[indexName]; ci != nil {
+		return ci
+	}
+	partition, sort := t.schema.partition, t.schema.sort
+	if indexName != "" {
+		i := slices.IndexFunc(t.spec.GlobalSecondaryIndexes, func(gsi *dynamodb.GlobalSecondaryIndex) bool {
+			return *gsi.IndexName == indexName
+		})
+		if i < 0 {
+			return nil
+		}
+		keySchema := t.spec.GlobalSecondaryIndexes[i].KeySchema
+		partition = *keySchema[0].AttributeName
+		sort = ""
+		if len(keySchema) > 1 {
+			sort = *keySchema[1].AttributeName
+		}
+	}
+	ci := newContributorInsights(partition, sort)
+	t.insights[indexName] = ci
+	return ci
+}
+
+// count notes an access to the given item. Items without the key attributes
+// (e.g. items missing from a sparse index) are ignored.
+func (ci *contributorInsights) count(item avmap, throttled bool, now time.Time) {
+	if ci.status != dynamodb.ContributorInsightsStatusEnabled || item[ci.partition] == nil {
+		return
+	}
+
+	ci.mu.Lock()
+	defer ci.mu.Unlock()
+	window := ci.currentWindow(now)
+	partitionKey := avmap{ci.partition: item[ci.partition]}
+	rule := MostAccessedPartitionKeys
+	if throttled {
+		rule = MostThrottledPartitionKeys
+	}
+	window.increment(rule, partitionKey)
+
+	if ci.sort != "" && item[ci.sort] != nil {
+		rule = MostAccessedItems
+		if throttled {
+			rule = MostThrottledItems
+		}
+		window.increment(rule, avmap{ci.partition: item[ci.partition], ci.sort: item[ci.sort]})
+	}
+}
+
+// currentWindow returns the window containing now, creating it if needed. A
+// window is trimmed to its top contributors when the next one starts. The
+// caller MUST hold mu.
+func (ci *contributorInsights) currentWindow(now time.Time) *insightsWindow {
+	start := now.Truncate(contributorInsightsWindow)
+	if n := len(ci.windows); n > 0 && ci.windows[n-1].start.Equal(start) {
+		return ci.windows[n-1]
+	}
+	if n := len(ci.windows); n > 0 {
+		ci.windows[n-1].trim()
+	}
+
+	horizon := now.Add(-contributorInsightsRetention)
+	ci.windows = slices.DeleteFunc(ci.windows, func(w *insightsWindow) bool {
+		return w.start.Before(horizon)
+	})
+	window := &insightsWindow{start: start, counts: [numContributorInsightsRules]map[string]*Contributor{}}
+	for i := range window.counts {
+		window.counts[i] = map[string]*Contributor{}
+	}
+	ci.windows = append(ci.windows, window)
+	return window
+}
+
+func (w *insightsWindow) increment(rule ContributorInsightsRule, key avmap) {
+	id := contributorID(key)
+	contributor := w.counts[rule][id]
+	if contributor == nil {
+		contributor = &Contributor{Key: key, Count: 0}
+		w.counts[rule][id] = contributor
+	}
+	contributor.Count++
+}
+
+// trim discards all but the top contributors for each rule.
+func (w *insightsWindow) trim() {
+	for rule, counts := range w.counts {
+		top := rankContributors(counts)
+		w.counts[rule] = make(map[string]*Contributor, len(top))
+		for _, contributor := range top {
+			w.counts[rule][contributorID(contributor.Key)] = contributor
+		}
+	}
+}
+
+// contributorID identifies a key; attribute names are sorted so that the
+// result is deterministic.
+func contributorID(key avmap) string {
+	var id string
+	for _, name := range slices.Sorted(maps.Keys(key)) {
+		id += name + "\x00" + keyValueString(key[name]) + "\x00"
+	}
+	return id
+}
+
+// rankContributors returns the top contributors, ordered by descending
+// count. Ties are broken by key, so that the order is deterministic.
+func rankContributors(counts map[string]*Contributor) []*Contributor {
+	ids := slices.Collect(maps.Keys(counts))
+	slices.SortFunc(ids, func(a, b string) int {
+		if c := cmp.Compare(counts[b].Count, counts[a].Count); c != 0 {
+			return c
+		}
+		return cmp.Compare(a, b)
+	})
+	top := make([]*Contributor, 0, min(len(ids), contributorInsightsTopN))
+	for _, id := range ids[:cap(top)] {
+		top = append(top, counts[id])
+	}
+	return top
+}
+
+// countRead notes a read of the item with the given key from the table at
+// the given time, for Contributor Insights.
+func (t *table) countRead(key avmap, now time.Time) {
+	if ci := t.insights[""]; ci != nil {
+		ci.count(key, false, now)
+	}
+}
+
+// countWrite notes a write to the given item at the given time, for
+// Contributor Insights. The write is also counted against every global
+// secondary index which the item appears in.
+func (t *table) countWrite(item avmap, now time.Time) {
+	for _, ci := range t.insights {
+		ci.count(item, false, now)
+	}
+}
+
+// countThrottle notes a request for the given item which was throttled at
+// the given time, for Contributor Insights.
+func (t *table) countThrottle(item avmap, now time.Time) {
+	for _, ci := range t.insights {
+		ci.count(item, true, now)
+	}
+}
+
+// TopContributors ranks the keys of a table, or one of its global secondary
+// indexes if indexName is non-empty, by the given rule over the period since
+// the given time. At most 10 contributors are returned. Contributor Insights
+// must be enabled with UpdateContributorInsights for accesses to be counted.
+func (d *DB) TopContributors(tableName, indexName string, rule ContributorInsightsRule, since time.Time) ([]Contributor, error) {
+	if rule < 0 || rule >= numContributorInsightsRules {
+		return nil, fmt.Errorf("unknown ContributorInsightsRule %d", rule)
+	}
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	t, exists := d.tables.Get(tableKey(tableName))
+	if !exists {
+		return nil, &dynamodb.ResourceNotFoundException{}
+	}
+	ci := t.insights[indexName]
+	if ci == nil {
+		return nil, nil
+	}
+
+	ci.mu.Lock()
+	defer ci.mu.Unlock()
+	totals := map[string]*Contributor{}
+	for _, window := range ci.windows {
+		if window.start.Add(contributorInsightsWindow).Before(since) {
+			continue
+		}
+		for id, contributor := range window.counts[rule] {
+			if total := totals[id]; total != nil {
+				total.Count += contributor.Count
+			} else {
+				totals[id] = &Contributor{Key: contributor.Key, Count: contributor.Count}
+			}
+		}
+	}
+
+	top := rankContributors(totals)
+	result := make([]Contributor, 0, len(top))
+	for _, contributor := range top {
+		result = append(result, *contributor)
+	}
+	return result, nil
+}
+
+// ruleNames lists the CloudWatch rules DynamoDB would create for this table
+// or index.
+func (ci *contributorInsights) ruleNames(tableName, indexName string) []*string {
+	resource := tableName
+	if indexName != "" {
+		resource += "-" + indexName
+	}
+	rules := ContributorInsightsRule(numContributorInsightsRules)
+	if ci.sort == "" {
+		// Only the partition key rules apply.
+		rules = MostAccessedItems
+	}
+	names := make([]*string, 0, rules)
+	for rule := range rules {
+		names = append(names, ptr(fmt.Sprintf("DynamoDBContributorInsights-%s-%s-%d",
+			ruleAbbreviations[rule], resource, ci.updatedAt.UnixMilli())))
+	}
+	return names
+}
+
+func validateContributorInsightsTarget(tableName, indexName *string) error {
+	var errs []error
+	if tableName == nil {
+		errs = append(errs, newValidationError("TableName is a required field"))
+	}
+	if indexName != nil && (len(*indexName) < 3 || len(*indexName) > 255) {
+		errs = append(errs, newValidationError("IndexName must be between 3 and 255 characters"))
+	}
+	return errors.Join(errs...)
+}
+
+func (d *DB) UpdateContributorInsights(input *dynamodb.UpdateContributorInsightsInput) (*dynamodb.UpdateContributorInsightsOutput, error) {
+	return d.updateContributorInsightsContext(aws.BackgroundContext(), input)
+}
+
+func (d *DB) updateContributorInsightsContext(ctx aws.Context, input *dynamodb.UpdateContributorInsightsInput) (*dynamodb.UpdateContributorInsightsOutput, error) {
+	if err := d.injectFault(ctx, "UpdateContributorInsights", input); err != nil {
+		return nil, err
+	}
+
+	errs := []error{validateContributorInsightsTarget(input.TableName, input.IndexName)}
+	var status, transitionalStatus string
+	switch val(input.ContributorInsightsAction) {
+	case dynamodb.ContributorInsightsActionEnable:
+		status, transitionalStatus = dynamodb.ContributorInsightsStatusEnabled, dynamodb.ContributorInsightsStatusEnabling
+	case dynamodb.ContributorInsightsActionDisable:
+		status, transitionalStatus = dynamodb.ContributorInsightsStatusDisabled, dynamodb.ContributorInsightsStatusDisabling
+	default:
+		errs = append(errs, newValidationErrorf("ContributorInsightsAction must be one of %v",
+			dynamodb.ContributorInsightsAction_Values()))
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	t, exists := d.tables.Get(tableKey(*input.TableName))
+	if !exists {
+		return nil, &dynamodb.ResourceNotFoundException{}
+	}
+	ci := t.insightsFor(val(input.IndexName))
+	if ci == nil {
+		return nil, &dynamodb.ResourceNotFoundException{}
+	}
+
+	if ci.status != status {
+		ci.status = status
+		ci.updatedAt = d.now().UTC()
+		ci.mu.Lock()
+		ci.windows = nil
+		ci.mu.Unlock()
+	}
+	return &dynamodb.UpdateContributorInsightsOutput{
+		ContributorInsightsStatus: ptr(transitionalStatus),
+		IndexName:                 input.IndexName,
+		TableName:                 input.TableName,
+	}, nil
+}
+
+func (d *DB) UpdateContributorInsightsWithContext(ctx aws.Context, input *dynamodb.UpdateContributorInsightsInput, opts ...request.Option) (*dynamodb.UpdateContributorInsightsOutput, error) {
+	req, output := d.UpdateContributorInsightsRequest(input)
+	req.SetContext(ctx)
+	req.ApplyOptions(opts...)
+	return output, req.Send()
+}
+
+func (d *DB) UpdateContributorInsightsRequest(input *dynamodb.UpdateContributorInsightsInput) (*request.Request, *dynamodb.UpdateContributorInsightsOutput) {
+	return newContextRequest(d, "UpdateContributorInsights", input, d.updateContributorInsightsContext)
+}
+
+func (d *DB) DescribeContributorInsights(input *dynamodb.DescribeContributorInsightsInput) (*dynamodb.DescribeContributorInsightsOutput, error) {
+	return d.describeContributorInsightsContext(aws.BackgroundContext(), input)
+}
+
+func (d *DB) describeContributorInsightsContext(ctx aws.Context, input *dynamodb.DescribeContributorInsightsInput) (*dynamodb.DescribeContributorInsightsOutput, error) {
+	if err := d.injectFault(ctx, "DescribeContributorInsights", input); err != nil {
+		return nil, err
+	}
+
+	if err := validateContributorInsightsTarget(input.TableName, input.IndexName); err != nil {
+		return nil, err
+	}
+
+	// We take the write lock, since insightsFor may need to create the
+	// Contributor Insights state.
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	t, exists := d.tables.Get(tableKey(*input.TableName))
+	if !exists {
+		return nil, &dynamodb.ResourceNotFoundException{}
+	}
+	ci := t.insightsFor(val(input.IndexName))
+	if ci == nil {
+		return nil, &dynamodb.ResourceNotFoundException{}
+	}
+
+	output := &dynamodb.DescribeContributorInsightsOutput{
+		ContributorInsightsRuleList: nil,
+		ContributorInsightsStatus:   ptr(ci.status),
+		FailureException:            nil,
+		IndexName:                   input.IndexName,
+		LastUpdateDateTime:          nil,
+		TableName:                   input.TableName,
+	}
+	if !ci.updatedAt.IsZero() {
+		output.LastUpdateDateTime = ptr(ci.updatedAt)
+	}
+	if ci.status == dynamodb.ContributorInsightsStatusEnabled {
+		output.ContributorInsightsRuleList = ci.ruleNames(*input.TableName, val(input.IndexName))
+	}
+	return output, nil
+}
+
+func (d *DB) DescribeContributorInsightsWithContext(ctx aws.Context, input *dynamodb.DescribeContributorInsightsInput, opts ...request.Option) (*dynamodb.DescribeContributorInsightsOutput, error) {
+	req, output := d.DescribeContributorInsightsRequest(input)
+	req.SetContext(ctx)
+	req.ApplyOptions(opts...)
+	return output, req.Send()
+}
+
+func (d *DB) DescribeContributorInsightsRequest(input *dynamodb.DescribeContributorInsightsInput) (*request.Request, *dynamodb.DescribeContributorInsightsOutput) {
+	return newContextRequest(d, "DescribeContributorInsights", input, d.describeContributorInsightsContext)
+}
+
+// ListContributorInsights lists the Contributor Insights status of every
+// table and global secondary index, including those where it is disabled.
+func (d *DB) ListContributorInsights(input *dynamodb.ListContributorInsightsInput) (*dynamodb.ListContributorInsightsOutput, error) {
+	return d.listContributorInsightsContext(aws.BackgroundContext(), input)
+}
+
+func (d *DB) listContributorInsightsContext(ctx aws.Context, input *dynamodb.ListContributorInsightsInput) (*dynamodb.ListContributorInsightsOutput, error) {
+	if err := d.injectFault(ctx, "ListContributorInsights", input); err != nil {
+		return nil, err
+	}
+
+	maxResults := valOr(input.MaxResults, 10)
+	if maxResults < 1 || maxResults > 100 {
+		return nil, newValidationError("MaxResults must be between 1 and 100")
+	}
+	start := 0
+	if input.NextToken != nil {
+		var err error
+		start, err = strconv.Atoi(*input.NextToken)
+		if err != nil || start < 0 {
+			return nil, newValidationError("invalid NextToken")
+		}
+	}
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	var summaries []*dynamodb.ContributorInsightsSummary
+	summarise := func(t *table, indexName *string) {
+		status := dynamodb.ContributorInsightsStatusDisabled
+		if ci := t.insights[val(indexName)]; ci != nil {
+			status = ci.status
+		}
+		summaries = append(summaries, &dynamodb.ContributorInsightsSummary{
+			ContributorInsightsStatus: ptr(status),
+			IndexName:                 indexName,
+			TableName:                 t.spec.TableName,
+		})
+	}
+	d.tables.Ascend(func(t *table) bool {
+		if input.TableName != nil && *t.spec.TableName != *input.TableName {
+			return true
+		}
+		summarise(t, nil)
+		for _, gsi := range t.spec.GlobalSecondaryIndexes {
+			summarise(t, gsi.IndexName)
+		}
+		return true
+	})
+
+	output := &dynamodb.ListContributorInsightsOutput{
+		ContributorInsightsSummaries: []*dynamodb.ContributorInsightsSummary{},
+		NextToken:                    nil,
+	}
+	if start < len(summaries) {
+		end := min(start+int(maxResults), len(summaries))
+		output.ContributorInsightsSummaries = summaries[start:end]
+		if end < len(summaries) {
+			output.NextToken = ptr(strconv.Itoa(end))
+		}
+	}
+	return output, nil
+}
+
+func (d *DB) ListContributorInsightsWithContext(ctx aws.Context, input *dynamodb.ListContributorInsightsInput, opts ...request.Option) (*dynamodb.ListContributorInsightsOutput, error) {
+	req, output := d.ListContributorInsightsRequest(input)
+	req.SetContext(ctx)
+	req.ApplyOptions(opts...)
+	return output, req.Send()
+}
+
+func (d *DB) ListContributorInsightsRequest(input *dynamodb.ListContributorInsightsInput) (*request.Request, *dynamodb.ListContributorInsightsOutput) {
+	return newContextRequest(d, "ListContributorInsights", input, d.listContributorInsightsContext)
+}
+
+func (d *DB) ListContributorInsightsPages(input *dynamodb.ListContributorInsightsInput, processPage func(*dynamodb.ListContributorInsightsOutput, bool) bool) error {
+	return d.ListContributorInsightsPagesWithContext(aws.BackgroundContext(), input, processPage)
+}
+
+func (d *DB) ListContributorInsightsPagesWithContext(ctx aws.Context, input *dynamodb.ListContributorInsightsInput, processPage func(*dynamodb.ListContributorInsightsOutput, bool) bool, opts ...request.Option) error {
+	input = shallowCopy(input)
+	for {
+		output, err := d.ListContributorInsightsWithContext(ctx, input, opts...)
+		if err != nil {
+			return err
+		}
+		lastPage := output.NextToken == nil
+		shouldContinue := processPage(output, lastPage)
+		if lastPage || !shouldContinue {
+			break
+		}
+		input.NextToken = output.NextToken
+	}
+	return nil
+}
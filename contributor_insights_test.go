@@ -0,0 +1,141 @@
+package fakedynamo_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DMRobertson/fakedynamo"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDB_UpdateContributorInsights_RanksContributors(t *testing.T) {
+	t.Parallel()
+	db := fakedynamo.NewDB()
+	input := exampleCreateTableInputCompositePrimaryKey()
+	_, err := db.CreateTable(input)
+	require.NoError(t, err)
+
+	described, err := db.DescribeContributorInsights(&dynamodb.DescribeContributorInsightsInput{TableName: input.TableName})
+	require.NoError(t, err)
+	assert.Equal(t, ptr(dynamodb.ContributorInsightsStatusDisabled), described.ContributorInsightsStatus)
+
+	updated, err := db.UpdateContributorInsights(&dynamodb.UpdateContributorInsightsInput{
+		ContributorInsightsAction: ptr(dynamodb.ContributorInsightsActionEnable),
+		TableName:                 input.TableName,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, ptr(dynamodb.ContributorInsightsStatusEnabling), updated.ContributorInsightsStatus)
+
+	described, err = db.DescribeContributorInsights(&dynamodb.DescribeContributorInsightsInput{TableName: input.TableName})
+	require.NoError(t, err)
+	assert.Equal(t, ptr(dynamodb.ContributorInsightsStatusEnabled), described.ContributorInsightsStatus)
+	assert.Len(t, described.ContributorInsightsRuleList, 4)
+
+	item := func(foo, bar string) map[string]*dynamodb.AttributeValue {
+		return map[string]*dynamodb.AttributeValue{"Foo": {S: ptr(foo)}, "Bar": {S: ptr(bar)}}
+	}
+	for _, key := range []map[string]*dynamodb.AttributeValue{
+		item("hot", "1"), item("hot", "2"), item("hot", "1"), item("cold", "1"),
+	} {
+		_, err = db.PutItem(&dynamodb.PutItemInput{Item: key, TableName: input.TableName})
+		require.NoError(t, err)
+	}
+	_, err = db.GetItem(&dynamodb.GetItemInput{Key: item("hot", "1"), TableName: input.TableName})
+	require.NoError(t, err)
+
+	since := time.Now().Add(-time.Hour)
+	partitions, err := db.TopContributors(*input.TableName, "", fakedynamo.MostAccessedPartitionKeys, since)
+	require.NoError(t, err)
+	assert.Equal(t, []fakedynamo.Contributor{
+		{Key: map[string]*dynamodb.AttributeValue{"Foo": {S: ptr("hot")}}, Count: 4},
+		{Key: map[string]*dynamodb.AttributeValue{"Foo": {S: ptr("cold")}}, Count: 1},
+	}, partitions)
+
+	items, err := db.TopContributors(*input.TableName, "", fakedynamo.MostAccessedItems, since)
+	require.NoError(t, err)
+	require.Len(t, items, 3)
+	assert.Equal(t, fakedynamo.Contributor{Key: item("hot", "1"), Count: 3}, items[0])
+
+	throttled, err := db.TopContributors(*input.TableName, "", fakedynamo.MostThrottledPartitionKeys, since)
+	require.NoError(t, err)
+	assert.Empty(t, throttled)
+}
+
+func TestDB_ListContributorInsights(t *testing.T) {
+	t.Parallel()
+	db := fakedynamo.NewDB()
+	input := exampleCreateTableInputSimplePrimaryKey()
+	input.BillingMode = ptr(dynamodb.BillingModePayPerRequest)
+	input.ProvisionedThroughput = nil
+	input.AttributeDefinitions = append(input.AttributeDefinitions, &dynamodb.AttributeDefinition{
+		AttributeName: ptr("Bar"),
+		AttributeType: ptr(dynamodb.ScalarAttributeTypeS),
+	})
+	input.GlobalSecondaryIndexes = []*dynamodb.GlobalSecondaryIndex{{
+		IndexName:  ptr("by-bar"),
+		KeySchema:  []*dynamodb.KeySchemaElement{{AttributeName: ptr("Bar"), KeyType: ptr(dynamodb.KeyTypeHash)}},
+		Projection: &dynamodb.Projection{ProjectionType: ptr(dynamodb.ProjectionTypeAll)},
+	}}
+	_, err := db.CreateTable(input)
+	require.NoError(t, err)
+
+	_, err = db.UpdateContributorInsights(&dynamodb.UpdateContributorInsightsInput{
+		ContributorInsightsAction: ptr(dynamodb.ContributorInsightsActionEnable),
+		IndexName:                 ptr("by-bar"),
+		TableName:                 input.TableName,
+	})
+	require.NoError(t, err)
+
+	listed, err := db.ListContributorInsights(&dynamodb.ListContributorInsightsInput{MaxResults: ptr[int64](1)})
+	require.NoError(t, err)
+	require.Len(t, listed.ContributorInsightsSummaries, 1)
+	assert.Equal(t, ptr(dynamodb.ContributorInsightsStatusDisabled), listed.ContributorInsightsSummaries[0].ContributorInsightsStatus)
+	require.NotNil(t, listed.NextToken)
+	listed, err = db.ListContributorInsights(&dynamodb.ListContributorInsightsInput{NextToken: listed.NextToken})
+	require.NoError(t, err)
+	require.Len(t, listed.ContributorInsightsSummaries, 1)
+	assert.Equal(t, ptr("by-bar"), listed.ContributorInsightsSummaries[0].IndexName)
+	assert.Equal(t, ptr(dynamodb.ContributorInsightsStatusEnabled), listed.ContributorInsightsSummaries[0].ContributorInsightsStatus)
+	assert.Nil(t, listed.NextToken)
+
+	// Writes count against the index, but only for items which have its key.
+	for _, bar := range []string{"x", "x", ""} {
+		item := map[string]*dynamodb.AttributeValue{"Foo": {S: ptr("foo" + bar)}}
+		if bar != "" {
+			item["Bar"] = &dynamodb.AttributeValue{S: ptr(bar)}
+		}
+		_, err = db.PutItem(&dynamodb.PutItemInput{Item: item, TableName: input.TableName})
+		require.NoError(t, err)
+	}
+	contributors, err := db.TopContributors(*input.TableName, "by-bar", fakedynamo.MostAccessedPartitionKeys, time.Time{})
+	require.NoError(t, err)
+	assert.Equal(t, []fakedynamo.Contributor{
+		{Key: map[string]*dynamodb.AttributeValue{"Bar": {S: ptr("x")}}, Count: 2},
+	}, contributors)
+}
+
+func TestDB_TopContributors_UsesClock(t *testing.T) {
+	t.Parallel()
+	clock := newFakeClock()
+	db := fakedynamo.NewDB(fakedynamo.WithClock(clock.Now))
+	input := exampleCreateTableInputSimplePrimaryKey()
+	_, err := db.CreateTable(input)
+	require.NoError(t, err)
+	_, err = db.UpdateContributorInsights(&dynamodb.UpdateContributorInsightsInput{
+		ContributorInsightsAction: ptr(dynamodb.ContributorInsightsActionEnable),
+		TableName:                 input.TableName,
+	})
+	require.NoError(t, err)
+
+	putFoo(t, db, input.TableName, "early")
+	clock.Advance(time.Hour)
+	putFoo(t, db, input.TableName, "late")
+
+	partitions, err := db.TopContributors(*input.TableName, "", fakedynamo.MostAccessedPartitionKeys, clock.Now())
+	require.NoError(t, err)
+	assert.Equal(t, []fakedynamo.Contributor{
+		{Key: map[string]*dynamodb.AttributeValue{"Foo": {S: ptr("late")}}, Count: 1},
+	}, partitions)
+}
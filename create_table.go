@@ -9,10 +9,22 @@ import (
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
-	"github.com/google/btree"
 )
 
 func (d *DB) CreateTable(input *dynamodb.CreateTableInput) (*dynamodb.CreateTableOutput, error) {
+	return d.createTableContext(aws.BackgroundContext(), input)
+}
+
+func (d *DB) createTableContext(ctx aws.Context, input *dynamodb.CreateTableInput) (*dynamodb.CreateTableOutput, error) {
+	if err := d.injectFault(ctx, "CreateTable", input); err != nil {
+		return nil, err
+	}
+	return d.createTable(input)
+}
+
+// createTable implements CreateTable. Other operations which create tables use it
+// directly, so that faults injected into CreateTable don't affect them.
+func (d *DB) createTable(input *dynamodb.CreateTableInput) (*dynamodb.CreateTableOutput, error) {
 	errs := []error{
 		validateCreateTableInputAttributeDefinitions(input.AttributeDefinitions),
 		validateCreateTableInputKeySchema(input.KeySchema),
@@ -31,7 +43,14 @@ func (d *DB) CreateTable(input *dynamodb.CreateTableInput) (*dynamodb.CreateTabl
 		errs = append(errs, err)
 	}
 
-	errs = append(errs, validateCreateTableInputTableName(input.TableName))
+	errs = append(errs,
+		validateCreateTableInputTableName(input.TableName),
+		validateCreateTableInputGlobalSecondaryIndexes(input.GlobalSecondaryIndexes, schema),
+		validateTags(input.Tags, "Tags"),
+	)
+	if input.ResourcePolicy != nil {
+		errs = append(errs, validateResourcePolicy(*input.ResourcePolicy))
+	}
 	if err := errors.Join(errs...); err != nil {
 		return nil, err
 	}
@@ -44,12 +63,42 @@ func (d *DB) CreateTable(input *dynamodb.CreateTableInput) (*dynamodb.CreateTabl
 	if schema == nil {
 		return nil, errors.New("couldn't parse schema")
 	}
-	_, _ = d.tables.ReplaceOrInsert(table{
-		spec:       input,
-		createdAt:  time.Now().UTC(),
-		schema:     *schema,
-		partitions: map[string]*btree.BTreeG[avmap]{},
-	})
+	tags := map[string]string{}
+	if err := applyTags(tags, input.Tags); err != nil {
+		return nil, err
+	}
+	var policy *resourcePolicy
+	if input.ResourcePolicy != nil {
+		policy = &resourcePolicy{
+			document:   *input.ResourcePolicy,
+			revisionID: newPolicyRevisionID(nil),
+		}
+	}
+	items, err := d.newStorage(*input.TableName, *schema)
+	if err != nil {
+		return nil, err
+	}
+	t := &table{
+		spec:                input,
+		createdAt:           time.Now().UTC(),
+		schema:              *schema,
+		items:               items,
+		pitr:                nil,
+		ttlAttribute:        "",
+		tags:                tags,
+		policy:              policy,
+		streamPolicy:        nil,
+		replication:         map[string]replicationMeta{},
+		kinesisDestinations: nil,
+		insights:            map[string]*contributorInsights{},
+		throughput:          newThroughput(input, d.now()),
+		deleting:            false,
+	}
+	if err := d.logTable(t); err != nil {
+		_ = items.Close()
+		return nil, err
+	}
+	_, _ = d.tables.ReplaceOrInsert(t)
 	return &dynamodb.CreateTableOutput{
 		TableDescription: d.describeTable(*input.TableName),
 	}, nil
@@ -126,6 +175,55 @@ func checkKeySchema(
 	return errors.Join(errs...)
 }
 
+var validProjectionTypes = []string{
+	dynamodb.ProjectionTypeAll,
+	dynamodb.ProjectionTypeInclude,
+	dynamodb.ProjectionTypeKeysOnly,
+}
+
+func validateCreateTableInputGlobalSecondaryIndexes(input []*dynamodb.GlobalSecondaryIndex, schema *tableSchema) error {
+	if len(input) > 20 {
+		return newValidationError("GlobalSecondaryIndexes must contain at most 20 items")
+	}
+
+	var errs []error
+	seen := make(map[string]bool)
+	for i, gsi := range input {
+		if gsi == nil {
+			errs = append(errs, newValidationErrorf("GlobalSecondaryIndexes[%d] is nil", i))
+			continue
+		}
+
+		if gsi.IndexName == nil {
+			errs = append(errs, newValidationErrorf("GlobalSecondaryIndexes[%d].IndexName is a required field", i))
+		} else if len(*gsi.IndexName) < 3 || len(*gsi.IndexName) > 255 {
+			errs = append(errs, newValidationErrorf("GlobalSecondaryIndexes[%d].IndexName must be between 3 and 255 characters", i))
+		} else if seen[*gsi.IndexName] {
+			errs = append(errs, newValidationErrorf("Duplicate index name: %s", *gsi.IndexName))
+		} else {
+			seen[*gsi.IndexName] = true
+		}
+
+		if err := validateCreateTableInputKeySchema(gsi.KeySchema); err != nil {
+			errs = append(errs, newValidationErrorf("GlobalSecondaryIndexes[%d]: %s", i, err))
+		} else if schema != nil {
+			for _, element := range gsi.KeySchema {
+				if _, defined := schema.types[*element.AttributeName]; !defined {
+					errs = append(errs, newValidationErrorf("%s is missing from AttributeDefinitions", *element.AttributeName))
+				}
+			}
+		}
+
+		if gsi.Projection == nil {
+			errs = append(errs, newValidationErrorf("GlobalSecondaryIndexes[%d].Projection is a required field", i))
+		} else if projectionType := val(gsi.Projection.ProjectionType); !slices.Contains(validProjectionTypes, projectionType) {
+			errs = append(errs, newValidationErrorf("GlobalSecondaryIndexes[%d].Projection.ProjectionType must be one of [%s]",
+				i, strings.Join(validProjectionTypes, ", ")))
+		}
+	}
+	return errors.Join(errs...)
+}
+
 func validateCreateTableInputTableName(input *string) error {
 	if input == nil {
 		return newValidationError("TableName is a required field")
@@ -178,10 +276,13 @@ func parseTableSchema(input *dynamodb.CreateTableInput) (*tableSchema, error) {
 	return &schema, nil
 }
 
-func (d *DB) CreateTableWithContext(_ aws.Context, input *dynamodb.CreateTableInput, _ ...request.Option) (*dynamodb.CreateTableOutput, error) {
-	return d.CreateTable(input)
+func (d *DB) CreateTableWithContext(ctx aws.Context, input *dynamodb.CreateTableInput, opts ...request.Option) (*dynamodb.CreateTableOutput, error) {
+	req, output := d.CreateTableRequest(input)
+	req.SetContext(ctx)
+	req.ApplyOptions(opts...)
+	return output, req.Send()
 }
 
-func (d *DB) CreateTableRequest(_ *dynamodb.CreateTableInput) (*request.Request, *dynamodb.CreateTableOutput) {
-	panic("not implemented: CreateTableRequest")
+func (d *DB) CreateTableRequest(input *dynamodb.CreateTableInput) (*request.Request, *dynamodb.CreateTableOutput) {
+	return newContextRequest(d, "CreateTable", input, d.createTableContext)
 }
@@ -185,6 +185,30 @@ func TestDB_CreateTable_ValidationErrors(t *testing.T) {
 			},
 			ExpectErrorMessages: []string{"Attribute", "Definitions"},
 		},
+		{
+			Name: "Returns ValidationException when global secondary index's attribute is not defined",
+			Input: dynamodb.CreateTableInput{
+				AttributeDefinitions: []*dynamodb.AttributeDefinition{{
+					AttributeName: ptr("Foo"),
+					AttributeType: ptr("S"),
+				}},
+				BillingMode: ptr(dynamodb.BillingModePayPerRequest),
+				GlobalSecondaryIndexes: []*dynamodb.GlobalSecondaryIndex{{
+					IndexName: ptr("example-index"),
+					KeySchema: []*dynamodb.KeySchemaElement{{
+						AttributeName: ptr("Bar"),
+						KeyType:       ptr(dynamodb.KeyTypeHash),
+					}},
+					Projection: &dynamodb.Projection{ProjectionType: ptr(dynamodb.ProjectionTypeAll)},
+				}},
+				KeySchema: []*dynamodb.KeySchemaElement{{
+					AttributeName: ptr("Foo"),
+					KeyType:       ptr(dynamodb.KeyTypeHash),
+				}},
+				TableName: ptr("example-table"),
+			},
+			ExpectErrorMessages: []string{"Attribute", "Definitions"},
+		},
 	}
 	for _, tc := range testCases {
 		t.Run(tc.Name, func(t *testing.T) {
@@ -18,12 +18,13 @@
 package fakedynamo
 
 import (
-	"bytes"
 	"cmp"
 	"encoding/base64"
+	"errors"
 	"sync"
 	"time"
 
+	"github.com/DMRobertson/fakedynamo/fakekinesis"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/google/btree"
 )
@@ -34,14 +35,101 @@ type DB struct {
 	// tables tracks the tables stored in the database. It's a BTree rather than
 	// a plain map, because ListTables needs to be able to paginate through in a
 	// consistent order.
-	tables *btree.BTreeG[table]
+	tables *btree.BTreeG[*table]
+
+	// region and accountID are used to build ARNs for resources in this DB.
+	region    string
+	accountID string
+	// s3Dir is a local directory standing in for S3. Each bucket is a
+	// subdirectory. Empty if unconfigured.
+	s3Dir string
+
+	// exports records every export made by ExportTableToPointInTime, in the
+	// order they were requested.
+	exports []*dynamodb.ExportDescription
+	// imports records every import made by ImportTable, in the order they
+	// were requested.
+	imports []*dynamodb.ImportTableDescription
+
+	// regions links this DB to DBs modelling other regions, so that they can
+	// host global tables. Nil unless the DB was created by [NewRegions].
+	regions *Regions
+	// kinesis receives item changes for tables with a Kinesis streaming
+	// destination. Nil if unconfigured.
+	kinesis *fakekinesis.Kinesis
+	// transientStatusDelay is how long tables spend CREATING and DELETING.
+	transientStatusDelay time.Duration
+
+	// clock tells the time for simulating throughput. See [WithClock].
+	clock func() time.Time
+	// adaptiveCapacity lets hot partitions split. See [WithAdaptiveCapacity].
+	adaptiveCapacity bool
+	// storageDir is where tables store their items, if configured with
+	// [WithFileStorage]. Empty if items are kept in memory.
+	storageDir string
+
+	// wal is the write-ahead log of a DB opened with [OpenDB], or nil.
+	wal *wal
+	// compactEvery is how many changes a DB opened with [OpenDB] logs between
+	// snapshots. See [WithCompactEvery].
+	compactEvery int
+
+	// faults are injected into operations by [DB.AddFault].
+	faults *faults
 }
 
-func NewDB() *DB {
-	return &DB{
-		mu:     sync.RWMutex{},
-		tables: btree.NewG(2, tableLess),
+func NewDB(opts ...Option) *DB {
+	d := &DB{
+		mu:        sync.RWMutex{},
+		tables:    btree.NewG(2, tableLess),
+		region:    "us-east-1",
+		accountID: "000000000000",
+		s3Dir:     "",
+		exports:   nil,
+		imports:   nil,
+		regions:   nil,
+		kinesis:   nil,
+
+		transientStatusDelay: 0,
+		clock:                time.Now,
+		adaptiveCapacity:     false,
+		storageDir:           "",
+		wal:                  nil,
+		compactEvery:         defaultCompactEvery,
+		faults:               newFaults(),
 	}
+	for _, opt := range opts {
+		opt(d)
+	}
+	return d
+}
+
+func (d *DB) now() time.Time {
+	return d.clock()
+}
+
+// Region returns the AWS region which this DB models.
+func (d *DB) Region() string {
+	return d.region
+}
+
+// Close releases the files used by the DB: it compacts and closes the
+// write-ahead log of a DB opened with [OpenDB], and deletes the files of a DB
+// configured with [WithFileStorage]. The DB mustn't be used afterwards. Close
+// has nothing to do for other DBs.
+func (d *DB) Close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	var errs []error
+	if d.wal != nil {
+		errs = append(errs, d.compactWAL(), d.wal.file.Close())
+		d.wal = nil
+	}
+	d.tables.Ascend(func(t *table) bool {
+		errs = append(errs, t.items.Close())
+		return true
+	})
+	return errors.Join(errs...)
 }
 
 // table models a single DynamoDB table.
@@ -51,21 +139,58 @@ type table struct {
 	schema    tableSchema
 	createdAt time.Time
 
-	// DynamoDB's records are conceptually stored in two ways.
-	//
-	//  - A simple table (no sort key) is a key-value map from
-	//    partition keys to records.
-	//  - A composite table is a map from partition key to a list of records
-	//    sorted by their sort key values.
-	//
-	// For simplicity, we implement the simple table as a composite table where
-	// all the sort keys are identical.
-	//
-	// We store each partition separately, which more closely mimics Dynamo's
-	// implementation. We previously considered storing partitions in the same
-	// BTree, using a lexicographic sort on the pair (partition key, sort key).
-	// But this makes it harder to implement a parallel scan.
-	partitions map[string]*btree.BTreeG[avmap]
+	// items holds the table's items.
+	items storage
+
+	// pitr is nil unless point-in-time recovery is enabled for this table.
+	pitr *pitrState
+	// ttlAttribute names the attribute holding items' expiry times, if Time to
+	// Live is enabled for this table.
+	ttlAttribute string
+	// tags maps tag keys to values.
+	tags map[string]string
+	// policy and streamPolicy are the resource-based policies attached to the
+	// table and its stream, or nil if there are none.
+	policy       *resourcePolicy
+	streamPolicy *resourcePolicy
+	// replication records the last write to each item, keyed by
+	// [table.itemKeyString], if this table is a replica of a global table.
+	replication map[string]replicationMeta
+	// kinesisDestinations lists the Kinesis streams which have ever been
+	// enabled as destinations for this table.
+	kinesisDestinations []*kinesisDestination
+	// insights holds Contributor Insights state for the table (keyed by "")
+	// and its global secondary indexes (keyed by index name).
+	insights map[string]*contributorInsights
+	// throughput limits the rate of reads and writes to the table.
+	throughput *throughput
+	// deleting is set once DeleteTable is called, if the table lingers in
+	// the DELETING state.
+	deleting bool
+}
+
+// tableStatus reports whether the table is CREATING, ACTIVE or DELETING.
+// Tables only spend time CREATING or DELETING if configured to with
+// [WithTransientStatusDelay].
+func (d *DB) tableStatus(t *table) string {
+	switch {
+	case t.deleting:
+		return dynamodb.TableStatusDeleting
+	case time.Since(t.createdAt) < d.transientStatusDelay:
+		return dynamodb.TableStatusCreating
+	}
+	return dynamodb.TableStatusActive
+}
+
+// activeTable looks up a table for reading or writing items. Tables which are
+// still being created or are being deleted can't be used. The caller MUST
+// ensure that mu is Locked or RLocked appropriately.
+func (d *DB) activeTable(name string) (*table, error) {
+	t, exists := d.tables.Get(tableKey(name))
+	if !exists || d.tableStatus(t) != dynamodb.TableStatusActive {
+		return nil, &dynamodb.ResourceNotFoundException{}
+	}
+	return t, nil
 }
 
 type tableSchema struct {
@@ -77,57 +202,38 @@ type tableSchema struct {
 	types map[string]string
 }
 
-func tableKey(name string) table {
-	return table{spec: &dynamodb.CreateTableInput{ //nolint:exhaustruct
+func tableKey(name string) *table {
+	return &table{spec: &dynamodb.CreateTableInput{ //nolint:exhaustruct
 		TableName: &name,
 	}}
 }
 
-func tableLess(a, b table) bool {
+func tableLess(a, b *table) bool {
 	return cmp.Less(*a.spec.TableName, *b.spec.TableName)
 }
 
 type avmap = map[string]*dynamodb.AttributeValue
 
-func (t *table) getPartition(pval *dynamodb.AttributeValue) *btree.BTreeG[avmap] {
-	var pvalString string
+// keyValueString converts a key attribute value (of type S, N or B) to a
+// string, suitable for use as a Go map key.
+func keyValueString(v *dynamodb.AttributeValue) string {
 	switch {
-	case pval.S != nil:
-		pvalString = *pval.S
-	case pval.N != nil:
-		pvalString = *pval.N
-	case pval.B != nil:
-		pvalString = base64.StdEncoding.EncodeToString(pval.B)
-	}
-
-	partition := t.partitions[pvalString]
-	if partition == nil {
-		partition = btree.NewG[avmap](4, makePartitionLess(t.schema))
-		t.partitions[pvalString] = partition
+	case v.S != nil:
+		return *v.S
+	case v.N != nil:
+		return *v.N
+	case v.B != nil:
+		return base64.StdEncoding.EncodeToString(v.B)
 	}
-	return partition
+	return ""
 }
 
-func makePartitionLess(schema tableSchema) btree.LessFunc[avmap] {
-	if schema.sort == "" {
-		return func(a, b avmap) bool {
-			return false
-		}
-	}
-
-	switch schema.types[schema.sort] {
-	case dynamodb.ScalarAttributeTypeS:
-		return func(a, b avmap) bool {
-			return cmp.Less(*a[schema.sort].S, *b[schema.sort].S)
-		}
-	case dynamodb.ScalarAttributeTypeN:
-		return func(a, b avmap) bool {
-			return cmp.Less(*a[schema.sort].N, *b[schema.sort].N)
-		}
-	case dynamodb.ScalarAttributeTypeB:
-		return func(a, b avmap) bool {
-			return bytes.Compare(a[schema.sort].B, b[schema.sort].B) < 0
-		}
+// itemKeyString identifies the given item by its primary key, for use as a Go
+// map key.
+func (t *table) itemKeyString(item avmap) string {
+	key := keyValueString(item[t.schema.partition])
+	if t.schema.sort != "" {
+		key += "\x00" + keyValueString(item[t.schema.sort])
 	}
-	panic("unreachable")
+	return key
 }
@@ -10,6 +10,14 @@ import (
 )
 
 func (d *DB) DeleteItem(input *dynamodb.DeleteItemInput) (*dynamodb.DeleteItemOutput, error) {
+	return d.deleteItemContext(aws.BackgroundContext(), input)
+}
+
+func (d *DB) deleteItemContext(ctx aws.Context, input *dynamodb.DeleteItemInput) (*dynamodb.DeleteItemOutput, error) {
+	if err := d.injectFault(ctx, "DeleteItem", input); err != nil {
+		return nil, err
+	}
+
 	var errs []error
 	if input.Key == nil {
 		errs = append(errs, newValidationError("Key is a required field"))
@@ -56,18 +64,29 @@ func (d *DB) DeleteItem(input *dynamodb.DeleteItemInput) (*dynamodb.DeleteItemOu
 	d.mu.Lock()
 	defer d.mu.Unlock()
 
-	t, exists := d.tables.Get(tableKey(*input.TableName))
-	if !exists {
-		return nil, &dynamodb.ResourceNotFoundException{}
+	t, err := d.activeTable(*input.TableName)
+	if err != nil {
+		return nil, err
 	}
 
-	pVal, err := validateAvmapMatchesSchema(input.Key, t, "Key")
-	if err != nil {
+	if _, err := validateAvmapMatchesSchema(input.Key, t, "Key"); err != nil {
 		return nil, err
 	}
 
-	partition := t.getPartition(pVal)
-	previous, _ := partition.Get(input.Key)
+	previous, _, err := t.items.Get(input.Key)
+	if err != nil {
+		return nil, err
+	}
+	used := t.writeConsumption(previous, nil)
+	if err := d.consume(t, true, input.Key, used); err != nil {
+		t.countThrottle(input.Key, d.now())
+		return nil, err
+	}
+	if previous != nil {
+		t.countWrite(previous, d.now())
+	} else {
+		t.countWrite(input.Key, d.now())
+	}
 	if condition != nil {
 		match, err := condition.Evaluate(previous, input.ExpressionAttributeNames, input.ExpressionAttributeValues)
 		if err != nil {
@@ -82,22 +101,29 @@ func (d *DB) DeleteItem(input *dynamodb.DeleteItemInput) (*dynamodb.DeleteItemOu
 		}
 	}
 
-	_, _ = partition.Delete(input.Key)
+	if _, _, err := d.deleteItem(t, input.Key); err != nil {
+		return nil, err
+	}
 	// Unless you specify conditions, the DeleteItem is an idempotent operation;
 	// running it multiple times on the same item or attribute does not result
 	// in an error response.
 
+	output := &dynamodb.DeleteItemOutput{
+		ConsumedCapacity: consumedCapacity(*input.TableName, input.ReturnConsumedCapacity, true, used),
+	}
 	if returnValues == dynamodb.ReturnValueAllOld {
-		return &dynamodb.DeleteItemOutput{Attributes: previous}, nil
-	} else {
-		return &dynamodb.DeleteItemOutput{}, nil
+		output.Attributes = previous
 	}
+	return output, nil
 }
 
-func (d *DB) DeleteItemWithContext(_ aws.Context, input *dynamodb.DeleteItemInput, _ ...request.Option) (*dynamodb.DeleteItemOutput, error) {
-	return d.DeleteItem(input)
+func (d *DB) DeleteItemWithContext(ctx aws.Context, input *dynamodb.DeleteItemInput, opts ...request.Option) (*dynamodb.DeleteItemOutput, error) {
+	req, output := d.DeleteItemRequest(input)
+	req.SetContext(ctx)
+	req.ApplyOptions(opts...)
+	return output, req.Send()
 }
 
-func (d *DB) DeleteItemRequest(_ *dynamodb.DeleteItemInput) (*request.Request, *dynamodb.DeleteItemOutput) {
-	panic("not implemented: DeleteItemRequest")
+func (d *DB) DeleteItemRequest(input *dynamodb.DeleteItemInput) (*request.Request, *dynamodb.DeleteItemOutput) {
+	return newContextRequest(d, "DeleteItem", input, d.deleteItemContext)
 }
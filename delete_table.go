@@ -1,35 +1,81 @@
 package fakedynamo
 
 import (
+	"time"
+
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 )
 
 func (d *DB) DeleteTable(input *dynamodb.DeleteTableInput) (*dynamodb.DeleteTableOutput, error) {
+	return d.deleteTableContext(aws.BackgroundContext(), input)
+}
+
+func (d *DB) deleteTableContext(ctx aws.Context, input *dynamodb.DeleteTableInput) (*dynamodb.DeleteTableOutput, error) {
+	if err := d.injectFault(ctx, "DeleteTable", input); err != nil {
+		return nil, err
+	}
+	return d.deleteTable(input)
+}
+
+// deleteTable implements DeleteTable. Other operations which delete tables use it
+// directly, so that faults injected into DeleteTable don't affect them.
+func (d *DB) deleteTable(input *dynamodb.DeleteTableInput) (*dynamodb.DeleteTableOutput, error) {
 	if input.TableName == nil {
 		return nil, newValidationError("TableName is a required field")
 	}
 	d.mu.Lock()
 	defer d.mu.Unlock()
 
-	desc := d.describeTable(*input.TableName)
-	if desc == nil {
+	t, exists := d.tables.Get(tableKey(*input.TableName))
+	if !exists {
 		return nil, &dynamodb.ResourceNotFoundException{}
 	}
+	if t.deleting {
+		return nil, &dynamodb.ResourceInUseException{
+			Message_: ptr("Table is being deleted: " + *input.TableName),
+		}
+	}
 
+	if err := d.appendWAL(walRecord{Table: nil, DeleteTable: *input.TableName, PutItem: nil, DeleteItem: nil}); err != nil {
+		return nil, err
+	}
+	desc := d.describeTable(*input.TableName)
 	desc.TableStatus = ptr(dynamodb.TableStatusDeleting)
 
-	_, _ = d.tables.Delete(tableKey(*input.TableName))
+	d.regions.removeReplica(*input.TableName, d.region)
+	if d.transientStatusDelay > 0 {
+		t.deleting = true
+		time.AfterFunc(d.transientStatusDelay, func() {
+			d.mu.Lock()
+			defer d.mu.Unlock()
+			if current, exists := d.tables.Get(t); exists && current == t {
+				d.removeTable(t)
+			}
+		})
+	} else {
+		d.removeTable(t)
+	}
 	return &dynamodb.DeleteTableOutput{
 		TableDescription: desc,
 	}, nil
 }
 
-func (d *DB) DeleteTableWithContext(_ aws.Context, input *dynamodb.DeleteTableInput, _ ...request.Option) (*dynamodb.DeleteTableOutput, error) {
-	return d.DeleteTable(input)
+// removeTable deletes a table and releases its storage. The caller MUST hold
+// the write lock.
+func (d *DB) removeTable(t *table) {
+	_, _ = d.tables.Delete(t)
+	_ = t.items.Close()
+}
+
+func (d *DB) DeleteTableWithContext(ctx aws.Context, input *dynamodb.DeleteTableInput, opts ...request.Option) (*dynamodb.DeleteTableOutput, error) {
+	req, output := d.DeleteTableRequest(input)
+	req.SetContext(ctx)
+	req.ApplyOptions(opts...)
+	return output, req.Send()
 }
 
-func (d *DB) DeleteTableRequest(_ *dynamodb.DeleteTableInput) (*request.Request, *dynamodb.DeleteTableOutput) {
-	panic("not implemented: DeleteTableRequest")
+func (d *DB) DeleteTableRequest(input *dynamodb.DeleteTableInput) (*request.Request, *dynamodb.DeleteTableOutput) {
+	return newContextRequest(d, "DeleteTable", input, d.deleteTableContext)
 }
@@ -1,8 +1,11 @@
 package fakedynamo_test
 
 import (
+	"errors"
 	"testing"
+	"time"
 
+	"github.com/DMRobertson/fakedynamo"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -43,3 +46,38 @@ func TestDB_DeleteTable_HappyPath(t *testing.T) {
 	var expectedErr *dynamodb.ResourceNotFoundException
 	assert.ErrorAs(t, err, &expectedErr)
 }
+
+func TestDB_DeleteTable_TransientStatuses(t *testing.T) {
+	t.Parallel()
+	db := fakedynamo.NewDB(fakedynamo.WithTransientStatusDelay(50 * time.Millisecond))
+	input := exampleCreateTableInputSimplePrimaryKey()
+	_, err := db.CreateTable(input)
+	require.NoError(t, err)
+	item := map[string]*dynamodb.AttributeValue{"Foo": {S: ptr("foo")}}
+
+	described, err := db.DescribeTable(&dynamodb.DescribeTableInput{TableName: input.TableName})
+	require.NoError(t, err)
+	assert.Equal(t, ptr(dynamodb.TableStatusCreating), described.Table.TableStatus)
+	_, err = db.PutItem(&dynamodb.PutItemInput{Item: item, TableName: input.TableName})
+	var notFound *dynamodb.ResourceNotFoundException
+	require.ErrorAs(t, err, &notFound)
+
+	require.Eventually(t, func() bool {
+		_, err = db.PutItem(&dynamodb.PutItemInput{Item: item, TableName: input.TableName})
+		return err == nil
+	}, time.Second, 10*time.Millisecond)
+
+	_, err = db.DeleteTable(&dynamodb.DeleteTableInput{TableName: input.TableName})
+	require.NoError(t, err)
+	described, err = db.DescribeTable(&dynamodb.DescribeTableInput{TableName: input.TableName})
+	require.NoError(t, err)
+	assert.Equal(t, ptr(dynamodb.TableStatusDeleting), described.Table.TableStatus)
+	_, err = db.DeleteTable(&dynamodb.DeleteTableInput{TableName: input.TableName})
+	var inUse *dynamodb.ResourceInUseException
+	require.ErrorAs(t, err, &inUse)
+
+	require.Eventually(t, func() bool {
+		_, err = db.DescribeTable(&dynamodb.DescribeTableInput{TableName: input.TableName})
+		return errors.As(err, &notFound)
+	}, time.Second, 10*time.Millisecond)
+}
@@ -9,6 +9,14 @@ import (
 )
 
 func (d *DB) DescribeTable(input *dynamodb.DescribeTableInput) (*dynamodb.DescribeTableOutput, error) {
+	return d.describeTableContext(aws.BackgroundContext(), input)
+}
+
+func (d *DB) describeTableContext(ctx aws.Context, input *dynamodb.DescribeTableInput) (*dynamodb.DescribeTableOutput, error) {
+	if err := d.injectFault(ctx, "DescribeTable", input); err != nil {
+		return nil, err
+	}
+
 	if input.TableName == nil {
 		return nil, newValidationError("TableName is a required field")
 	}
@@ -31,6 +39,21 @@ func (d *DB) describeTable(tableName string) *dynamodb.TableDescription {
 		return nil
 	}
 	spec := table.spec
+	var streamArn, streamLabel *string
+	if arn, ok := d.streamArn(table); ok {
+		label, _ := table.streamLabel()
+		streamArn, streamLabel = &arn, &label
+	}
+	var replicas []*dynamodb.ReplicaDescription
+	var globalTableVersion *string
+	if global := d.regions.globalTable(tableName); global != nil {
+		globalTableVersion = ptr(global.version)
+		for _, region := range global.regions {
+			if region != d.region {
+				replicas = append(replicas, describeReplica(region))
+			}
+		}
+	}
 
 	return &dynamodb.TableDescription{
 		ArchivalSummary:           nil,
@@ -38,26 +61,20 @@ func (d *DB) describeTable(tableName string) *dynamodb.TableDescription {
 		BillingModeSummary:        nil,
 		CreationDateTime:          &table.createdAt,
 		DeletionProtectionEnabled: spec.DeletionProtectionEnabled,
-		GlobalSecondaryIndexes:    nil,
-		GlobalTableVersion:        nil,
+		GlobalSecondaryIndexes:    d.describeGlobalSecondaryIndexes(spec),
+		GlobalTableVersion:        globalTableVersion,
 		ItemCount:                 ptr[int64](0),
 		KeySchema:                 spec.KeySchema,
-		LatestStreamArn:           nil,
-		LatestStreamLabel:         nil,
+		LatestStreamArn:           streamArn,
+		LatestStreamLabel:         streamLabel,
 		LocalSecondaryIndexes:     nil,
 		OnDemandThroughput:        spec.OnDemandThroughput,
-		ProvisionedThroughput: &dynamodb.ProvisionedThroughputDescription{
-			LastDecreaseDateTime:   &time.Time{},
-			LastIncreaseDateTime:   &time.Time{},
-			NumberOfDecreasesToday: nil,
-			ReadCapacityUnits:      nil,
-			WriteCapacityUnits:     nil,
-		},
-		Replicas:            nil,
-		RestoreSummary:      nil,
-		SSEDescription:      nil,
-		StreamSpecification: spec.StreamSpecification,
-		TableArn:            nil,
+		ProvisionedThroughput:     describeProvisionedThroughput(spec, spec.ProvisionedThroughput),
+		Replicas:                  replicas,
+		RestoreSummary:            nil,
+		SSEDescription:            nil,
+		StreamSpecification:       spec.StreamSpecification,
+		TableArn:                  ptr(d.tableArn(tableName)),
 		TableClassSummary: &dynamodb.TableClassSummary{
 			LastUpdateDateTime: &time.Time{},
 			TableClass:         nil,
@@ -65,14 +82,126 @@ func (d *DB) describeTable(tableName string) *dynamodb.TableDescription {
 		TableId:        nil,
 		TableName:      spec.TableName,
 		TableSizeBytes: ptr[int64](0),
-		TableStatus:    ptr(dynamodb.TableStatusActive),
+		TableStatus:    ptr(d.tableStatus(table)),
+	}
+}
+
+func (d *DB) describeGlobalSecondaryIndexes(spec *dynamodb.CreateTableInput) []*dynamodb.GlobalSecondaryIndexDescription {
+	if len(spec.GlobalSecondaryIndexes) == 0 {
+		return nil
+	}
+
+	descs := make([]*dynamodb.GlobalSecondaryIndexDescription, 0, len(spec.GlobalSecondaryIndexes))
+	for _, gsi := range spec.GlobalSecondaryIndexes {
+		descs = append(descs, &dynamodb.GlobalSecondaryIndexDescription{
+			Backfilling:           ptr(false),
+			IndexArn:              ptr(d.tableArn(*spec.TableName) + "/index/" + *gsi.IndexName),
+			IndexName:             gsi.IndexName,
+			IndexSizeBytes:        ptr[int64](0),
+			IndexStatus:           ptr(dynamodb.IndexStatusActive),
+			ItemCount:             ptr[int64](0),
+			KeySchema:             gsi.KeySchema,
+			OnDemandThroughput:    gsi.OnDemandThroughput,
+			Projection:            gsi.Projection,
+			ProvisionedThroughput: describeProvisionedThroughput(spec, gsi.ProvisionedThroughput),
+		})
+	}
+	return descs
+}
+
+// describeProvisionedThroughput reports provisioned capacity. On-demand
+// tables and indexes report zero capacity, as in DynamoDB.
+func describeProvisionedThroughput(spec *dynamodb.CreateTableInput, throughput *dynamodb.ProvisionedThroughput) *dynamodb.ProvisionedThroughputDescription {
+	desc := &dynamodb.ProvisionedThroughputDescription{
+		LastDecreaseDateTime:   &time.Time{},
+		LastIncreaseDateTime:   &time.Time{},
+		NumberOfDecreasesToday: ptr[int64](0),
+		ReadCapacityUnits:      ptr[int64](0),
+		WriteCapacityUnits:     ptr[int64](0),
+	}
+	if throughput != nil && valOr(spec.BillingMode, dynamodb.BillingModeProvisioned) == dynamodb.BillingModeProvisioned {
+		desc.ReadCapacityUnits = ptr(val(throughput.ReadCapacityUnits))
+		desc.WriteCapacityUnits = ptr(val(throughput.WriteCapacityUnits))
 	}
+	return desc
 }
 
-func (d *DB) DescribeTableWithContext(_ aws.Context, input *dynamodb.DescribeTableInput, _ ...request.Option) (*dynamodb.DescribeTableOutput, error) {
-	return d.DescribeTable(input)
+func (d *DB) DescribeTableWithContext(ctx aws.Context, input *dynamodb.DescribeTableInput, opts ...request.Option) (*dynamodb.DescribeTableOutput, error) {
+	req, output := d.DescribeTableRequest(input)
+	req.SetContext(ctx)
+	req.ApplyOptions(opts...)
+	return output, req.Send()
 }
 
-func (d *DB) DescribeTableRequest(_ *dynamodb.DescribeTableInput) (*request.Request, *dynamodb.DescribeTableOutput) {
-	panic("not implemented: DescribeTableRequest")
+func (d *DB) DescribeTableRequest(input *dynamodb.DescribeTableInput) (*request.Request, *dynamodb.DescribeTableOutput) {
+	return newContextRequest(d, "DescribeTable", input, d.describeTableContext)
+}
+
+// WaitUntilTableExists waits for the table to become ACTIVE, polling
+// DescribeTable like the SDK's waiter does. Tables are only ever CREATING
+// when configured with [WithTransientStatusDelay], so the waiter polls in
+// proportion to that delay rather than every 20 seconds.
+func (d *DB) WaitUntilTableExists(input *dynamodb.DescribeTableInput) error {
+	return d.WaitUntilTableExistsWithContext(aws.BackgroundContext(), input)
+}
+
+func (d *DB) WaitUntilTableExistsWithContext(ctx aws.Context, input *dynamodb.DescribeTableInput, opts ...request.WaiterOption) error {
+	return d.tableWaiter(ctx, "WaitUntilTableExists", input, []request.WaiterAcceptor{
+		{
+			State:    request.SuccessWaiterState,
+			Matcher:  request.PathWaiterMatch,
+			Argument: "Table.TableStatus",
+			Expected: dynamodb.TableStatusActive,
+		},
+		{
+			State:    request.RetryWaiterState,
+			Matcher:  request.ErrorWaiterMatch,
+			Argument: "",
+			Expected: dynamodb.ErrCodeResourceNotFoundException,
+		},
+	}, opts)
+}
+
+// WaitUntilTableNotExists waits for the table to be deleted, polling
+// DescribeTable like the SDK's waiter does.
+func (d *DB) WaitUntilTableNotExists(input *dynamodb.DescribeTableInput) error {
+	return d.WaitUntilTableNotExistsWithContext(aws.BackgroundContext(), input)
+}
+
+func (d *DB) WaitUntilTableNotExistsWithContext(ctx aws.Context, input *dynamodb.DescribeTableInput, opts ...request.WaiterOption) error {
+	return d.tableWaiter(ctx, "WaitUntilTableNotExists", input, []request.WaiterAcceptor{
+		{
+			State:    request.SuccessWaiterState,
+			Matcher:  request.ErrorWaiterMatch,
+			Argument: "",
+			Expected: dynamodb.ErrCodeResourceNotFoundException,
+		},
+	}, opts)
+}
+
+// minWaiterDelay bounds how often waiters poll.
+const minWaiterDelay = 10 * time.Millisecond
+
+func (d *DB) tableWaiter(ctx aws.Context, name string, input *dynamodb.DescribeTableInput, acceptors []request.WaiterAcceptor, opts []request.WaiterOption) error {
+	w := request.Waiter{
+		Name:             name,
+		MaxAttempts:      25,
+		Delay:            request.ConstantWaiterDelay(max(d.transientStatusDelay/10, minWaiterDelay)),
+		RequestOptions:   nil,
+		Acceptors:        acceptors,
+		Logger:           nil,
+		SleepWithContext: nil,
+		NewRequest: func(opts []request.Option) (*request.Request, error) {
+			var inCopy *dynamodb.DescribeTableInput
+			if input != nil {
+				inCopy = shallowCopy(input)
+			}
+			req, _ := d.DescribeTableRequest(inCopy)
+			req.SetContext(ctx)
+			req.ApplyOptions(opts...)
+			return req, nil
+		},
+	}
+	w.ApplyOptions(opts...)
+	return w.WaitWithContext(ctx)
 }
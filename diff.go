@@ -0,0 +1,352 @@
+package fakedynamo
+
+import (
+	"bytes"
+	"cmp"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/shopspring/decimal"
+)
+
+// Diff holds the differences between the items of two DBs, as found by
+// [DB.Diff] or [DB.DiffSnapshot]. Its String method formats them for test
+// failure messages.
+type Diff struct {
+	// Tables holds a TableDiff for each table whose items differ, in name
+	// order.
+	Tables []*TableDiff
+}
+
+// TableDiff holds the differences between the items of a table in two DBs.
+type TableDiff struct {
+	TableName string
+	// Added is set if the table is only in the second DB, and Removed if it's
+	// only in the first. All its items are then added or removed.
+	Added, Removed bool
+	// Items holds an ItemDiff for each item which differs, in order of
+	// partition key, then sort key.
+	Items []*ItemDiff
+}
+
+// ItemDiff describes an item which was added, removed or changed.
+type ItemDiff struct {
+	// Key holds the item's primary key attributes.
+	Key map[string]*dynamodb.AttributeValue
+	// Old is nil if the item was added, and New is nil if it was removed.
+	Old, New map[string]*dynamodb.AttributeValue
+	// Attributes holds the differences between the old and new item, if it
+	// was changed.
+	Attributes []*AttributeDiff
+}
+
+// AttributeDiff describes an attribute which was added, removed or changed.
+// Maps and lists are compared element by element, so the attribute may be
+// nested in another.
+type AttributeDiff struct {
+	// Path locates the attribute, as in a document path: Address.City or
+	// Orders[2], for example.
+	Path string
+	// Old is nil if the attribute was added, and New is nil if it was
+	// removed.
+	Old, New *dynamodb.AttributeValue
+}
+
+// Empty reports whether there are no differences.
+func (d *Diff) Empty() bool {
+	return len(d.Tables) == 0
+}
+
+// Diff compares the items of every table in d with those in other, reporting
+// the changes which would turn d's items into other's. Tables are matched by
+// name, and items by the primary key of the table in d, or in other if the
+// table is only in other. Tables' settings aren't compared, and tables which
+// are being created or deleted are ignored.
+//
+// Attribute values are compared as DynamoDB does: numbers by value, and sets
+// regardless of order.
+func (d *DB) Diff(other *DB) (*Diff, error) {
+	// Collect each DB's items separately, rather than holding both locks.
+	before, err := d.diffTables()
+	if err != nil {
+		return nil, err
+	}
+	after, err := other.diffTables()
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &Diff{Tables: nil}
+	names := slices.Sorted(maps.Keys(before))
+	for name := range after {
+		if _, exists := before[name]; !exists {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		oldTable, oldExists := before[name]
+		newTable, newExists := after[name]
+		tableDiff := &TableDiff{TableName: name, Added: !oldExists, Removed: !newExists, Items: nil}
+		t := oldTable.table
+		if !oldExists {
+			t = newTable.table
+		}
+		tableDiff.Items = diffItems(t, oldTable.items, newTable.items)
+		if len(tableDiff.Items) > 0 || tableDiff.Added || tableDiff.Removed {
+			diff.Tables = append(diff.Tables, tableDiff)
+		}
+	}
+	return diff, nil
+}
+
+// DiffSnapshot compares the items in a snapshot written by [DB.SaveSnapshot]
+// with those in d, reporting the changes made since the snapshot was saved.
+// See [DB.Diff].
+func (d *DB) DiffSnapshot(r io.Reader) (*Diff, error) {
+	snapshot := NewDB()
+	if err := snapshot.LoadSnapshot(r); err != nil {
+		return nil, err
+	}
+	return snapshot.Diff(d)
+}
+
+type diffTable struct {
+	table *table
+	items []avmap
+}
+
+// diffTables returns the items of every ACTIVE table, keyed by table name.
+func (d *DB) diffTables() (map[string]diffTable, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	tables := map[string]diffTable{}
+	var err error
+	d.tables.Ascend(func(t *table) bool {
+		if d.tableStatus(t) != dynamodb.TableStatusActive {
+			return true
+		}
+		collected := diffTable{table: t, items: nil}
+		err = t.items.Ascend(func(item avmap) bool {
+			collected.items = append(collected.items, item)
+			return true
+		})
+		tables[*t.spec.TableName] = collected
+		return err == nil
+	})
+	return tables, err
+}
+
+// diffItems compares two tables' items, which are in key order.
+func diffItems(t *table, before, after []avmap) []*ItemDiff {
+	schema := t.schema
+	keyString := func(item avmap) string {
+		key := diffKeyValueString(item[schema.partition])
+		if schema.sort != "" {
+			key += "\x00" + diffKeyValueString(item[schema.sort])
+		}
+		return key
+	}
+	remaining := make(map[string]avmap, len(after))
+	for _, item := range after {
+		remaining[keyString(item)] = item
+	}
+
+	var diffs []*ItemDiff
+	for _, oldItem := range before {
+		key := keyString(oldItem)
+		newItem, exists := remaining[key]
+		delete(remaining, key)
+		if !exists {
+			diffs = append(diffs, &ItemDiff{Key: t.extractKeys(oldItem), Old: oldItem, New: nil, Attributes: nil})
+		} else if attrs := diffAttributes("", oldItem, newItem); len(attrs) > 0 {
+			diffs = append(diffs, &ItemDiff{Key: t.extractKeys(oldItem), Old: oldItem, New: newItem, Attributes: attrs})
+		}
+	}
+	for _, newItem := range after {
+		if _, added := remaining[keyString(newItem)]; added {
+			diffs = append(diffs, &ItemDiff{Key: t.extractKeys(newItem), Old: nil, New: newItem, Attributes: nil})
+		}
+	}
+	slices.SortStableFunc(diffs, func(a, b *ItemDiff) int {
+		return compareDiffKeys(schema, a.Key, b.Key)
+	})
+	return diffs
+}
+
+// diffKeyValueString is like [keyValueString], but allows the value to be
+// missing, as it may be if the tables' key schemas differ.
+func diffKeyValueString(v *dynamodb.AttributeValue) string {
+	if v == nil {
+		return ""
+	}
+	return keyValueString(v)
+}
+
+// compareDiffKeys orders keys as the tables' storage does.
+func compareDiffKeys(schema tableSchema, a, b avmap) int {
+	c := cmp.Compare(diffKeyValueString(a[schema.partition]), diffKeyValueString(b[schema.partition]))
+	if c != 0 || schema.sort == "" {
+		return c
+	}
+	aSort, bSort := a[schema.sort], b[schema.sort]
+	if aSort != nil && bSort != nil && aSort.B != nil && bSort.B != nil {
+		return bytes.Compare(aSort.B, bSort.B)
+	}
+	return cmp.Compare(diffKeyValueString(aSort), diffKeyValueString(bSort))
+}
+
+// diffAttributes compares two maps of attributes, which are nested at path.
+func diffAttributes(path string, before, after avmap) []*AttributeDiff {
+	names := slices.Sorted(maps.Keys(before))
+	for name := range after {
+		if _, exists := before[name]; !exists {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+
+	var diffs []*AttributeDiff
+	for _, name := range names {
+		childPath := name
+		if path != "" {
+			childPath = path + "." + name
+		}
+		diffs = append(diffs, diffAttributeValues(childPath, before[name], after[name])...)
+	}
+	return diffs
+}
+
+func diffAttributeValues(path string, before, after *dynamodb.AttributeValue) []*AttributeDiff {
+	switch {
+	case before == nil || after == nil:
+		if before == after {
+			return nil
+		}
+	case before.M != nil && after.M != nil:
+		return diffAttributes(path, before.M, after.M)
+	case before.L != nil && after.L != nil:
+		var diffs []*AttributeDiff
+		for i := range max(len(before.L), len(after.L)) {
+			var oldElem, newElem *dynamodb.AttributeValue
+			if i < len(before.L) {
+				oldElem = before.L[i]
+			}
+			if i < len(after.L) {
+				newElem = after.L[i]
+			}
+			diffs = append(diffs, diffAttributeValues(fmt.Sprintf("%s[%d]", path, i), oldElem, newElem)...)
+		}
+		return diffs
+	case attributeValuesEqual(before, after):
+		return nil
+	}
+	return []*AttributeDiff{{Path: path, Old: before, New: after}}
+}
+
+// attributeValuesEqual reports whether two attribute values are equal, as
+// DynamoDB compares them.
+func attributeValuesEqual(a, b *dynamodb.AttributeValue) bool {
+	switch {
+	case a.S != nil || b.S != nil:
+		return a.S != nil && b.S != nil && *a.S == *b.S
+	case a.N != nil || b.N != nil:
+		return a.N != nil && b.N != nil && numbersEqual(*a.N, *b.N)
+	case a.B != nil || b.B != nil:
+		return a.B != nil && b.B != nil && bytes.Equal(a.B, b.B)
+	case a.BOOL != nil || b.BOOL != nil:
+		return a.BOOL != nil && b.BOOL != nil && *a.BOOL == *b.BOOL
+	case a.NULL != nil || b.NULL != nil:
+		return a.NULL != nil && b.NULL != nil
+	case a.SS != nil || b.SS != nil:
+		return setsEqual(a.SS, b.SS, func(x, y *string) bool { return *x == *y })
+	case a.NS != nil || b.NS != nil:
+		return setsEqual(a.NS, b.NS, func(x, y *string) bool { return numbersEqual(*x, *y) })
+	case a.BS != nil || b.BS != nil:
+		return setsEqual(a.BS, b.BS, bytes.Equal)
+	case a.M != nil || b.M != nil:
+		return a.M != nil && b.M != nil && len(diffAttributes("", a.M, b.M)) == 0
+	case a.L != nil || b.L != nil:
+		return a.L != nil && b.L != nil && len(diffAttributeValues("", a, b)) == 0
+	}
+	return true
+}
+
+func numbersEqual(a, b string) bool {
+	x, errX := decimal.NewFromString(a)
+	y, errY := decimal.NewFromString(b)
+	if errX != nil || errY != nil {
+		return a == b
+	}
+	return x.Equal(y)
+}
+
+// setsEqual reports whether two sets have the same elements, in any order.
+// Sets have no duplicates, so it's enough that each element of a is in b.
+func setsEqual[T any](a, b []T, equal func(x, y T) bool) bool {
+	if a == nil || b == nil || len(a) != len(b) {
+		return false
+	}
+	for _, x := range a {
+		if !slices.ContainsFunc(b, func(y T) bool { return equal(x, y) }) {
+			return false
+		}
+	}
+	return true
+}
+
+// String formats the differences like a unified diff: removed items are
+// prefixed with -, added items with + and changed items with ~, followed by
+// their changed attributes. Values are written in DynamoDB JSON.
+func (d *Diff) String() string {
+	var b strings.Builder
+	for _, table := range d.Tables {
+		switch {
+		case table.Added:
+			fmt.Fprintf(&b, "table %s (added):\n", table.TableName)
+		case table.Removed:
+			fmt.Fprintf(&b, "table %s (removed):\n", table.TableName)
+		default:
+			fmt.Fprintf(&b, "table %s:\n", table.TableName)
+		}
+		for _, item := range table.Items {
+			switch {
+			case item.Old == nil:
+				fmt.Fprintf(&b, "  + %s\n", diffJSON(item.New))
+			case item.New == nil:
+				fmt.Fprintf(&b, "  - %s\n", diffJSON(item.Old))
+			default:
+				fmt.Fprintf(&b, "  ~ %s\n", diffJSON(item.Key))
+				for _, attr := range item.Attributes {
+					fmt.Fprintf(&b, "      %s: %s -> %s\n", attr.Path, diffValueJSON(attr.Old), diffValueJSON(attr.New))
+				}
+			}
+		}
+	}
+	return b.String()
+}
+
+// diffJSON formats an item in DynamoDB JSON.
+func diffJSON(item avmap) string {
+	encoded, err := marshalDynamoDBJSON(item)
+	if err != nil {
+		return fmt.Sprintf("(%s)", err)
+	}
+	return string(encoded)
+}
+
+// diffValueJSON formats an attribute value in DynamoDB JSON.
+func diffValueJSON(v *dynamodb.AttributeValue) string {
+	if v == nil {
+		return "(absent)"
+	}
+	encoded, err := marshalDynamoDBJSON(v)
+	if err != nil {
+		return fmt.Sprintf("(%s)", err)
+	}
+	return string(encoded)
+}
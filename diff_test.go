@@ -0,0 +1,111 @@
+package fakedynamo_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/DMRobertson/fakedynamo"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func makeDiffTestDB(t *testing.T, tableName string, items ...map[string]*dynamodb.AttributeValue) *fakedynamo.DB {
+	t.Helper()
+	db := fakedynamo.NewDB()
+	input := exampleCreateTableInputSimplePrimaryKey()
+	input.TableName = &tableName
+	_, err := db.CreateTable(input)
+	require.NoError(t, err)
+	for _, item := range items {
+		_, err := db.PutItem(&dynamodb.PutItemInput{Item: item, TableName: &tableName}) //nolint:exhaustruct
+		require.NoError(t, err)
+	}
+	return db
+}
+
+func TestDB_Diff(t *testing.T) {
+	t.Parallel()
+	before := makeDiffTestDB(t, "things",
+		map[string]*dynamodb.AttributeValue{"Foo": {S: ptr("kept")}, "Count": {N: ptr("1.0")}, "Tags": {SS: []*string{ptr("x"), ptr("y")}}},
+		map[string]*dynamodb.AttributeValue{"Foo": {S: ptr("removed")}},
+		map[string]*dynamodb.AttributeValue{"Foo": {S: ptr("changed")}, "Count": {N: ptr("1")}, "Address": {M: map[string]*dynamodb.AttributeValue{
+			"City": {S: ptr("Leeds")},
+			"Zip":  {S: ptr("LS1")},
+		}}, "Lines": {L: []*dynamodb.AttributeValue{{S: ptr("a")}}}},
+	)
+	after := makeDiffTestDB(t, "things",
+		map[string]*dynamodb.AttributeValue{"Foo": {S: ptr("kept")}, "Count": {N: ptr("1")}, "Tags": {SS: []*string{ptr("y"), ptr("x")}}},
+		map[string]*dynamodb.AttributeValue{"Foo": {S: ptr("added")}},
+		map[string]*dynamodb.AttributeValue{"Foo": {S: ptr("changed")}, "Count": {N: ptr("2")}, "Address": {M: map[string]*dynamodb.AttributeValue{
+			"City": {S: ptr("York")},
+			"Zip":  {S: ptr("LS1")},
+		}}, "Lines": {L: []*dynamodb.AttributeValue{{S: ptr("a")}, {S: ptr("b")}}}, "Note": {S: ptr("new")}},
+	)
+
+	diff, err := before.Diff(after)
+	require.NoError(t, err)
+	require.Len(t, diff.Tables, 1)
+	table := diff.Tables[0]
+	assert.Equal(t, "things", table.TableName)
+	assert.False(t, table.Added || table.Removed)
+	require.Len(t, table.Items, 3)
+	assert.Equal(t, "added", val(table.Items[0].Key["Foo"].S))
+	assert.Nil(t, table.Items[0].Old)
+	assert.Equal(t, "removed", val(table.Items[2].Key["Foo"].S))
+	assert.Nil(t, table.Items[2].New)
+
+	changed := table.Items[1]
+	var paths []string
+	for _, attr := range changed.Attributes {
+		paths = append(paths, attr.Path)
+	}
+	assert.Equal(t, []string{"Address.City", "Count", "Lines[1]", "Note"}, paths)
+
+	assert.Equal(t, `table things:
+  + {"Foo":{"S":"added"}}
+  ~ {"Foo":{"S":"changed"}}
+      Address.City: {"S":"Leeds"} -> {"S":"York"}
+      Count: {"N":"1"} -> {"N":"2"}
+      Lines[1]: (absent) -> {"S":"b"}
+      Note: (absent) -> {"S":"new"}
+  - {"Foo":{"S":"removed"}}
+`, diff.String())
+
+	same, err := before.Diff(before)
+	require.NoError(t, err)
+	assert.True(t, same.Empty(), same.String())
+}
+
+func TestDB_Diff_Tables(t *testing.T) {
+	t.Parallel()
+	before := makeDiffTestDB(t, "old", map[string]*dynamodb.AttributeValue{"Foo": {S: ptr("a")}})
+	after := makeDiffTestDB(t, "new")
+
+	diff, err := before.Diff(after)
+	require.NoError(t, err)
+	assert.Equal(t, `table new (added):
+table old (removed):
+  - {"Foo":{"S":"a"}}
+`, diff.String())
+}
+
+func TestDB_DiffSnapshot(t *testing.T) {
+	t.Parallel()
+	db := makeDiffTestDB(t, "things", map[string]*dynamodb.AttributeValue{"Foo": {S: ptr("a")}})
+	var snapshot bytes.Buffer
+	require.NoError(t, db.SaveSnapshot(&snapshot))
+
+	_, err := db.PutItem(&dynamodb.PutItemInput{ //nolint:exhaustruct
+		Item:      map[string]*dynamodb.AttributeValue{"Foo": {S: ptr("a")}, "Bar": {BOOL: ptr(true)}},
+		TableName: ptr("things"),
+	})
+	require.NoError(t, err)
+
+	diff, err := db.DiffSnapshot(bytes.NewReader(snapshot.Bytes()))
+	require.NoError(t, err)
+	assert.Equal(t, `table things:
+  ~ {"Foo":{"S":"a"}}
+      Bar: (absent) -> {"BOOL":true}
+`, diff.String())
+}
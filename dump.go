@@ -0,0 +1,62 @@
+package fakedynamo
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Dump writes the items of a table to w in DynamoDB JSON, one item per line.
+// Items are written in order of partition key, then sort key, and their
+// attributes in name order, so that the output is deterministic. Dump fails
+// with a ResourceNotFoundException if the table doesn't exist or isn't
+// ACTIVE.
+func (d *DB) Dump(tableName string, w io.Writer) error {
+	return d.dump(tableName, w, false)
+}
+
+// DumpPretty writes the items of a table to w like [DB.Dump], but with each
+// item indented over several lines.
+func (d *DB) DumpPretty(tableName string, w io.Writer) error {
+	return d.dump(tableName, w, true)
+}
+
+func (d *DB) dump(tableName string, w io.Writer, pretty bool) error {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	t, err := d.activeTable(tableName)
+	if err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriter(w)
+	var indented bytes.Buffer
+	ascendErr := t.items.Ascend(func(item avmap) bool {
+		var line []byte
+		line, err = marshalDynamoDBJSON(item)
+		if err != nil {
+			return false
+		}
+		if pretty {
+			indented.Reset()
+			if err = json.Indent(&indented, line, "", "  "); err != nil {
+				return false
+			}
+			line = indented.Bytes()
+		}
+		_, err = bw.Write(append(line, '\n'))
+		return err == nil
+	})
+	if err == nil {
+		err = ascendErr
+	}
+	if err == nil {
+		err = bw.Flush()
+	}
+	if err != nil {
+		return fmt.Errorf("error dumping table %s: %w", tableName, err)
+	}
+	return nil
+}
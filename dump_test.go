@@ -0,0 +1,74 @@
+package fakedynamo_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/DMRobertson/fakedynamo"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDB_Dump(t *testing.T) {
+	t.Parallel()
+	db := fakedynamo.NewDB()
+	input := exampleCreateTableInputCompositePrimaryKey()
+	_, err := db.CreateTable(input)
+	require.NoError(t, err)
+	for _, item := range []map[string]*dynamodb.AttributeValue{
+		{"Foo": {S: ptr("b")}, "Bar": {S: ptr("1")}},
+		{"Foo": {S: ptr("a")}, "Bar": {S: ptr("2")}, "Tags": {SS: []*string{ptr("x")}}},
+		{"Foo": {S: ptr("a")}, "Bar": {S: ptr("1")}, "Count": {N: ptr("3")}},
+	} {
+		_, err := db.PutItem(&dynamodb.PutItemInput{Item: item, TableName: input.TableName}) //nolint:exhaustruct
+		require.NoError(t, err)
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, db.Dump(*input.TableName, &buf))
+	assert.Equal(t, `{"Bar":{"S":"1"},"Count":{"N":"3"},"Foo":{"S":"a"}}
+{"Bar":{"S":"2"},"Foo":{"S":"a"},"Tags":{"SS":["x"]}}
+{"Bar":{"S":"1"},"Foo":{"S":"b"}}
+`, buf.String())
+
+	buf.Reset()
+	require.NoError(t, db.DumpPretty(*input.TableName, &buf))
+	assert.Equal(t, `{
+  "Bar": {
+    "S": "1"
+  },
+  "Count": {
+    "N": "3"
+  },
+  "Foo": {
+    "S": "a"
+  }
+}
+{
+  "Bar": {
+    "S": "2"
+  },
+  "Foo": {
+    "S": "a"
+  },
+  "Tags": {
+    "SS": [
+      "x"
+    ]
+  }
+}
+{
+  "Bar": {
+    "S": "1"
+  },
+  "Foo": {
+    "S": "b"
+  }
+}
+`, buf.String())
+
+	err = db.Dump("missing", &buf)
+	var notFound *dynamodb.ResourceNotFoundException
+	assert.ErrorAs(t, err, &notFound)
+}
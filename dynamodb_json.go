@@ -0,0 +1,340 @@
+package fakedynamo
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// marshalDynamoDBJSON encodes a value in DynamoDB JSON, the format used by
+// the DynamoDB wire protocol, exports and imports. Attribute values are
+// written with explicit type tags, e.g. {"S": "hello"}. The value should be
+// an avmap, or a struct or map built from them.
+//
+// Values are encoded as the SDK's JSON protocol does: struct fields are named
+// by their locationName tag, nil fields are left out, map keys are sorted,
+// blobs are base64 encoded and timestamps are written in seconds since the
+// epoch.
+func marshalDynamoDBJSON(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := encodeDynamoDBJSON(&buf, reflect.ValueOf(v)); err != nil {
+		return nil, fmt.Errorf("error encoding DynamoDB JSON: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// unmarshalDynamoDBJSON decodes DynamoDB JSON into v, which must be a pointer
+// to a struct. Fields of type avmap are decoded from their DynamoDB JSON
+// representation; unknown fields are ignored.
+func unmarshalDynamoDBJSON(data []byte, v any) error {
+	var decoded any
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	err := decoder.Decode(&decoded)
+	if err == nil {
+		err = decodeDynamoDBJSON(reflect.ValueOf(v), decoded)
+	}
+	if err != nil {
+		return fmt.Errorf("error decoding DynamoDB JSON: %w", err)
+	}
+	return nil
+}
+
+var (
+	timeType      = reflect.TypeFor[time.Time]()
+	byteSliceType = reflect.TypeFor[[]byte]()
+)
+
+func encodeDynamoDBJSON(buf *bytes.Buffer, v reflect.Value) error {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			buf.WriteString("null")
+			return nil
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		buf.WriteString("null")
+		return nil
+	}
+
+	switch {
+	case v.Type() == timeType:
+		ms := v.Interface().(time.Time).UnixMilli()
+		buf.WriteString(strconv.FormatFloat(float64(ms)/1e3, 'f', -1, 64))
+		return nil
+	case v.Type() == byteSliceType:
+		writeJSONString(buf, base64.StdEncoding.EncodeToString(v.Bytes()))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		buf.WriteByte('{')
+		first := true
+		for i := range v.NumField() {
+			name, ok := dynamoDBJSONFieldName(v.Type().Field(i))
+			member := v.Field(i)
+			if !ok || isNilDynamoDBJSONValue(member) {
+				continue
+			}
+			if !first {
+				buf.WriteByte(',')
+			}
+			first = false
+			writeJSONString(buf, name)
+			buf.WriteByte(':')
+			if err := encodeDynamoDBJSON(buf, member); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	case reflect.Slice, reflect.Array:
+		buf.WriteByte('[')
+		for i := range v.Len() {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := encodeDynamoDBJSON(buf, v.Index(i)); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return fmt.Errorf("unsupported map key type %s", v.Type().Key())
+		}
+		keys := v.MapKeys()
+		slices.SortFunc(keys, func(a, b reflect.Value) int {
+			return strings.Compare(a.String(), b.String())
+		})
+		buf.WriteByte('{')
+		for i, key := range keys {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			writeJSONString(buf, key.String())
+			buf.WriteByte(':')
+			if err := encodeDynamoDBJSON(buf, v.MapIndex(key)); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	case reflect.String:
+		writeJSONString(buf, v.String())
+	case reflect.Bool:
+		buf.WriteString(strconv.FormatBool(v.Bool()))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		buf.WriteString(strconv.FormatInt(v.Int(), 10))
+	case reflect.Float32, reflect.Float64:
+		switch f := v.Float(); {
+		case math.IsNaN(f):
+			writeJSONString(buf, "NaN")
+		case math.IsInf(f, 1):
+			writeJSONString(buf, "Infinity")
+		case math.IsInf(f, -1):
+			writeJSONString(buf, "-Infinity")
+		default:
+			buf.WriteString(strconv.FormatFloat(f, 'f', -1, 64))
+		}
+	default:
+		return fmt.Errorf("unsupported value of type %s", v.Type())
+	}
+	return nil
+}
+
+// dynamoDBJSONFieldName returns the name of a struct field in DynamoDB JSON,
+// or false if the field isn't part of the encoding: it's unexported, or it's
+// sent outside the body, like the SDK's response metadata.
+func dynamoDBJSONFieldName(field reflect.StructField) (string, bool) {
+	if !field.IsExported() || field.Tag.Get("json") == "-" ||
+		field.Tag.Get("location") != "" || field.Tag.Get("ignore") != "" {
+		return "", false
+	}
+	if name := field.Tag.Get("locationName"); name != "" {
+		return name, true
+	}
+	return field.Name, true
+}
+
+func isNilDynamoDBJSONValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface, reflect.Slice, reflect.Map:
+		return v.IsNil()
+	default:
+		return false
+	}
+}
+
+// writeJSONString writes s as a JSON string. Unlike [json.Marshal], it
+// doesn't escape HTML characters, just as the SDK doesn't.
+func writeJSONString(buf *bytes.Buffer, s string) {
+	encoder := json.NewEncoder(buf)
+	encoder.SetEscapeHTML(false)
+	_ = encoder.Encode(s) // Encoding a string can't fail.
+	buf.Truncate(buf.Len() - 1)
+}
+
+// decodeDynamoDBJSON stores data, as decoded by encoding/json with numbers
+// preserved, in v, which must be a pointer or otherwise settable.
+func decodeDynamoDBJSON(v reflect.Value, data any) error {
+	if data == nil {
+		return nil
+	}
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			if !v.CanSet() {
+				return errors.New("cannot decode into a nil pointer")
+			}
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return decodeDynamoDBJSON(v.Elem(), data)
+	}
+
+	switch {
+	case v.Type() == timeType:
+		number, ok := data.(json.Number)
+		if !ok {
+			return decodeTypeError(v, data)
+		}
+		seconds, err := number.Float64()
+		if err != nil {
+			return err
+		}
+		whole, frac := math.Modf(seconds)
+		v.Set(reflect.ValueOf(time.Unix(int64(whole), int64(math.Round(frac*1e3))*int64(time.Millisecond)).UTC()))
+		return nil
+	case v.Type() == byteSliceType:
+		s, ok := data.(string)
+		if !ok {
+			return decodeTypeError(v, data)
+		}
+		b, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			return err
+		}
+		v.SetBytes(b)
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		object, ok := data.(map[string]any)
+		if !ok {
+			return decodeTypeError(v, data)
+		}
+		for i := range v.NumField() {
+			name, ok := dynamoDBJSONFieldName(v.Type().Field(i))
+			if !ok {
+				continue
+			}
+			if err := decodeDynamoDBJSON(v.Field(i), object[name]); err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+		}
+	case reflect.Slice:
+		list, ok := data.([]any)
+		if !ok {
+			return decodeTypeError(v, data)
+		}
+		decoded := reflect.MakeSlice(v.Type(), len(list), len(list))
+		for i, elem := range list {
+			if err := decodeDynamoDBJSON(decoded.Index(i), elem); err != nil {
+				return err
+			}
+		}
+		v.Set(decoded)
+	case reflect.Map:
+		object, ok := data.(map[string]any)
+		if !ok || v.Type().Key().Kind() != reflect.String {
+			return decodeTypeError(v, data)
+		}
+		decoded := reflect.MakeMapWithSize(v.Type(), len(object))
+		for key, elem := range object {
+			value := reflect.New(v.Type().Elem()).Elem()
+			if err := decodeDynamoDBJSON(value, elem); err != nil {
+				return err
+			}
+			decoded.SetMapIndex(reflect.ValueOf(key).Convert(v.Type().Key()), value)
+		}
+		v.Set(decoded)
+	case reflect.String:
+		s, ok := data.(string)
+		if !ok {
+			return decodeTypeError(v, data)
+		}
+		v.SetString(s)
+	case reflect.Bool:
+		b, ok := data.(bool)
+		if !ok {
+			return decodeTypeError(v, data)
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		number, ok := data.(json.Number)
+		if !ok {
+			return decodeTypeError(v, data)
+		}
+		f, err := number.Float64()
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(f))
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		switch data := data.(type) {
+		case json.Number:
+			var err error
+			if f, err = data.Float64(); err != nil {
+				return err
+			}
+		case string:
+			switch {
+			case strings.EqualFold(data, "NaN"):
+				f = math.NaN()
+			case strings.EqualFold(data, "Infinity"):
+				f = math.Inf(1)
+			case strings.EqualFold(data, "-Infinity"):
+				f = math.Inf(-1)
+			default:
+				return decodeTypeError(v, data)
+			}
+		default:
+			return decodeTypeError(v, data)
+		}
+		v.SetFloat(f)
+	case reflect.Interface:
+		if v.NumMethod() != 0 {
+			return decodeTypeError(v, data)
+		}
+		v.Set(reflect.ValueOf(data))
+	default:
+		return decodeTypeError(v, data)
+	}
+	return nil
+}
+
+func decodeTypeError(v reflect.Value, data any) error {
+	var kind string
+	switch data.(type) {
+	case map[string]any:
+		kind = "an object"
+	case []any:
+		kind = "an array"
+	case string:
+		kind = "a string"
+	case json.Number:
+		kind = "a number"
+	case bool:
+		kind = "a boolean"
+	}
+	return fmt.Errorf("cannot decode %s into a value of type %s", kind, v.Type())
+}
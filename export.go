@@ -0,0 +1,555 @@
+package fakedynamo
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/md5" //nolint:gosec // S3 uses MD5 for checksums and ETags
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// maxIncrementalExportWindow is the longest period that an incremental export
+// may cover.
+//
+// DynamoDB also requires the window to be at least 15 minutes long. We don't
+// enforce that, so that tests needn't wait around for 15 minutes.
+const maxIncrementalExportWindow = 24 * time.Hour
+
+// ExportTableToPointInTime writes the table's contents to the S3 stand-in
+// directory configured with [WithS3Directory], following the layout described
+// in https://docs.aws.amazon.com/amazondynamodb/latest/developerguide/S3DataExport.Output.html.
+//
+// Exports happen synchronously: the returned export has already COMPLETED.
+func (d *DB) ExportTableToPointInTime(input *dynamodb.ExportTableToPointInTimeInput) (*dynamodb.ExportTableToPointInTimeOutput, error) {
+	return d.exportTableToPointInTimeContext(aws.BackgroundContext(), input)
+}
+
+func (d *DB) exportTableToPointInTimeContext(ctx aws.Context, input *dynamodb.ExportTableToPointInTimeInput) (*dynamodb.ExportTableToPointInTimeOutput, error) {
+	if err := d.injectFault(ctx, "ExportTableToPointInTime", input); err != nil {
+		return nil, err
+	}
+
+	var errs []error
+	if input.TableArn == nil {
+		errs = append(errs, newValidationError("TableArn is a required field"))
+	}
+	if input.S3Bucket == nil {
+		errs = append(errs, newValidationError("S3Bucket is a required field"))
+	}
+
+	exportFormat := valOr(input.ExportFormat, dynamodb.ExportFormatDynamodbJson)
+	switch exportFormat {
+	case dynamodb.ExportFormatDynamodbJson:
+	case dynamodb.ExportFormatIon:
+		errs = append(errs, errors.New("not implemented: ExportFormat ION"))
+	default:
+		errs = append(errs, newValidationError("ExportFormat must be DYNAMODB_JSON or ION"))
+	}
+
+	exportType := valOr(input.ExportType, dynamodb.ExportTypeFullExport)
+	incremental := input.IncrementalExportSpecification
+	switch exportType {
+	case dynamodb.ExportTypeFullExport:
+		if incremental != nil {
+			errs = append(errs, newValidationError("IncrementalExportSpecification must not be set for a FULL_EXPORT"))
+		}
+	case dynamodb.ExportTypeIncrementalExport:
+		if incremental == nil {
+			errs = append(errs, newValidationError("IncrementalExportSpecification is required for an INCREMENTAL_EXPORT"))
+		} else if incremental.ExportFromTime == nil {
+			errs = append(errs, newValidationError("IncrementalExportSpecification.ExportFromTime is a required field"))
+		}
+		if input.ExportTime != nil {
+			errs = append(errs, newValidationError("ExportTime must not be set for an INCREMENTAL_EXPORT"))
+		}
+	default:
+		errs = append(errs, newValidationError("ExportType must be FULL_EXPORT or INCREMENTAL_EXPORT"))
+	}
+
+	viewType := dynamodb.ExportViewTypeNewAndOldImages
+	if incremental != nil {
+		viewType = valOr(incremental.ExportViewType, viewType)
+		switch viewType {
+		case dynamodb.ExportViewTypeNewAndOldImages:
+		case dynamodb.ExportViewTypeNewImage:
+		default:
+			errs = append(errs, newValidationError("ExportViewType must be NEW_IMAGE or NEW_AND_OLD_IMAGES"))
+		}
+	}
+
+	if d.s3Dir == "" {
+		errs = append(errs, errors.New("fakedynamo: no S3 stand-in directory configured, see WithS3Directory"))
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	desc, exportID, lines, err := d.collectExport(input, exportFormat, exportType, viewType)
+	if err != nil {
+		return nil, err
+	}
+	// Write the export without holding the lock, so that other operations
+	// needn't wait for the file system.
+	if err := d.writeExport(desc, exportID, lines); err != nil {
+		return nil, err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.exports = append(d.exports, desc)
+	return &dynamodb.ExportTableToPointInTimeOutput{
+		ExportDescription: desc,
+	}, nil
+}
+
+// collectExport renders the lines of an export's data file, and describes
+// the export.
+func (d *DB) collectExport(
+	input *dynamodb.ExportTableToPointInTimeInput, exportFormat, exportType, viewType string,
+) (*dynamodb.ExportDescription, string, [][]byte, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	incremental := input.IncrementalExportSpecification
+	tableName, ok := d.tableNameFromArn(*input.TableArn)
+	if !ok {
+		return nil, "", nil, &dynamodb.TableNotFoundException{}
+	}
+	t, exists := d.tables.Get(tableKey(tableName))
+	if !exists {
+		return nil, "", nil, &dynamodb.TableNotFoundException{}
+	}
+	if t.pitr == nil {
+		return nil, "", nil, &dynamodb.PointInTimeRecoveryUnavailableException{}
+	}
+
+	now := time.Now().UTC()
+	earliest := t.pitr.earliestRestorable(now)
+
+	var lines [][]byte
+	var err error
+	desc := &dynamodb.ExportDescription{
+		ClientToken:    input.ClientToken,
+		ExportFormat:   &exportFormat,
+		ExportStatus:   ptr(dynamodb.ExportStatusCompleted),
+		ExportType:     &exportType,
+		S3Bucket:       input.S3Bucket,
+		S3BucketOwner:  input.S3BucketOwner,
+		S3Prefix:       input.S3Prefix,
+		S3SseAlgorithm: input.S3SseAlgorithm,
+		S3SseKmsKeyId:  input.S3SseKmsKeyId,
+		StartTime:      &now,
+		TableArn:       input.TableArn,
+	}
+
+	if exportType == dynamodb.ExportTypeFullExport {
+		exportTime := valOr(input.ExportTime, now)
+		if exportTime.Before(earliest) || exportTime.After(now) {
+			return nil, "", nil, &dynamodb.InvalidExportTimeException{
+				Message_: aws.String("ExportTime must be within the table's point-in-time recovery window"),
+			}
+		}
+		desc.ExportTime = &exportTime
+		lines, err = t.fullExportLines(exportTime)
+	} else {
+		from := *incremental.ExportFromTime
+		to := valOr(incremental.ExportToTime, now)
+		switch {
+		case !from.Before(to):
+			return nil, "", nil, &dynamodb.InvalidExportTimeException{
+				Message_: aws.String("ExportFromTime must be before ExportToTime"),
+			}
+		case to.Sub(from) > maxIncrementalExportWindow:
+			return nil, "", nil, &dynamodb.InvalidExportTimeException{
+				Message_: aws.String("incremental exports may cover at most 24 hours"),
+			}
+		case from.Before(earliest) || to.After(now):
+			return nil, "", nil, &dynamodb.InvalidExportTimeException{
+				Message_: aws.String("export period must be within the table's point-in-time recovery window"),
+			}
+		}
+		desc.IncrementalExportSpecification = &dynamodb.IncrementalExportSpecification{
+			ExportFromTime: &from,
+			ExportToTime:   &to,
+			ExportViewType: &viewType,
+		}
+		lines, err = t.incrementalExportLines(from, to, viewType)
+	}
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	exportID := fmt.Sprintf("%014d-%08x", now.UnixMilli(), rand.Uint32()) //nolint:gosec
+	desc.ExportArn = ptr(fmt.Sprintf("%s/export/%s", d.tableArn(tableName), exportID))
+	desc.ItemCount = ptr(int64(len(lines)))
+	return desc, exportID, lines, nil
+}
+
+// itemsAt reconstructs the contents of the table as they were at the given
+// time, by undoing every write made after it. The items are sorted by their
+// primary key. Callers must ensure that PITR is enabled, and that the given
+// time is within the recovery window.
+func (t *table) itemsAt(at time.Time) ([]avmap, error) {
+	items := make(map[string]avmap)
+	err := t.items.Ascend(func(item avmap) bool {
+		items[t.itemKeyString(item)] = item
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, change := range slices.Backward(t.pitr.changes) {
+		if !change.at.After(at) {
+			break
+		}
+		key := t.itemKeyString(change.keys)
+		if change.oldImage == nil {
+			delete(items, key)
+		} else {
+			items[key] = change.oldImage
+		}
+	}
+
+	keys := make([]string, 0, len(items))
+	for key := range items {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	result := make([]avmap, 0, len(keys))
+	for _, key := range keys {
+		result = append(result, items[key])
+	}
+	return result, nil
+}
+
+// fullExportLines renders the table contents at the given time in the full
+// export format.
+func (t *table) fullExportLines(at time.Time) ([][]byte, error) {
+	items, err := t.itemsAt(at)
+	if err != nil {
+		return nil, err
+	}
+	var lines [][]byte
+	for _, item := range items {
+		line, err := marshalDynamoDBJSON(struct{ Item avmap }{item})
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, line)
+	}
+	return lines, nil
+}
+
+// incrementalExportRecord is a single line of an incremental export. See
+// https://docs.aws.amazon.com/amazondynamodb/latest/developerguide/S3DataExport.Output.html#S3DataExport.Output_Data_Incremental
+type incrementalExportRecord struct {
+	Metadata struct {
+		WriteTimestampMicros *dynamodb.AttributeValue
+	}
+	Keys     avmap
+	NewImage avmap
+	OldImage avmap
+}
+
+// incrementalExportLines renders every item which changed in the half-open
+// period (from, to] in the incremental export format.
+func (t *table) incrementalExportLines(from, to time.Time, viewType string) ([][]byte, error) {
+	var order []string
+	records := make(map[string]*incrementalExportRecord)
+	for _, change := range t.pitr.changes {
+		if !change.at.After(from) {
+			continue
+		} else if change.at.After(to) {
+			break
+		}
+
+		key := t.itemKeyString(change.keys)
+		record, seen := records[key]
+		if !seen {
+			record = &incrementalExportRecord{Keys: change.keys}
+			if viewType == dynamodb.ExportViewTypeNewAndOldImages {
+				record.OldImage = change.oldImage
+			}
+			records[key] = record
+			order = append(order, key)
+		}
+		record.NewImage = change.newImage
+		record.Metadata.WriteTimestampMicros = &dynamodb.AttributeValue{
+			N: ptr(strconv.FormatInt(change.at.UnixMicro(), 10)),
+		}
+	}
+
+	var lines [][]byte
+	for _, key := range order {
+		record := records[key]
+		if record.NewImage == nil && record.OldImage == nil &&
+			viewType == dynamodb.ExportViewTypeNewAndOldImages {
+			// The item was created and deleted within the export period.
+			continue
+		}
+		line, err := marshalDynamoDBJSON(record)
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, line)
+	}
+	return lines, nil
+}
+
+// exportManifestSummary is the contents of manifest-summary.json.
+type exportManifestSummary struct {
+	Version            string     `json:"version"`
+	ExportArn          string     `json:"exportArn"`
+	StartTime          time.Time  `json:"startTime"`
+	EndTime            time.Time  `json:"endTime"`
+	TableArn           string     `json:"tableArn"`
+	ExportTime         *time.Time `json:"exportTime,omitempty"`
+	ExportFromTime     *time.Time `json:"exportFromTime,omitempty"`
+	ExportToTime       *time.Time `json:"exportToTime,omitempty"`
+	S3Bucket           string     `json:"s3Bucket"`
+	S3Prefix           *string    `json:"s3Prefix"`
+	S3SseAlgorithm     *string    `json:"s3SseAlgorithm"`
+	S3SseKmsKeyID      *string    `json:"s3SseKmsKeyId"`
+	ManifestFilesS3Key string     `json:"manifestFilesS3Key"`
+	BilledSizeBytes    int64      `json:"billedSizeBytes"`
+	ItemCount          int64      `json:"itemCount"`
+	OutputFormat       string     `json:"outputFormat"`
+	OutputView         *string    `json:"outputView,omitempty"`
+	ExportType         string     `json:"exportType"`
+}
+
+// exportManifestFile is a line of manifest-files.json.
+type exportManifestFile struct {
+	ItemCount     int64  `json:"itemCount"`
+	MD5Checksum   string `json:"md5Checksum"`
+	ETag          string `json:"etag"`
+	DataFileS3Key string `json:"dataFileS3Key"`
+}
+
+// writeExport writes the export's data file and manifests to the S3 stand-in
+// directory, and fills in the remaining fields of desc.
+func (d *DB) writeExport(desc *dynamodb.ExportDescription, exportID string, lines [][]byte) error {
+	exportKey := path.Join(val(desc.S3Prefix), "AWSDynamoDB", exportID)
+	dataKey := path.Join(exportKey, "data", fmt.Sprintf("%016x.json.gz", rand.Uint64())) //nolint:gosec
+	manifestFilesKey := path.Join(exportKey, "manifest-files.json")
+	manifestSummaryKey := path.Join(exportKey, "manifest-summary.json")
+
+	var data bytes.Buffer
+	gz := gzip.NewWriter(&data)
+	for _, line := range lines {
+		_, _ = gz.Write(line)
+		_, _ = gz.Write([]byte("\n"))
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("error compressing export: %w", err)
+	}
+	checksum := md5.Sum(data.Bytes()) //nolint:gosec
+
+	manifestFiles, err := json.Marshal(exportManifestFile{
+		ItemCount:     *desc.ItemCount,
+		MD5Checksum:   base64.StdEncoding.EncodeToString(checksum[:]),
+		ETag:          hex.EncodeToString(checksum[:]),
+		DataFileS3Key: dataKey,
+	})
+	if err != nil {
+		return fmt.Errorf("error encoding export manifest: %w", err)
+	}
+
+	endTime := time.Now().UTC()
+	desc.EndTime = &endTime
+	desc.BilledSizeBytes = ptr(int64(data.Len()))
+	desc.ExportManifest = &manifestSummaryKey
+
+	summary := exportManifestSummary{
+		Version:            "2020-06-30",
+		ExportArn:          *desc.ExportArn,
+		StartTime:          *desc.StartTime,
+		EndTime:            endTime,
+		TableArn:           *desc.TableArn,
+		ExportTime:         desc.ExportTime,
+		ExportFromTime:     nil,
+		ExportToTime:       nil,
+		S3Bucket:           *desc.S3Bucket,
+		S3Prefix:           desc.S3Prefix,
+		S3SseAlgorithm:     desc.S3SseAlgorithm,
+		S3SseKmsKeyID:      desc.S3SseKmsKeyId,
+		ManifestFilesS3Key: manifestFilesKey,
+		BilledSizeBytes:    *desc.BilledSizeBytes,
+		ItemCount:          *desc.ItemCount,
+		OutputFormat:       *desc.ExportFormat,
+		OutputView:         nil,
+		ExportType:         *desc.ExportType,
+	}
+	if spec := desc.IncrementalExportSpecification; spec != nil {
+		summary.Version = "2023-08-01"
+		summary.ExportFromTime = spec.ExportFromTime
+		summary.ExportToTime = spec.ExportToTime
+		summary.OutputView = spec.ExportViewType
+	}
+	manifestSummary, err := json.Marshal(summary)
+	if err != nil {
+		return fmt.Errorf("error encoding export manifest: %w", err)
+	}
+
+	files := map[string][]byte{
+		dataKey:            data.Bytes(),
+		manifestFilesKey:   append(manifestFiles, '\n'),
+		manifestSummaryKey: manifestSummary,
+	}
+	for key, contents := range files {
+		if err := d.writeS3Object(*desc.S3Bucket, key, contents); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeS3Object writes an object to the S3 stand-in directory.
+func (d *DB) writeS3Object(bucket, key string, contents []byte) error {
+	filename := filepath.Join(d.s3Dir, bucket, filepath.FromSlash(key))
+	if err := os.MkdirAll(filepath.Dir(filename), 0o750); err != nil {
+		return fmt.Errorf("error writing to S3 stand-in: %w", err)
+	}
+	if err := os.WriteFile(filename, contents, 0o600); err != nil {
+		return fmt.Errorf("error writing to S3 stand-in: %w", err)
+	}
+	return nil
+}
+
+func (d *DB) ExportTableToPointInTimeWithContext(ctx aws.Context, input *dynamodb.ExportTableToPointInTimeInput, opts ...request.Option) (*dynamodb.ExportTableToPointInTimeOutput, error) {
+	req, output := d.ExportTableToPointInTimeRequest(input)
+	req.SetContext(ctx)
+	req.ApplyOptions(opts...)
+	return output, req.Send()
+}
+
+func (d *DB) ExportTableToPointInTimeRequest(input *dynamodb.ExportTableToPointInTimeInput) (*request.Request, *dynamodb.ExportTableToPointInTimeOutput) {
+	return newContextRequest(d, "ExportTableToPointInTime", input, d.exportTableToPointInTimeContext)
+}
+
+func (d *DB) DescribeExport(input *dynamodb.DescribeExportInput) (*dynamodb.DescribeExportOutput, error) {
+	return d.describeExportContext(aws.BackgroundContext(), input)
+}
+
+func (d *DB) describeExportContext(ctx aws.Context, input *dynamodb.DescribeExportInput) (*dynamodb.DescribeExportOutput, error) {
+	if err := d.injectFault(ctx, "DescribeExport", input); err != nil {
+		return nil, err
+	}
+
+	if input.ExportArn == nil {
+		return nil, newValidationError("ExportArn is a required field")
+	}
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	for _, export := range d.exports {
+		if *export.ExportArn == *input.ExportArn {
+			return &dynamodb.DescribeExportOutput{ExportDescription: export}, nil
+		}
+	}
+	return nil, &dynamodb.ExportNotFoundException{}
+}
+
+func (d *DB) DescribeExportWithContext(ctx aws.Context, input *dynamodb.DescribeExportInput, opts ...request.Option) (*dynamodb.DescribeExportOutput, error) {
+	req, output := d.DescribeExportRequest(input)
+	req.SetContext(ctx)
+	req.ApplyOptions(opts...)
+	return output, req.Send()
+}
+
+func (d *DB) DescribeExportRequest(input *dynamodb.DescribeExportInput) (*request.Request, *dynamodb.DescribeExportOutput) {
+	return newContextRequest(d, "DescribeExport", input, d.describeExportContext)
+}
+
+func (d *DB) ListExports(input *dynamodb.ListExportsInput) (*dynamodb.ListExportsOutput, error) {
+	return d.listExportsContext(aws.BackgroundContext(), input)
+}
+
+func (d *DB) listExportsContext(ctx aws.Context, input *dynamodb.ListExportsInput) (*dynamodb.ListExportsOutput, error) {
+	if err := d.injectFault(ctx, "ListExports", input); err != nil {
+		return nil, err
+	}
+
+	maxResults := valOr(input.MaxResults, 25)
+	if maxResults < 1 || maxResults > 25 {
+		return nil, newValidationError("MaxResults must be between 1 and 25")
+	}
+	start := 0
+	if input.NextToken != nil {
+		var err error
+		start, err = strconv.Atoi(*input.NextToken)
+		if err != nil || start < 0 {
+			return nil, newValidationError("invalid NextToken")
+		}
+	}
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	output := &dynamodb.ListExportsOutput{
+		ExportSummaries: []*dynamodb.ExportSummary{},
+	}
+	for i := start; i < len(d.exports); i++ {
+		export := d.exports[i]
+		if input.TableArn != nil && *export.TableArn != *input.TableArn {
+			continue
+		}
+		if len(output.ExportSummaries) == int(maxResults) {
+			output.NextToken = ptr(strconv.Itoa(i))
+			break
+		}
+		output.ExportSummaries = append(output.ExportSummaries, &dynamodb.ExportSummary{
+			ExportArn:    export.ExportArn,
+			ExportStatus: export.ExportStatus,
+			ExportType:   export.ExportType,
+		})
+	}
+	return output, nil
+}
+
+func (d *DB) ListExportsWithContext(ctx aws.Context, input *dynamodb.ListExportsInput, opts ...request.Option) (*dynamodb.ListExportsOutput, error) {
+	req, output := d.ListExportsRequest(input)
+	req.SetContext(ctx)
+	req.ApplyOptions(opts...)
+	return output, req.Send()
+}
+
+func (d *DB) ListExportsRequest(input *dynamodb.ListExportsInput) (*request.Request, *dynamodb.ListExportsOutput) {
+	return newContextRequest(d, "ListExports", input, d.listExportsContext)
+}
+
+func (d *DB) ListExportsPages(input *dynamodb.ListExportsInput, processPage func(*dynamodb.ListExportsOutput, bool) bool) error {
+	return d.ListExportsPagesWithContext(aws.BackgroundContext(), input, processPage)
+}
+
+func (d *DB) ListExportsPagesWithContext(ctx aws.Context, input *dynamodb.ListExportsInput, processPage func(*dynamodb.ListExportsOutput, bool) bool, opts ...request.Option) error {
+	input = shallowCopy(input)
+	for {
+		output, err := d.ListExportsWithContext(ctx, input, opts...)
+		if err != nil {
+			return err
+		}
+		lastPage := output.NextToken == nil
+		shouldContinue := processPage(output, lastPage)
+		if lastPage || !shouldContinue {
+			break
+		}
+		input.NextToken = output.NextToken
+	}
+	return nil
+}
@@ -0,0 +1,182 @@
+package fakedynamo_test
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/DMRobertson/fakedynamo"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// makeExportTestDB creates a fake with a PITR-enabled table, ready for
+// exporting. DynamoDB Local doesn't support exports, so these tests only run
+// against the fake.
+func makeExportTestDB(t *testing.T) (*fakedynamo.DB, string, string) {
+	t.Helper()
+	s3Dir := t.TempDir()
+	db := fakedynamo.NewDB(fakedynamo.WithS3Directory(s3Dir))
+	created, err := db.CreateTable(exampleCreateTableInputSimplePrimaryKey())
+	require.NoError(t, err)
+	_, err = db.UpdateContinuousBackups(&dynamodb.UpdateContinuousBackupsInput{
+		PointInTimeRecoverySpecification: &dynamodb.PointInTimeRecoverySpecification{
+			PointInTimeRecoveryEnabled: ptr(true),
+		},
+		TableName: created.TableDescription.TableName,
+	})
+	require.NoError(t, err)
+	return db, s3Dir, *created.TableDescription.TableName
+}
+
+// readExportLines decodes every line in the export's data files.
+func readExportLines(t *testing.T, s3Dir string, desc *dynamodb.ExportDescription) []map[string]any {
+	t.Helper()
+	bucketDir := filepath.Join(s3Dir, *desc.S3Bucket)
+	manifestFiles, err := os.ReadFile(filepath.Join(bucketDir, filepath.Dir(*desc.ExportManifest), "manifest-files.json"))
+	require.NoError(t, err)
+	var manifest struct {
+		DataFileS3Key string `json:"dataFileS3Key"`
+	}
+	require.NoError(t, json.Unmarshal(manifestFiles, &manifest))
+
+	dataFile, err := os.Open(filepath.Join(bucketDir, manifest.DataFileS3Key))
+	require.NoError(t, err)
+	defer dataFile.Close()
+	gz, err := gzip.NewReader(dataFile)
+	require.NoError(t, err)
+
+	var lines []map[string]any
+	scanner := bufio.NewScanner(gz)
+	for scanner.Scan() {
+		var line map[string]any
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &line))
+		lines = append(lines, line)
+	}
+	require.NoError(t, scanner.Err())
+	return lines
+}
+
+func TestDB_ExportTableToPointInTime_RequiresPITR(t *testing.T) {
+	t.Parallel()
+	db := fakedynamo.NewDB(fakedynamo.WithS3Directory(t.TempDir()))
+	created, err := db.CreateTable(exampleCreateTableInputSimplePrimaryKey())
+	require.NoError(t, err)
+
+	_, err = db.ExportTableToPointInTime(&dynamodb.ExportTableToPointInTimeInput{
+		S3Bucket: ptr("bucket"),
+		TableArn: created.TableDescription.TableArn,
+	})
+	var expectedErr *dynamodb.PointInTimeRecoveryUnavailableException
+	assert.ErrorAs(t, err, &expectedErr)
+}
+
+func TestDB_ExportTableToPointInTime_FullExport(t *testing.T) {
+	t.Parallel()
+	db, s3Dir, tableName := makeExportTestDB(t)
+	for _, foo := range []string{"a", "b"} {
+		_, err := db.PutItem(&dynamodb.PutItemInput{
+			Item:      map[string]*dynamodb.AttributeValue{"Foo": {S: ptr(foo)}},
+			TableName: &tableName,
+		})
+		require.NoError(t, err)
+	}
+	exportTime := time.Now()
+	_, err := db.DeleteItem(&dynamodb.DeleteItemInput{
+		Key:       map[string]*dynamodb.AttributeValue{"Foo": {S: ptr("a")}},
+		TableName: &tableName,
+	})
+	require.NoError(t, err)
+
+	described, err := db.DescribeTable(&dynamodb.DescribeTableInput{TableName: &tableName})
+	require.NoError(t, err)
+	exported, err := db.ExportTableToPointInTime(&dynamodb.ExportTableToPointInTimeInput{
+		ExportTime: &exportTime,
+		S3Bucket:   ptr("bucket"),
+		S3Prefix:   ptr("exports"),
+		TableArn:   described.Table.TableArn,
+	})
+	require.NoError(t, err)
+	desc := exported.ExportDescription
+	assert.Equal(t, dynamodb.ExportStatusCompleted, val(desc.ExportStatus))
+	assert.Equal(t, int64(2), val(desc.ItemCount))
+
+	lines := readExportLines(t, s3Dir, desc)
+	assert.Equal(t, []map[string]any{
+		{"Item": map[string]any{"Foo": map[string]any{"S": "a"}}},
+		{"Item": map[string]any{"Foo": map[string]any{"S": "b"}}},
+	}, lines)
+
+	describedExport, err := db.DescribeExport(&dynamodb.DescribeExportInput{ExportArn: desc.ExportArn})
+	require.NoError(t, err)
+	assert.Equal(t, desc, describedExport.ExportDescription)
+}
+
+func TestDB_ExportTableToPointInTime_IncrementalExport(t *testing.T) {
+	t.Parallel()
+	db, s3Dir, tableName := makeExportTestDB(t)
+	put := func(foo, bar string) {
+		_, err := db.PutItem(&dynamodb.PutItemInput{
+			Item: map[string]*dynamodb.AttributeValue{
+				"Foo": {S: ptr(foo)},
+				"Bar": {S: ptr(bar)},
+			},
+			TableName: &tableName,
+		})
+		require.NoError(t, err)
+	}
+
+	put("unchanged", "1")
+	put("updated", "1")
+	put("deleted", "1")
+	from := time.Now()
+	put("updated", "2")
+	put("updated", "3")
+	put("created", "1")
+	_, err := db.DeleteItem(&dynamodb.DeleteItemInput{
+		Key:       map[string]*dynamodb.AttributeValue{"Foo": {S: ptr("deleted")}},
+		TableName: &tableName,
+	})
+	require.NoError(t, err)
+
+	described, err := db.DescribeTable(&dynamodb.DescribeTableInput{TableName: &tableName})
+	require.NoError(t, err)
+	exported, err := db.ExportTableToPointInTime(&dynamodb.ExportTableToPointInTimeInput{
+		ExportType: ptr(dynamodb.ExportTypeIncrementalExport),
+		IncrementalExportSpecification: &dynamodb.IncrementalExportSpecification{
+			ExportFromTime: &from,
+		},
+		S3Bucket: ptr("bucket"),
+		TableArn: described.Table.TableArn,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), val(exported.ExportDescription.ItemCount))
+
+	lines := readExportLines(t, s3Dir, exported.ExportDescription)
+	require.Len(t, lines, 3)
+	for _, line := range lines {
+		assert.Contains(t, line, "Metadata")
+		delete(line, "Metadata")
+	}
+	image := func(foo, bar string) map[string]any {
+		return map[string]any{"Foo": map[string]any{"S": foo}, "Bar": map[string]any{"S": bar}}
+	}
+	keys := func(foo string) map[string]any {
+		return map[string]any{"Foo": map[string]any{"S": foo}}
+	}
+	assert.Equal(t, []map[string]any{
+		{"Keys": keys("updated"), "OldImage": image("updated", "1"), "NewImage": image("updated", "3")},
+		{"Keys": keys("created"), "NewImage": image("created", "1")},
+		{"Keys": keys("deleted"), "OldImage": image("deleted", "1")},
+	}, lines)
+
+	listed, err := db.ListExports(&dynamodb.ListExportsInput{TableArn: described.Table.TableArn})
+	require.NoError(t, err)
+	require.Len(t, listed.ExportSummaries, 1)
+	assert.Equal(t, exported.ExportDescription.ExportArn, listed.ExportSummaries[0].ExportArn)
+}
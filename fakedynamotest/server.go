@@ -0,0 +1,55 @@
+// Package fakedynamotest runs fakedynamo over HTTP for tests, so that code
+// which builds its own DynamoDB client from an endpoint can be tested against
+// the fake.
+package fakedynamotest
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DMRobertson/fakedynamo"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// Server is an HTTP server backed by a fresh [fakedynamo.DB].
+type Server struct {
+	*httptest.Server
+
+	// DB is the fake served by the server. Tests may use it directly, e.g. to
+	// seed data, alongside clients talking to the server over HTTP.
+	DB *fakedynamo.DB
+
+	region string
+}
+
+// NewServer starts a server backed by a new DB configured with opts. The
+// server is closed when the test finishes.
+func NewServer(tb testing.TB, opts ...fakedynamo.Option) *Server {
+	tb.Helper()
+	db := fakedynamo.NewDB(opts...)
+	s := &Server{
+		Server: httptest.NewServer(fakedynamo.NewHandler(db)),
+		DB:     db,
+		region: db.Region(),
+	}
+	tb.Cleanup(s.Close)
+	return s
+}
+
+// Config returns AWS SDK configuration for talking to the server, with dummy
+// credentials.
+func (s *Server) Config() *aws.Config {
+	return &aws.Config{
+		Credentials: credentials.NewStaticCredentials("fakeAccessKey", "fakeSecretKey", ""),
+		Endpoint:    aws.String(s.URL),
+		Region:      aws.String(s.region),
+	}
+}
+
+// Client returns a DynamoDB client which talks to the server.
+func (s *Server) Client() *dynamodb.DynamoDB {
+	return dynamodb.New(session.Must(session.NewSession(s.Config())))
+}
@@ -0,0 +1,76 @@
+package fakedynamotest_test
+
+import (
+	"testing"
+
+	"github.com/DMRobertson/fakedynamo/fakedynamotest"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer_RoundTripsThroughSDK(t *testing.T) {
+	t.Parallel()
+	server := fakedynamotest.NewServer(t)
+	client := server.Client()
+
+	_, err := client.CreateTable(&dynamodb.CreateTableInput{
+		AttributeDefinitions: []*dynamodb.AttributeDefinition{{
+			AttributeName: aws.String("Foo"),
+			AttributeType: aws.String(dynamodb.ScalarAttributeTypeS),
+		}},
+		BillingMode: aws.String(dynamodb.BillingModePayPerRequest),
+		KeySchema: []*dynamodb.KeySchemaElement{{
+			AttributeName: aws.String("Foo"),
+			KeyType:       aws.String(dynamodb.KeyTypeHash),
+		}},
+		TableName: aws.String("example-table"),
+	})
+	require.NoError(t, err)
+
+	item := map[string]*dynamodb.AttributeValue{
+		"Foo": {S: aws.String("foo")},
+		"Bin": {B: []byte{0, 1, 2}},
+	}
+	_, err = client.PutItem(&dynamodb.PutItemInput{Item: item, TableName: aws.String("example-table")})
+	require.NoError(t, err)
+
+	// The item was stored in the server's DB, with binary data decoded.
+	got, err := server.DB.GetItem(&dynamodb.GetItemInput{
+		Key:       map[string]*dynamodb.AttributeValue{"Foo": {S: aws.String("foo")}},
+		TableName: aws.String("example-table"),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, item, got.Item)
+
+	_, err = client.PutItem(&dynamodb.PutItemInput{
+		ConditionExpression:                 aws.String("attribute_not_exists(Foo)"),
+		Item:                                item,
+		ReturnValuesOnConditionCheckFailure: aws.String(dynamodb.ReturnValuesOnConditionCheckFailureAllOld),
+		TableName:                           aws.String("example-table"),
+	})
+	var conditionErr *dynamodb.ConditionalCheckFailedException
+	require.ErrorAs(t, err, &conditionErr)
+	assert.Equal(t, item, conditionErr.Item)
+
+	_, err = client.DescribeTable(&dynamodb.DescribeTableInput{TableName: aws.String("missing")})
+	var notFound *dynamodb.ResourceNotFoundException
+	require.ErrorAs(t, err, &notFound)
+}
+
+func TestServer_ReportsValidationErrors(t *testing.T) {
+	t.Parallel()
+	server := fakedynamotest.NewServer(t)
+	client := server.Client()
+	client.Handlers.Validate.Clear() // let the server do the validating
+
+	_, err := client.GetItem(&dynamodb.GetItemInput{})
+	var awsErr awserr.RequestFailure
+	require.ErrorAs(t, err, &awsErr)
+	assert.Equal(t, "ValidationException", awsErr.Code())
+	assert.Equal(t, 400, awsErr.StatusCode())
+	assert.Contains(t, awsErr.Message(), "Key is a required field")
+	assert.Contains(t, awsErr.Message(), "TableName is a required field")
+}
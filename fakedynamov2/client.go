@@ -0,0 +1,66 @@
+// Package fakedynamov2 lets code written against aws-sdk-go-v2 use
+// fakedynamo. Requests made by a v2 DynamoDB client are served in-process by
+// the same wire protocol handler as [fakedynamo.NewHandler], without opening
+// a socket. Errors are therefore decoded by the v2 SDK itself, so that e.g.
+//
+//	var conditionErr *types.ConditionalCheckFailedException
+//	errors.As(err, &conditionErr)
+//
+// works as it does against DynamoDB.
+package fakedynamov2
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/DMRobertson/fakedynamo"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+)
+
+// endpoint is the base endpoint of clients returned by [NewClient]. Nothing
+// listens on it: requests never leave the process.
+const endpoint = "http://fakedynamo.invalid"
+
+// HTTPClient is an [aws.HTTPClient] which serves DynamoDB requests
+// in-process.
+type HTTPClient struct {
+	handler http.Handler
+}
+
+var _ aws.HTTPClient = (*HTTPClient)(nil)
+
+// NewHTTPClient returns an HTTP client which serves requests using api,
+// typically a [fakedynamo.DB]. Use it as the HTTPClient of a
+// [dynamodb.Options] to configure clients by hand; otherwise see [NewClient].
+func NewHTTPClient(api dynamodbiface.DynamoDBAPI) *HTTPClient {
+	return &HTTPClient{handler: fakedynamo.NewHandler(api)}
+}
+
+// Do serves the request.
+func (c *HTTPClient) Do(r *http.Request) (*http.Response, error) {
+	if err := r.Context().Err(); err != nil {
+		return nil, err
+	}
+	recorder := httptest.NewRecorder()
+	c.handler.ServeHTTP(recorder, r)
+	response := recorder.Result()
+	response.Request = r
+	return response, nil
+}
+
+// NewClient returns a v2 DynamoDB client backed by db, with dummy credentials
+// and db's region. optFns can adjust the client's options as usual, e.g. to
+// change its retry behaviour.
+func NewClient(db *fakedynamo.DB, optFns ...func(*dynamodb.Options)) *dynamodb.Client {
+	options := dynamodb.Options{ //nolint:exhaustruct
+		AccountIDEndpointMode: aws.AccountIDEndpointModeDisabled,
+		BaseEndpoint:          aws.String(endpoint),
+		Credentials:           credentials.NewStaticCredentialsProvider("fakeAccessKey", "fakeSecretKey", ""),
+		HTTPClient:            NewHTTPClient(db),
+		Region:                db.Region(),
+	}
+	return dynamodb.New(options, optFns...)
+}
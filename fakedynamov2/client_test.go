@@ -0,0 +1,82 @@
+package fakedynamov2_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DMRobertson/fakedynamo"
+	"github.com/DMRobertson/fakedynamo/fakedynamov2"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	dynamodbv1 "github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_RoundTripsThroughSDK(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	db := fakedynamo.NewDB()
+	client := fakedynamov2.NewClient(db)
+
+	_, err := client.CreateTable(ctx, &dynamodb.CreateTableInput{
+		AttributeDefinitions: []types.AttributeDefinition{{
+			AttributeName: aws.String("Foo"),
+			AttributeType: types.ScalarAttributeTypeS,
+		}},
+		BillingMode: types.BillingModePayPerRequest,
+		KeySchema: []types.KeySchemaElement{{
+			AttributeName: aws.String("Foo"),
+			KeyType:       types.KeyTypeHash,
+		}},
+		TableName: aws.String("example-table"),
+	})
+	require.NoError(t, err)
+
+	item := map[string]types.AttributeValue{
+		"Foo":  &types.AttributeValueMemberS{Value: "foo"},
+		"Bin":  &types.AttributeValueMemberB{Value: []byte{0, 1, 2}},
+		"Nums": &types.AttributeValueMemberNS{Value: []string{"1", "2.5"}},
+	}
+	_, err = client.PutItem(ctx, &dynamodb.PutItemInput{Item: item, TableName: aws.String("example-table")})
+	require.NoError(t, err)
+
+	// The item landed in the DB, which the v1 API can read back.
+	stored, err := db.GetItem(&dynamodbv1.GetItemInput{
+		Key:       map[string]*dynamodbv1.AttributeValue{"Foo": {S: aws.String("foo")}},
+		TableName: aws.String("example-table"),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0, 1, 2}, stored.Item["Bin"].B)
+
+	got, err := client.GetItem(ctx, &dynamodb.GetItemInput{
+		Key:       map[string]types.AttributeValue{"Foo": &types.AttributeValueMemberS{Value: "foo"}},
+		TableName: aws.String("example-table"),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, item, got.Item)
+
+	_, err = client.PutItem(ctx, &dynamodb.PutItemInput{
+		ConditionExpression:                 aws.String("attribute_not_exists(Foo)"),
+		Item:                                item,
+		ReturnValuesOnConditionCheckFailure: types.ReturnValuesOnConditionCheckFailureAllOld,
+		TableName:                           aws.String("example-table"),
+	})
+	var conditionErr *types.ConditionalCheckFailedException
+	require.ErrorAs(t, err, &conditionErr)
+	assert.Equal(t, item, conditionErr.Item)
+
+	_, err = client.DescribeTable(ctx, &dynamodb.DescribeTableInput{TableName: aws.String("missing")})
+	var notFound *types.ResourceNotFoundException
+	require.ErrorAs(t, err, &notFound)
+}
+
+func TestClient_RespectsCancellation(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	client := fakedynamov2.NewClient(fakedynamo.NewDB())
+	_, err := client.ListTables(ctx, &dynamodb.ListTablesInput{})
+	require.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,424 @@
+// Package fakekinesis is a minimal in-memory stand-in for Amazon Kinesis Data
+// Streams. It exists so that fakedynamo can stream item changes to Kinesis,
+// and so that tests can read those changes back with the usual Kinesis
+// consumer APIs.
+//
+// Only stream creation and the read side of the API are implemented: see
+// [Kinesis]. Records are published with [Kinesis.Publish] rather than
+// PutRecord(s).
+package fakekinesis
+
+import (
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/kinesis"
+	"github.com/aws/aws-sdk-go/service/kinesis/kinesisiface"
+)
+
+const (
+	// defaultShardCount matches the default for on-demand streams.
+	defaultShardCount = 4
+	// maxGetRecordsLimit is the most records returned by one GetRecords call.
+	maxGetRecordsLimit = 10000
+	// retentionPeriodHours is reported by DescribeStreamSummary. We don't
+	// actually expire records.
+	retentionPeriodHours = 24
+)
+
+// Kinesis is an in-memory collection of Kinesis data streams. It implements
+// CreateStream, DescribeStreamSummary, ListShards, GetShardIterator and
+// GetRecords from [kinesisiface.KinesisAPI]. Calling any other method panics.
+type Kinesis struct {
+	kinesisiface.KinesisAPI
+
+	region    string
+	accountID string
+
+	// mu guards streams.
+	mu      sync.Mutex
+	streams map[string]*stream
+}
+
+var _ kinesisiface.KinesisAPI = (*Kinesis)(nil)
+
+type stream struct {
+	name      string
+	arn       string
+	createdAt time.Time
+	shards    []*shard
+	// sequence is the sequence number of the most recently published record.
+	sequence int64
+}
+
+type shard struct {
+	id           string
+	startHashKey *big.Int
+	endHashKey   *big.Int
+	records      []*kinesis.Record
+}
+
+// New creates an empty Kinesis stand-in. Stream ARNs use the given region and
+// account ID.
+func New(region, accountID string) *Kinesis {
+	return &Kinesis{
+		KinesisAPI: nil,
+		region:     region,
+		accountID:  accountID,
+		mu:         sync.Mutex{},
+		streams:    map[string]*stream{},
+	}
+}
+
+func newError(code, message string) error {
+	return awserr.New(code, message, nil)
+}
+
+func invalidArgument(format string, args ...any) error {
+	return newError(kinesis.ErrCodeInvalidArgumentException, fmt.Sprintf(format, args...))
+}
+
+func (k *Kinesis) streamArn(name string) string {
+	return fmt.Sprintf("arn:aws:kinesis:%s:%s:stream/%s", k.region, k.accountID, name)
+}
+
+// lookup finds a stream by name or ARN. The caller MUST hold mu.
+func (k *Kinesis) lookup(name, arn *string) (*stream, error) {
+	var key string
+	switch {
+	case arn != nil:
+		key = aws.StringValue(arn)
+	case name != nil:
+		key = k.streamArn(*name)
+	default:
+		return nil, invalidArgument("StreamName or StreamARN is required")
+	}
+	s, exists := k.streams[key]
+	if !exists {
+		return nil, newError(kinesis.ErrCodeResourceNotFoundException, "Stream "+key+" not found")
+	}
+	return s, nil
+}
+
+// HasStream reports whether a stream with the given ARN exists.
+func (k *Kinesis) HasStream(arn string) bool {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	_, exists := k.streams[arn]
+	return exists
+}
+
+// Publish appends a record to a stream, choosing the shard by hashing the
+// partition key as Kinesis does. It is the equivalent of PutRecord.
+func (k *Kinesis) Publish(streamArn, partitionKey string, data []byte) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	s, exists := k.streams[streamArn]
+	if !exists {
+		return newError(kinesis.ErrCodeResourceNotFoundException, "Stream "+streamArn+" not found")
+	}
+
+	hash := md5.Sum([]byte(partitionKey))
+	hashKey := new(big.Int).SetBytes(hash[:])
+	target := s.shards[len(s.shards)-1]
+	for _, sh := range s.shards {
+		if hashKey.Cmp(sh.endHashKey) <= 0 {
+			target = sh
+			break
+		}
+	}
+
+	s.sequence++
+	target.records = append(target.records, &kinesis.Record{
+		ApproximateArrivalTimestamp: aws.Time(time.Now().UTC()),
+		Data:                        data,
+		EncryptionType:              aws.String(kinesis.EncryptionTypeNone),
+		PartitionKey:                aws.String(partitionKey),
+		SequenceNumber:              aws.String(formatSequenceNumber(s.sequence)),
+	})
+	return nil
+}
+
+// formatSequenceNumber pads sequence numbers so that they sort
+// lexicographically, like real Kinesis sequence numbers.
+func formatSequenceNumber(n int64) string {
+	return fmt.Sprintf("%056d", n)
+}
+
+func (k *Kinesis) CreateStream(input *kinesis.CreateStreamInput) (*kinesis.CreateStreamOutput, error) {
+	if input.StreamName == nil {
+		return nil, invalidArgument("StreamName is a required field")
+	}
+	shardCount := aws.Int64Value(input.ShardCount)
+	if input.ShardCount == nil {
+		shardCount = defaultShardCount
+	}
+	if shardCount < 1 {
+		return nil, invalidArgument("ShardCount must be at least 1")
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	arn := k.streamArn(*input.StreamName)
+	if _, exists := k.streams[arn]; exists {
+		return nil, newError(kinesis.ErrCodeResourceInUseException, "Stream "+*input.StreamName+" already exists")
+	}
+
+	// Split the 128-bit hash key space evenly between the shards.
+	space := new(big.Int).Lsh(big.NewInt(1), 128)
+	width := new(big.Int).Div(space, big.NewInt(shardCount))
+	shards := make([]*shard, 0, shardCount)
+	for i := range shardCount {
+		start := new(big.Int).Mul(width, big.NewInt(i))
+		end := new(big.Int).Add(start, width)
+		if i == shardCount-1 {
+			end = space
+		}
+		shards = append(shards, &shard{
+			id:           fmt.Sprintf("shardId-%012d", i),
+			startHashKey: start,
+			endHashKey:   end.Sub(end, big.NewInt(1)),
+			records:      nil,
+		})
+	}
+	k.streams[arn] = &stream{
+		name:      *input.StreamName,
+		arn:       arn,
+		createdAt: time.Now().UTC(),
+		shards:    shards,
+		sequence:  0,
+	}
+	return &kinesis.CreateStreamOutput{}, nil
+}
+
+func (k *Kinesis) CreateStreamWithContext(_ aws.Context, input *kinesis.CreateStreamInput, _ ...request.Option) (*kinesis.CreateStreamOutput, error) {
+	return k.CreateStream(input)
+}
+
+func (k *Kinesis) DescribeStreamSummary(input *kinesis.DescribeStreamSummaryInput) (*kinesis.DescribeStreamSummaryOutput, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	s, err := k.lookup(input.StreamName, input.StreamARN)
+	if err != nil {
+		return nil, err
+	}
+	return &kinesis.DescribeStreamSummaryOutput{
+		StreamDescriptionSummary: &kinesis.StreamDescriptionSummary{
+			ConsumerCount:           aws.Int64(0),
+			EncryptionType:          aws.String(kinesis.EncryptionTypeNone),
+			EnhancedMonitoring:      []*kinesis.EnhancedMetrics{},
+			KeyId:                   nil,
+			OpenShardCount:          aws.Int64(int64(len(s.shards))),
+			RetentionPeriodHours:    aws.Int64(retentionPeriodHours),
+			StreamARN:               aws.String(s.arn),
+			StreamCreationTimestamp: aws.Time(s.createdAt),
+			StreamModeDetails:       nil,
+			StreamName:              aws.String(s.name),
+			StreamStatus:            aws.String(kinesis.StreamStatusActive),
+		},
+	}, nil
+}
+
+func (k *Kinesis) DescribeStreamSummaryWithContext(_ aws.Context, input *kinesis.DescribeStreamSummaryInput, _ ...request.Option) (*kinesis.DescribeStreamSummaryOutput, error) {
+	return k.DescribeStreamSummary(input)
+}
+
+// ListShards lists every shard in one page, since streams never reshard.
+func (k *Kinesis) ListShards(input *kinesis.ListShardsInput) (*kinesis.ListShardsOutput, error) {
+	if input.NextToken != nil {
+		return nil, invalidArgument("NextToken is not valid: shards are never paginated")
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	s, err := k.lookup(input.StreamName, input.StreamARN)
+	if err != nil {
+		return nil, err
+	}
+
+	shards := make([]*kinesis.Shard, 0, len(s.shards))
+	for _, sh := range s.shards {
+		if input.ExclusiveStartShardId != nil && sh.id <= *input.ExclusiveStartShardId {
+			continue
+		}
+		shards = append(shards, &kinesis.Shard{
+			AdjacentParentShardId: nil,
+			HashKeyRange: &kinesis.HashKeyRange{
+				EndingHashKey:   aws.String(sh.endHashKey.String()),
+				StartingHashKey: aws.String(sh.startHashKey.String()),
+			},
+			ParentShardId: nil,
+			SequenceNumberRange: &kinesis.SequenceNumberRange{
+				EndingSequenceNumber:   nil,
+				StartingSequenceNumber: aws.String(formatSequenceNumber(0)),
+			},
+			ShardId: aws.String(sh.id),
+		})
+	}
+	return &kinesis.ListShardsOutput{
+		NextToken: nil,
+		Shards:    shards,
+	}, nil
+}
+
+func (k *Kinesis) ListShardsWithContext(_ aws.Context, input *kinesis.ListShardsInput, _ ...request.Option) (*kinesis.ListShardsOutput, error) {
+	return k.ListShards(input)
+}
+
+// shardIterator is the decoded form of a shard iterator: the position of the
+// next record to read from a shard.
+type shardIterator struct {
+	StreamArn string `json:"s"`
+	ShardID   string `json:"i"`
+	Position  int    `json:"p"`
+}
+
+func (it shardIterator) encode() *string {
+	encoded, _ := json.Marshal(it)
+	return aws.String(base64.StdEncoding.EncodeToString(encoded))
+}
+
+func decodeShardIterator(s string) (shardIterator, error) {
+	var it shardIterator
+	decoded, err := base64.StdEncoding.DecodeString(s)
+	if err == nil {
+		err = json.Unmarshal(decoded, &it)
+	}
+	if err != nil {
+		return it, invalidArgument("invalid ShardIterator")
+	}
+	return it, nil
+}
+
+func (s *stream) shard(id string) (*shard, error) {
+	for _, sh := range s.shards {
+		if sh.id == id {
+			return sh, nil
+		}
+	}
+	return nil, newError(kinesis.ErrCodeResourceNotFoundException, "Shard "+id+" not found")
+}
+
+func (k *Kinesis) GetShardIterator(input *kinesis.GetShardIteratorInput) (*kinesis.GetShardIteratorOutput, error) {
+	if input.ShardId == nil {
+		return nil, invalidArgument("ShardId is a required field")
+	}
+	if input.ShardIteratorType == nil {
+		return nil, invalidArgument("ShardIteratorType is a required field")
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	s, err := k.lookup(input.StreamName, input.StreamARN)
+	if err != nil {
+		return nil, err
+	}
+	sh, err := s.shard(*input.ShardId)
+	if err != nil {
+		return nil, err
+	}
+
+	var position int
+	switch *input.ShardIteratorType {
+	case kinesis.ShardIteratorTypeTrimHorizon:
+		position = 0
+	case kinesis.ShardIteratorTypeLatest:
+		position = len(sh.records)
+	case kinesis.ShardIteratorTypeAtSequenceNumber, kinesis.ShardIteratorTypeAfterSequenceNumber:
+		if input.StartingSequenceNumber == nil {
+			return nil, invalidArgument("StartingSequenceNumber is required for %s", *input.ShardIteratorType)
+		}
+		position = len(sh.records)
+		for i, record := range sh.records {
+			if *record.SequenceNumber >= *input.StartingSequenceNumber {
+				position = i
+				if *record.SequenceNumber == *input.StartingSequenceNumber &&
+					*input.ShardIteratorType == kinesis.ShardIteratorTypeAfterSequenceNumber {
+					position++
+				}
+				break
+			}
+		}
+	case kinesis.ShardIteratorTypeAtTimestamp:
+		if input.Timestamp == nil {
+			return nil, invalidArgument("Timestamp is required for AT_TIMESTAMP")
+		}
+		position = len(sh.records)
+		for i, record := range sh.records {
+			if !record.ApproximateArrivalTimestamp.Before(*input.Timestamp) {
+				position = i
+				break
+			}
+		}
+	default:
+		return nil, invalidArgument("ShardIteratorType must be one of %s",
+			strings.Join(kinesis.ShardIteratorType_Values(), ", "))
+	}
+
+	it := shardIterator{StreamArn: s.arn, ShardID: sh.id, Position: position}
+	return &kinesis.GetShardIteratorOutput{ShardIterator: it.encode()}, nil
+}
+
+func (k *Kinesis) GetShardIteratorWithContext(_ aws.Context, input *kinesis.GetShardIteratorInput, _ ...request.Option) (*kinesis.GetShardIteratorOutput, error) {
+	return k.GetShardIterator(input)
+}
+
+func (k *Kinesis) GetRecords(input *kinesis.GetRecordsInput) (*kinesis.GetRecordsOutput, error) {
+	if input.ShardIterator == nil {
+		return nil, invalidArgument("ShardIterator is a required field")
+	}
+	limit := aws.Int64Value(input.Limit)
+	if input.Limit == nil {
+		limit = maxGetRecordsLimit
+	}
+	if limit < 1 || limit > maxGetRecordsLimit {
+		return nil, invalidArgument("Limit must be between 1 and %d", maxGetRecordsLimit)
+	}
+	it, err := decodeShardIterator(*input.ShardIterator)
+	if err != nil {
+		return nil, err
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	s, err := k.lookup(nil, &it.StreamArn)
+	if err != nil {
+		return nil, err
+	}
+	sh, err := s.shard(it.ShardID)
+	if err != nil {
+		return nil, err
+	}
+	if it.Position < 0 || it.Position > len(sh.records) {
+		return nil, invalidArgument("invalid ShardIterator")
+	}
+
+	end := min(it.Position+int(limit), len(sh.records))
+	records := make([]*kinesis.Record, 0, end-it.Position)
+	records = append(records, sh.records[it.Position:end]...)
+
+	var millisBehind int64
+	if end < len(sh.records) {
+		millisBehind = time.Since(*sh.records[end].ApproximateArrivalTimestamp).Milliseconds()
+	}
+	it.Position = end
+	return &kinesis.GetRecordsOutput{
+		ChildShards:        nil,
+		MillisBehindLatest: aws.Int64(millisBehind),
+		NextShardIterator:  it.encode(),
+		Records:            records,
+	}, nil
+}
+
+func (k *Kinesis) GetRecordsWithContext(_ aws.Context, input *kinesis.GetRecordsInput, _ ...request.Option) (*kinesis.GetRecordsOutput, error) {
+	return k.GetRecords(input)
+}
@@ -0,0 +1,73 @@
+package fakekinesis_test
+
+import (
+	"encoding/base64"
+	"fmt"
+	"testing"
+
+	"github.com/DMRobertson/fakedynamo/fakekinesis"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/kinesis"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKinesis_ShardIterators(t *testing.T) {
+	t.Parallel()
+	k := fakekinesis.New("eu-west-2", "123456789012")
+	_, err := k.CreateStream(&kinesis.CreateStreamInput{StreamName: aws.String("s"), ShardCount: aws.Int64(1)})
+	require.NoError(t, err)
+	arn := "arn:aws:kinesis:eu-west-2:123456789012:stream/s"
+	for i := range 3 {
+		require.NoError(t, k.Publish(arn, "pk", []byte(fmt.Sprint(i))))
+	}
+
+	shards, err := k.ListShards(&kinesis.ListShardsInput{StreamName: aws.String("s")})
+	require.NoError(t, err)
+	require.Len(t, shards.Shards, 1)
+	shardID := shards.Shards[0].ShardId
+
+	read := func(input *kinesis.GetShardIteratorInput, limit int64) ([]string, *string) {
+		input.ShardId = shardID
+		input.StreamARN = aws.String(arn)
+		it, err := k.GetShardIterator(input)
+		require.NoError(t, err)
+		out, err := k.GetRecords(&kinesis.GetRecordsInput{ShardIterator: it.ShardIterator, Limit: aws.Int64(limit)})
+		require.NoError(t, err)
+		var data []string
+		for _, record := range out.Records {
+			data = append(data, string(record.Data))
+		}
+		return data, out.NextShardIterator
+	}
+
+	data, next := read(&kinesis.GetShardIteratorInput{ShardIteratorType: aws.String(kinesis.ShardIteratorTypeTrimHorizon)}, 2)
+	assert.Equal(t, []string{"0", "1"}, data)
+	out, err := k.GetRecords(&kinesis.GetRecordsInput{ShardIterator: next})
+	require.NoError(t, err)
+	require.Len(t, out.Records, 1)
+	assert.Equal(t, "2", string(out.Records[0].Data))
+
+	data, _ = read(&kinesis.GetShardIteratorInput{
+		ShardIteratorType:      aws.String(kinesis.ShardIteratorTypeAfterSequenceNumber),
+		StartingSequenceNumber: out.Records[0].SequenceNumber,
+	}, 10)
+	assert.Empty(t, data)
+
+	data, _ = read(&kinesis.GetShardIteratorInput{
+		ShardIteratorType:      aws.String(kinesis.ShardIteratorTypeAtSequenceNumber),
+		StartingSequenceNumber: out.Records[0].SequenceNumber,
+	}, 10)
+	assert.Equal(t, []string{"2"}, data)
+
+	data, _ = read(&kinesis.GetShardIteratorInput{ShardIteratorType: aws.String(kinesis.ShardIteratorTypeLatest)}, 10)
+	assert.Empty(t, data)
+
+	for _, position := range []int{-1, 4} {
+		forged := fmt.Sprintf(`{"s":%q,"i":%q,"p":%d}`, arn, *shardID, position)
+		_, err = k.GetRecords(&kinesis.GetRecordsInput{
+			ShardIterator: aws.String(base64.StdEncoding.EncodeToString([]byte(forged))),
+		})
+		assert.ErrorContains(t, err, kinesis.ErrCodeInvalidArgumentException, "position %d", position)
+	}
+}
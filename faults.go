@@ -0,0 +1,205 @@
+package fakedynamo
+
+import (
+	"math/rand/v2"
+	"reflect"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// Fault describes a fault to inject into operations, to test how callers
+// handle errors and slow responses. Add faults with [DB.AddFault].
+//
+// A fault matches a call if it matches every field which is set. It fires for
+// some matching calls, as controlled by After, Times and Probability. Every
+// fault which fires adds its Latency to the call; the call then fails with the
+// Err of the first fault which fired with one, without running the operation.
+type Fault struct {
+	// Operation restricts the fault to the named operation, e.g. "PutItem".
+	Operation string
+	// TableName restricts the fault to operations on the named table, given
+	// by name, by ARN or as a global table name.
+	TableName string
+	// Key restricts the fault to operations on items for which it returns
+	// true. It is called with the Key of operations like GetItem, or the Item
+	// of PutItem. Operations without a key never match.
+	Key func(map[string]*dynamodb.AttributeValue) bool
+
+	// After is how many matching calls to let through before the fault
+	// starts firing.
+	After int
+	// Times limits how many times the fault fires. Zero means no limit.
+	Times int
+	// Probability is the chance that the fault fires for a matching call,
+	// between 0 and 1. Zero means always. See [WithFaultSeed] for
+	// reproducible tests.
+	Probability float64
+
+	// Err is returned by calls the fault fires for. Typically it is an
+	// [awserr.RequestFailure] such as a
+	// [dynamodb.ProvisionedThroughputExceededException], but any error can be
+	// used, e.g. to simulate network errors. If nil, the fault only adds
+	// Latency.
+	Err error
+	// Latency delays calls the fault fires for. Calls made with a context
+	// stop waiting when the context ends.
+	Latency time.Duration
+}
+
+// FaultID identifies a fault added with [DB.AddFault].
+type FaultID int
+
+// faultState is a fault, along with how often it has matched and fired.
+type faultState struct {
+	Fault
+
+	id      FaultID
+	matched int
+	fired   int
+}
+
+// faults holds the faults injected into a DB.
+type faults struct {
+	mu     sync.Mutex
+	rules  []*faultState
+	nextID FaultID
+	rand   *rand.Rand
+}
+
+func newFaults() *faults {
+	return &faults{
+		mu:     sync.Mutex{},
+		rules:  nil,
+		nextID: 1,
+		rand:   rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64())), //nolint:gosec
+	}
+}
+
+// AddFault injects a fault into subsequent operations, until it is removed
+// with [DB.RemoveFault]. Faults can be added and removed at any time, e.g.
+// part way through a test.
+func (d *DB) AddFault(fault Fault) FaultID {
+	d.faults.mu.Lock()
+	defer d.faults.mu.Unlock()
+	id := d.faults.nextID
+	d.faults.nextID++
+	d.faults.rules = append(d.faults.rules, &faultState{Fault: fault, id: id, matched: 0, fired: 0})
+	return id
+}
+
+// RemoveFault stops injecting the given fault. It does nothing if the fault
+// has already been removed.
+func (d *DB) RemoveFault(id FaultID) {
+	d.faults.mu.Lock()
+	defer d.faults.mu.Unlock()
+	d.faults.rules = slices.DeleteFunc(d.faults.rules, func(f *faultState) bool {
+		return f.id == id
+	})
+}
+
+// ClearFaults removes every fault.
+func (d *DB) ClearFaults() {
+	d.faults.mu.Lock()
+	defer d.faults.mu.Unlock()
+	d.faults.rules = nil
+}
+
+// injectFault applies the faults which fire for a call of the operation with
+// the given input. Operations call it before doing anything else, and return
+// its error if any. Latency is cut short when ctx ends.
+func (d *DB) injectFault(ctx aws.Context, operation string, input any) error {
+	latency, err := d.fireFaults(operation, input)
+	if latency == 0 {
+		return err
+	}
+
+	if sleepErr := aws.SleepWithContext(ctx, latency); sleepErr != nil {
+		return ctx.Err()
+	}
+	return err
+}
+
+// fireFaults decides which faults fire for a call, returning their total
+// latency and the error to fail the call with.
+func (d *DB) fireFaults(operation string, input any) (time.Duration, error) {
+	d.faults.mu.Lock()
+	defer d.faults.mu.Unlock()
+	if len(d.faults.rules) == 0 {
+		return 0, nil
+	}
+
+	fields := reflect.ValueOf(input).Elem()
+	var latency time.Duration
+	var err error
+	for _, f := range d.faults.rules {
+		if !d.faultMatches(f.Fault, operation, fields) {
+			continue
+		}
+		f.matched++
+		if f.matched <= f.After || (f.Times > 0 && f.fired >= f.Times) {
+			continue
+		}
+		if f.Probability > 0 && d.faults.rand.Float64() >= f.Probability {
+			continue
+		}
+		f.fired++
+		latency += f.Latency
+		if err == nil {
+			err = f.Err
+		}
+	}
+	return latency, err
+}
+
+func (d *DB) faultMatches(f Fault, operation string, fields reflect.Value) bool {
+	if f.Operation != "" && f.Operation != operation {
+		return false
+	}
+	if f.TableName != "" && f.TableName != d.inputTableName(fields) {
+		return false
+	}
+	if f.Key != nil {
+		key := inputAvmap(fields, "Key")
+		if key == nil {
+			key = inputAvmap(fields, "Item")
+		}
+		if key == nil || !f.Key(key) {
+			return false
+		}
+	}
+	return true
+}
+
+// inputTableName finds the table which an operation's input refers to, or
+// "" if it doesn't refer to one.
+func (d *DB) inputTableName(fields reflect.Value) string {
+	for _, name := range []string{"TableName", "GlobalTableName"} {
+		if field := fields.FieldByName(name); field.IsValid() {
+			if tableName, ok := field.Interface().(*string); ok && tableName != nil {
+				return *tableName
+			}
+		}
+	}
+	for _, name := range []string{"TableArn", "ResourceArn", "ExportArn", "ImportArn"} {
+		if field := fields.FieldByName(name); field.IsValid() {
+			if arn, ok := field.Interface().(*string); ok && arn != nil {
+				tableName, _ := d.tableNameFromArn(*arn)
+				return tableName
+			}
+		}
+	}
+	return ""
+}
+
+func inputAvmap(fields reflect.Value, name string) avmap {
+	field := fields.FieldByName(name)
+	if !field.IsValid() {
+		return nil
+	}
+	m, _ := field.Interface().(map[string]*dynamodb.AttributeValue)
+	return m
+}
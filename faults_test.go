@@ -0,0 +1,108 @@
+package fakedynamo_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DMRobertson/fakedynamo"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDB_AddFault_MatchesOperationTableAndKey(t *testing.T) {
+	t.Parallel()
+	db := fakedynamo.NewDB()
+	input := exampleCreateTableInputSimplePrimaryKey()
+	_, err := db.CreateTable(input)
+	require.NoError(t, err)
+	other := exampleCreateTableInputSimplePrimaryKey()
+	_, err = db.CreateTable(other)
+	require.NoError(t, err)
+
+	db.AddFault(fakedynamo.Fault{
+		Operation: "PutItem",
+		TableName: *input.TableName,
+		Key: func(key map[string]*dynamodb.AttributeValue) bool {
+			return val(key["Foo"].S) == "hot"
+		},
+		Err: &dynamodb.ProvisionedThroughputExceededException{},
+	})
+	put := func(table *string, foo string) error {
+		_, err := db.PutItem(&dynamodb.PutItemInput{
+			Item:      map[string]*dynamodb.AttributeValue{"Foo": {S: ptr(foo)}},
+			TableName: table,
+		})
+		return err
+	}
+
+	var throttled *dynamodb.ProvisionedThroughputExceededException
+	require.ErrorAs(t, put(input.TableName, "hot"), &throttled)
+	require.NoError(t, put(input.TableName, "cold"))
+	require.NoError(t, put(other.TableName, "hot"))
+
+	// The item wasn't written when the fault fired.
+	got, err := db.GetItem(&dynamodb.GetItemInput{
+		Key:       map[string]*dynamodb.AttributeValue{"Foo": {S: ptr("hot")}},
+		TableName: input.TableName,
+	})
+	require.NoError(t, err)
+	assert.Nil(t, got.Item)
+}
+
+func TestDB_AddFault_AfterAndTimes(t *testing.T) {
+	t.Parallel()
+	db := fakedynamo.NewDB()
+	unavailable := awserr.NewRequestFailure(awserr.New("InternalServerError", "try again", nil), 500, "")
+	id := db.AddFault(fakedynamo.Fault{Operation: "ListTables", After: 1, Times: 2, Err: unavailable})
+
+	var results []bool
+	for range 4 {
+		_, err := db.ListTables(&dynamodb.ListTablesInput{})
+		results = append(results, err == nil)
+	}
+	assert.Equal(t, []bool{true, false, false, true}, results)
+
+	db.RemoveFault(id)
+	db.AddFault(fakedynamo.Fault{Err: errors.New("connection reset by peer")})
+	_, err := db.ListTables(&dynamodb.ListTablesInput{})
+	assertErrorContains(t, err, "connection reset")
+	db.ClearFaults()
+	_, err = db.ListTables(&dynamodb.ListTablesInput{})
+	require.NoError(t, err)
+}
+
+func TestDB_AddFault_ProbabilityIsSeedable(t *testing.T) {
+	t.Parallel()
+	outcomes := func() []bool {
+		db := fakedynamo.NewDB(fakedynamo.WithFaultSeed(42))
+		db.AddFault(fakedynamo.Fault{Probability: 0.5, Err: &dynamodb.RequestLimitExceeded{}})
+		var outcomes []bool
+		for range 20 {
+			_, err := db.ListTables(&dynamodb.ListTablesInput{})
+			outcomes = append(outcomes, err == nil)
+		}
+		return outcomes
+	}
+	first := outcomes()
+	assert.Equal(t, first, outcomes())
+	assert.Contains(t, first, true)
+	assert.Contains(t, first, false)
+}
+
+func TestDB_AddFault_LatencyHonoursContext(t *testing.T) {
+	t.Parallel()
+	db := fakedynamo.NewDB()
+	db.AddFault(fakedynamo.Fault{Operation: "ListTables", Latency: time.Hour})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := db.ListTablesWithContext(ctx, &dynamodb.ListTablesInput{})
+	var awsErr awserr.Error
+	require.ErrorAs(t, err, &awsErr)
+	assert.Equal(t, request.CanceledErrorCode, awsErr.Code())
+}
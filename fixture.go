@@ -0,0 +1,454 @@
+package fakedynamo
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"gopkg.in/yaml.v3"
+)
+
+// Fixtures
+//
+// A fixture is a YAML or JSON document describing tables to create and items
+// to put in them, for seeding a DB in tests. For example:
+//
+//	Tables:
+//	  - TableName: Music
+//	    KeySchema:
+//	      - {AttributeName: Artist, KeyType: HASH}
+//	      - {AttributeName: SongTitle, KeyType: RANGE}
+//	    AttributeDefinitions:
+//	      - {AttributeName: Artist, AttributeType: S}
+//	      - {AttributeName: SongTitle, AttributeType: S}
+//	    TimeToLiveSpecification: {AttributeName: Expires, Enabled: true}
+//	    Items:
+//	      - {Artist: No One You Know, SongTitle: Call Me Today, Year: 2019}
+//	      - {Artist: {S: Acme Band}, SongTitle: {S: Happy Day}, Tags: {SS: [pop]}}
+//
+// Each table has the fields of a [dynamodb.CreateTableInput], such as
+// GlobalSecondaryIndexes and BillingMode, which defaults to PAY_PER_REQUEST
+// unless ProvisionedThroughput is given. TimeToLiveSpecification is applied
+// with UpdateTimeToLive.
+//
+// Items are written either in DynamoDB JSON or in plain JSON. An item is read
+// as DynamoDB JSON if every attribute value is a mapping with a single key
+// naming an attribute type, such as S or NS. Otherwise its attribute types
+// are inferred: strings are S, numbers N, booleans BOOL, nulls NULL, lists L
+// and mappings M. In YAML, values tagged !!binary are B. Sets can only be
+// written in DynamoDB JSON.
+
+// FixtureError reports a problem at a position in a fixture or template.
+type FixtureError struct {
+	// Name identifies the fixture or template, usually by its file name.
+	Name string
+	// Line and Column are 1-based.
+	Line   int
+	Column int
+	Err    error
+}
+
+func (e *FixtureError) Error() string {
+	return fmt.Sprintf("%s:%d:%d: %v", e.Name, e.Line, e.Column, e.Err)
+}
+
+func (e *FixtureError) Unwrap() error {
+	return e.Err
+}
+
+// LoadFixtureFile loads the fixture in the file at path. See [DB.LoadFixture].
+func (d *DB) LoadFixtureFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return d.LoadFixture(path, f)
+}
+
+// LoadFixture reads a fixture from r, creating its tables and putting its
+// items through the DB's API, so that they are validated as usual. name
+// identifies the fixture in errors, which are *[FixtureError]s unless the
+// fixture can't be parsed at all. Loading stops at the first error, keeping
+// the tables and items created before it.
+func (d *DB) LoadFixture(name string, r io.Reader) error {
+	var doc yaml.Node
+	if err := yaml.NewDecoder(r).Decode(&doc); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	l := fixtureLoader{d: d, name: name}
+	return l.loadDocument(doc.Content[0])
+}
+
+type fixtureLoader struct {
+	d    *DB
+	name string
+}
+
+func (l *fixtureLoader) errorf(n *yaml.Node, format string, args ...any) error {
+	return l.wrap(n, fmt.Errorf(format, args...))
+}
+
+func (l *fixtureLoader) wrap(n *yaml.Node, err error) error {
+	return &FixtureError{Name: l.name, Line: n.Line, Column: n.Column, Err: err}
+}
+
+func (l *fixtureLoader) loadDocument(doc *yaml.Node) error {
+	doc = resolveAlias(doc)
+	if doc.Kind != yaml.MappingNode {
+		return l.errorf(doc, "expected a mapping with a Tables field")
+	}
+	for i := 0; i < len(doc.Content); i += 2 {
+		key, value := doc.Content[i], resolveAlias(doc.Content[i+1])
+		if key.Value != "Tables" {
+			return l.errorf(key, "unknown field %s", key.Value)
+		}
+		if value.Kind != yaml.SequenceNode {
+			return l.errorf(value, "Tables must be a list")
+		}
+		for _, table := range value.Content {
+			if err := l.loadTable(resolveAlias(table)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (l *fixtureLoader) loadTable(n *yaml.Node) error {
+	if n.Kind != yaml.MappingNode {
+		return l.errorf(n, "expected a table")
+	}
+	var items, ttl *yaml.Node
+	spec := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Line: n.Line, Column: n.Column} //nolint:exhaustruct
+	for i := 0; i < len(n.Content); i += 2 {
+		switch n.Content[i].Value {
+		case "Items":
+			items = resolveAlias(n.Content[i+1])
+		case "TimeToLiveSpecification":
+			ttl = n.Content[i+1]
+		default:
+			spec.Content = append(spec.Content, n.Content[i], n.Content[i+1])
+		}
+	}
+
+	var input dynamodb.CreateTableInput
+	if err := l.decodeAPIShape(spec, &input); err != nil {
+		return err
+	}
+	if input.BillingMode == nil && input.ProvisionedThroughput == nil {
+		input.BillingMode = ptr(dynamodb.BillingModePayPerRequest)
+	}
+	if _, err := l.d.CreateTable(&input); err != nil {
+		return l.wrap(n, err)
+	}
+
+	if ttl != nil {
+		var ttlSpec dynamodb.TimeToLiveSpecification
+		if err := l.decodeAPIShape(ttl, &ttlSpec); err != nil {
+			return err
+		}
+		_, err := l.d.UpdateTimeToLive(&dynamodb.UpdateTimeToLiveInput{
+			TableName:               input.TableName,
+			TimeToLiveSpecification: &ttlSpec,
+		})
+		if err != nil {
+			return l.wrap(ttl, err)
+		}
+	}
+
+	if items == nil {
+		return nil
+	}
+	if items.Kind != yaml.SequenceNode {
+		return l.errorf(items, "Items must be a list")
+	}
+	for _, itemNode := range items.Content {
+		item, err := l.item(resolveAlias(itemNode))
+		if err != nil {
+			return err
+		}
+		_, err = l.d.PutItem(&dynamodb.PutItemInput{ //nolint:exhaustruct
+			Item:      item,
+			TableName: input.TableName,
+		})
+		if err != nil {
+			return l.wrap(itemNode, err)
+		}
+	}
+	return nil
+}
+
+// decodeAPIShape decodes n into v, one of the SDK's input shapes, whose
+// fields are named as in the DynamoDB API.
+func (l *fixtureLoader) decodeAPIShape(n *yaml.Node, v any) error {
+	plain, err := l.plainValue(n)
+	if err != nil {
+		return err
+	}
+	encoded, err := json.Marshal(plain)
+	if err != nil {
+		return l.wrap(n, err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(encoded))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return l.wrap(n, err)
+	}
+	return nil
+}
+
+// plainValue converts n to a value which encodes as the equivalent JSON.
+func (l *fixtureLoader) plainValue(n *yaml.Node) (any, error) {
+	n = resolveAlias(n)
+	switch n.Kind {
+	case yaml.MappingNode:
+		m := make(map[string]any, len(n.Content)/2)
+		for i := 0; i < len(n.Content); i += 2 {
+			v, err := l.plainValue(n.Content[i+1])
+			if err != nil {
+				return nil, err
+			}
+			m[n.Content[i].Value] = v
+		}
+		return m, nil
+	case yaml.SequenceNode:
+		s := make([]any, 0, len(n.Content))
+		for _, child := range n.Content {
+			v, err := l.plainValue(child)
+			if err != nil {
+				return nil, err
+			}
+			s = append(s, v)
+		}
+		return s, nil
+	}
+	switch n.ShortTag() {
+	case "!!int", "!!float":
+		if !fixtureNumber.MatchString(n.Value) {
+			return nil, l.errorf(n, "%s isn't a number DynamoDB can store", n.Value)
+		}
+		return json.Number(n.Value), nil
+	case "!!bool":
+		b, err := strconv.ParseBool(n.Value)
+		if err != nil {
+			return nil, l.wrap(n, err)
+		}
+		return b, nil
+	case "!!null":
+		return nil, nil
+	}
+	return n.Value, nil
+}
+
+// fixtureNumber matches the numbers written in fixtures which DynamoDB
+// accepts. YAML allows others, like 0x1F and .inf.
+var fixtureNumber = regexp.MustCompile(`^-?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][+-]?[0-9]+)?$`)
+
+func (l *fixtureLoader) item(n *yaml.Node) (avmap, error) {
+	if n.Kind != yaml.MappingNode {
+		return nil, l.errorf(n, "expected an item")
+	}
+	typed := true
+	for i := 1; i < len(n.Content); i += 2 {
+		typed = typed && isTypedAttributeValue(resolveAlias(n.Content[i]))
+	}
+	item := make(avmap, len(n.Content)/2)
+	for i := 0; i < len(n.Content); i += 2 {
+		var av *dynamodb.AttributeValue
+		var err error
+		if typed {
+			av, err = l.typedAttributeValue(n.Content[i+1])
+		} else {
+			av, err = l.inferAttributeValue(n.Content[i+1])
+		}
+		if err != nil {
+			return nil, err
+		}
+		item[n.Content[i].Value] = av
+	}
+	return item, nil
+}
+
+// attributeTypes are the keys of an attribute value in DynamoDB JSON.
+var attributeTypes = []string{"S", "N", "B", "BOOL", "NULL", "M", "L", "SS", "NS", "BS"}
+
+func isTypedAttributeValue(n *yaml.Node) bool {
+	if n.Kind != yaml.MappingNode || len(n.Content) != 2 {
+		return false
+	}
+	for _, t := range attributeTypes {
+		if n.Content[0].Value == t {
+			return true
+		}
+	}
+	return false
+}
+
+// inferAttributeValue reads an attribute value written in plain JSON.
+func (l *fixtureLoader) inferAttributeValue(n *yaml.Node) (*dynamodb.AttributeValue, error) {
+	n = resolveAlias(n)
+	switch n.Kind {
+	case yaml.MappingNode:
+		m := make(avmap, len(n.Content)/2)
+		for i := 0; i < len(n.Content); i += 2 {
+			v, err := l.inferAttributeValue(n.Content[i+1])
+			if err != nil {
+				return nil, err
+			}
+			m[n.Content[i].Value] = v
+		}
+		return &dynamodb.AttributeValue{M: m}, nil //nolint:exhaustruct
+	case yaml.SequenceNode:
+		list := make([]*dynamodb.AttributeValue, 0, len(n.Content))
+		for _, child := range n.Content {
+			v, err := l.inferAttributeValue(child)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, v)
+		}
+		return &dynamodb.AttributeValue{L: list}, nil //nolint:exhaustruct
+	}
+
+	switch n.ShortTag() {
+	case "!!binary":
+		b, err := base64.StdEncoding.DecodeString(n.Value)
+		if err != nil {
+			return nil, l.wrap(n, err)
+		}
+		return &dynamodb.AttributeValue{B: b}, nil //nolint:exhaustruct
+	case "!!int", "!!float", "!!bool", "!!null":
+		plain, err := l.plainValue(n)
+		if err != nil {
+			return nil, err
+		}
+		switch v := plain.(type) {
+		case json.Number:
+			return &dynamodb.AttributeValue{N: ptr(v.String())}, nil //nolint:exhaustruct
+		case bool:
+			return &dynamodb.AttributeValue{BOOL: &v}, nil //nolint:exhaustruct
+		}
+		return &dynamodb.AttributeValue{NULL: ptr(true)}, nil //nolint:exhaustruct
+	}
+	return &dynamodb.AttributeValue{S: ptr(n.Value)}, nil //nolint:exhaustruct
+}
+
+// typedAttributeValue reads an attribute value written in DynamoDB JSON.
+func (l *fixtureLoader) typedAttributeValue(n *yaml.Node) (*dynamodb.AttributeValue, error) {
+	n = resolveAlias(n)
+	if !isTypedAttributeValue(n) {
+		return nil, l.errorf(n, "expected an attribute value in DynamoDB JSON, like {\"S\": \"hello\"}")
+	}
+	typ, v := n.Content[0].Value, resolveAlias(n.Content[1])
+	av := &dynamodb.AttributeValue{} //nolint:exhaustruct
+	var err error
+	switch typ {
+	case "S":
+		av.S, err = l.scalar(v)
+	case "N":
+		av.N, err = l.scalar(v)
+	case "B":
+		av.B, err = l.binary(v)
+	case "BOOL":
+		var b *string
+		if b, err = l.scalar(v); err == nil {
+			var parsed bool
+			parsed, err = strconv.ParseBool(*b)
+			av.BOOL = &parsed
+		}
+	case "NULL":
+		av.NULL = ptr(true)
+	case "M":
+		if v.Kind != yaml.MappingNode {
+			return nil, l.errorf(v, "M must be a mapping")
+		}
+		av.M = make(avmap, len(v.Content)/2)
+		for i := 0; i < len(v.Content); i += 2 {
+			if av.M[v.Content[i].Value], err = l.typedAttributeValue(v.Content[i+1]); err != nil {
+				return nil, err
+			}
+		}
+	case "L":
+		av.L, err = listOf(l, v, l.typedAttributeValue)
+	case "SS":
+		av.SS, err = listOf(l, v, l.scalar)
+	case "NS":
+		av.NS, err = listOf(l, v, l.scalar)
+	case "BS":
+		av.BS, err = listOf(l, v, l.binary)
+	}
+	if err != nil {
+		var fixtureErr *FixtureError
+		if !errors.As(err, &fixtureErr) {
+			err = l.wrap(v, err)
+		}
+		return nil, err
+	}
+	return av, nil
+}
+
+func (l *fixtureLoader) scalar(n *yaml.Node) (*string, error) {
+	n = resolveAlias(n)
+	if n.Kind != yaml.ScalarNode {
+		return nil, l.errorf(n, "expected a string")
+	}
+	return ptr(n.Value), nil
+}
+
+func (l *fixtureLoader) binary(n *yaml.Node) ([]byte, error) {
+	s, err := l.scalar(n)
+	if err != nil {
+		return nil, err
+	}
+	b, err := base64.StdEncoding.DecodeString(*s)
+	if err != nil {
+		return nil, l.wrap(n, err)
+	}
+	return b, nil
+}
+
+func listOf[T any](l *fixtureLoader, n *yaml.Node, f func(*yaml.Node) (T, error)) ([]T, error) {
+	if n.Kind != yaml.SequenceNode {
+		return nil, l.errorf(n, "expected a list")
+	}
+	list := make([]T, 0, len(n.Content))
+	for _, child := range n.Content {
+		v, err := f(child)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, v)
+	}
+	return list, nil
+}
+
+// yamlField returns the value of the named field of a mapping, or nil.
+func yamlField(n *yaml.Node, name string) *yaml.Node {
+	if n == nil || n.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i < len(n.Content); i += 2 {
+		if n.Content[i].Value == name {
+			return resolveAlias(n.Content[i+1])
+		}
+	}
+	return nil
+}
+
+func resolveAlias(n *yaml.Node) *yaml.Node {
+	for n.Kind == yaml.AliasNode {
+		n = n.Alias
+	}
+	return n
+}
@@ -0,0 +1,148 @@
+package fakedynamo_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/DMRobertson/fakedynamo"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const exampleFixture = `
+Tables:
+  - TableName: Music
+    KeySchema:
+      - {AttributeName: Artist, KeyType: HASH}
+      - {AttributeName: SongTitle, KeyType: RANGE}
+    AttributeDefinitions:
+      - {AttributeName: Artist, AttributeType: S}
+      - {AttributeName: SongTitle, AttributeType: S}
+      - {AttributeName: Genre, AttributeType: S}
+    GlobalSecondaryIndexes:
+      - IndexName: ByGenre
+        KeySchema: [{AttributeName: Genre, KeyType: HASH}]
+        Projection: {ProjectionType: ALL}
+    TimeToLiveSpecification: {AttributeName: Expires, Enabled: true}
+    Items:
+      - Artist: No One You Know
+        SongTitle: Call Me Today
+        Year: 2019
+        Price: 1.50
+        Explicit: false
+        Label: null
+        Chart: [1, two]
+        Cover: !!binary aGk=
+        Credits: {Producer: Acme}
+      - {Artist: {S: Acme Band}, SongTitle: {S: Happy Day}, Tags: {SS: [pop, rock]}, Plays: {N: 3}}
+`
+
+func TestDB_LoadFixture(t *testing.T) {
+	t.Parallel()
+	db := fakedynamo.NewDB()
+	require.NoError(t, db.LoadFixture("music.yaml", strings.NewReader(exampleFixture)))
+
+	described, err := db.DescribeTable(&dynamodb.DescribeTableInput{TableName: ptr("Music")})
+	require.NoError(t, err)
+	require.Len(t, described.Table.GlobalSecondaryIndexes, 1)
+	assert.Equal(t, "ByGenre", val(described.Table.GlobalSecondaryIndexes[0].IndexName))
+
+	ttl, err := db.DescribeTimeToLive(&dynamodb.DescribeTimeToLiveInput{TableName: ptr("Music")})
+	require.NoError(t, err)
+	assert.Equal(t, "Expires", val(ttl.TimeToLiveDescription.AttributeName))
+
+	got, err := db.GetItem(&dynamodb.GetItemInput{
+		Key: map[string]*dynamodb.AttributeValue{
+			"Artist":    {S: ptr("No One You Know")},
+			"SongTitle": {S: ptr("Call Me Today")},
+		},
+		TableName: ptr("Music"),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]*dynamodb.AttributeValue{
+		"Artist":    {S: ptr("No One You Know")},
+		"SongTitle": {S: ptr("Call Me Today")},
+		"Year":      {N: ptr("2019")},
+		"Price":     {N: ptr("1.50")},
+		"Explicit":  {BOOL: ptr(false)},
+		"Label":     {NULL: ptr(true)},
+		"Chart":     {L: []*dynamodb.AttributeValue{{N: ptr("1")}, {S: ptr("two")}}},
+		"Cover":     {B: []byte("hi")},
+		"Credits":   {M: map[string]*dynamodb.AttributeValue{"Producer": {S: ptr("Acme")}}},
+	}, got.Item)
+
+	got, err = db.GetItem(&dynamodb.GetItemInput{
+		Key: map[string]*dynamodb.AttributeValue{
+			"Artist":    {S: ptr("Acme Band")},
+			"SongTitle": {S: ptr("Happy Day")},
+		},
+		TableName: ptr("Music"),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]*dynamodb.AttributeValue{
+		"Artist":    {S: ptr("Acme Band")},
+		"SongTitle": {S: ptr("Happy Day")},
+		"Tags":      {SS: []*string{ptr("pop"), ptr("rock")}},
+		"Plays":     {N: ptr("3")},
+	}, got.Item)
+}
+
+func TestDB_LoadFixtureFile_JSON(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "fixture.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{
+  "Tables": [{
+    "TableName": "Simple",
+    "KeySchema": [{"AttributeName": "Foo", "KeyType": "HASH"}],
+    "AttributeDefinitions": [{"AttributeName": "Foo", "AttributeType": "S"}],
+    "Items": [{"Foo": "a", "Count": 1}]
+  }]
+}`), 0o600))
+	db := fakedynamo.NewDB()
+	require.NoError(t, db.LoadFixtureFile(path))
+	got, err := db.GetItem(&dynamodb.GetItemInput{
+		Key:       map[string]*dynamodb.AttributeValue{"Foo": {S: ptr("a")}},
+		TableName: ptr("Simple"),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]*dynamodb.AttributeValue{
+		"Foo":   {S: ptr("a")},
+		"Count": {N: ptr("1")},
+	}, got.Item)
+}
+
+func TestDB_LoadFixture_ReportsPositions(t *testing.T) {
+	t.Parallel()
+	const table = `
+Tables:
+  - TableName: Simple
+    KeySchema: [{AttributeName: Foo, KeyType: HASH}]
+    AttributeDefinitions: [{AttributeName: Foo, AttributeType: S}]
+`
+	for _, test := range []struct {
+		name    string
+		fixture string
+		line    int
+		message string
+	}{
+		{"unknown field", "Tablez: []", 1, "unknown field Tablez"},
+		{"unknown table field", "Tables:\n  - TableName: X\n    Colour: red", 2, `unknown field "Colour"`},
+		{"invalid table", "Tables:\n  - TableName: X", 2, "KeySchema"},
+		{"item missing key", table + "    Items:\n      - {Bar: b}", 7, "key"},
+		{"bad number", table + "    Items:\n      - {Foo: a, Bar: 0x1F}", 7, "0x1F"},
+		{"bad DynamoDB JSON", table + "    Items:\n      - Foo: {S: a}\n        Bar: {SS: b}", 8, "expected a list"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			err := fakedynamo.NewDB().LoadFixture("fixture.yaml", strings.NewReader(test.fixture))
+			var fixtureErr *fakedynamo.FixtureError
+			require.ErrorAs(t, err, &fixtureErr)
+			assert.Equal(t, "fixture.yaml", fixtureErr.Name)
+			assert.Equal(t, test.line, fixtureErr.Line)
+			assertErrorContains(t, err, test.message)
+		})
+	}
+}
@@ -9,6 +9,14 @@ import (
 )
 
 func (d *DB) GetItem(input *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
+	return d.getItemContext(aws.BackgroundContext(), input)
+}
+
+func (d *DB) getItemContext(ctx aws.Context, input *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
+	if err := d.injectFault(ctx, "GetItem", input); err != nil {
+		return nil, err
+	}
+
 	var errs []error
 	if input.Key == nil {
 		errs = append(errs, newValidationError("Key is a required field"))
@@ -22,13 +30,13 @@ func (d *DB) GetItem(input *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, err
 
 	d.mu.RLock()
 	defer d.mu.RUnlock()
-	t, exists := d.tables.Get(tableKey(*input.TableName))
-	if !exists {
-		return nil, &dynamodb.ResourceNotFoundException{}
+	t, err := d.activeTable(*input.TableName)
+	if err != nil {
+		return nil, err
 	}
 
-	pVal, schemaErr := validateAvmapMatchesSchema(input.Key, t, "Key")
-	err := errors.Join(
+	_, schemaErr := validateAvmapMatchesSchema(input.Key, t, "Key")
+	err = errors.Join(
 		validateKeyAttributeCount(input.Key, t),
 		schemaErr,
 	)
@@ -36,9 +44,20 @@ func (d *DB) GetItem(input *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, err
 		return nil, err
 	}
 
-	var output dynamodb.GetItemOutput
-	partition := t.getPartition(pVal)
-	record, exists := partition.Get(input.Key)
+	record, exists, err := t.items.Get(input.Key)
+	if err != nil {
+		return nil, err
+	}
+	used := readConsumption(itemSize(record), val(input.ConsistentRead))
+	if err := d.consume(t, false, input.Key, used); err != nil {
+		t.countThrottle(input.Key, d.now())
+		return nil, err
+	}
+	t.countRead(input.Key, d.now())
+
+	output := dynamodb.GetItemOutput{
+		ConsumedCapacity: consumedCapacity(*input.TableName, input.ReturnConsumedCapacity, false, used),
+	}
 	if exists {
 		// TODO: projection expressions here
 		output.Item = record
@@ -52,12 +71,15 @@ func (d *DB) BatchGetItem(input *dynamodb.BatchGetItemInput) (*dynamodb.BatchGet
 	panic("implement me")
 }
 
-func (d *DB) BatchGetItemWithContext(_ aws.Context, input *dynamodb.BatchGetItemInput, _ ...request.Option) (*dynamodb.BatchGetItemOutput, error) {
-	return d.BatchGetItem(input)
+func (d *DB) BatchGetItemWithContext(ctx aws.Context, input *dynamodb.BatchGetItemInput, opts ...request.Option) (*dynamodb.BatchGetItemOutput, error) {
+	req, output := d.BatchGetItemRequest(input)
+	req.SetContext(ctx)
+	req.ApplyOptions(opts...)
+	return output, req.Send()
 }
 
-func (d *DB) BatchGetItemRequest(_ *dynamodb.BatchGetItemInput) (*request.Request, *dynamodb.BatchGetItemOutput) {
-	panic("not implemented: BatchGetItemRequest")
+func (d *DB) BatchGetItemRequest(input *dynamodb.BatchGetItemInput) (*request.Request, *dynamodb.BatchGetItemOutput) {
+	return newRequest(d, "BatchGetItem", input, d.BatchGetItem)
 }
 
 func (d *DB) BatchGetItemPages(input *dynamodb.BatchGetItemInput, f func(*dynamodb.BatchGetItemOutput, bool) bool) error {
@@ -69,12 +91,15 @@ func (d *DB) BatchGetItemPagesWithContext(_ aws.Context, input *dynamodb.BatchGe
 	return d.BatchGetItemPages(input, f)
 }
 
-func (d *DB) GetItemWithContext(_ aws.Context, input *dynamodb.GetItemInput, _ ...request.Option) (*dynamodb.GetItemOutput, error) {
-	return d.GetItem(input)
+func (d *DB) GetItemWithContext(ctx aws.Context, input *dynamodb.GetItemInput, opts ...request.Option) (*dynamodb.GetItemOutput, error) {
+	req, output := d.GetItemRequest(input)
+	req.SetContext(ctx)
+	req.ApplyOptions(opts...)
+	return output, req.Send()
 }
 
-func (d *DB) GetItemRequest(_ *dynamodb.GetItemInput) (*request.Request, *dynamodb.GetItemOutput) {
-	panic("not implemented: GetItemRequest")
+func (d *DB) GetItemRequest(input *dynamodb.GetItemInput) (*request.Request, *dynamodb.GetItemOutput) {
+	return newContextRequest(d, "GetItem", input, d.getItemContext)
 }
 
 func (d *DB) TransactGetItems(input *dynamodb.TransactGetItemsInput) (*dynamodb.TransactGetItemsOutput, error) {
@@ -82,10 +107,13 @@ func (d *DB) TransactGetItems(input *dynamodb.TransactGetItemsInput) (*dynamodb.
 	panic("implement me")
 }
 
-func (d *DB) TransactGetItemsWithContext(_ aws.Context, input *dynamodb.TransactGetItemsInput, _ ...request.Option) (*dynamodb.TransactGetItemsOutput, error) {
-	return d.TransactGetItems(input)
+func (d *DB) TransactGetItemsWithContext(ctx aws.Context, input *dynamodb.TransactGetItemsInput, opts ...request.Option) (*dynamodb.TransactGetItemsOutput, error) {
+	req, output := d.TransactGetItemsRequest(input)
+	req.SetContext(ctx)
+	req.ApplyOptions(opts...)
+	return output, req.Send()
 }
 
 func (d *DB) TransactGetItemsRequest(input *dynamodb.TransactGetItemsInput) (*request.Request, *dynamodb.TransactGetItemsOutput) {
-	panic("not implemented: TransactGetItemsRequest")
+	return newRequest(d, "TransactGetItems", input, d.TransactGetItems)
 }
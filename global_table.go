@@ -0,0 +1,480 @@
+package fakedynamo
+
+import (
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// The CreateGlobalTable family of operations manage version 2017.11.29 global
+// tables. Each replica must be created by hand in its region before it is
+// added to the global table. Version 2019.11.21 global tables are managed
+// through UpdateTable's ReplicaUpdates instead; see update_table.go.
+
+// errNoRegions is returned by global table operations on a DB which was not
+// created by [NewRegions].
+var errNoRegions = errors.New("not implemented: global tables require a DB created by NewRegions")
+
+func (d *DB) CreateGlobalTable(input *dynamodb.CreateGlobalTableInput) (*dynamodb.CreateGlobalTableOutput, error) {
+	return d.createGlobalTableContext(aws.BackgroundContext(), input)
+}
+
+func (d *DB) createGlobalTableContext(ctx aws.Context, input *dynamodb.CreateGlobalTableInput) (*dynamodb.CreateGlobalTableOutput, error) {
+	if err := d.injectFault(ctx, "CreateGlobalTable", input); err != nil {
+		return nil, err
+	}
+
+	errs := []error{validateGlobalTableName(input.GlobalTableName)}
+	if input.ReplicationGroup == nil {
+		errs = append(errs, newValidationError("ReplicationGroup is a required field"))
+	}
+	for i, replica := range input.ReplicationGroup {
+		if replica == nil || replica.RegionName == nil {
+			errs = append(errs, newValidationErrorf("ReplicationGroup[%d].RegionName is a required field", i))
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	if d.regions == nil {
+		return nil, errNoRegions
+	}
+
+	name := *input.GlobalTableName
+	global := &globalTable{
+		name:      name,
+		version:   globalTableVersion2017,
+		createdAt: time.Now().UTC(),
+		regions:   make([]string, 0, len(input.ReplicationGroup)),
+	}
+	for _, replica := range input.ReplicationGroup {
+		if err := d.regions.checkReplicaEligible(*replica.RegionName, name); err != nil {
+			return nil, err
+		}
+		global.regions = append(global.regions, *replica.RegionName)
+	}
+	if err := d.regions.createGlobalTable(global); err != nil {
+		return nil, err
+	}
+
+	return &dynamodb.CreateGlobalTableOutput{
+		GlobalTableDescription: d.describeGlobalTable(global),
+	}, nil
+}
+
+func (d *DB) CreateGlobalTableWithContext(ctx aws.Context, input *dynamodb.CreateGlobalTableInput, opts ...request.Option) (*dynamodb.CreateGlobalTableOutput, error) {
+	req, output := d.CreateGlobalTableRequest(input)
+	req.SetContext(ctx)
+	req.ApplyOptions(opts...)
+	return output, req.Send()
+}
+
+func (d *DB) CreateGlobalTableRequest(input *dynamodb.CreateGlobalTableInput) (*request.Request, *dynamodb.CreateGlobalTableOutput) {
+	return newContextRequest(d, "CreateGlobalTable", input, d.createGlobalTableContext)
+}
+
+func (d *DB) UpdateGlobalTable(input *dynamodb.UpdateGlobalTableInput) (*dynamodb.UpdateGlobalTableOutput, error) {
+	return d.updateGlobalTableContext(aws.BackgroundContext(), input)
+}
+
+func (d *DB) updateGlobalTableContext(ctx aws.Context, input *dynamodb.UpdateGlobalTableInput) (*dynamodb.UpdateGlobalTableOutput, error) {
+	if err := d.injectFault(ctx, "UpdateGlobalTable", input); err != nil {
+		return nil, err
+	}
+
+	errs := []error{validateGlobalTableName(input.GlobalTableName)}
+	if input.ReplicaUpdates == nil {
+		errs = append(errs, newValidationError("ReplicaUpdates is a required field"))
+	}
+	for i, update := range input.ReplicaUpdates {
+		switch {
+		case update == nil || (update.Create == nil) == (update.Delete == nil):
+			errs = append(errs, newValidationErrorf("ReplicaUpdates[%d] must specify exactly one of Create and Delete", i))
+		case update.Create != nil && update.Create.RegionName == nil:
+			errs = append(errs, newValidationErrorf("ReplicaUpdates[%d].Create.RegionName is a required field", i))
+		case update.Delete != nil && update.Delete.RegionName == nil:
+			errs = append(errs, newValidationErrorf("ReplicaUpdates[%d].Delete.RegionName is a required field", i))
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	if d.regions == nil {
+		return nil, errNoRegions
+	}
+
+	name := *input.GlobalTableName
+	global := d.regions.globalTable(name)
+	if global == nil || global.version != globalTableVersion2017 {
+		return nil, &dynamodb.GlobalTableNotFoundException{}
+	}
+	for _, update := range input.ReplicaUpdates {
+		if update.Create != nil {
+			region := *update.Create.RegionName
+			if slices.Contains(global.regions, region) {
+				return nil, &dynamodb.ReplicaAlreadyExistsException{}
+			}
+			if err := d.regions.checkReplicaEligible(region, name); err != nil {
+				return nil, err
+			}
+			global.regions = append(global.regions, region)
+		} else {
+			region := *update.Delete.RegionName
+			i := slices.Index(global.regions, region)
+			if i < 0 {
+				return nil, &dynamodb.ReplicaNotFoundException{}
+			}
+			global.regions = slices.Delete(global.regions, i, i+1)
+		}
+	}
+	d.regions.registerGlobalTable(global)
+
+	return &dynamodb.UpdateGlobalTableOutput{
+		GlobalTableDescription: d.describeGlobalTable(global),
+	}, nil
+}
+
+func (d *DB) UpdateGlobalTableWithContext(ctx aws.Context, input *dynamodb.UpdateGlobalTableInput, opts ...request.Option) (*dynamodb.UpdateGlobalTableOutput, error) {
+	req, output := d.UpdateGlobalTableRequest(input)
+	req.SetContext(ctx)
+	req.ApplyOptions(opts...)
+	return output, req.Send()
+}
+
+func (d *DB) UpdateGlobalTableRequest(input *dynamodb.UpdateGlobalTableInput) (*request.Request, *dynamodb.UpdateGlobalTableOutput) {
+	return newContextRequest(d, "UpdateGlobalTable", input, d.updateGlobalTableContext)
+}
+
+func (d *DB) DescribeGlobalTable(input *dynamodb.DescribeGlobalTableInput) (*dynamodb.DescribeGlobalTableOutput, error) {
+	return d.describeGlobalTableContext(aws.BackgroundContext(), input)
+}
+
+func (d *DB) describeGlobalTableContext(ctx aws.Context, input *dynamodb.DescribeGlobalTableInput) (*dynamodb.DescribeGlobalTableOutput, error) {
+	if err := d.injectFault(ctx, "DescribeGlobalTable", input); err != nil {
+		return nil, err
+	}
+
+	if err := validateGlobalTableName(input.GlobalTableName); err != nil {
+		return nil, err
+	}
+	global := d.regions.globalTable(*input.GlobalTableName)
+	if global == nil {
+		return nil, &dynamodb.GlobalTableNotFoundException{}
+	}
+	return &dynamodb.DescribeGlobalTableOutput{
+		GlobalTableDescription: d.describeGlobalTable(global),
+	}, nil
+}
+
+func (d *DB) DescribeGlobalTableWithContext(ctx aws.Context, input *dynamodb.DescribeGlobalTableInput, opts ...request.Option) (*dynamodb.DescribeGlobalTableOutput, error) {
+	req, output := d.DescribeGlobalTableRequest(input)
+	req.SetContext(ctx)
+	req.ApplyOptions(opts...)
+	return output, req.Send()
+}
+
+func (d *DB) DescribeGlobalTableRequest(input *dynamodb.DescribeGlobalTableInput) (*request.Request, *dynamodb.DescribeGlobalTableOutput) {
+	return newContextRequest(d, "DescribeGlobalTable", input, d.describeGlobalTableContext)
+}
+
+func (d *DB) describeGlobalTable(global *globalTable) *dynamodb.GlobalTableDescription {
+	replicas := make([]*dynamodb.ReplicaDescription, 0, len(global.regions))
+	for _, region := range global.regions {
+		replicas = append(replicas, describeReplica(region))
+	}
+	return &dynamodb.GlobalTableDescription{
+		CreationDateTime:  &global.createdAt,
+		GlobalTableArn:    ptr(d.regions.globalTableArn(d.accountID, global.name)),
+		GlobalTableName:   ptr(global.name),
+		GlobalTableStatus: ptr(dynamodb.GlobalTableStatusActive),
+		ReplicationGroup:  replicas,
+	}
+}
+
+func describeReplica(region string) *dynamodb.ReplicaDescription {
+	return &dynamodb.ReplicaDescription{
+		GlobalSecondaryIndexes:        nil,
+		KMSMasterKeyId:                nil,
+		OnDemandThroughputOverride:    nil,
+		ProvisionedThroughputOverride: nil,
+		RegionName:                    ptr(region),
+		ReplicaInaccessibleDateTime:   nil,
+		ReplicaStatus:                 ptr(dynamodb.ReplicaStatusActive),
+		ReplicaStatusDescription:      nil,
+		ReplicaStatusPercentProgress:  nil,
+		ReplicaTableClassSummary:      nil,
+	}
+}
+
+const listGlobalTablesDefaultLimit = 100
+
+func (d *DB) ListGlobalTables(input *dynamodb.ListGlobalTablesInput) (*dynamodb.ListGlobalTablesOutput, error) {
+	return d.listGlobalTablesContext(aws.BackgroundContext(), input)
+}
+
+func (d *DB) listGlobalTablesContext(ctx aws.Context, input *dynamodb.ListGlobalTablesInput) (*dynamodb.ListGlobalTablesOutput, error) {
+	if err := d.injectFault(ctx, "ListGlobalTables", input); err != nil {
+		return nil, err
+	}
+
+	var errs []error
+	if input.ExclusiveStartGlobalTableName != nil {
+		errs = append(errs, validateGlobalTableName(input.ExclusiveStartGlobalTableName))
+	}
+	limit := valOr(input.Limit, listGlobalTablesDefaultLimit)
+	if limit < 1 {
+		errs = append(errs, newValidationError("Limit must be at least 1"))
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	output := &dynamodb.ListGlobalTablesOutput{
+		GlobalTables:                 []*dynamodb.GlobalTable{},
+		LastEvaluatedGlobalTableName: nil,
+	}
+	if d.regions == nil {
+		return output, nil
+	}
+
+	r := d.regions
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	visit := func(global *globalTable) bool {
+		if input.ExclusiveStartGlobalTableName != nil && global.name == *input.ExclusiveStartGlobalTableName {
+			return true
+		}
+		if input.RegionName != nil && !slices.Contains(global.regions, *input.RegionName) {
+			return true
+		}
+		if int64(len(output.GlobalTables)) == limit {
+			output.LastEvaluatedGlobalTableName = output.GlobalTables[limit-1].GlobalTableName
+			return false
+		}
+		replicas := make([]*dynamodb.Replica, 0, len(global.regions))
+		for _, region := range global.regions {
+			replicas = append(replicas, &dynamodb.Replica{RegionName: ptr(region)})
+		}
+		output.GlobalTables = append(output.GlobalTables, &dynamodb.GlobalTable{
+			GlobalTableName:  ptr(global.name),
+			ReplicationGroup: replicas,
+		})
+		return true
+	}
+	if input.ExclusiveStartGlobalTableName != nil {
+		r.globalTables.AscendGreaterOrEqual(&globalTable{name: *input.ExclusiveStartGlobalTableName}, visit) //nolint:exhaustruct
+	} else {
+		r.globalTables.Ascend(visit)
+	}
+	return output, nil
+}
+
+func (d *DB) ListGlobalTablesWithContext(ctx aws.Context, input *dynamodb.ListGlobalTablesInput, opts ...request.Option) (*dynamodb.ListGlobalTablesOutput, error) {
+	req, output := d.ListGlobalTablesRequest(input)
+	req.SetContext(ctx)
+	req.ApplyOptions(opts...)
+	return output, req.Send()
+}
+
+func (d *DB) ListGlobalTablesRequest(input *dynamodb.ListGlobalTablesInput) (*request.Request, *dynamodb.ListGlobalTablesOutput) {
+	return newContextRequest(d, "ListGlobalTables", input, d.listGlobalTablesContext)
+}
+
+func (d *DB) DescribeGlobalTableSettings(input *dynamodb.DescribeGlobalTableSettingsInput) (*dynamodb.DescribeGlobalTableSettingsOutput, error) {
+	return d.describeGlobalTableSettingsContext(aws.BackgroundContext(), input)
+}
+
+func (d *DB) describeGlobalTableSettingsContext(ctx aws.Context, input *dynamodb.DescribeGlobalTableSettingsInput) (*dynamodb.DescribeGlobalTableSettingsOutput, error) {
+	if err := d.injectFault(ctx, "DescribeGlobalTableSettings", input); err != nil {
+		return nil, err
+	}
+	return d.describeGlobalTableSettings(input)
+}
+
+// describeGlobalTableSettings implements DescribeGlobalTableSettings, which
+// UpdateGlobalTableSettings also uses to build its output.
+func (d *DB) describeGlobalTableSettings(input *dynamodb.DescribeGlobalTableSettingsInput) (*dynamodb.DescribeGlobalTableSettingsOutput, error) {
+	if err := validateGlobalTableName(input.GlobalTableName); err != nil {
+		return nil, err
+	}
+	global := d.regions.globalTable(*input.GlobalTableName)
+	if global == nil {
+		return nil, &dynamodb.GlobalTableNotFoundException{}
+	}
+
+	settings := make([]*dynamodb.ReplicaSettingsDescription, 0, len(global.regions))
+	for _, region := range global.regions {
+		db := d.regions.DB(region)
+		if db == nil {
+			continue
+		}
+		if setting := db.describeReplicaSettings(global.name); setting != nil {
+			settings = append(settings, setting)
+		}
+	}
+	return &dynamodb.DescribeGlobalTableSettingsOutput{
+		GlobalTableName: ptr(global.name),
+		ReplicaSettings: settings,
+	}, nil
+}
+
+func (d *DB) DescribeGlobalTableSettingsWithContext(ctx aws.Context, input *dynamodb.DescribeGlobalTableSettingsInput, opts ...request.Option) (*dynamodb.DescribeGlobalTableSettingsOutput, error) {
+	req, output := d.DescribeGlobalTableSettingsRequest(input)
+	req.SetContext(ctx)
+	req.ApplyOptions(opts...)
+	return output, req.Send()
+}
+
+func (d *DB) DescribeGlobalTableSettingsRequest(input *dynamodb.DescribeGlobalTableSettingsInput) (*request.Request, *dynamodb.DescribeGlobalTableSettingsOutput) {
+	return newContextRequest(d, "DescribeGlobalTableSettings", input, d.describeGlobalTableSettingsContext)
+}
+
+// describeReplicaSettings describes this region's replica of a global table,
+// or returns nil if there is no such table.
+func (d *DB) describeReplicaSettings(name string) *dynamodb.ReplicaSettingsDescription {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	t, exists := d.tables.Get(tableKey(name))
+	if !exists {
+		return nil
+	}
+
+	spec := t.spec
+	var rcu, wcu *int64
+	if spec.ProvisionedThroughput != nil {
+		rcu = spec.ProvisionedThroughput.ReadCapacityUnits
+		wcu = spec.ProvisionedThroughput.WriteCapacityUnits
+	}
+	return &dynamodb.ReplicaSettingsDescription{
+		RegionName: ptr(d.region),
+		ReplicaBillingModeSummary: &dynamodb.BillingModeSummary{
+			BillingMode:                       ptr(valOr(spec.BillingMode, dynamodb.BillingModeProvisioned)),
+			LastUpdateToPayPerRequestDateTime: nil,
+		},
+		ReplicaGlobalSecondaryIndexSettings:                nil,
+		ReplicaProvisionedReadCapacityAutoScalingSettings:  nil,
+		ReplicaProvisionedReadCapacityUnits:                rcu,
+		ReplicaProvisionedWriteCapacityAutoScalingSettings: nil,
+		ReplicaProvisionedWriteCapacityUnits:               wcu,
+		ReplicaStatus:                                      ptr(dynamodb.ReplicaStatusActive),
+		ReplicaTableClassSummary:                           nil,
+	}
+}
+
+func (d *DB) UpdateGlobalTableSettings(input *dynamodb.UpdateGlobalTableSettingsInput) (*dynamodb.UpdateGlobalTableSettingsOutput, error) {
+	return d.updateGlobalTableSettingsContext(aws.BackgroundContext(), input)
+}
+
+func (d *DB) updateGlobalTableSettingsContext(ctx aws.Context, input *dynamodb.UpdateGlobalTableSettingsInput) (*dynamodb.UpdateGlobalTableSettingsOutput, error) {
+	if err := d.injectFault(ctx, "UpdateGlobalTableSettings", input); err != nil {
+		return nil, err
+	}
+
+	var errs []error
+	errs = append(errs, validateGlobalTableName(input.GlobalTableName))
+	if input.GlobalTableGlobalSecondaryIndexSettingsUpdate != nil {
+		errs = append(errs, errors.New("not implemented: GlobalTableGlobalSecondaryIndexSettingsUpdate"))
+	}
+	if input.GlobalTableProvisionedWriteCapacityAutoScalingSettingsUpdate != nil {
+		errs = append(errs, errors.New("not implemented: GlobalTableProvisionedWriteCapacityAutoScalingSettingsUpdate"))
+	}
+	if input.ReplicaSettingsUpdate != nil {
+		errs = append(errs, errors.New("not implemented: ReplicaSettingsUpdate"))
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	global := d.regions.globalTable(*input.GlobalTableName)
+	if global == nil {
+		return nil, &dynamodb.GlobalTableNotFoundException{}
+	}
+
+	for _, region := range global.regions {
+		db := d.regions.DB(region)
+		if db == nil {
+			continue
+		}
+		update := &dynamodb.UpdateTableInput{ //nolint:exhaustruct
+			BillingMode: input.GlobalTableBillingMode,
+			TableName:   ptr(global.name),
+		}
+		if input.GlobalTableProvisionedWriteCapacityUnits != nil {
+			db.mu.RLock()
+			rcu := ptr[int64](1)
+			if t, exists := db.tables.Get(tableKey(global.name)); exists && t.spec.ProvisionedThroughput != nil {
+				rcu = t.spec.ProvisionedThroughput.ReadCapacityUnits
+			}
+			db.mu.RUnlock()
+			update.ProvisionedThroughput = &dynamodb.ProvisionedThroughput{
+				ReadCapacityUnits:  rcu,
+				WriteCapacityUnits: input.GlobalTableProvisionedWriteCapacityUnits,
+			}
+		}
+		if _, err := db.updateTable(update); err != nil {
+			return nil, err
+		}
+	}
+
+	settings, err := d.describeGlobalTableSettings(&dynamodb.DescribeGlobalTableSettingsInput{
+		GlobalTableName: input.GlobalTableName,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &dynamodb.UpdateGlobalTableSettingsOutput{
+		GlobalTableName: settings.GlobalTableName,
+		ReplicaSettings: settings.ReplicaSettings,
+	}, nil
+}
+
+func (d *DB) UpdateGlobalTableSettingsWithContext(ctx aws.Context, input *dynamodb.UpdateGlobalTableSettingsInput, opts ...request.Option) (*dynamodb.UpdateGlobalTableSettingsOutput, error) {
+	req, output := d.UpdateGlobalTableSettingsRequest(input)
+	req.SetContext(ctx)
+	req.ApplyOptions(opts...)
+	return output, req.Send()
+}
+
+func (d *DB) UpdateGlobalTableSettingsRequest(input *dynamodb.UpdateGlobalTableSettingsInput) (*request.Request, *dynamodb.UpdateGlobalTableSettingsOutput) {
+	return newContextRequest(d, "UpdateGlobalTableSettings", input, d.updateGlobalTableSettingsContext)
+}
+
+func validateGlobalTableName(name *string) error {
+	if name == nil {
+		return newValidationError("GlobalTableName is a required field")
+	} else if len(*name) < 3 || len(*name) > 255 {
+		return newValidationError("GlobalTableName must be between 3 and 255 characters")
+	}
+	return nil
+}
+
+// checkReplicaEligible checks that the named table in the given region can
+// join a version 2017.11.29 global table: it must exist, be empty, and have a
+// stream of new and old images.
+func (r *Regions) checkReplicaEligible(region, name string) error {
+	db := r.DB(region)
+	if db == nil {
+		return newValidationErrorf("unknown region %q", region)
+	}
+
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	t, exists := db.tables.Get(tableKey(name))
+	if !exists {
+		return &dynamodb.TableNotFoundException{
+			Message_: ptr("table " + name + " does not exist in region " + region),
+		}
+	}
+	stream := t.spec.StreamSpecification
+	if stream == nil || !val(stream.StreamEnabled) || val(stream.StreamViewType) != dynamodb.StreamViewTypeNewAndOldImages {
+		return newValidationErrorf("table %s in region %s must have a stream of NEW_AND_OLD_IMAGES", name, region)
+	}
+	if t.items.Len() > 0 {
+		return newValidationErrorf("table %s in region %s must be empty", name, region)
+	}
+	return nil
+}
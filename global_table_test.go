@@ -0,0 +1,236 @@
+package fakedynamo_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DMRobertson/fakedynamo"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// makeGlobalTable creates a version 2017.11.29 global table replicated across
+// every region.
+func makeGlobalTable(t *testing.T, regions *fakedynamo.Regions, names ...string) *string {
+	t.Helper()
+	input := exampleCreateTableInputSimplePrimaryKey()
+	input.StreamSpecification = &dynamodb.StreamSpecification{
+		StreamEnabled:  ptr(true),
+		StreamViewType: ptr(dynamodb.StreamViewTypeNewAndOldImages),
+	}
+	var group []*dynamodb.Replica
+	for _, name := range names {
+		_, err := regions.DB(name).CreateTable(input)
+		require.NoError(t, err)
+		group = append(group, &dynamodb.Replica{RegionName: ptr(name)})
+	}
+
+	_, err := regions.DB(names[0]).CreateGlobalTable(&dynamodb.CreateGlobalTableInput{
+		GlobalTableName:  input.TableName,
+		ReplicationGroup: group,
+	})
+	require.NoError(t, err)
+	return input.TableName
+}
+
+func TestDB_CreateGlobalTable_ReplicatesWrites(t *testing.T) {
+	t.Parallel()
+	regions := fakedynamo.NewRegions([]string{"eu-west-1", "us-east-1"})
+	regions.PauseReplication()
+	tableName := makeGlobalTable(t, regions, "eu-west-1", "us-east-1")
+	eu, us := regions.DB("eu-west-1"), regions.DB("us-east-1")
+	key := map[string]*dynamodb.AttributeValue{"Foo": {S: ptr("a")}}
+
+	_, err := eu.PutItem(&dynamodb.PutItemInput{
+		Item: map[string]*dynamodb.AttributeValue{
+			"Foo": {S: ptr("a")},
+			"Bar": {N: ptr("1")},
+		},
+		TableName: tableName,
+	})
+	require.NoError(t, err)
+
+	item := getItem(t, eu, tableName, key)
+	assert.Equal(t, ptr("eu-west-1"), item["aws:rep:updateregion"].S)
+	assert.Equal(t, ptr(false), item["aws:rep:deleting"].BOOL)
+	assert.NotNil(t, item["aws:rep:updatetime"].N)
+
+	assert.Nil(t, getItem(t, us, tableName, key), "write replicated while paused")
+	assert.Equal(t, 1, regions.PendingReplication())
+	regions.Flush()
+	assert.Equal(t, item, getItem(t, us, tableName, key))
+
+	_, err = us.DeleteItem(&dynamodb.DeleteItemInput{Key: key, TableName: tableName})
+	require.NoError(t, err)
+	regions.Flush()
+	assert.Nil(t, getItem(t, eu, tableName, key))
+
+	out, err := us.DescribeGlobalTable(&dynamodb.DescribeGlobalTableInput{GlobalTableName: tableName})
+	require.NoError(t, err)
+	assert.Equal(t, "arn:aws:dynamodb::000000000000:global-table/"+*tableName, *out.GlobalTableDescription.GlobalTableArn)
+	assert.Len(t, out.GlobalTableDescription.ReplicationGroup, 2)
+}
+
+func TestDB_CreateGlobalTable_TimestampsWritesWithClock(t *testing.T) {
+	t.Parallel()
+	now := time.Date(2024, 5, 6, 7, 8, 9, 123456000, time.UTC)
+	regions := fakedynamo.NewRegions([]string{"eu-west-1", "us-east-1"}, fakedynamo.WithClock(func() time.Time { return now }))
+	tableName := makeGlobalTable(t, regions, "eu-west-1", "us-east-1")
+	eu := regions.DB("eu-west-1")
+
+	_, err := eu.PutItem(&dynamodb.PutItemInput{
+		Item:      map[string]*dynamodb.AttributeValue{"Foo": {S: ptr("a")}},
+		TableName: tableName,
+	})
+	require.NoError(t, err)
+	item := getItem(t, eu, tableName, map[string]*dynamodb.AttributeValue{"Foo": {S: ptr("a")}})
+	assert.Equal(t, ptr("1714979289.123456"), item["aws:rep:updatetime"].N)
+}
+
+func TestDB_CreateGlobalTable_LastWriterWins(t *testing.T) {
+	t.Parallel()
+	regions := fakedynamo.NewRegions([]string{"eu-west-1", "us-east-1"})
+	regions.PauseReplication()
+	tableName := makeGlobalTable(t, regions, "eu-west-1", "us-east-1")
+	eu, us := regions.DB("eu-west-1"), regions.DB("us-east-1")
+	key := map[string]*dynamodb.AttributeValue{"Foo": {S: ptr("a")}}
+
+	// Both regions write the same item before either write is replicated.
+	for _, db := range []*fakedynamo.DB{eu, us} {
+		_, err := db.PutItem(&dynamodb.PutItemInput{
+			Item: map[string]*dynamodb.AttributeValue{
+				"Foo":    {S: ptr("a")},
+				"Writer": {S: ptr(db.Region())},
+			},
+			TableName: tableName,
+		})
+		require.NoError(t, err)
+	}
+	regions.Flush()
+
+	for _, db := range []*fakedynamo.DB{eu, us} {
+		item := getItem(t, db, tableName, key)
+		assert.Equal(t, ptr("us-east-1"), item["Writer"].S, db.Region())
+	}
+}
+
+func TestDB_CreateGlobalTable_ReplicatesAfterDelay(t *testing.T) {
+	t.Parallel()
+	regions := fakedynamo.NewRegions([]string{"eu-west-1", "us-east-1"})
+	regions.SetReplicationDelay(10 * time.Millisecond)
+	tableName := makeGlobalTable(t, regions, "eu-west-1", "us-east-1")
+	key := map[string]*dynamodb.AttributeValue{"Foo": {S: ptr("a")}}
+
+	_, err := regions.DB("eu-west-1").PutItem(&dynamodb.PutItemInput{Item: key, TableName: tableName})
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		return regions.PendingReplication() == 0
+	}, time.Second, time.Millisecond)
+	assert.NotNil(t, getItem(t, regions.DB("us-east-1"), tableName, key))
+}
+
+func TestDB_CreateGlobalTable_ReplicatesAfterDelayOnClock(t *testing.T) {
+	t.Parallel()
+	clock := newFakeClock()
+	regions := fakedynamo.NewRegions([]string{"eu-west-1", "us-east-1"}, fakedynamo.WithClock(clock.Now))
+	regions.SetReplicationDelay(time.Hour)
+	regions.PauseReplication()
+	tableName := makeGlobalTable(t, regions, "eu-west-1", "us-east-1")
+	key := map[string]*dynamodb.AttributeValue{"Foo": {S: ptr("a")}}
+
+	_, err := regions.DB("eu-west-1").PutItem(&dynamodb.PutItemInput{Item: key, TableName: tableName})
+	require.NoError(t, err)
+	clock.Advance(time.Hour)
+	regions.ResumeReplication()
+	assert.Eventually(t, func() bool {
+		return regions.PendingReplication() == 0
+	}, time.Second, time.Millisecond)
+	assert.NotNil(t, getItem(t, regions.DB("us-east-1"), tableName, key))
+}
+
+func TestDB_CreateGlobalTable_Errors(t *testing.T) {
+	t.Parallel()
+	regions := fakedynamo.NewRegions([]string{"eu-west-1", "us-east-1"})
+	eu := regions.DB("eu-west-1")
+	input := exampleCreateTableInputSimplePrimaryKey()
+	_, err := eu.CreateTable(input)
+	require.NoError(t, err)
+
+	_, err = eu.CreateGlobalTable(&dynamodb.CreateGlobalTableInput{
+		GlobalTableName:  input.TableName,
+		ReplicationGroup: []*dynamodb.Replica{{RegionName: ptr("eu-west-1")}},
+	})
+	assertErrorContains(t, err, "NEW_AND_OLD_IMAGES")
+
+	_, err = eu.CreateGlobalTable(&dynamodb.CreateGlobalTableInput{
+		GlobalTableName:  input.TableName,
+		ReplicationGroup: []*dynamodb.Replica{{RegionName: ptr("us-east-1")}},
+	})
+	var notFound *dynamodb.TableNotFoundException
+	require.ErrorAs(t, err, &notFound)
+
+	tableName := makeGlobalTable(t, regions, "us-east-1")
+	_, err = eu.CreateGlobalTable(&dynamodb.CreateGlobalTableInput{
+		GlobalTableName:  tableName,
+		ReplicationGroup: []*dynamodb.Replica{{RegionName: ptr("us-east-1")}},
+	})
+	var alreadyExists *dynamodb.GlobalTableAlreadyExistsException
+	require.ErrorAs(t, err, &alreadyExists)
+
+	_, err = eu.UpdateGlobalTable(&dynamodb.UpdateGlobalTableInput{
+		GlobalTableName: tableName,
+		ReplicaUpdates: []*dynamodb.ReplicaUpdate{{
+			Delete: &dynamodb.DeleteReplicaAction{RegionName: ptr("eu-west-1")},
+		}},
+	})
+	var replicaNotFound *dynamodb.ReplicaNotFoundException
+	require.ErrorAs(t, err, &replicaNotFound)
+}
+
+func TestDB_UpdateTable_ReplicaUpdates(t *testing.T) {
+	t.Parallel()
+	regions := fakedynamo.NewRegions([]string{"eu-west-1", "us-east-1"})
+	regions.PauseReplication()
+	eu, us := regions.DB("eu-west-1"), regions.DB("us-east-1")
+	input := exampleCreateTableInputSimplePrimaryKey()
+	_, err := eu.CreateTable(input)
+	require.NoError(t, err)
+	existing := map[string]*dynamodb.AttributeValue{"Foo": {S: ptr("existing")}}
+	_, err = eu.PutItem(&dynamodb.PutItemInput{Item: existing, TableName: input.TableName})
+	require.NoError(t, err)
+
+	out, err := eu.UpdateTable(&dynamodb.UpdateTableInput{
+		ReplicaUpdates: []*dynamodb.ReplicationGroupUpdate{{
+			Create: &dynamodb.CreateReplicationGroupMemberAction{RegionName: ptr("us-east-1")},
+		}},
+		TableName: input.TableName,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, ptr("2019.11.21"), out.TableDescription.GlobalTableVersion)
+	require.Len(t, out.TableDescription.Replicas, 1)
+	assert.Equal(t, ptr("us-east-1"), out.TableDescription.Replicas[0].RegionName)
+
+	// Existing items are backfilled immediately, without aws:rep attributes.
+	assert.Equal(t, existing, getItem(t, us, input.TableName, existing))
+
+	added := map[string]*dynamodb.AttributeValue{"Foo": {S: ptr("added")}}
+	_, err = us.PutItem(&dynamodb.PutItemInput{Item: added, TableName: input.TableName})
+	require.NoError(t, err)
+	regions.Flush()
+	assert.Equal(t, added, getItem(t, eu, input.TableName, added))
+
+	_, err = eu.UpdateTable(&dynamodb.UpdateTableInput{
+		ReplicaUpdates: []*dynamodb.ReplicationGroupUpdate{{
+			Delete: &dynamodb.DeleteReplicationGroupMemberAction{RegionName: ptr("us-east-1")},
+		}},
+		TableName: input.TableName,
+	})
+	require.NoError(t, err)
+	_, err = us.DescribeTable(&dynamodb.DescribeTableInput{TableName: input.TableName})
+	var notFound *dynamodb.ResourceNotFoundException
+	require.ErrorAs(t, err, &notFound)
+	desc, err := eu.DescribeTable(&dynamodb.DescribeTableInput{TableName: input.TableName})
+	require.NoError(t, err)
+	assert.Empty(t, desc.Table.Replicas)
+}
@@ -0,0 +1,496 @@
+package fakedynamo
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/klauspost/compress/zstd"
+)
+
+// ImportTable creates a new table and populates it from objects in the S3
+// stand-in directory configured with [WithS3Directory]. Every object whose
+// key starts with S3BucketSource.S3KeyPrefix is imported.
+//
+// Imports happen synchronously: the returned import has already COMPLETED or
+// FAILED. Items which can't be parsed or written are counted in ErrorCount and
+// otherwise skipped, as DynamoDB does. If the source objects can't be read at
+// all, the import FAILS and the new table is deleted.
+func (d *DB) ImportTable(input *dynamodb.ImportTableInput) (*dynamodb.ImportTableOutput, error) {
+	return d.importTableContext(aws.BackgroundContext(), input)
+}
+
+// importTableContext implements ImportTable. If ctx ends part way through, the
+// import is CANCELLED, leaving the items imported so far in the table.
+func (d *DB) importTableContext(ctx aws.Context, input *dynamodb.ImportTableInput) (*dynamodb.ImportTableOutput, error) {
+	if err := d.injectFault(ctx, "ImportTable", input); err != nil {
+		return nil, err
+	}
+
+	var errs []error
+	source := input.S3BucketSource
+	if source == nil {
+		errs = append(errs, newValidationError("S3BucketSource is a required field"))
+	} else if source.S3Bucket == nil {
+		errs = append(errs, newValidationError("S3BucketSource.S3Bucket is a required field"))
+	}
+
+	params := input.TableCreationParameters
+	if params == nil {
+		errs = append(errs, newValidationError("TableCreationParameters is a required field"))
+	}
+
+	inputFormat := val(input.InputFormat)
+	switch inputFormat {
+	case dynamodb.InputFormatCsv:
+	case dynamodb.InputFormatDynamodbJson, dynamodb.InputFormatIon:
+		if input.InputFormatOptions != nil {
+			errs = append(errs, newValidationError("InputFormatOptions may only be given for the CSV InputFormat"))
+		}
+	default:
+		errs = append(errs, newValidationError("InputFormat must be one of [CSV, DYNAMODB_JSON, ION]"))
+	}
+
+	compression := valOr(input.InputCompressionType, dynamodb.InputCompressionTypeNone)
+	switch compression {
+	case dynamodb.InputCompressionTypeNone:
+	case dynamodb.InputCompressionTypeGzip:
+	case dynamodb.InputCompressionTypeZstd:
+	default:
+		errs = append(errs, newValidationError("InputCompressionType must be one of [GZIP, NONE, ZSTD]"))
+	}
+
+	var csvOptions *dynamodb.CsvOptions
+	if input.InputFormatOptions != nil {
+		csvOptions = input.InputFormatOptions.Csv
+	}
+	if csvOptions != nil && csvOptions.Delimiter != nil && len([]rune(*csvOptions.Delimiter)) != 1 {
+		errs = append(errs, newValidationError("InputFormatOptions.Csv.Delimiter must be a single character"))
+	}
+
+	if d.s3Dir == "" {
+		errs = append(errs, errors.New("fakedynamo: no S3 stand-in directory configured, see WithS3Directory"))
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	createInput := &dynamodb.CreateTableInput{
+		AttributeDefinitions:   params.AttributeDefinitions,
+		BillingMode:            params.BillingMode,
+		GlobalSecondaryIndexes: params.GlobalSecondaryIndexes,
+		KeySchema:              params.KeySchema,
+		OnDemandThroughput:     params.OnDemandThroughput,
+		ProvisionedThroughput:  params.ProvisionedThroughput,
+		SSESpecification:       params.SSESpecification,
+		TableName:              params.TableName,
+	}
+
+	now := time.Now().UTC()
+	importID := fmt.Sprintf("%014d-%08x", now.UnixMilli(), rand.Uint32()) //nolint:gosec
+	desc := &dynamodb.ImportTableDescription{
+		ClientToken:             input.ClientToken,
+		ErrorCount:              ptr[int64](0),
+		ImportArn:               ptr(fmt.Sprintf("%s/import/%s", d.tableArn(val(params.TableName)), importID)),
+		ImportedItemCount:       ptr[int64](0),
+		InputCompressionType:    &compression,
+		InputFormat:             &inputFormat,
+		InputFormatOptions:      input.InputFormatOptions,
+		ProcessedItemCount:      ptr[int64](0),
+		ProcessedSizeBytes:      ptr[int64](0),
+		S3BucketSource:          source,
+		StartTime:               &now,
+		TableArn:                ptr(d.tableArn(val(params.TableName))),
+		TableCreationParameters: params,
+	}
+
+	if _, err := d.createTable(createInput); err != nil {
+		return nil, err
+	}
+
+	objects, err := d.readS3Objects(*source.S3Bucket, val(source.S3KeyPrefix), compression)
+	if err == nil && len(objects) == 0 {
+		err = errors.New("no objects found matching the S3 source")
+	}
+	if err != nil {
+		_, _ = d.deleteTable(&dynamodb.DeleteTableInput{TableName: params.TableName})
+		desc.ImportStatus = ptr(dynamodb.ImportStatusFailed)
+		desc.FailureCode = ptr("S3ReadFailure")
+		desc.FailureMessage = ptr(err.Error())
+		desc.EndTime = ptr(time.Now().UTC())
+		d.recordImport(desc)
+		return &dynamodb.ImportTableOutput{ImportTableDescription: desc}, nil
+	}
+
+	types := make(map[string]string)
+	for _, attr := range params.AttributeDefinitions {
+		types[*attr.AttributeName] = *attr.AttributeType
+	}
+	for _, object := range objects {
+		*desc.ProcessedSizeBytes += int64(len(object))
+		var items []avmap
+		var parseErrors int
+		switch inputFormat {
+		case dynamodb.InputFormatCsv:
+			items, parseErrors = parseCSVItems(object, csvOptions, types)
+		case dynamodb.InputFormatDynamodbJson:
+			items, parseErrors = parseDynamoDBJSONItems(object)
+		case dynamodb.InputFormatIon:
+			items, parseErrors = parseIonItems(object)
+		}
+
+		*desc.ProcessedItemCount += int64(len(items) + parseErrors)
+		*desc.ErrorCount += int64(parseErrors)
+		for _, item := range items {
+			if err := ctx.Err(); err != nil {
+				desc.ImportStatus = ptr(dynamodb.ImportStatusCancelled)
+				desc.EndTime = ptr(time.Now().UTC())
+				d.recordImport(desc)
+				return nil, err
+			}
+			if err := d.importItem(*params.TableName, item); err != nil {
+				*desc.ErrorCount++
+			} else {
+				*desc.ImportedItemCount++
+			}
+		}
+	}
+
+	desc.ImportStatus = ptr(dynamodb.ImportStatusCompleted)
+	desc.EndTime = ptr(time.Now().UTC())
+	d.recordImport(desc)
+	return &dynamodb.ImportTableOutput{ImportTableDescription: desc}, nil
+}
+
+// importItem writes an imported item. Unlike PutItem, it can write to a
+// table which is still CREATING.
+func (d *DB) importItem(tableName string, item avmap) error {
+	if err := validatePutItemInputMap(item, ""); err != nil {
+		return err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	t, exists := d.tables.Get(tableKey(tableName))
+	if !exists {
+		return &dynamodb.ResourceNotFoundException{}
+	}
+	if _, err := validateAvmapMatchesSchema(item, t, "Item"); err != nil {
+		return err
+	}
+	_, _, err := d.putItem(t, item)
+	return err
+}
+
+func (d *DB) recordImport(desc *dynamodb.ImportTableDescription) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.imports = append(d.imports, desc)
+}
+
+// readS3Objects reads and decompresses every object in the given bucket of
+// the S3 stand-in whose key starts with the given prefix, in key order.
+func (d *DB) readS3Objects(bucket, prefix, compression string) ([][]byte, error) {
+	bucketDir := filepath.Join(d.s3Dir, bucket)
+	var objects [][]byte
+	err := filepath.WalkDir(bucketDir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(bucketDir, path)
+		if err != nil {
+			return err
+		}
+		if !strings.HasPrefix(filepath.ToSlash(rel), prefix) {
+			return nil
+		}
+
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		object, err := decompress(raw, compression)
+		if err != nil {
+			return fmt.Errorf("%s: %w", filepath.ToSlash(rel), err)
+		}
+		objects = append(objects, object)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error reading from S3 stand-in: %w", err)
+	}
+	return objects, nil
+}
+
+func decompress(raw []byte, compression string) ([]byte, error) {
+	var reader io.Reader
+	switch compression {
+	case dynamodb.InputCompressionTypeGzip:
+		gz, err := gzip.NewReader(bytes.NewReader(raw))
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		reader = gz
+	case dynamodb.InputCompressionTypeZstd:
+		zr, err := zstd.NewReader(bytes.NewReader(raw))
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		reader = zr
+	default:
+		return raw, nil
+	}
+	return io.ReadAll(reader)
+}
+
+// parseDynamoDBJSONItems decodes lines of the form {"Item": {...}}, as
+// written by a DynamoDB JSON export. Returns the items decoded successfully
+// and the number of lines that couldn't be decoded.
+func parseDynamoDBJSONItems(object []byte) ([]avmap, int) {
+	var items []avmap
+	var failures int
+	scanner := bufio.NewScanner(bytes.NewReader(object))
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var record struct{ Item avmap }
+		if err := unmarshalDynamoDBJSON(line, &record); err != nil || record.Item == nil {
+			failures++
+			continue
+		}
+		items = append(items, record.Item)
+	}
+	if scanner.Err() != nil {
+		failures++
+	}
+	return items, failures
+}
+
+// parseCSVItems decodes CSV rows into items. Key attributes are converted to
+// the types given in the table's attribute definitions; all other attributes
+// are imported as strings. Empty values are omitted. Returns the items decoded
+// successfully and the number of rows that couldn't be decoded.
+func parseCSVItems(object []byte, options *dynamodb.CsvOptions, types map[string]string) ([]avmap, int) {
+	reader := csv.NewReader(bytes.NewReader(object))
+	reader.FieldsPerRecord = -1
+	var header []string
+	if options != nil {
+		if options.Delimiter != nil {
+			reader.Comma = []rune(*options.Delimiter)[0]
+		}
+		header = aws.StringValueSlice(options.HeaderList)
+	}
+
+	var items []avmap
+	var failures int
+	for {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return items, failures
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) {
+				return items, failures + 1
+			}
+			failures++
+			continue
+		}
+		if len(header) == 0 {
+			header = row
+			continue
+		}
+
+		item, err := csvRowToItem(header, row, types)
+		if err != nil {
+			failures++
+			continue
+		}
+		items = append(items, item)
+	}
+}
+
+func csvRowToItem(header, row []string, types map[string]string) (avmap, error) {
+	if len(row) > len(header) {
+		return nil, errors.New("row has more fields than the header")
+	}
+
+	item := avmap{}
+	for i, value := range row {
+		if value == "" {
+			continue
+		}
+		name := header[i]
+		switch types[name] {
+		case dynamodb.ScalarAttributeTypeN:
+			if _, err := strconv.ParseFloat(value, 64); err != nil {
+				return nil, fmt.Errorf("%s: invalid number", name)
+			}
+			item[name] = &dynamodb.AttributeValue{N: ptr(value)}
+		case dynamodb.ScalarAttributeTypeB:
+			decoded, err := base64.StdEncoding.DecodeString(value)
+			if err != nil {
+				return nil, fmt.Errorf("%s: invalid base64", name)
+			}
+			item[name] = &dynamodb.AttributeValue{B: decoded}
+		default:
+			item[name] = &dynamodb.AttributeValue{S: ptr(value)}
+		}
+	}
+	return item, nil
+}
+
+func (d *DB) ImportTableWithContext(ctx aws.Context, input *dynamodb.ImportTableInput, opts ...request.Option) (*dynamodb.ImportTableOutput, error) {
+	req, output := d.ImportTableRequest(input)
+	req.SetContext(ctx)
+	req.ApplyOptions(opts...)
+	return output, req.Send()
+}
+
+func (d *DB) ImportTableRequest(input *dynamodb.ImportTableInput) (*request.Request, *dynamodb.ImportTableOutput) {
+	return newContextRequest(d, "ImportTable", input, d.importTableContext)
+}
+
+func (d *DB) DescribeImport(input *dynamodb.DescribeImportInput) (*dynamodb.DescribeImportOutput, error) {
+	return d.describeImportContext(aws.BackgroundContext(), input)
+}
+
+func (d *DB) describeImportContext(ctx aws.Context, input *dynamodb.DescribeImportInput) (*dynamodb.DescribeImportOutput, error) {
+	if err := d.injectFault(ctx, "DescribeImport", input); err != nil {
+		return nil, err
+	}
+
+	if input.ImportArn == nil {
+		return nil, newValidationError("ImportArn is a required field")
+	}
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	for _, desc := range d.imports {
+		if *desc.ImportArn == *input.ImportArn {
+			return &dynamodb.DescribeImportOutput{ImportTableDescription: desc}, nil
+		}
+	}
+	return nil, &dynamodb.ImportNotFoundException{}
+}
+
+func (d *DB) DescribeImportWithContext(ctx aws.Context, input *dynamodb.DescribeImportInput, opts ...request.Option) (*dynamodb.DescribeImportOutput, error) {
+	req, output := d.DescribeImportRequest(input)
+	req.SetContext(ctx)
+	req.ApplyOptions(opts...)
+	return output, req.Send()
+}
+
+func (d *DB) DescribeImportRequest(input *dynamodb.DescribeImportInput) (*request.Request, *dynamodb.DescribeImportOutput) {
+	return newContextRequest(d, "DescribeImport", input, d.describeImportContext)
+}
+
+// importsPageTokenWidth pads page tokens for ListImports, which the SDK
+// requires to be at least 112 characters long.
+const importsPageTokenWidth = 112
+
+func (d *DB) ListImports(input *dynamodb.ListImportsInput) (*dynamodb.ListImportsOutput, error) {
+	return d.listImportsContext(aws.BackgroundContext(), input)
+}
+
+func (d *DB) listImportsContext(ctx aws.Context, input *dynamodb.ListImportsInput) (*dynamodb.ListImportsOutput, error) {
+	if err := d.injectFault(ctx, "ListImports", input); err != nil {
+		return nil, err
+	}
+
+	pageSize := valOr(input.PageSize, 25)
+	if pageSize < 1 || pageSize > 25 {
+		return nil, newValidationError("PageSize must be between 1 and 25")
+	}
+	start := 0
+	if input.NextToken != nil {
+		var err error
+		start, err = strconv.Atoi(*input.NextToken)
+		if err != nil || start < 0 {
+			return nil, newValidationError("invalid NextToken")
+		}
+	}
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	output := &dynamodb.ListImportsOutput{
+		ImportSummaryList: []*dynamodb.ImportSummary{},
+	}
+	for i := start; i < len(d.imports); i++ {
+		desc := d.imports[i]
+		if input.TableArn != nil && *desc.TableArn != *input.TableArn {
+			continue
+		}
+		if len(output.ImportSummaryList) == int(pageSize) {
+			output.NextToken = ptr(fmt.Sprintf("%0*d", importsPageTokenWidth, i))
+			break
+		}
+		output.ImportSummaryList = append(output.ImportSummaryList, &dynamodb.ImportSummary{
+			CloudWatchLogGroupArn: desc.CloudWatchLogGroupArn,
+			EndTime:               desc.EndTime,
+			ImportArn:             desc.ImportArn,
+			ImportStatus:          desc.ImportStatus,
+			InputFormat:           desc.InputFormat,
+			S3BucketSource:        desc.S3BucketSource,
+			StartTime:             desc.StartTime,
+			TableArn:              desc.TableArn,
+		})
+	}
+	return output, nil
+}
+
+func (d *DB) ListImportsWithContext(ctx aws.Context, input *dynamodb.ListImportsInput, opts ...request.Option) (*dynamodb.ListImportsOutput, error) {
+	req, output := d.ListImportsRequest(input)
+	req.SetContext(ctx)
+	req.ApplyOptions(opts...)
+	return output, req.Send()
+}
+
+func (d *DB) ListImportsRequest(input *dynamodb.ListImportsInput) (*request.Request, *dynamodb.ListImportsOutput) {
+	return newContextRequest(d, "ListImports", input, d.listImportsContext)
+}
+
+func (d *DB) ListImportsPages(input *dynamodb.ListImportsInput, processPage func(*dynamodb.ListImportsOutput, bool) bool) error {
+	return d.ListImportsPagesWithContext(aws.BackgroundContext(), input, processPage)
+}
+
+func (d *DB) ListImportsPagesWithContext(ctx aws.Context, input *dynamodb.ListImportsInput, processPage func(*dynamodb.ListImportsOutput, bool) bool, opts ...request.Option) error {
+	input = shallowCopy(input)
+	for {
+		output, err := d.ListImportsWithContext(ctx, input, opts...)
+		if err != nil {
+			return err
+		}
+		lastPage := output.NextToken == nil
+		shouldContinue := processPage(output, lastPage)
+		if lastPage || !shouldContinue {
+			break
+		}
+		input.NextToken = output.NextToken
+	}
+	return nil
+}
@@ -0,0 +1,256 @@
+package fakedynamo_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/DMRobertson/fakedynamo"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeS3Object writes an object into an S3 stand-in directory.
+func writeS3Object(t *testing.T, s3Dir, bucket, key string, contents []byte) {
+	t.Helper()
+	path := filepath.Join(s3Dir, bucket, filepath.FromSlash(key))
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o750))
+	require.NoError(t, os.WriteFile(path, contents, 0o600))
+}
+
+func exampleTableCreationParameters() *dynamodb.TableCreationParameters {
+	spec := exampleCreateTableInputCompositePrimaryKey()
+	spec.AttributeDefinitions = append(spec.AttributeDefinitions, &dynamodb.AttributeDefinition{
+		AttributeName: ptr("Score"),
+		AttributeType: ptr(dynamodb.ScalarAttributeTypeN),
+	})
+	return &dynamodb.TableCreationParameters{
+		AttributeDefinitions: spec.AttributeDefinitions,
+		BillingMode:          ptr(dynamodb.BillingModePayPerRequest),
+		GlobalSecondaryIndexes: []*dynamodb.GlobalSecondaryIndex{{
+			IndexName: ptr("by-score"),
+			KeySchema: []*dynamodb.KeySchemaElement{{
+				AttributeName: ptr("Score"),
+				KeyType:       ptr(dynamodb.KeyTypeHash),
+			}},
+			Projection: &dynamodb.Projection{ProjectionType: ptr(dynamodb.ProjectionTypeAll)},
+		}},
+		KeySchema: spec.KeySchema,
+		TableName: spec.TableName,
+	}
+}
+
+func getItem(t *testing.T, db *fakedynamo.DB, tableName *string, key map[string]*dynamodb.AttributeValue) map[string]*dynamodb.AttributeValue {
+	t.Helper()
+	result, err := db.GetItem(&dynamodb.GetItemInput{Key: key, TableName: tableName})
+	require.NoError(t, err)
+	return result.Item
+}
+
+func TestDB_ImportTable_CSV(t *testing.T) {
+	t.Parallel()
+	s3Dir := t.TempDir()
+	db := fakedynamo.NewDB(fakedynamo.WithS3Directory(s3Dir))
+	writeS3Object(t, s3Dir, "bucket", "csv/part-1.csv", []byte(
+		"a|1|10|hello\n"+
+			"b|2||\n"+
+			"c|3|not-a-number|\n",
+	))
+	params := exampleTableCreationParameters()
+
+	result, err := db.ImportTable(&dynamodb.ImportTableInput{
+		InputFormat: ptr(dynamodb.InputFormatCsv),
+		InputFormatOptions: &dynamodb.InputFormatOptions{Csv: &dynamodb.CsvOptions{
+			Delimiter:  ptr("|"),
+			HeaderList: []*string{ptr("Foo"), ptr("Bar"), ptr("Score"), ptr("Other")},
+		}},
+		S3BucketSource:          &dynamodb.S3BucketSource{S3Bucket: ptr("bucket"), S3KeyPrefix: ptr("csv/")},
+		TableCreationParameters: params,
+	})
+	require.NoError(t, err)
+	desc := result.ImportTableDescription
+	assert.Equal(t, dynamodb.ImportStatusCompleted, val(desc.ImportStatus))
+	assert.Equal(t, int64(3), val(desc.ProcessedItemCount))
+	assert.Equal(t, int64(2), val(desc.ImportedItemCount))
+	assert.Equal(t, int64(1), val(desc.ErrorCount))
+
+	assert.Equal(t, map[string]*dynamodb.AttributeValue{
+		"Foo":   {S: ptr("a")},
+		"Bar":   {S: ptr("1")},
+		"Score": {N: ptr("10")},
+		"Other": {S: ptr("hello")},
+	}, getItem(t, db, params.TableName, map[string]*dynamodb.AttributeValue{
+		"Foo": {S: ptr("a")},
+		"Bar": {S: ptr("1")},
+	}))
+
+	described, err := db.DescribeTable(&dynamodb.DescribeTableInput{TableName: params.TableName})
+	require.NoError(t, err)
+	require.Len(t, described.Table.GlobalSecondaryIndexes, 1)
+	assert.Equal(t, "by-score", val(described.Table.GlobalSecondaryIndexes[0].IndexName))
+
+	imported, err := db.DescribeImport(&dynamodb.DescribeImportInput{ImportArn: desc.ImportArn})
+	require.NoError(t, err)
+	assert.Equal(t, desc, imported.ImportTableDescription)
+}
+
+func TestDB_ImportTable_DynamoDBJSONWithGzip(t *testing.T) {
+	t.Parallel()
+	s3Dir := t.TempDir()
+	db := fakedynamo.NewDB(fakedynamo.WithS3Directory(s3Dir))
+
+	var compressed bytes.Buffer
+	gz := gzip.NewWriter(&compressed)
+	_, err := gz.Write([]byte(`{"Item":{"Foo":{"S":"a"},"Bar":{"S":"1"},"Tags":{"SS":["x","y"]}}}
+not json
+{"Item":{"Foo":{"S":"b"},"Bar":{"S":"2"},"Nested":{"M":{"L":{"L":[{"BOOL":true},{"NULL":true}]}}}}}
+`))
+	require.NoError(t, err)
+	require.NoError(t, gz.Close())
+	writeS3Object(t, s3Dir, "bucket", "data.json.gz", compressed.Bytes())
+	params := exampleTableCreationParameters()
+
+	result, err := db.ImportTable(&dynamodb.ImportTableInput{
+		InputCompressionType:    ptr(dynamodb.InputCompressionTypeGzip),
+		InputFormat:             ptr(dynamodb.InputFormatDynamodbJson),
+		S3BucketSource:          &dynamodb.S3BucketSource{S3Bucket: ptr("bucket")},
+		TableCreationParameters: params,
+	})
+	require.NoError(t, err)
+	desc := result.ImportTableDescription
+	assert.Equal(t, int64(3), val(desc.ProcessedItemCount))
+	assert.Equal(t, int64(2), val(desc.ImportedItemCount))
+	assert.Equal(t, int64(1), val(desc.ErrorCount))
+
+	item := getItem(t, db, params.TableName, map[string]*dynamodb.AttributeValue{
+		"Foo": {S: ptr("b")},
+		"Bar": {S: ptr("2")},
+	})
+	assert.Equal(t, &dynamodb.AttributeValue{L: []*dynamodb.AttributeValue{
+		{BOOL: ptr(true)}, {NULL: ptr(true)},
+	}}, item["Nested"].M["L"])
+}
+
+func TestDB_ImportTable_IonWithZstd(t *testing.T) {
+	t.Parallel()
+	s3Dir := t.TempDir()
+	db := fakedynamo.NewDB(fakedynamo.WithS3Directory(s3Dir))
+
+	encoder, err := zstd.NewWriter(nil)
+	require.NoError(t, err)
+	ion := `$ion_1_0 {Item:{Foo:"a",Bar:"1",Score:12.,Ratio:1.5d2,Blob:{{aGk=}},Set:$dynamodb_SS::["x","y"],Flag:true,Nothing:null}}
+// A comment
+{Item:{Foo:"b", 'Bar':"2", Nums:$dynamodb_NS::[1.,2.], List:[1, "two", {three: 3}]}}
+{Item:{Foo:"bad", Bar:}}
+{NotAnItem:{}}
+{Item:{Foo:"c", Bar:"3"}}
+`
+	writeS3Object(t, s3Dir, "bucket", "data.ion.zst", encoder.EncodeAll([]byte(ion), nil))
+	params := exampleTableCreationParameters()
+
+	result, err := db.ImportTable(&dynamodb.ImportTableInput{
+		InputCompressionType:    ptr(dynamodb.InputCompressionTypeZstd),
+		InputFormat:             ptr(dynamodb.InputFormatIon),
+		S3BucketSource:          &dynamodb.S3BucketSource{S3Bucket: ptr("bucket")},
+		TableCreationParameters: params,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), val(result.ImportTableDescription.ImportedItemCount))
+	assert.Equal(t, int64(2), val(result.ImportTableDescription.ErrorCount))
+
+	assert.Equal(t, map[string]*dynamodb.AttributeValue{
+		"Foo":     {S: ptr("a")},
+		"Bar":     {S: ptr("1")},
+		"Score":   {N: ptr("12")},
+		"Ratio":   {N: ptr("1.5E2")},
+		"Blob":    {B: []byte("hi")},
+		"Set":     {SS: []*string{ptr("x"), ptr("y")}},
+		"Flag":    {BOOL: ptr(true)},
+		"Nothing": {NULL: ptr(true)},
+	}, getItem(t, db, params.TableName, map[string]*dynamodb.AttributeValue{
+		"Foo": {S: ptr("a")},
+		"Bar": {S: ptr("1")},
+	}))
+	assert.Equal(t, map[string]*dynamodb.AttributeValue{
+		"Foo":  {S: ptr("b")},
+		"Bar":  {S: ptr("2")},
+		"Nums": {NS: []*string{ptr("1"), ptr("2")}},
+		"List": {L: []*dynamodb.AttributeValue{
+			{N: ptr("1")},
+			{S: ptr("two")},
+			{M: map[string]*dynamodb.AttributeValue{"three": {N: ptr("3")}}},
+		}},
+	}, getItem(t, db, params.TableName, map[string]*dynamodb.AttributeValue{
+		"Foo": {S: ptr("b")},
+		"Bar": {S: ptr("2")},
+	}))
+	assert.Equal(t, map[string]*dynamodb.AttributeValue{
+		"Foo": {S: ptr("c")},
+		"Bar": {S: ptr("3")},
+	}, getItem(t, db, params.TableName, map[string]*dynamodb.AttributeValue{
+		"Foo": {S: ptr("c")},
+		"Bar": {S: ptr("3")},
+	}))
+}
+
+func TestDB_ImportTable_FailsWithoutSourceObjects(t *testing.T) {
+	t.Parallel()
+	db := fakedynamo.NewDB(fakedynamo.WithS3Directory(t.TempDir()))
+	params := exampleTableCreationParameters()
+
+	result, err := db.ImportTable(&dynamodb.ImportTableInput{
+		InputFormat:             ptr(dynamodb.InputFormatDynamodbJson),
+		S3BucketSource:          &dynamodb.S3BucketSource{S3Bucket: ptr("missing")},
+		TableCreationParameters: params,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, dynamodb.ImportStatusFailed, val(result.ImportTableDescription.ImportStatus))
+
+	_, err = db.DescribeTable(&dynamodb.DescribeTableInput{TableName: params.TableName})
+	var expectedErr *dynamodb.ResourceNotFoundException
+	require.ErrorAs(t, err, &expectedErr)
+
+	listed, err := db.ListImports(&dynamodb.ListImportsInput{})
+	require.NoError(t, err)
+	require.Len(t, listed.ImportSummaryList, 1)
+	assert.Equal(t, result.ImportTableDescription.ImportArn, listed.ImportSummaryList[0].ImportArn)
+}
+
+func TestDB_ImportTable_LargeIon(t *testing.T) {
+	t.Parallel()
+	s3Dir := t.TempDir()
+	db := fakedynamo.NewDB(fakedynamo.WithS3Directory(s3Dir))
+
+	var ion bytes.Buffer
+	ion.WriteString("$ion_1_0\n")
+	n := 0
+	for ; ion.Len() < 1<<20; n++ {
+		fmt.Fprintf(&ion, "/* item %d */ {Item:{Foo:\"%d\", Bar:'''b''' '''%d''', Blob:{{aGk=}}}}\n", n, n, n)
+	}
+	writeS3Object(t, s3Dir, "bucket", "data.ion", ion.Bytes())
+	params := exampleTableCreationParameters()
+
+	start := time.Now()
+	result, err := db.ImportTable(&dynamodb.ImportTableInput{
+		InputFormat:             ptr(dynamodb.InputFormatIon),
+		S3BucketSource:          &dynamodb.S3BucketSource{S3Bucket: ptr("bucket")},
+		TableCreationParameters: params,
+	})
+	elapsed := time.Since(start)
+	require.NoError(t, err)
+	assert.Equal(t, int64(n), val(result.ImportTableDescription.ImportedItemCount))
+	assert.Zero(t, val(result.ImportTableDescription.ErrorCount))
+	// Parsing used to copy the rest of the input at every step, taking
+	// minutes for a file this size.
+	limit := time.Second
+	if raceEnabled {
+		limit *= 5
+	}
+	assert.Less(t, elapsed, limit)
+}
@@ -0,0 +1,525 @@
+package fakedynamo
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+	"gopkg.in/yaml.v3"
+)
+
+// Infrastructure code
+//
+// WriteCloudFormationTemplate and WriteTerraformConfig go the other way from
+// the loaders, declaring tables prototyped in a DB. They export the settings
+// which the loaders translate: the key schema, attribute definitions, billing
+// mode and throughput, global and local secondary indexes, stream,
+// server-side encryption, table class, deletion protection, tags, resource
+// policy, Time to Live and point-in-time recovery. Replicas aren't exported,
+// so each replica of a global table is declared as a separate table.
+//
+// Tables are declared in the order of ListTables, and their settings in a
+// fixed order, so that the output can be committed and diffed.
+
+// WriteCloudFormationTemplate writes a YAML CloudFormation template to w,
+// declaring an AWS::DynamoDB::Table resource for each of the named tables,
+// or for every table if no names are given. The resources' logical IDs are
+// the tables' names in CamelCase.
+func (d *DB) WriteCloudFormationTemplate(w io.Writer, tableNames ...string) error {
+	tables, err := d.exportedTables(tableNames)
+	if err != nil {
+		return err
+	}
+	resources := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"} //nolint:exhaustruct
+	ids := map[string]bool{}
+	for _, t := range tables {
+		var resource yaml.Node
+		if err := resource.Encode(t.cloudFormationResource()); err != nil {
+			return err
+		}
+		id := uniqueName(ids, cfnLogicalID(t.name), "")
+		resources.Content = append(resources.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: id}, //nolint:exhaustruct
+			&resource,
+		)
+	}
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	err = encoder.Encode(struct {
+		AWSTemplateFormatVersion string     `yaml:"AWSTemplateFormatVersion"`
+		Resources                *yaml.Node `yaml:"Resources"`
+	}{"2010-09-09", resources})
+	if err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+// WriteTerraformConfig writes a Terraform configuration file to w, declaring
+// an aws_dynamodb_table resource for each of the named tables, or for every
+// table if no names are given. Resource policies are declared by
+// aws_dynamodb_resource_policy resources. The resources' names are the
+// tables' names, with characters which Terraform doesn't allow replaced by
+// underscores.
+func (d *DB) WriteTerraformConfig(w io.Writer, tableNames ...string) error {
+	tables, err := d.exportedTables(tableNames)
+	if err != nil {
+		return err
+	}
+	f := hclwrite.NewEmptyFile()
+	body := f.Body()
+	names := map[string]bool{}
+	for i, t := range tables {
+		if i > 0 {
+			body.AppendNewline()
+		}
+		name := uniqueName(names, tfResourceName(t.name), "_")
+		t.writeTerraform(body.AppendNewBlock("resource", []string{tfTableType, name}).Body())
+		if t.policy != "" {
+			body.AppendNewline()
+			policy := body.AppendNewBlock("resource", []string{"aws_dynamodb_resource_policy", name}).Body()
+			policy.SetAttributeTraversal("resource_arn", hcl.Traversal{
+				hcl.TraverseRoot{Name: tfTableType}, //nolint:exhaustruct
+				hcl.TraverseAttr{Name: name},        //nolint:exhaustruct
+				hcl.TraverseAttr{Name: "arn"},       //nolint:exhaustruct
+			})
+			policy.SetAttributeValue("policy", cty.StringVal(t.policy))
+		}
+	}
+	_, err = w.Write(hclwrite.Format(f.Bytes()))
+	return err
+}
+
+// exportedTable holds the settings of a table which are exported as
+// infrastructure code.
+type exportedTable struct {
+	name         string
+	spec         *dynamodb.CreateTableInput
+	ttlAttribute string
+	pitr         bool
+	tags         map[string]string
+	policy       string
+}
+
+// exportedTables returns the settings of the named tables, or every table if
+// names is empty, ordered by name.
+func (d *DB) exportedTables(names []string) ([]*exportedTable, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	var tables []*table
+	if len(names) == 0 {
+		d.tables.Ascend(func(t *table) bool {
+			tables = append(tables, t)
+			return true
+		})
+	}
+	for _, name := range slices.Compact(slices.Sorted(slices.Values(names))) {
+		t, exists := d.tables.Get(tableKey(name))
+		if !exists {
+			return nil, fmt.Errorf("table %s: %w", name, &dynamodb.ResourceNotFoundException{})
+		}
+		tables = append(tables, t)
+	}
+
+	exported := make([]*exportedTable, 0, len(tables))
+	for _, t := range tables {
+		e := &exportedTable{
+			name:         *t.spec.TableName,
+			spec:         t.spec,
+			ttlAttribute: t.ttlAttribute,
+			pitr:         t.pitr != nil,
+			tags:         maps.Clone(t.tags),
+			policy:       "",
+		}
+		if t.policy != nil {
+			e.policy = t.policy.document
+		}
+		exported = append(exported, e)
+	}
+	return exported, nil
+}
+
+func (t *exportedTable) provisioned() bool {
+	return valOr(t.spec.BillingMode, dynamodb.BillingModeProvisioned) == dynamodb.BillingModeProvisioned
+}
+
+func (t *exportedTable) streamViewType() string {
+	if stream := t.spec.StreamSpecification; stream != nil && val(stream.StreamEnabled) {
+		return val(stream.StreamViewType)
+	}
+	return ""
+}
+
+// uniqueName returns name, or if it's already taken, name with the smallest
+// numeric suffix which isn't, and marks the result as taken.
+func uniqueName(taken map[string]bool, name, separator string) string {
+	unique := name
+	for i := 2; taken[unique]; i++ {
+		unique = fmt.Sprintf("%s%s%d", name, separator, i)
+	}
+	taken[unique] = true
+	return unique
+}
+
+// cfnLogicalID converts a table name to an alphanumeric logical ID, such as
+// OrdersV2 for orders-v2.
+func cfnLogicalID(name string) string {
+	var id strings.Builder
+	for _, word := range strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		id.WriteString(strings.ToUpper(word[:1]) + word[1:])
+	}
+	if id.Len() == 0 || unicode.IsDigit(rune(id.String()[0])) {
+		return "Table" + id.String()
+	}
+	return id.String()
+}
+
+var tfInvalidNameChars = regexp.MustCompile(`[^A-Za-z0-9_-]`)
+
+// tfResourceName converts a table name to a Terraform resource name, which
+// must start with a letter or an underscore.
+func tfResourceName(name string) string {
+	name = tfInvalidNameChars.ReplaceAllString(name, "_")
+	if !unicode.IsLetter(rune(name[0])) && name[0] != '_' {
+		name = "_" + name
+	}
+	return name
+}
+
+// The cfnXxxOutput types are the properties of an AWS::DynamoDB::Table
+// resource, in the order they're written.
+type cfnTableOutput struct {
+	TableName                        string                       `yaml:"TableName"`
+	BillingMode                      string                       `yaml:"BillingMode"`
+	AttributeDefinitions             []cfnAttributeOutput         `yaml:"AttributeDefinitions"`
+	KeySchema                        []cfnKeyOutput               `yaml:"KeySchema"`
+	ProvisionedThroughput            *cfnThroughputOutput         `yaml:"ProvisionedThroughput,omitempty"`
+	OnDemandThroughput               *cfnOnDemandThroughputOutput `yaml:"OnDemandThroughput,omitempty"`
+	GlobalSecondaryIndexes           []cfnIndexOutput             `yaml:"GlobalSecondaryIndexes,omitempty"`
+	LocalSecondaryIndexes            []cfnIndexOutput             `yaml:"LocalSecondaryIndexes,omitempty"`
+	StreamSpecification              *cfnStreamOutput             `yaml:"StreamSpecification,omitempty"`
+	SSESpecification                 *cfnSSEOutput                `yaml:"SSESpecification,omitempty"`
+	TableClass                       string                       `yaml:"TableClass,omitempty"`
+	DeletionProtectionEnabled        bool                         `yaml:"DeletionProtectionEnabled,omitempty"`
+	TimeToLiveSpecification          *cfnTimeToLiveOutput         `yaml:"TimeToLiveSpecification,omitempty"`
+	PointInTimeRecoverySpecification *cfnPITROutput               `yaml:"PointInTimeRecoverySpecification,omitempty"`
+	ResourcePolicy                   *cfnResourcePolicyOutput     `yaml:"ResourcePolicy,omitempty"`
+	Tags                             []cfnTagOutput               `yaml:"Tags,omitempty"`
+}
+
+type cfnAttributeOutput struct {
+	AttributeName string `yaml:"AttributeName"`
+	AttributeType string `yaml:"AttributeType"`
+}
+
+type cfnKeyOutput struct {
+	AttributeName string `yaml:"AttributeName"`
+	KeyType       string `yaml:"KeyType"`
+}
+
+type cfnThroughputOutput struct {
+	ReadCapacityUnits  int64 `yaml:"ReadCapacityUnits"`
+	WriteCapacityUnits int64 `yaml:"WriteCapacityUnits"`
+}
+
+type cfnOnDemandThroughputOutput struct {
+	MaxReadRequestUnits  *int64 `yaml:"MaxReadRequestUnits,omitempty"`
+	MaxWriteRequestUnits *int64 `yaml:"MaxWriteRequestUnits,omitempty"`
+}
+
+type cfnIndexOutput struct {
+	IndexName             string                       `yaml:"IndexName"`
+	KeySchema             []cfnKeyOutput               `yaml:"KeySchema"`
+	Projection            cfnProjectionOutput          `yaml:"Projection"`
+	ProvisionedThroughput *cfnThroughputOutput         `yaml:"ProvisionedThroughput,omitempty"`
+	OnDemandThroughput    *cfnOnDemandThroughputOutput `yaml:"OnDemandThroughput,omitempty"`
+}
+
+type cfnProjectionOutput struct {
+	ProjectionType   string   `yaml:"ProjectionType"`
+	NonKeyAttributes []string `yaml:"NonKeyAttributes,omitempty"`
+}
+
+type cfnStreamOutput struct {
+	StreamViewType string `yaml:"StreamViewType"`
+}
+
+type cfnSSEOutput struct {
+	SSEEnabled     bool   `yaml:"SSEEnabled"`
+	SSEType        string `yaml:"SSEType,omitempty"`
+	KMSMasterKeyId string `yaml:"KMSMasterKeyId,omitempty"` //nolint:revive
+}
+
+type cfnTimeToLiveOutput struct {
+	AttributeName string `yaml:"AttributeName"`
+	Enabled       bool   `yaml:"Enabled"`
+}
+
+type cfnPITROutput struct {
+	PointInTimeRecoveryEnabled bool `yaml:"PointInTimeRecoveryEnabled"`
+}
+
+type cfnResourcePolicyOutput struct {
+	PolicyDocument any `yaml:"PolicyDocument"`
+}
+
+type cfnTagOutput struct {
+	Key   string `yaml:"Key"`
+	Value string `yaml:"Value"`
+}
+
+func (t *exportedTable) cloudFormationResource() any {
+	spec := t.spec
+	props := cfnTableOutput{
+		TableName:                        t.name,
+		BillingMode:                      valOr(spec.BillingMode, dynamodb.BillingModeProvisioned),
+		AttributeDefinitions:             nil,
+		KeySchema:                        cfnKeySchemaOf(spec.KeySchema),
+		ProvisionedThroughput:            nil,
+		OnDemandThroughput:               cfnOnDemandThroughputOf(spec.OnDemandThroughput),
+		GlobalSecondaryIndexes:           nil,
+		LocalSecondaryIndexes:            nil,
+		StreamSpecification:              nil,
+		SSESpecification:                 nil,
+		TableClass:                       val(spec.TableClass),
+		DeletionProtectionEnabled:        val(spec.DeletionProtectionEnabled),
+		TimeToLiveSpecification:          nil,
+		PointInTimeRecoverySpecification: nil,
+		ResourcePolicy:                   nil,
+		Tags:                             nil,
+	}
+	for _, attr := range spec.AttributeDefinitions {
+		props.AttributeDefinitions = append(props.AttributeDefinitions, cfnAttributeOutput{
+			AttributeName: val(attr.AttributeName),
+			AttributeType: val(attr.AttributeType),
+		})
+	}
+	if t.provisioned() {
+		props.ProvisionedThroughput = cfnThroughputOf(spec.ProvisionedThroughput)
+	}
+	for _, gsi := range spec.GlobalSecondaryIndexes {
+		index := cfnIndexOutput{
+			IndexName:             val(gsi.IndexName),
+			KeySchema:             cfnKeySchemaOf(gsi.KeySchema),
+			Projection:            cfnProjectionOf(gsi.Projection),
+			ProvisionedThroughput: nil,
+			OnDemandThroughput:    cfnOnDemandThroughputOf(gsi.OnDemandThroughput),
+		}
+		if t.provisioned() {
+			index.ProvisionedThroughput = cfnThroughputOf(gsi.ProvisionedThroughput)
+		}
+		props.GlobalSecondaryIndexes = append(props.GlobalSecondaryIndexes, index)
+	}
+	for _, lsi := range spec.LocalSecondaryIndexes {
+		props.LocalSecondaryIndexes = append(props.LocalSecondaryIndexes, cfnIndexOutput{
+			IndexName:             val(lsi.IndexName),
+			KeySchema:             cfnKeySchemaOf(lsi.KeySchema),
+			Projection:            cfnProjectionOf(lsi.Projection),
+			ProvisionedThroughput: nil,
+			OnDemandThroughput:    nil,
+		})
+	}
+	if viewType := t.streamViewType(); viewType != "" {
+		props.StreamSpecification = &cfnStreamOutput{StreamViewType: viewType}
+	}
+	if sse := spec.SSESpecification; sse != nil && val(sse.Enabled) {
+		props.SSESpecification = &cfnSSEOutput{
+			SSEEnabled:     true,
+			SSEType:        val(sse.SSEType),
+			KMSMasterKeyId: val(sse.KMSMasterKeyId),
+		}
+	}
+	if t.ttlAttribute != "" {
+		props.TimeToLiveSpecification = &cfnTimeToLiveOutput{AttributeName: t.ttlAttribute, Enabled: true}
+	}
+	if t.pitr {
+		props.PointInTimeRecoverySpecification = &cfnPITROutput{PointInTimeRecoveryEnabled: true}
+	}
+	if t.policy != "" {
+		// Write the policy as YAML rather than as a string of JSON. It was
+		// validated when it was attached, so it can be decoded.
+		var document any
+		_ = json.Unmarshal([]byte(t.policy), &document)
+		props.ResourcePolicy = &cfnResourcePolicyOutput{PolicyDocument: document}
+	}
+	for _, key := range slices.Sorted(maps.Keys(t.tags)) {
+		props.Tags = append(props.Tags, cfnTagOutput{Key: key, Value: t.tags[key]})
+	}
+	return struct {
+		Type       string         `yaml:"Type"`
+		Properties cfnTableOutput `yaml:"Properties"`
+	}{cfnTable, props}
+}
+
+func cfnKeySchemaOf(schema []*dynamodb.KeySchemaElement) []cfnKeyOutput {
+	keys := make([]cfnKeyOutput, 0, len(schema))
+	for _, key := range schema {
+		keys = append(keys, cfnKeyOutput{AttributeName: val(key.AttributeName), KeyType: val(key.KeyType)})
+	}
+	return keys
+}
+
+func cfnThroughputOf(throughput *dynamodb.ProvisionedThroughput) *cfnThroughputOutput {
+	if throughput == nil {
+		return nil
+	}
+	return &cfnThroughputOutput{
+		ReadCapacityUnits:  val(throughput.ReadCapacityUnits),
+		WriteCapacityUnits: val(throughput.WriteCapacityUnits),
+	}
+}
+
+func cfnOnDemandThroughputOf(throughput *dynamodb.OnDemandThroughput) *cfnOnDemandThroughputOutput {
+	if throughput == nil || (throughput.MaxReadRequestUnits == nil && throughput.MaxWriteRequestUnits == nil) {
+		return nil
+	}
+	return &cfnOnDemandThroughputOutput{
+		MaxReadRequestUnits:  throughput.MaxReadRequestUnits,
+		MaxWriteRequestUnits: throughput.MaxWriteRequestUnits,
+	}
+}
+
+func cfnProjectionOf(projection *dynamodb.Projection) cfnProjectionOutput {
+	output := cfnProjectionOutput{ProjectionType: dynamodb.ProjectionTypeAll, NonKeyAttributes: nil}
+	if projection == nil {
+		return output
+	}
+	output.ProjectionType = valOr(projection.ProjectionType, dynamodb.ProjectionTypeAll)
+	for _, attr := range projection.NonKeyAttributes {
+		output.NonKeyAttributes = append(output.NonKeyAttributes, val(attr))
+	}
+	return output
+}
+
+// writeTerraform writes the attributes and blocks of the table's
+// aws_dynamodb_table resource to body.
+func (t *exportedTable) writeTerraform(body *hclwrite.Body) {
+	spec := t.spec
+	body.SetAttributeValue("name", cty.StringVal(t.name))
+	body.SetAttributeValue("billing_mode", cty.StringVal(valOr(spec.BillingMode, dynamodb.BillingModeProvisioned)))
+	var hashKey, rangeKey string
+	for _, key := range spec.KeySchema {
+		if val(key.KeyType) == dynamodb.KeyTypeHash {
+			hashKey = val(key.AttributeName)
+		} else {
+			rangeKey = val(key.AttributeName)
+		}
+	}
+	body.SetAttributeValue("hash_key", cty.StringVal(hashKey))
+	if rangeKey != "" {
+		body.SetAttributeValue("range_key", cty.StringVal(rangeKey))
+	}
+	if t.provisioned() {
+		tfSetThroughput(body, spec.ProvisionedThroughput)
+	}
+	if viewType := t.streamViewType(); viewType != "" {
+		body.SetAttributeValue("stream_enabled", cty.True)
+		body.SetAttributeValue("stream_view_type", cty.StringVal(viewType))
+	}
+	if spec.TableClass != nil {
+		body.SetAttributeValue("table_class", cty.StringVal(*spec.TableClass))
+	}
+	if val(spec.DeletionProtectionEnabled) {
+		body.SetAttributeValue("deletion_protection_enabled", cty.True)
+	}
+
+	for _, attr := range spec.AttributeDefinitions {
+		block := body.AppendNewBlock("attribute", nil).Body()
+		block.SetAttributeValue("name", cty.StringVal(val(attr.AttributeName)))
+		block.SetAttributeValue("type", cty.StringVal(val(attr.AttributeType)))
+	}
+	tfSetOnDemandThroughput(body, spec.OnDemandThroughput)
+	for _, gsi := range spec.GlobalSecondaryIndexes {
+		block := body.AppendNewBlock("global_secondary_index", nil).Body()
+		block.SetAttributeValue("name", cty.StringVal(val(gsi.IndexName)))
+		for _, key := range gsi.KeySchema {
+			if val(key.KeyType) == dynamodb.KeyTypeHash {
+				block.SetAttributeValue("hash_key", cty.StringVal(val(key.AttributeName)))
+			} else {
+				block.SetAttributeValue("range_key", cty.StringVal(val(key.AttributeName)))
+			}
+		}
+		tfSetProjection(block, gsi.Projection)
+		if t.provisioned() {
+			tfSetThroughput(block, gsi.ProvisionedThroughput)
+		}
+		tfSetOnDemandThroughput(block, gsi.OnDemandThroughput)
+	}
+	for _, lsi := range spec.LocalSecondaryIndexes {
+		block := body.AppendNewBlock("local_secondary_index", nil).Body()
+		block.SetAttributeValue("name", cty.StringVal(val(lsi.IndexName)))
+		for _, key := range lsi.KeySchema {
+			if val(key.KeyType) == dynamodb.KeyTypeRange {
+				block.SetAttributeValue("range_key", cty.StringVal(val(key.AttributeName)))
+			}
+		}
+		tfSetProjection(block, lsi.Projection)
+	}
+	if sse := spec.SSESpecification; sse != nil && val(sse.Enabled) {
+		block := body.AppendNewBlock("server_side_encryption", nil).Body()
+		block.SetAttributeValue("enabled", cty.True)
+		if sse.KMSMasterKeyId != nil {
+			block.SetAttributeValue("kms_key_arn", cty.StringVal(*sse.KMSMasterKeyId))
+		}
+	}
+	if t.ttlAttribute != "" {
+		block := body.AppendNewBlock("ttl", nil).Body()
+		block.SetAttributeValue("attribute_name", cty.StringVal(t.ttlAttribute))
+		block.SetAttributeValue("enabled", cty.True)
+	}
+	if t.pitr {
+		body.AppendNewBlock("point_in_time_recovery", nil).Body().SetAttributeValue("enabled", cty.True)
+	}
+	if len(t.tags) > 0 {
+		tags := make(map[string]cty.Value, len(t.tags))
+		for key, value := range t.tags {
+			tags[key] = cty.StringVal(value)
+		}
+		body.SetAttributeValue("tags", cty.MapVal(tags))
+	}
+}
+
+func tfSetThroughput(body *hclwrite.Body, throughput *dynamodb.ProvisionedThroughput) {
+	if throughput == nil {
+		return
+	}
+	body.SetAttributeValue("read_capacity", cty.NumberIntVal(val(throughput.ReadCapacityUnits)))
+	body.SetAttributeValue("write_capacity", cty.NumberIntVal(val(throughput.WriteCapacityUnits)))
+}
+
+func tfSetOnDemandThroughput(body *hclwrite.Body, throughput *dynamodb.OnDemandThroughput) {
+	if throughput == nil || (throughput.MaxReadRequestUnits == nil && throughput.MaxWriteRequestUnits == nil) {
+		return
+	}
+	block := body.AppendNewBlock("on_demand_throughput", nil).Body()
+	if throughput.MaxReadRequestUnits != nil {
+		block.SetAttributeValue("max_read_request_units", cty.NumberIntVal(*throughput.MaxReadRequestUnits))
+	}
+	if throughput.MaxWriteRequestUnits != nil {
+		block.SetAttributeValue("max_write_request_units", cty.NumberIntVal(*throughput.MaxWriteRequestUnits))
+	}
+}
+
+func tfSetProjection(body *hclwrite.Body, projection *dynamodb.Projection) {
+	output := cfnProjectionOf(projection)
+	body.SetAttributeValue("projection_type", cty.StringVal(output.ProjectionType))
+	if len(output.NonKeyAttributes) > 0 {
+		attrs := make([]cty.Value, 0, len(output.NonKeyAttributes))
+		for _, attr := range output.NonKeyAttributes {
+			attrs = append(attrs, cty.StringVal(attr))
+		}
+		body.SetAttributeValue("non_key_attributes", cty.ListVal(attrs))
+	}
+}
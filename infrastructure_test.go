@@ -0,0 +1,210 @@
+package fakedynamo_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/DMRobertson/fakedynamo"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// makeInfrastructureTestDB returns a DB with a simple table, orders-v2, and
+// a table using most of the settings which are exported, orders.events.
+func makeInfrastructureTestDB(t *testing.T) *fakedynamo.DB {
+	t.Helper()
+	db := fakedynamo.NewDB()
+	_, err := db.CreateTable(&dynamodb.CreateTableInput{ //nolint:exhaustruct
+		AttributeDefinitions: []*dynamodb.AttributeDefinition{
+			{AttributeName: ptr("ID"), AttributeType: ptr("S")},
+		},
+		BillingMode: ptr(dynamodb.BillingModePayPerRequest),
+		KeySchema: []*dynamodb.KeySchemaElement{
+			{AttributeName: ptr("ID"), KeyType: ptr(dynamodb.KeyTypeHash)},
+		},
+		TableName: ptr("orders-v2"),
+		Tags: []*dynamodb.Tag{
+			{Key: ptr("team"), Value: ptr("shop")},
+			{Key: ptr("env"), Value: ptr("dev")},
+		},
+	})
+	require.NoError(t, err)
+
+	_, err = db.CreateTable(&dynamodb.CreateTableInput{ //nolint:exhaustruct
+		AttributeDefinitions: []*dynamodb.AttributeDefinition{
+			{AttributeName: ptr("PK"), AttributeType: ptr("S")},
+			{AttributeName: ptr("SK"), AttributeType: ptr("N")},
+			{AttributeName: ptr("GSI1PK"), AttributeType: ptr("S")},
+			{AttributeName: ptr("Created"), AttributeType: ptr("N")},
+		},
+		BillingMode:               ptr(dynamodb.BillingModeProvisioned),
+		DeletionProtectionEnabled: ptr(true),
+		GlobalSecondaryIndexes: []*dynamodb.GlobalSecondaryIndex{{ //nolint:exhaustruct
+			IndexName: ptr("GSI1"),
+			KeySchema: []*dynamodb.KeySchemaElement{
+				{AttributeName: ptr("GSI1PK"), KeyType: ptr(dynamodb.KeyTypeHash)},
+			},
+			Projection: &dynamodb.Projection{
+				NonKeyAttributes: []*string{ptr("Total")},
+				ProjectionType:   ptr(dynamodb.ProjectionTypeInclude),
+			},
+			ProvisionedThroughput: &dynamodb.ProvisionedThroughput{
+				ReadCapacityUnits:  ptr[int64](3),
+				WriteCapacityUnits: ptr[int64](4),
+			},
+		}},
+		KeySchema: []*dynamodb.KeySchemaElement{
+			{AttributeName: ptr("PK"), KeyType: ptr(dynamodb.KeyTypeHash)},
+			{AttributeName: ptr("SK"), KeyType: ptr(dynamodb.KeyTypeRange)},
+		},
+		LocalSecondaryIndexes: []*dynamodb.LocalSecondaryIndex{{
+			IndexName: ptr("ByCreated"),
+			KeySchema: []*dynamodb.KeySchemaElement{
+				{AttributeName: ptr("PK"), KeyType: ptr(dynamodb.KeyTypeHash)},
+				{AttributeName: ptr("Created"), KeyType: ptr(dynamodb.KeyTypeRange)},
+			},
+			Projection: &dynamodb.Projection{ProjectionType: ptr(dynamodb.ProjectionTypeKeysOnly)}, //nolint:exhaustruct
+		}},
+		ProvisionedThroughput: &dynamodb.ProvisionedThroughput{
+			ReadCapacityUnits:  ptr[int64](5),
+			WriteCapacityUnits: ptr[int64](6),
+		},
+		SSESpecification: &dynamodb.SSESpecification{
+			Enabled:        ptr(true),
+			KMSMasterKeyId: ptr("alias/orders"),
+			SSEType:        ptr(dynamodb.SSETypeKms),
+		},
+		StreamSpecification: &dynamodb.StreamSpecification{
+			StreamEnabled:  ptr(true),
+			StreamViewType: ptr(dynamodb.StreamViewTypeNewAndOldImages),
+		},
+		TableClass: ptr(dynamodb.TableClassStandardInfrequentAccess),
+		TableName:  ptr("orders.events"),
+	})
+	require.NoError(t, err)
+	_, err = db.UpdateTimeToLive(&dynamodb.UpdateTimeToLiveInput{
+		TableName: ptr("orders.events"),
+		TimeToLiveSpecification: &dynamodb.TimeToLiveSpecification{
+			AttributeName: ptr("Expires"),
+			Enabled:       ptr(true),
+		},
+	})
+	require.NoError(t, err)
+	_, err = db.UpdateContinuousBackups(&dynamodb.UpdateContinuousBackupsInput{
+		PointInTimeRecoverySpecification: &dynamodb.PointInTimeRecoverySpecification{
+			PointInTimeRecoveryEnabled: ptr(true),
+		},
+		TableName: ptr("orders.events"),
+	})
+	require.NoError(t, err)
+	return db
+}
+
+func TestDB_WriteCloudFormationTemplate(t *testing.T) {
+	t.Parallel()
+	db := makeInfrastructureTestDB(t)
+	var buf bytes.Buffer
+	require.NoError(t, db.WriteCloudFormationTemplate(&buf, "orders-v2"))
+	assert.Equal(t, `AWSTemplateFormatVersion: "2010-09-09"
+Resources:
+  OrdersV2:
+    Type: AWS::DynamoDB::Table
+    Properties:
+      TableName: orders-v2
+      BillingMode: PAY_PER_REQUEST
+      AttributeDefinitions:
+        - AttributeName: ID
+          AttributeType: S
+      KeySchema:
+        - AttributeName: ID
+          KeyType: HASH
+      Tags:
+        - Key: env
+          Value: dev
+        - Key: team
+          Value: shop
+`, buf.String())
+
+	// Loading the template recreates the tables, which export identically.
+	buf.Reset()
+	require.NoError(t, db.WriteCloudFormationTemplate(&buf))
+	loaded := fakedynamo.NewDB()
+	tables, err := loaded.LoadCloudFormationTemplate("exported.yaml", bytes.NewReader(buf.Bytes()), fakedynamo.CloudFormationOptions{}) //nolint:exhaustruct
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"OrdersEvents": "orders.events", "OrdersV2": "orders-v2"}, tables)
+	var reexported bytes.Buffer
+	require.NoError(t, loaded.WriteCloudFormationTemplate(&reexported))
+	assert.Equal(t, buf.String(), reexported.String())
+}
+
+func TestDB_WriteTerraformConfig(t *testing.T) {
+	t.Parallel()
+	db := makeInfrastructureTestDB(t)
+	var buf bytes.Buffer
+	require.NoError(t, db.WriteTerraformConfig(&buf, "orders-v2"))
+	assert.Equal(t, `resource "aws_dynamodb_table" "orders-v2" {
+  name         = "orders-v2"
+  billing_mode = "PAY_PER_REQUEST"
+  hash_key     = "ID"
+  attribute {
+    name = "ID"
+    type = "S"
+  }
+  tags = {
+    env  = "dev"
+    team = "shop"
+  }
+}
+`, buf.String())
+
+	buf.Reset()
+	require.NoError(t, db.WriteTerraformConfig(&buf))
+	loaded := fakedynamo.NewDB()
+	tables, err := loaded.LoadTerraformConfig("exported.tf", buf.Bytes(), fakedynamo.TerraformOptions{}) //nolint:exhaustruct
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"aws_dynamodb_table.orders-v2":     "orders-v2",
+		"aws_dynamodb_table.orders_events": "orders.events",
+	}, tables)
+	var reexported bytes.Buffer
+	require.NoError(t, loaded.WriteTerraformConfig(&reexported))
+	assert.Equal(t, buf.String(), reexported.String())
+}
+
+func TestDB_WriteInfrastructure_ResourcePolicy(t *testing.T) {
+	t.Parallel()
+	db := fakedynamo.NewDB()
+	input := exampleCreateTableInputSimplePrimaryKey()
+	input.ResourcePolicy = ptr(examplePolicy)
+	_, err := db.CreateTable(input)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, db.WriteCloudFormationTemplate(&buf))
+	assert.Contains(t, buf.String(), `
+      ResourcePolicy:
+        PolicyDocument:
+          Statement:
+            - Action: dynamodb:GetItem
+`)
+
+	buf.Reset()
+	require.NoError(t, db.WriteTerraformConfig(&buf))
+	assert.Contains(t, buf.String(), `resource "aws_dynamodb_resource_policy" `)
+	assert.Contains(t, buf.String(), `resource_arn = aws_dynamodb_table.`)
+}
+
+func TestDB_WriteInfrastructure_MissingTable(t *testing.T) {
+	t.Parallel()
+	db := makeInfrastructureTestDB(t)
+	var buf bytes.Buffer
+	err := db.WriteCloudFormationTemplate(&buf, "orders-v2", "missing")
+	var notFound *dynamodb.ResourceNotFoundException
+	require.ErrorAs(t, err, &notFound)
+	assertErrorContains(t, err, "missing")
+
+	err = db.WriteTerraformConfig(&buf, "missing")
+	require.ErrorAs(t, err, &notFound)
+	assert.Empty(t, buf.String())
+}
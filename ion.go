@@ -0,0 +1,440 @@
+package fakedynamo
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// This file implements just enough of the Ion text format to read the data
+// files produced by DynamoDB's ION exports, which are also accepted by
+// ImportTable. Each top-level value is a struct {Item: {...}}. DynamoDB types
+// map onto Ion types as described in
+// https://docs.aws.amazon.com/amazondynamodb/latest/developerguide/S3DataImport.Format.html#S3DataImport.Requesting.Formats.Ion
+//
+//   - S is a string, N a decimal (or integer), B a blob and BOOL a bool.
+//   - NULL is a null value of any type.
+//   - M is a struct and L is a list.
+//   - SS, NS and BS are lists annotated with $dynamodb_SS, $dynamodb_NS and
+//     $dynamodb_BS respectively.
+//
+// Binary Ion, timestamps, clobs and s-expressions are not supported.
+
+// parseIonItems decodes a stream of Ion {Item: ...} structs. Returns the items
+// decoded successfully and the number of values that couldn't be decoded.
+// After a syntax error, parsing resumes on the next line, since DynamoDB's
+// exports write one value per line.
+func parseIonItems(data []byte) ([]avmap, int) {
+	p := &ionParser{data: data, pos: 0}
+	var items []avmap
+	var failures int
+	for {
+		p.skipWhitespace()
+		if p.pos >= len(p.data) {
+			return items, failures
+		}
+
+		start := p.pos
+		value, annotations, err := p.parseValue()
+		if err != nil {
+			failures++
+			p.skipLine(start)
+			continue
+		}
+		if value.symbol != nil && len(annotations) == 0 && strings.HasPrefix(*value.symbol, "$ion_") {
+			// Ion version marker.
+			continue
+		}
+		if value.av == nil || value.av.M == nil || value.av.M["Item"] == nil || value.av.M["Item"].M == nil {
+			// Not an {Item: {...}} struct.
+			failures++
+			continue
+		}
+		items = append(items, value.av.M["Item"].M)
+	}
+}
+
+type ionParser struct {
+	data []byte
+	pos  int
+}
+
+// ionValue is a parsed Ion value. Symbols are kept separate from strings so
+// that we can spot the version marker and type annotations.
+type ionValue struct {
+	av     *dynamodb.AttributeValue
+	symbol *string
+}
+
+func (p *ionParser) errorf(format string, args ...any) error {
+	line := 1 + bytes.Count(p.data[:min(p.pos, len(p.data))], []byte("\n"))
+	return fmt.Errorf("ion: line %d: %s", line, fmt.Sprintf(format, args...))
+}
+
+func (p *ionParser) peek() byte {
+	if p.pos >= len(p.data) {
+		return 0
+	}
+	return p.data[p.pos]
+}
+
+func (p *ionParser) hasPrefix(prefix string) bool {
+	return bytes.HasPrefix(p.data[p.pos:], []byte(prefix))
+}
+
+// skipLine moves to the start of the line after the one containing start.
+func (p *ionParser) skipLine(start int) {
+	end := bytes.IndexByte(p.data[start:], '\n')
+	if end < 0 {
+		p.pos = len(p.data)
+	} else {
+		p.pos = start + end + 1
+	}
+}
+
+func (p *ionParser) skipWhitespace() {
+	for p.pos < len(p.data) {
+		switch {
+		case strings.IndexByte(" \t\r\n\f\v", p.data[p.pos]) >= 0:
+			p.pos++
+		case p.hasPrefix("//"):
+			for p.pos < len(p.data) && p.data[p.pos] != '\n' {
+				p.pos++
+			}
+		case p.hasPrefix("/*"):
+			end := bytes.Index(p.data[p.pos+2:], []byte("*/"))
+			if end < 0 {
+				p.pos = len(p.data)
+			} else {
+				p.pos += end + 4
+			}
+		default:
+			return
+		}
+	}
+}
+
+// parseValue reads a value with any preceding annotations.
+func (p *ionParser) parseValue() (ionValue, []string, error) {
+	var annotations []string
+	for {
+		p.skipWhitespace()
+		value, err := p.parseBareValue()
+		if err != nil {
+			return ionValue{}, nil, err
+		}
+		p.skipWhitespace()
+		if value.symbol != nil && p.hasPrefix("::") {
+			p.pos += 2
+			annotations = append(annotations, *value.symbol)
+			continue
+		}
+		if len(annotations) > 0 {
+			value, err = p.applyAnnotations(value, annotations)
+		}
+		return value, annotations, err
+	}
+}
+
+// applyAnnotations converts lists annotated as DynamoDB sets into sets.
+func (p *ionParser) applyAnnotations(value ionValue, annotations []string) (ionValue, error) {
+	setType := ""
+	for _, annotation := range annotations {
+		if t, found := strings.CutPrefix(annotation, "$dynamodb_"); found {
+			setType = t
+		}
+	}
+	if setType == "" {
+		return value, nil
+	}
+	if value.av == nil || value.av.L == nil {
+		return ionValue{}, p.errorf("$dynamodb_%s annotation must be applied to a list", setType)
+	}
+
+	set := &dynamodb.AttributeValue{}
+	for _, element := range value.av.L {
+		switch {
+		case setType == "SS" && element.S != nil:
+			set.SS = append(set.SS, element.S)
+		case setType == "NS" && element.N != nil:
+			set.NS = append(set.NS, element.N)
+		case setType == "BS" && element.B != nil:
+			set.BS = append(set.BS, element.B)
+		default:
+			return ionValue{}, p.errorf("unexpected element in $dynamodb_%s set", setType)
+		}
+	}
+	return ionValue{av: set, symbol: nil}, nil
+}
+
+func (p *ionParser) parseBareValue() (ionValue, error) {
+	switch c := p.peek(); {
+	case c == 0:
+		return ionValue{}, p.errorf("unexpected end of input")
+	case p.hasPrefix("{{"):
+		return p.parseBlob()
+	case c == '{':
+		return p.parseStruct()
+	case c == '[':
+		return p.parseList()
+	case c == '"' || p.hasPrefix("'''"):
+		s, err := p.parseString()
+		return ionValue{av: &dynamodb.AttributeValue{S: &s}, symbol: nil}, err
+	case c == '\'':
+		s, err := p.parseQuoted('\'')
+		return ionValue{av: nil, symbol: &s}, err
+	case c == '-' || c == '+' || (c >= '0' && c <= '9'):
+		return p.parseNumber()
+	case isIonIdentifierStart(c):
+		return p.parseKeywordOrSymbol()
+	default:
+		return ionValue{}, p.errorf("unexpected character %q", c)
+	}
+}
+
+func isIonIdentifierStart(c byte) bool {
+	return c == '$' || c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isIonIdentifierPart(c byte) bool {
+	return isIonIdentifierStart(c) || (c >= '0' && c <= '9')
+}
+
+func (p *ionParser) parseKeywordOrSymbol() (ionValue, error) {
+	start := p.pos
+	for p.pos < len(p.data) && isIonIdentifierPart(p.data[p.pos]) {
+		p.pos++
+	}
+	word := string(p.data[start:p.pos])
+	switch word {
+	case "true", "false":
+		return ionValue{av: &dynamodb.AttributeValue{BOOL: ptr(word == "true")}, symbol: nil}, nil
+	case "null":
+		// Typed nulls like null.string are all DynamoDB NULLs.
+		if p.peek() == '.' {
+			p.pos++
+			for p.pos < len(p.data) && isIonIdentifierPart(p.data[p.pos]) {
+				p.pos++
+			}
+		}
+		return ionValue{av: &dynamodb.AttributeValue{NULL: ptr(true)}, symbol: nil}, nil
+	}
+	return ionValue{av: nil, symbol: &word}, nil
+}
+
+func (p *ionParser) parseNumber() (ionValue, error) {
+	start := p.pos
+	for p.pos < len(p.data) && strings.IndexByte("+-0123456789.eEdD_xXabcfABCF", p.data[p.pos]) >= 0 {
+		p.pos++
+	}
+	raw := strings.ReplaceAll(string(p.data[start:p.pos]), "_", "")
+	// Ion decimals use d for their exponent, and may have a trailing point.
+	number := strings.NewReplacer("d", "E", "D", "E").Replace(raw)
+	number = strings.Replace(number, ".E", "E", 1)
+	number = strings.TrimSuffix(number, ".")
+	if strings.HasPrefix(number, "+") || strings.Contains(number, "x") || strings.Contains(number, "X") {
+		return ionValue{}, p.errorf("unsupported number %q", raw)
+	}
+	if _, err := strconv.ParseFloat(number, 64); err != nil {
+		return ionValue{}, p.errorf("invalid number %q", raw)
+	}
+	return ionValue{av: &dynamodb.AttributeValue{N: &number}, symbol: nil}, nil
+}
+
+func (p *ionParser) parseBlob() (ionValue, error) {
+	p.pos += 2
+	end := bytes.Index(p.data[p.pos:], []byte("}}"))
+	if end < 0 {
+		return ionValue{}, p.errorf("unterminated blob")
+	}
+	encoded := strings.Join(strings.Fields(string(p.data[p.pos:p.pos+end])), "")
+	p.pos += end + 2
+	decoded, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return ionValue{}, p.errorf("invalid blob: %s", err)
+	}
+	return ionValue{av: &dynamodb.AttributeValue{B: decoded}, symbol: nil}, nil
+}
+
+// parseString reads a double-quoted string, or a sequence of triple-quoted
+// long strings.
+func (p *ionParser) parseString() (string, error) {
+	if p.peek() == '"' {
+		return p.parseQuoted('"')
+	}
+
+	var sb strings.Builder
+	for p.hasPrefix("'''") {
+		p.pos += 3
+		end := bytes.Index(p.data[p.pos:], []byte("'''"))
+		if end < 0 {
+			return "", p.errorf("unterminated long string")
+		}
+		unescaped, err := unescapeIon(string(p.data[p.pos : p.pos+end]))
+		if err != nil {
+			return "", p.errorf("%s", err)
+		}
+		sb.WriteString(unescaped)
+		p.pos += end + 3
+		p.skipWhitespace()
+	}
+	return sb.String(), nil
+}
+
+func (p *ionParser) parseQuoted(quote byte) (string, error) {
+	p.pos++
+	start := p.pos
+	for p.pos < len(p.data) && p.data[p.pos] != quote {
+		if p.data[p.pos] == '\\' {
+			p.pos++
+		}
+		p.pos++
+	}
+	if p.pos >= len(p.data) {
+		return "", p.errorf("unterminated string")
+	}
+	unescaped, err := unescapeIon(string(p.data[start:p.pos]))
+	p.pos++
+	if err != nil {
+		return "", p.errorf("%s", err)
+	}
+	return unescaped, nil
+}
+
+func unescapeIon(s string) (string, error) {
+	if !strings.Contains(s, "\\") {
+		return s, nil
+	}
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' {
+			sb.WriteByte(s[i])
+			continue
+		}
+		i++
+		if i >= len(s) {
+			return "", errors.New("trailing backslash in string")
+		}
+		switch c := s[i]; c {
+		case 'n':
+			sb.WriteByte('\n')
+		case 't':
+			sb.WriteByte('\t')
+		case 'r':
+			sb.WriteByte('\r')
+		case '0':
+			sb.WriteByte(0)
+		case 'x', 'u', 'U':
+			width := map[byte]int{'x': 2, 'u': 4, 'U': 8}[c]
+			if i+width >= len(s) {
+				return "", fmt.Errorf("truncated \\%c escape", c)
+			}
+			code, err := strconv.ParseUint(s[i+1:i+1+width], 16, 32)
+			if err != nil {
+				return "", fmt.Errorf("invalid \\%c escape", c)
+			}
+			sb.WriteRune(rune(code))
+			i += width
+		case '\n':
+			// Escaped newlines are removed.
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	if !utf8.ValidString(sb.String()) {
+		return "", errors.New("string is not valid UTF-8")
+	}
+	return sb.String(), nil
+}
+
+func (p *ionParser) parseStruct() (ionValue, error) {
+	p.pos++
+	fields := avmap{}
+	for {
+		p.skipWhitespace()
+		if p.peek() == '}' {
+			p.pos++
+			return ionValue{av: &dynamodb.AttributeValue{M: fields}, symbol: nil}, nil
+		}
+
+		var name string
+		var err error
+		switch c := p.peek(); {
+		case c == '"' || p.hasPrefix("'''"):
+			name, err = p.parseString()
+		case c == '\'':
+			name, err = p.parseQuoted('\'')
+		case isIonIdentifierStart(c):
+			start := p.pos
+			for p.pos < len(p.data) && isIonIdentifierPart(p.data[p.pos]) {
+				p.pos++
+			}
+			name = string(p.data[start:p.pos])
+		default:
+			err = p.errorf("expected a field name")
+		}
+		if err != nil {
+			return ionValue{}, err
+		}
+
+		p.skipWhitespace()
+		if p.peek() != ':' {
+			return ionValue{}, p.errorf("expected ':' after field name %q", name)
+		}
+		p.pos++
+
+		value, _, err := p.parseValue()
+		if err != nil {
+			return ionValue{}, err
+		}
+		if value.av == nil {
+			return ionValue{}, p.errorf("symbol values are not supported (field %q)", name)
+		}
+		fields[name] = value.av
+
+		p.skipWhitespace()
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case '}':
+		default:
+			return ionValue{}, p.errorf("expected ',' or '}' in struct")
+		}
+	}
+}
+
+func (p *ionParser) parseList() (ionValue, error) {
+	p.pos++
+	elements := []*dynamodb.AttributeValue{}
+	for {
+		p.skipWhitespace()
+		if p.peek() == ']' {
+			p.pos++
+			return ionValue{av: &dynamodb.AttributeValue{L: elements}, symbol: nil}, nil
+		}
+
+		value, _, err := p.parseValue()
+		if err != nil {
+			return ionValue{}, err
+		}
+		if value.av == nil {
+			return ionValue{}, p.errorf("symbol values are not supported")
+		}
+		elements = append(elements, value.av)
+
+		p.skipWhitespace()
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case ']':
+		default:
+			return ionValue{}, p.errorf("expected ',' or ']' in list")
+		}
+	}
+}
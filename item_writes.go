@@ -0,0 +1,95 @@
+package fakedynamo
+
+import (
+	"fmt"
+	"maps"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// putItem stores an item, replacing any existing item with the same key.
+// Every write made by the API passes through putItem or deleteItem, so that
+// they can be logged, recorded and replicated consistently. It only fails if
+// the write can't be logged. The caller MUST hold the write lock.
+func (d *DB) putItem(t *table, item avmap) (avmap, bool, error) {
+	now := d.now().UTC()
+	global := d.regions.globalTable(*t.spec.TableName)
+	if global != nil && global.version == globalTableVersion2017 {
+		item = maps.Clone(item)
+		addReplicationAttributes(item, d.region, now)
+	}
+	if err := d.logItemWrite(t, item, item); err != nil {
+		return nil, false, err
+	}
+
+	previous, replaced, err := t.items.Put(item)
+	if err != nil {
+		return nil, false, err
+	}
+	d.recordWrite(t, previous, item)
+	if global != nil {
+		d.replicate(t, global, item, item, now)
+	}
+	return previous, replaced, nil
+}
+
+// deleteItem removes the item with the given key, if it exists. See
+// [DB.putItem]. The caller MUST hold the write lock.
+func (d *DB) deleteItem(t *table, key avmap) (avmap, bool, error) {
+	if _, exists, err := t.items.Get(key); err != nil || !exists {
+		return nil, false, err
+	}
+	if err := d.logItemWrite(t, key, nil); err != nil {
+		return nil, false, err
+	}
+	previous, _, err := t.items.Delete(key)
+	if err != nil {
+		return nil, false, err
+	}
+
+	d.recordWrite(t, previous, nil)
+	if global := d.regions.globalTable(*t.spec.TableName); global != nil {
+		d.replicate(t, global, t.extractKeys(previous), nil, d.now().UTC())
+	}
+	return previous, true, nil
+}
+
+// recordWrite notifies everything which observes changes to items: the PITR
+// history and any Kinesis streaming destinations. The caller MUST hold the
+// write lock.
+func (d *DB) recordWrite(t *table, oldImage, newImage avmap) {
+	t.recordWrite(oldImage, newImage)
+	d.publishChange(t, oldImage, newImage)
+}
+
+// replicationMeta records the last write to an item in a global table, for
+// last-writer-wins conflict resolution.
+type replicationMeta struct {
+	updatedAt time.Time
+	region    string
+}
+
+// supersedes reports whether a write described by m should win over a write
+// described by other. Ties on timestamps are broken by region name, so that
+// every replica makes the same decision.
+func (m replicationMeta) supersedes(other replicationMeta) bool {
+	if !m.updatedAt.Equal(other.updatedAt) {
+		return m.updatedAt.After(other.updatedAt)
+	}
+	return m.region > other.region
+}
+
+// addReplicationAttributes adds the attributes which version 2017.11.29 global
+// tables use to track replication.
+func addReplicationAttributes(item avmap, region string, at time.Time) {
+	item["aws:rep:deleting"] = &dynamodb.AttributeValue{BOOL: ptr(false)}
+	item["aws:rep:updateregion"] = &dynamodb.AttributeValue{S: ptr(region)}
+	item["aws:rep:updatetime"] = &dynamodb.AttributeValue{N: ptr(formatReplicationTime(at))}
+}
+
+// formatReplicationTime formats a time as fractional seconds since the epoch,
+// as used by aws:rep:updatetime.
+func formatReplicationTime(at time.Time) string {
+	return fmt.Sprintf("%d.%06d", at.Unix(), at.Nanosecond()/int(time.Microsecond))
+}
@@ -0,0 +1,362 @@
+package fakedynamo
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"slices"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// kinesisDestination is a Kinesis data stream which receives a table's item
+// changes. Destinations become ACTIVE and DISABLED immediately, though the
+// Enable and Disable operations report the intermediate ENABLING and DISABLING
+// states as DynamoDB does.
+type kinesisDestination struct {
+	streamArn string
+	status    string
+	precision string
+}
+
+// kinesisChangeRecord is the JSON document published to Kinesis for each item
+// change. See
+// https://docs.aws.amazon.com/amazondynamodb/latest/developerguide/kds.html
+type kinesisChangeRecord struct {
+	AwsRegion    string               `json:"awsRegion"`
+	EventID      string               `json:"eventID"`
+	EventName    string               `json:"eventName"`
+	UserIdentity any                  `json:"userIdentity"`
+	RecordFormat string               `json:"recordFormat"`
+	TableName    string               `json:"tableName"`
+	Dynamodb     kinesisChangeDetails `json:"dynamodb"`
+	EventSource  string               `json:"eventSource"`
+}
+
+type kinesisChangeDetails struct {
+	ApproximateCreationDateTime          int64           `json:"ApproximateCreationDateTime"`
+	ApproximateCreationDateTimePrecision string          `json:"ApproximateCreationDateTimePrecision"`
+	Keys                                 json.RawMessage `json:"Keys"`
+	NewImage                             json.RawMessage `json:"NewImage,omitempty"`
+	OldImage                             json.RawMessage `json:"OldImage,omitempty"`
+	SizeBytes                            int             `json:"SizeBytes"`
+}
+
+// publishChange sends an item change to the table's active Kinesis streaming
+// destinations. The caller MUST hold the write lock.
+func (d *DB) publishChange(t *table, oldImage, newImage avmap) {
+	if d.kinesis == nil || (oldImage == nil && newImage == nil) {
+		return
+	}
+	var active []*kinesisDestination
+	for _, dest := range t.kinesisDestinations {
+		if dest.status == dynamodb.DestinationStatusActive {
+			active = append(active, dest)
+		}
+	}
+	if len(active) == 0 {
+		return
+	}
+
+	eventName := "MODIFY"
+	image := newImage
+	switch {
+	case oldImage == nil:
+		eventName = "INSERT"
+	case newImage == nil:
+		eventName = "REMOVE"
+		image = oldImage
+	}
+	keys := t.extractKeys(image)
+
+	// Encoding can only fail for malformed attribute values, which we
+	// validated before writing them.
+	var details kinesisChangeDetails
+	details.Keys, _ = marshalDynamoDBJSON(keys)
+	if newImage != nil {
+		details.NewImage, _ = marshalDynamoDBJSON(newImage)
+	}
+	if oldImage != nil {
+		details.OldImage, _ = marshalDynamoDBJSON(oldImage)
+	}
+	details.SizeBytes = len(details.Keys) + len(details.NewImage) + len(details.OldImage)
+
+	now := d.now()
+	for _, dest := range active {
+		details.ApproximateCreationDateTimePrecision = dest.precision
+		details.ApproximateCreationDateTime = now.UnixMilli()
+		if dest.precision == dynamodb.ApproximateCreationDateTimePrecisionMicrosecond {
+			details.ApproximateCreationDateTime = now.UnixMicro()
+		}
+		data, _ := json.Marshal(kinesisChangeRecord{
+			AwsRegion:    d.region,
+			EventID:      newEventID(),
+			EventName:    eventName,
+			UserIdentity: nil,
+			RecordFormat: "application/json",
+			TableName:    *t.spec.TableName,
+			Dynamodb:     details,
+			EventSource:  "aws:dynamodb",
+		})
+		// The stream may have been deleted since the destination was enabled.
+		// DynamoDB would disable the destination; we drop the record.
+		_ = d.kinesis.Publish(dest.streamArn, keyValueString(keys[t.schema.partition]), data)
+	}
+}
+
+func newEventID() string {
+	var id [16]byte
+	_, _ = rand.Read(id[:])
+	return hex.EncodeToString(id[:])
+}
+
+// findKinesisDestination returns the table's destination for the given
+// stream, or nil.
+func (t *table) findKinesisDestination(streamArn string) *kinesisDestination {
+	i := slices.IndexFunc(t.kinesisDestinations, func(dest *kinesisDestination) bool {
+		return dest.streamArn == streamArn
+	})
+	if i < 0 {
+		return nil
+	}
+	return t.kinesisDestinations[i]
+}
+
+func validateKinesisStreamingInput(tableName, streamArn, precision *string) error {
+	var errs []error
+	if tableName == nil {
+		errs = append(errs, newValidationError("TableName is a required field"))
+	}
+	if streamArn == nil {
+		errs = append(errs, newValidationError("StreamArn is a required field"))
+	} else if len(*streamArn) < 37 {
+		errs = append(errs, newValidationError("StreamArn must be at least 37 characters"))
+	}
+	if precision != nil && !slices.Contains(dynamodb.ApproximateCreationDateTimePrecision_Values(), *precision) {
+		errs = append(errs, newValidationErrorf("ApproximateCreationDateTimePrecision must be one of %v",
+			dynamodb.ApproximateCreationDateTimePrecision_Values()))
+	}
+	return errors.Join(errs...)
+}
+
+func (d *DB) EnableKinesisStreamingDestination(input *dynamodb.EnableKinesisStreamingDestinationInput) (*dynamodb.EnableKinesisStreamingDestinationOutput, error) {
+	return d.enableKinesisStreamingDestinationContext(aws.BackgroundContext(), input)
+}
+
+func (d *DB) enableKinesisStreamingDestinationContext(ctx aws.Context, input *dynamodb.EnableKinesisStreamingDestinationInput) (*dynamodb.EnableKinesisStreamingDestinationOutput, error) {
+	if err := d.injectFault(ctx, "EnableKinesisStreamingDestination", input); err != nil {
+		return nil, err
+	}
+
+	var precision *string
+	if input.EnableKinesisStreamingConfiguration != nil {
+		precision = input.EnableKinesisStreamingConfiguration.ApproximateCreationDateTimePrecision
+	}
+	if err := validateKinesisStreamingInput(input.TableName, input.StreamArn, precision); err != nil {
+		return nil, err
+	}
+	if d.kinesis == nil || !d.kinesis.HasStream(*input.StreamArn) {
+		return nil, &dynamodb.ResourceNotFoundException{
+			Message_: ptr("Kinesis stream " + *input.StreamArn + " does not exist"),
+		}
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	t, exists := d.tables.Get(tableKey(*input.TableName))
+	if !exists {
+		return nil, &dynamodb.ResourceNotFoundException{}
+	}
+	for _, dest := range t.kinesisDestinations {
+		if dest.status == dynamodb.DestinationStatusActive {
+			return nil, &dynamodb.ResourceInUseException{
+				Message_: ptr("table already streams to " + dest.streamArn),
+			}
+		}
+	}
+
+	dest := t.findKinesisDestination(*input.StreamArn)
+	if dest == nil {
+		dest = &kinesisDestination{streamArn: *input.StreamArn, status: "", precision: ""}
+		t.kinesisDestinations = append(t.kinesisDestinations, dest)
+	}
+	dest.status = dynamodb.DestinationStatusActive
+	dest.precision = valOr(precision, dynamodb.ApproximateCreationDateTimePrecisionMillisecond)
+
+	return &dynamodb.EnableKinesisStreamingDestinationOutput{
+		DestinationStatus: ptr(dynamodb.DestinationStatusEnabling),
+		EnableKinesisStreamingConfiguration: &dynamodb.EnableKinesisStreamingConfiguration{
+			ApproximateCreationDateTimePrecision: ptr(dest.precision),
+		},
+		StreamArn: input.StreamArn,
+		TableName: input.TableName,
+	}, nil
+}
+
+func (d *DB) EnableKinesisStreamingDestinationWithContext(ctx aws.Context, input *dynamodb.EnableKinesisStreamingDestinationInput, opts ...request.Option) (*dynamodb.EnableKinesisStreamingDestinationOutput, error) {
+	req, output := d.EnableKinesisStreamingDestinationRequest(input)
+	req.SetContext(ctx)
+	req.ApplyOptions(opts...)
+	return output, req.Send()
+}
+
+func (d *DB) EnableKinesisStreamingDestinationRequest(input *dynamodb.EnableKinesisStreamingDestinationInput) (*request.Request, *dynamodb.EnableKinesisStreamingDestinationOutput) {
+	return newContextRequest(d, "EnableKinesisStreamingDestination", input, d.enableKinesisStreamingDestinationContext)
+}
+
+// activeKinesisDestination finds the named table's active destination for
+// the given stream. The caller MUST hold the lock.
+func (d *DB) activeKinesisDestination(tableName, streamArn string) (*kinesisDestination, error) {
+	t, exists := d.tables.Get(tableKey(tableName))
+	if !exists {
+		return nil, &dynamodb.ResourceNotFoundException{}
+	}
+	dest := t.findKinesisDestination(streamArn)
+	if dest == nil || dest.status != dynamodb.DestinationStatusActive {
+		return nil, newValidationErrorf("table %s is not streaming to %s", tableName, streamArn)
+	}
+	return dest, nil
+}
+
+func (d *DB) DisableKinesisStreamingDestination(input *dynamodb.DisableKinesisStreamingDestinationInput) (*dynamodb.DisableKinesisStreamingDestinationOutput, error) {
+	return d.disableKinesisStreamingDestinationContext(aws.BackgroundContext(), input)
+}
+
+func (d *DB) disableKinesisStreamingDestinationContext(ctx aws.Context, input *dynamodb.DisableKinesisStreamingDestinationInput) (*dynamodb.DisableKinesisStreamingDestinationOutput, error) {
+	if err := d.injectFault(ctx, "DisableKinesisStreamingDestination", input); err != nil {
+		return nil, err
+	}
+
+	if err := validateKinesisStreamingInput(input.TableName, input.StreamArn, nil); err != nil {
+		return nil, err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	dest, err := d.activeKinesisDestination(*input.TableName, *input.StreamArn)
+	if err != nil {
+		return nil, err
+	}
+	dest.status = dynamodb.DestinationStatusDisabled
+
+	return &dynamodb.DisableKinesisStreamingDestinationOutput{
+		DestinationStatus: ptr(dynamodb.DestinationStatusDisabling),
+		EnableKinesisStreamingConfiguration: &dynamodb.EnableKinesisStreamingConfiguration{
+			ApproximateCreationDateTimePrecision: ptr(dest.precision),
+		},
+		StreamArn: input.StreamArn,
+		TableName: input.TableName,
+	}, nil
+}
+
+func (d *DB) DisableKinesisStreamingDestinationWithContext(ctx aws.Context, input *dynamodb.DisableKinesisStreamingDestinationInput, opts ...request.Option) (*dynamodb.DisableKinesisStreamingDestinationOutput, error) {
+	req, output := d.DisableKinesisStreamingDestinationRequest(input)
+	req.SetContext(ctx)
+	req.ApplyOptions(opts...)
+	return output, req.Send()
+}
+
+func (d *DB) DisableKinesisStreamingDestinationRequest(input *dynamodb.DisableKinesisStreamingDestinationInput) (*request.Request, *dynamodb.DisableKinesisStreamingDestinationOutput) {
+	return newContextRequest(d, "DisableKinesisStreamingDestination", input, d.disableKinesisStreamingDestinationContext)
+}
+
+func (d *DB) UpdateKinesisStreamingDestination(input *dynamodb.UpdateKinesisStreamingDestinationInput) (*dynamodb.UpdateKinesisStreamingDestinationOutput, error) {
+	return d.updateKinesisStreamingDestinationContext(aws.BackgroundContext(), input)
+}
+
+func (d *DB) updateKinesisStreamingDestinationContext(ctx aws.Context, input *dynamodb.UpdateKinesisStreamingDestinationInput) (*dynamodb.UpdateKinesisStreamingDestinationOutput, error) {
+	if err := d.injectFault(ctx, "UpdateKinesisStreamingDestination", input); err != nil {
+		return nil, err
+	}
+
+	var precision *string
+	if input.UpdateKinesisStreamingConfiguration != nil {
+		precision = input.UpdateKinesisStreamingConfiguration.ApproximateCreationDateTimePrecision
+	}
+	if err := validateKinesisStreamingInput(input.TableName, input.StreamArn, precision); err != nil {
+		return nil, err
+	}
+	if precision == nil {
+		return nil, newValidationError("UpdateKinesisStreamingConfiguration.ApproximateCreationDateTimePrecision is a required field")
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	dest, err := d.activeKinesisDestination(*input.TableName, *input.StreamArn)
+	if err != nil {
+		return nil, err
+	}
+	if dest.precision == *precision {
+		return nil, newValidationErrorf("ApproximateCreationDateTimePrecision is already %s", *precision)
+	}
+	dest.precision = *precision
+
+	return &dynamodb.UpdateKinesisStreamingDestinationOutput{
+		DestinationStatus: ptr(dynamodb.DestinationStatusUpdating),
+		StreamArn:         input.StreamArn,
+		TableName:         input.TableName,
+		UpdateKinesisStreamingConfiguration: &dynamodb.UpdateKinesisStreamingConfiguration{
+			ApproximateCreationDateTimePrecision: precision,
+		},
+	}, nil
+}
+
+func (d *DB) UpdateKinesisStreamingDestinationWithContext(ctx aws.Context, input *dynamodb.UpdateKinesisStreamingDestinationInput, opts ...request.Option) (*dynamodb.UpdateKinesisStreamingDestinationOutput, error) {
+	req, output := d.UpdateKinesisStreamingDestinationRequest(input)
+	req.SetContext(ctx)
+	req.ApplyOptions(opts...)
+	return output, req.Send()
+}
+
+func (d *DB) UpdateKinesisStreamingDestinationRequest(input *dynamodb.UpdateKinesisStreamingDestinationInput) (*request.Request, *dynamodb.UpdateKinesisStreamingDestinationOutput) {
+	return newContextRequest(d, "UpdateKinesisStreamingDestination", input, d.updateKinesisStreamingDestinationContext)
+}
+
+func (d *DB) DescribeKinesisStreamingDestination(input *dynamodb.DescribeKinesisStreamingDestinationInput) (*dynamodb.DescribeKinesisStreamingDestinationOutput, error) {
+	return d.describeKinesisStreamingDestinationContext(aws.BackgroundContext(), input)
+}
+
+func (d *DB) describeKinesisStreamingDestinationContext(ctx aws.Context, input *dynamodb.DescribeKinesisStreamingDestinationInput) (*dynamodb.DescribeKinesisStreamingDestinationOutput, error) {
+	if err := d.injectFault(ctx, "DescribeKinesisStreamingDestination", input); err != nil {
+		return nil, err
+	}
+
+	if input.TableName == nil {
+		return nil, newValidationError("TableName is a required field")
+	}
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	t, exists := d.tables.Get(tableKey(*input.TableName))
+	if !exists {
+		return nil, &dynamodb.ResourceNotFoundException{}
+	}
+
+	dests := make([]*dynamodb.KinesisDataStreamDestination, 0, len(t.kinesisDestinations))
+	for _, dest := range t.kinesisDestinations {
+		dests = append(dests, &dynamodb.KinesisDataStreamDestination{
+			ApproximateCreationDateTimePrecision: ptr(dest.precision),
+			DestinationStatus:                    ptr(dest.status),
+			DestinationStatusDescription:         nil,
+			StreamArn:                            ptr(dest.streamArn),
+		})
+	}
+	return &dynamodb.DescribeKinesisStreamingDestinationOutput{
+		KinesisDataStreamDestinations: dests,
+		TableName:                     input.TableName,
+	}, nil
+}
+
+func (d *DB) DescribeKinesisStreamingDestinationWithContext(ctx aws.Context, input *dynamodb.DescribeKinesisStreamingDestinationInput, opts ...request.Option) (*dynamodb.DescribeKinesisStreamingDestinationOutput, error) {
+	req, output := d.DescribeKinesisStreamingDestinationRequest(input)
+	req.SetContext(ctx)
+	req.ApplyOptions(opts...)
+	return output, req.Send()
+}
+
+func (d *DB) DescribeKinesisStreamingDestinationRequest(input *dynamodb.DescribeKinesisStreamingDestinationInput) (*request.Request, *dynamodb.DescribeKinesisStreamingDestinationOutput) {
+	return newContextRequest(d, "DescribeKinesisStreamingDestination", input, d.describeKinesisStreamingDestinationContext)
+}
@@ -0,0 +1,122 @@
+package fakedynamo_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/DMRobertson/fakedynamo"
+	"github.com/DMRobertson/fakedynamo/fakekinesis"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/kinesis"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readAllKinesisRecords decodes every record in every shard of the stream.
+func readAllKinesisRecords(t *testing.T, k *fakekinesis.Kinesis, streamArn *string) []map[string]any {
+	t.Helper()
+	shards, err := k.ListShards(&kinesis.ListShardsInput{StreamARN: streamArn})
+	require.NoError(t, err)
+
+	var records []map[string]any
+	for _, shard := range shards.Shards {
+		it, err := k.GetShardIterator(&kinesis.GetShardIteratorInput{
+			ShardId:           shard.ShardId,
+			ShardIteratorType: ptr(kinesis.ShardIteratorTypeTrimHorizon),
+			StreamARN:         streamArn,
+		})
+		require.NoError(t, err)
+		out, err := k.GetRecords(&kinesis.GetRecordsInput{ShardIterator: it.ShardIterator})
+		require.NoError(t, err)
+		for _, record := range out.Records {
+			var decoded map[string]any
+			require.NoError(t, json.Unmarshal(record.Data, &decoded))
+			records = append(records, decoded)
+		}
+	}
+	return records
+}
+
+func TestDB_EnableKinesisStreamingDestination_PublishesChanges(t *testing.T) {
+	t.Parallel()
+	k := fakekinesis.New("us-east-1", "000000000000")
+	_, err := k.CreateStream(&kinesis.CreateStreamInput{StreamName: ptr("changes"), ShardCount: ptr[int64](1)})
+	require.NoError(t, err)
+	summary, err := k.DescribeStreamSummary(&kinesis.DescribeStreamSummaryInput{StreamName: ptr("changes")})
+	require.NoError(t, err)
+	streamArn := summary.StreamDescriptionSummary.StreamARN
+
+	clock := newFakeClock()
+	db := fakedynamo.NewDB(fakedynamo.WithKinesis(k), fakedynamo.WithClock(clock.Now))
+	input := exampleCreateTableInputSimplePrimaryKey()
+	_, err = db.CreateTable(input)
+	require.NoError(t, err)
+
+	enabled, err := db.EnableKinesisStreamingDestination(&dynamodb.EnableKinesisStreamingDestinationInput{
+		EnableKinesisStreamingConfiguration: &dynamodb.EnableKinesisStreamingConfiguration{
+			ApproximateCreationDateTimePrecision: ptr(dynamodb.ApproximateCreationDateTimePrecisionMicrosecond),
+		},
+		StreamArn: streamArn,
+		TableName: input.TableName,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, ptr(dynamodb.DestinationStatusEnabling), enabled.DestinationStatus)
+
+	described, err := db.DescribeKinesisStreamingDestination(&dynamodb.DescribeKinesisStreamingDestinationInput{
+		TableName: input.TableName,
+	})
+	require.NoError(t, err)
+	require.Len(t, described.KinesisDataStreamDestinations, 1)
+	assert.Equal(t, ptr(dynamodb.DestinationStatusActive), described.KinesisDataStreamDestinations[0].DestinationStatus)
+
+	key := map[string]*dynamodb.AttributeValue{"Foo": {S: ptr("a")}}
+	_, err = db.PutItem(&dynamodb.PutItemInput{Item: key, TableName: input.TableName})
+	require.NoError(t, err)
+	_, err = db.DeleteItem(&dynamodb.DeleteItemInput{Key: key, TableName: input.TableName})
+	require.NoError(t, err)
+
+	records := readAllKinesisRecords(t, k, streamArn)
+	require.Len(t, records, 2)
+	assert.Equal(t, "INSERT", records[0]["eventName"])
+	assert.Equal(t, "REMOVE", records[1]["eventName"])
+	change := records[0]["dynamodb"].(map[string]any)
+	assert.Equal(t, map[string]any{"Foo": map[string]any{"S": "a"}}, change["Keys"])
+	assert.Equal(t, map[string]any{"Foo": map[string]any{"S": "a"}}, change["NewImage"])
+	assert.Equal(t, "MICROSECOND", change["ApproximateCreationDateTimePrecision"])
+	assert.Equal(t, clock.Now().UnixMicro(), int64(change["ApproximateCreationDateTime"].(float64)))
+
+	_, err = db.DisableKinesisStreamingDestination(&dynamodb.DisableKinesisStreamingDestinationInput{
+		StreamArn: streamArn,
+		TableName: input.TableName,
+	})
+	require.NoError(t, err)
+	_, err = db.PutItem(&dynamodb.PutItemInput{Item: key, TableName: input.TableName})
+	require.NoError(t, err)
+	assert.Len(t, readAllKinesisRecords(t, k, streamArn), 2, "published after disabling")
+}
+
+func TestDB_EnableKinesisStreamingDestination_Errors(t *testing.T) {
+	t.Parallel()
+	k := fakekinesis.New("us-east-1", "000000000000")
+	db := fakedynamo.NewDB(fakedynamo.WithKinesis(k))
+	input := exampleCreateTableInputSimplePrimaryKey()
+	_, err := db.CreateTable(input)
+	require.NoError(t, err)
+	streamArn := ptr("arn:aws:kinesis:us-east-1:000000000000:stream/missing")
+
+	_, err = db.EnableKinesisStreamingDestination(&dynamodb.EnableKinesisStreamingDestinationInput{
+		StreamArn: streamArn,
+		TableName: input.TableName,
+	})
+	var notFound *dynamodb.ResourceNotFoundException
+	require.ErrorAs(t, err, &notFound)
+
+	_, err = db.UpdateKinesisStreamingDestination(&dynamodb.UpdateKinesisStreamingDestinationInput{
+		StreamArn: streamArn,
+		TableName: input.TableName,
+		UpdateKinesisStreamingConfiguration: &dynamodb.UpdateKinesisStreamingConfiguration{
+			ApproximateCreationDateTimePrecision: ptr(dynamodb.ApproximateCreationDateTimePrecisionMillisecond),
+		},
+	})
+	assertErrorContains(t, err, "not streaming")
+}
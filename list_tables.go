@@ -7,6 +7,14 @@ import (
 )
 
 func (d *DB) ListTables(input *dynamodb.ListTablesInput) (*dynamodb.ListTablesOutput, error) {
+	return d.listTablesContext(aws.BackgroundContext(), input)
+}
+
+func (d *DB) listTablesContext(ctx aws.Context, input *dynamodb.ListTablesInput) (*dynamodb.ListTablesOutput, error) {
+	if err := d.injectFault(ctx, "ListTables", input); err != nil {
+		return nil, err
+	}
+
 	if input.Limit != nil && (*input.Limit < 1 || *input.Limit > 100) {
 		return nil, newValidationError("Limit must be between 1 and 100")
 	}
@@ -22,7 +30,7 @@ func (d *DB) ListTables(input *dynamodb.ListTablesInput) (*dynamodb.ListTablesOu
 	}
 	d.mu.RLock()
 	defer d.mu.RUnlock()
-	d.tables.AscendGreaterOrEqual(start, func(t table) bool {
+	d.tables.AscendGreaterOrEqual(start, func(t *table) bool {
 		if *t.spec.TableName == *start.spec.TableName {
 			// Ignore the previous ExclusiveStartTableName
 			return true
@@ -44,18 +52,25 @@ func (d *DB) ListTables(input *dynamodb.ListTablesInput) (*dynamodb.ListTablesOu
 	return &output, nil
 }
 
-func (d *DB) ListTablesWithContext(_ aws.Context, input *dynamodb.ListTablesInput, _ ...request.Option) (*dynamodb.ListTablesOutput, error) {
-	return d.ListTables(input)
+func (d *DB) ListTablesWithContext(ctx aws.Context, input *dynamodb.ListTablesInput, opts ...request.Option) (*dynamodb.ListTablesOutput, error) {
+	req, output := d.ListTablesRequest(input)
+	req.SetContext(ctx)
+	req.ApplyOptions(opts...)
+	return output, req.Send()
 }
 
-func (d *DB) ListTablesRequest(_ *dynamodb.ListTablesInput) (*request.Request, *dynamodb.ListTablesOutput) {
-	panic("not implemented: ListTablesRequest")
+func (d *DB) ListTablesRequest(input *dynamodb.ListTablesInput) (*request.Request, *dynamodb.ListTablesOutput) {
+	return newContextRequest(d, "ListTables", input, d.listTablesContext)
 }
 
 func (d *DB) ListTablesPages(input *dynamodb.ListTablesInput, processPage func(*dynamodb.ListTablesOutput, bool) bool) error {
+	return d.ListTablesPagesWithContext(aws.BackgroundContext(), input, processPage)
+}
+
+func (d *DB) ListTablesPagesWithContext(ctx aws.Context, input *dynamodb.ListTablesInput, processPage func(*dynamodb.ListTablesOutput, bool) bool, opts ...request.Option) error {
 	input = shallowCopy(input)
 	for {
-		output, err := d.ListTables(input)
+		output, err := d.ListTablesWithContext(ctx, input, opts...)
 		if err != nil {
 			return err
 		}
@@ -68,7 +83,3 @@ func (d *DB) ListTablesPages(input *dynamodb.ListTablesInput, processPage func(*
 	}
 	return nil
 }
-
-func (d *DB) ListTablesPagesWithContext(_ aws.Context, input *dynamodb.ListTablesInput, processPage func(*dynamodb.ListTablesOutput, bool) bool, _ ...request.Option) error {
-	return d.ListTablesPages(input, processPage)
-}
@@ -0,0 +1,7 @@
+//go:build !race
+
+package fakedynamo_test
+
+// raceEnabled is set when the tests are run with the race detector, which
+// makes them several times slower.
+const raceEnabled = false
@@ -0,0 +1,109 @@
+package fakedynamo
+
+import (
+	"math/rand/v2"
+	"time"
+
+	"github.com/DMRobertson/fakedynamo/fakekinesis"
+)
+
+// Option configures a [DB] created by [NewDB].
+type Option func(*DB)
+
+// WithRegion sets the AWS region used when building ARNs. Defaults to
+// us-east-1.
+func WithRegion(region string) Option {
+	return func(d *DB) {
+		d.region = region
+	}
+}
+
+// WithAccountID sets the AWS account ID used when building ARNs. Defaults to
+// 000000000000, matching DynamoDB Local.
+func WithAccountID(accountID string) Option {
+	return func(d *DB) {
+		d.accountID = accountID
+	}
+}
+
+// WithS3Directory configures a local directory to stand in for S3, for
+// operations like ExportTableToPointInTime which read from or write to S3.
+// An S3 bucket named B corresponds to the subdirectory B of dir, and object
+// keys are paths relative to that.
+func WithS3Directory(dir string) Option {
+	return func(d *DB) {
+		d.s3Dir = dir
+	}
+}
+
+// WithKinesis configures a Kinesis stand-in to receive item changes from
+// tables with a Kinesis streaming destination. Streams must be created in k
+// before EnableKinesisStreamingDestination can refer to them.
+func WithKinesis(k *fakekinesis.Kinesis) Option {
+	return func(d *DB) {
+		d.kinesis = k
+	}
+}
+
+// WithTransientStatusDelay makes tables spend the given time in the CREATING
+// state after CreateTable, and in the DELETING state after DeleteTable,
+// instead of changing state instantly. Items can't be read or written while a
+// table is CREATING or DELETING.
+func WithTransientStatusDelay(delay time.Duration) Option {
+	return func(d *DB) {
+		d.transientStatusDelay = delay
+	}
+}
+
+// WithFaultSeed seeds the random number generator which decides whether
+// faults with a Probability fire, so that tests using them are reproducible.
+// See [DB.AddFault].
+func WithFaultSeed(seed uint64) Option {
+	return func(d *DB) {
+		d.faults.rand = rand.New(rand.NewPCG(seed, seed)) //nolint:gosec
+	}
+}
+
+// WithClock sets the clock used to simulate throughput limits: tables'
+// capacity refills as the clock advances. Defaults to [time.Now]. Tests can
+// use a fake clock to exhaust and refill capacity without waiting. The clock
+// also timestamps writes to global tables, for last-writer-wins conflict
+// resolution, places accesses in Contributor Insights' time windows, dates
+// the changes published to Kinesis data streams, and measures
+// [Regions.SetReplicationDelay].
+func WithClock(now func() time.Time) Option {
+	return func(d *DB) {
+		d.clock = now
+	}
+}
+
+// WithAdaptiveCapacity simulates DynamoDB splitting hot partitions. Each
+// partition key can be read at up to 3000 read capacity units and written at
+// up to 1000 write capacity units per second. Once a partition of a table
+// with a sort key has been throttled, this option splits it so that each of
+// its items gets its own cap, as DynamoDB does with sustained hot traffic.
+// Throttling of a single hot item persists.
+func WithAdaptiveCapacity() Option {
+	return func(d *DB) {
+		d.adaptiveCapacity = true
+	}
+}
+
+// WithCompactEvery sets how many changes a DB opened with [OpenDB] writes to
+// its log before compacting the log into a snapshot. Defaults to 10000.
+func WithCompactEvery(changes int) Option {
+	return func(d *DB) {
+		d.compactEvery = changes
+	}
+}
+
+// WithFileStorage makes tables store their items in temporary files in the
+// given directory, rather than in memory, so that large datasets needn't fit
+// in the Go heap. Reads and writes are slower. The files are deleted when
+// tables are deleted and when the DB is closed with [DB.Close]. For state
+// which survives restarts, see [OpenDB].
+func WithFileStorage(dir string) Option {
+	return func(d *DB) {
+		d.storageDir = dir
+	}
+}
@@ -11,6 +11,14 @@ import (
 )
 
 func (d *DB) PutItem(input *dynamodb.PutItemInput) (*dynamodb.PutItemOutput, error) {
+	return d.putItemContext(aws.BackgroundContext(), input)
+}
+
+func (d *DB) putItemContext(ctx aws.Context, input *dynamodb.PutItemInput) (*dynamodb.PutItemOutput, error) {
+	if err := d.injectFault(ctx, "PutItem", input); err != nil {
+		return nil, err
+	}
+
 	if input.Expected != nil {
 		return nil, errors.New("not implemented: PutItemInput.Expected (deprecated by DynamoDB)")
 	} else if input.ConditionalOperator != nil {
@@ -46,30 +54,33 @@ func (d *DB) PutItem(input *dynamodb.PutItemInput) (*dynamodb.PutItemOutput, err
 		conditionexpr = &expr
 	}
 
-	var t table
-	var exists bool
 	if input.TableName == nil {
 		errs = append(errs, newValidationError("TableName is a required field"))
 		return nil, errors.Join(errs...)
 	}
 
-	d.mu.RLock()
-	defer d.mu.RUnlock()
-	if t, exists = d.tables.Get(tableKey(*input.TableName)); !exists {
-		errs = append(errs, &dynamodb.ResourceNotFoundException{})
-	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	t, err := d.activeTable(*input.TableName)
+	errs = append(errs, err)
 
 	if err := errors.Join(errs...); err != nil {
 		return nil, err
 	}
 
-	pVal, err := validateAvmapMatchesSchema(input.Item, t, "Item")
+	if _, err := validateAvmapMatchesSchema(input.Item, t, "Item"); err != nil {
+		return nil, err
+	}
+	existing, _, err := t.items.Get(input.Item)
 	if err != nil {
 		return nil, err
 	}
-	var existing avmap
-	partition := t.getPartition(pVal)
-	existing, _ = partition.Get(input.Item)
+	used := t.writeConsumption(existing, input.Item)
+	if err := d.consume(t, true, input.Item, used); err != nil {
+		t.countThrottle(input.Item, d.now())
+		return nil, err
+	}
+	t.countWrite(input.Item, d.now())
 
 	if conditionexpr != nil {
 		result, err := conditionexpr.Evaluate(existing, input.ExpressionAttributeNames, input.ExpressionAttributeValues)
@@ -85,8 +96,13 @@ func (d *DB) PutItem(input *dynamodb.PutItemInput) (*dynamodb.PutItemOutput, err
 		}
 	}
 
-	output := &dynamodb.PutItemOutput{}
-	previous, replaced := partition.ReplaceOrInsert(input.Item)
+	output := &dynamodb.PutItemOutput{
+		ConsumedCapacity: consumedCapacity(*input.TableName, input.ReturnConsumedCapacity, true, used),
+	}
+	previous, replaced, err := d.putItem(t, input.Item)
+	if err != nil {
+		return nil, err
+	}
 	if replaced && returnValues == dynamodb.ReturnValueAllOld {
 		output.Attributes = previous
 	}
@@ -94,7 +110,7 @@ func (d *DB) PutItem(input *dynamodb.PutItemInput) (*dynamodb.PutItemOutput, err
 	return output, nil
 }
 
-func validateKeyAttributeCount(key avmap, t table) error {
+func validateKeyAttributeCount(key avmap, t *table) error {
 	if t.schema.sort == "" && len(key) != 1 {
 		return newValidationError("must provide partition key only")
 	}
@@ -105,7 +121,7 @@ func validateKeyAttributeCount(key avmap, t table) error {
 	return nil
 }
 
-func validateAvmapMatchesSchema(item avmap, t table, itemDesc string) (
+func validateAvmapMatchesSchema(item avmap, t *table, itemDesc string) (
 	*dynamodb.AttributeValue, error,
 ) {
 	var errs []error
@@ -221,10 +237,13 @@ func validatePutItemInputAttributeValue(value *dynamodb.AttributeValue, fieldPat
 	return nil
 }
 
-func (d *DB) PutItemWithContext(_ aws.Context, input *dynamodb.PutItemInput, _ ...request.Option) (*dynamodb.PutItemOutput, error) {
-	return d.PutItem(input)
+func (d *DB) PutItemWithContext(ctx aws.Context, input *dynamodb.PutItemInput, opts ...request.Option) (*dynamodb.PutItemOutput, error) {
+	req, output := d.PutItemRequest(input)
+	req.SetContext(ctx)
+	req.ApplyOptions(opts...)
+	return output, req.Send()
 }
 
-func (d *DB) PutItemRequest(_ *dynamodb.PutItemInput) (*request.Request, *dynamodb.PutItemOutput) {
-	panic("not implemented: PutItemRequest")
+func (d *DB) PutItemRequest(input *dynamodb.PutItemInput) (*request.Request, *dynamodb.PutItemOutput) {
+	return newContextRequest(d, "PutItem", input, d.putItemContext)
 }
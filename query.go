@@ -1,29 +1,289 @@
 package fakedynamo
 
 import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/DMRobertson/fakedynamo/conditionexpression"
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 )
 
+// Query reads the items in a partition of a table, in sort key order. The
+// KeyConditionExpression must test the partition key for equality; it may
+// also test the sort key with any of the operators DynamoDB allows. Only top
+// level attributes can be projected.
+//
+// Unlike DynamoDB, which orders numeric sort keys by value, the items are in
+// the order the table's storage keeps them, which compares numbers as
+// strings: 10 comes before 9.
+//
+// Indexes, filter expressions and the legacy KeyConditions, QueryFilter and
+// AttributesToGet parameters aren't implemented.
 func (d *DB) Query(input *dynamodb.QueryInput) (*dynamodb.QueryOutput, error) {
-	// TODO implement me
-	panic("implement me")
+	return d.queryContext(aws.BackgroundContext(), input)
+}
+
+func (d *DB) queryContext(ctx aws.Context, input *dynamodb.QueryInput) (*dynamodb.QueryOutput, error) {
+	if err := d.injectFault(ctx, "Query", input); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case input.IndexName != nil:
+		return nil, errors.New("not implemented: QueryInput.IndexName")
+	case input.FilterExpression != nil:
+		return nil, errors.New("not implemented: QueryInput.FilterExpression")
+	case input.KeyConditions != nil || input.QueryFilter != nil || input.ConditionalOperator != nil:
+		return nil, errors.New("not implemented: QueryInput.KeyConditions (deprecated by DynamoDB)")
+	case input.AttributesToGet != nil:
+		return nil, errors.New("not implemented: QueryInput.AttributesToGet (deprecated by DynamoDB)")
+	}
+
+	var errs []error
+	if input.TableName == nil {
+		errs = append(errs, newValidationError("TableName is a required field"))
+	}
+	if input.KeyConditionExpression == nil {
+		errs = append(errs, newValidationError("KeyConditionExpression is a required field"))
+	}
+	if input.Limit != nil && *input.Limit < 1 {
+		errs = append(errs, newValidationError("Limit must be at least 1"))
+	}
+	selected := valOr(input.Select, dynamodb.SelectAllAttributes)
+	if input.Select == nil && input.ProjectionExpression != nil {
+		selected = dynamodb.SelectSpecificAttributes
+	}
+	switch selected {
+	case dynamodb.SelectAllAttributes, dynamodb.SelectCount:
+		if input.ProjectionExpression != nil {
+			errs = append(errs, newValidationErrorf("ProjectionExpression can't be used with Select %s", selected))
+		}
+	case dynamodb.SelectSpecificAttributes:
+		if input.ProjectionExpression == nil {
+			errs = append(errs, newValidationError("Select SPECIFIC_ATTRIBUTES requires a ProjectionExpression"))
+		}
+	default:
+		errs = append(errs, newValidationError("Select must be ALL_ATTRIBUTES, SPECIFIC_ATTRIBUTES or COUNT"))
+	}
+	var projection []string
+	if input.ProjectionExpression != nil {
+		var err error
+		projection, err = parseProjection(*input.ProjectionExpression, input.ExpressionAttributeNames)
+		errs = append(errs, err)
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	condition, err := conditionexpression.Parse(*input.KeyConditionExpression)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse key condition expression: %w", err)
+	}
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	t, err := d.activeTable(*input.TableName)
+	if err != nil {
+		return nil, err
+	}
+	partitionKey, err := t.queryPartitionKey(*input.KeyConditionExpression, input.ExpressionAttributeNames, input.ExpressionAttributeValues)
+	if err != nil {
+		return nil, err
+	}
+	if input.ExclusiveStartKey != nil {
+		if _, err := validateAvmapMatchesSchema(input.ExclusiveStartKey, t, "ExclusiveStartKey"); err != nil {
+			return nil, err
+		}
+	}
+
+	var partition []avmap
+	err = t.items.AscendPartition(partitionKey, nil, func(item avmap) bool {
+		partition = append(partition, item)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	forward := valOr(input.ScanIndexForward, true)
+	if input.ExclusiveStartKey != nil {
+		// Find where the start key is, or would be if it has been deleted
+		// since the previous page, by counting the items from there on.
+		var after int
+		err = t.items.AscendPartition(partitionKey, input.ExclusiveStartKey, func(avmap) bool {
+			after++
+			return true
+		})
+		if err != nil {
+			return nil, err
+		}
+		at := len(partition) - after
+		if forward {
+			if at < len(partition) && t.itemKeyString(partition[at]) == t.itemKeyString(input.ExclusiveStartKey) {
+				at++
+			}
+			partition = partition[at:]
+		} else {
+			partition = partition[:at]
+		}
+	}
+	if !forward {
+		slices.Reverse(partition)
+	}
+
+	var matched []avmap
+	for _, item := range partition {
+		ok, err := condition.Evaluate(item, input.ExpressionAttributeNames, input.ExpressionAttributeValues)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			matched = append(matched, item)
+		}
+	}
+	var lastEvaluatedKey avmap
+	if input.Limit != nil && int64(len(matched)) > *input.Limit {
+		matched = matched[:*input.Limit]
+		lastEvaluatedKey = t.extractKeys(matched[len(matched)-1])
+	}
+
+	var size int
+	for _, item := range matched {
+		size += itemSize(item)
+	}
+	used := readConsumption(size, val(input.ConsistentRead))
+	key := avmap{t.schema.partition: partitionKey}
+	if err := d.consume(t, false, key, used); err != nil {
+		t.countThrottle(key, d.now())
+		return nil, err
+	}
+	t.countRead(key, d.now())
+
+	output := &dynamodb.QueryOutput{
+		ConsumedCapacity: consumedCapacity(*input.TableName, input.ReturnConsumedCapacity, false, used),
+		Count:            ptr(int64(len(matched))),
+		Items:            nil,
+		LastEvaluatedKey: lastEvaluatedKey,
+		ScannedCount:     ptr(int64(len(matched))),
+	}
+	if selected != dynamodb.SelectCount {
+		output.Items = make([]map[string]*dynamodb.AttributeValue, 0, len(matched))
+		for _, item := range matched {
+			output.Items = append(output.Items, projectItem(item, projection))
+		}
+	}
+	return output, nil
+}
+
+// keyEqualityConditions match the first and last clauses of a key condition
+// expression which test an attribute for equality, capturing the attribute and
+// the value placeholder. DynamoDB requires one of them to test the partition
+// key.
+var keyEqualityConditions = []*regexp.Regexp{
+	regexp.MustCompile(`^\s*(#?\w+)\s*=\s*(:\w+)\s*(?:(?i:AND)\b|$)`),
+	regexp.MustCompile(`\b(?i:AND)\s+(#?\w+)\s*=\s*(:\w+)\s*$`),
+}
+
+// queryPartitionKey finds the value of the partition key that a Query's key
+// condition expression tests for equality.
+func (t *table) queryPartitionKey(expr string, names map[string]*string, values avmap) (*dynamodb.AttributeValue, error) {
+	var candidates [][2]string
+	for _, re := range keyEqualityConditions {
+		if m := re.FindStringSubmatch(expr); m != nil {
+			candidates = append(candidates, [2]string{m[1], m[2]})
+		}
+	}
+	for _, candidate := range candidates {
+		name, placeholder := candidate[0], candidate[1]
+		if strings.HasPrefix(name, "#") {
+			if names[name] == nil {
+				return nil, newValidationErrorf("An expression attribute name used in the document path is not defined; attribute name: %s", name)
+			}
+			name = *names[name]
+		}
+		if name != t.schema.partition {
+			continue
+		}
+		value := values[placeholder]
+		if value == nil {
+			return nil, newValidationErrorf("An expression attribute value used in expression is not defined; attribute value: %s", placeholder)
+		}
+		if err := checkAttributeType(t.schema.types[name], value); err != nil {
+			return nil, newValidationErrorf("KeyConditionExpression: %s: %s", name, err.Error())
+		}
+		return value, nil
+	}
+	return nil, newValidationErrorf("Query condition missed key schema element: %s", t.schema.partition)
+}
+
+// parseProjection returns the attributes named by a projection expression.
+func parseProjection(expr string, names map[string]*string) ([]string, error) {
+	var attrs []string
+	for _, name := range strings.Split(expr, ",") {
+		name = strings.TrimSpace(name)
+		if strings.ContainsAny(name, ".[") {
+			return nil, errors.New("not implemented: nested attributes in ProjectionExpression")
+		}
+		if strings.HasPrefix(name, "#") {
+			if names[name] == nil {
+				return nil, newValidationErrorf("An expression attribute name used in the document path is not defined; attribute name: %s", name)
+			}
+			name = *names[name]
+		}
+		if name == "" {
+			return nil, newValidationError("Invalid ProjectionExpression: The expression has an empty attribute name")
+		}
+		attrs = append(attrs, name)
+	}
+	return attrs, nil
+}
+
+// projectItem returns the given attributes of an item, or the whole item if
+// attrs is nil.
+func projectItem(item avmap, attrs []string) avmap {
+	if attrs == nil {
+		return item
+	}
+	projected := avmap{}
+	for _, attr := range attrs {
+		if v, exists := item[attr]; exists {
+			projected[attr] = v
+		}
+	}
+	return projected
 }
 
-func (d *DB) QueryWithContext(_ aws.Context, input *dynamodb.QueryInput, _ ...request.Option) (*dynamodb.QueryOutput, error) {
-	return d.Query(input)
+func (d *DB) QueryWithContext(ctx aws.Context, input *dynamodb.QueryInput, opts ...request.Option) (*dynamodb.QueryOutput, error) {
+	req, output := d.QueryRequest(input)
+	req.SetContext(ctx)
+	req.ApplyOptions(opts...)
+	return output, req.Send()
 }
 
 func (d *DB) QueryRequest(input *dynamodb.QueryInput) (*request.Request, *dynamodb.QueryOutput) {
-	panic("not implemented: QueryRequest")
+	return newContextRequest(d, "Query", input, d.queryContext)
 }
 
-func (d *DB) QueryPages(input *dynamodb.QueryInput, f func(*dynamodb.QueryOutput, bool) bool) error {
-	// TODO implement me
-	panic("implement me")
+func (d *DB) QueryPages(input *dynamodb.QueryInput, processPage func(*dynamodb.QueryOutput, bool) bool) error {
+	return d.QueryPagesWithContext(aws.BackgroundContext(), input, processPage)
 }
 
-func (d *DB) QueryPagesWithContext(_ aws.Context, input *dynamodb.QueryInput, f func(*dynamodb.QueryOutput, bool) bool, _ ...request.Option) error {
-	return d.QueryPages(input, f)
+func (d *DB) QueryPagesWithContext(ctx aws.Context, input *dynamodb.QueryInput, processPage func(*dynamodb.QueryOutput, bool) bool, opts ...request.Option) error {
+	input = shallowCopy(input)
+	for {
+		output, err := d.QueryWithContext(ctx, input, opts...)
+		if err != nil {
+			return err
+		}
+		lastPage := output.LastEvaluatedKey == nil
+		shouldContinue := processPage(output, lastPage)
+		if lastPage || !shouldContinue {
+			break
+		}
+		input.ExclusiveStartKey = output.LastEvaluatedKey
+	}
+	return nil
 }
@@ -0,0 +1,172 @@
+package fakedynamo_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func makeQueryTestTable(t *testing.T) (dynamodbiface.DynamoDBAPI, *string) {
+	t.Helper()
+	db := makeTestDB(t)
+	tableOutput, err := db.CreateTable(exampleCreateTableInputCompositePrimaryKey())
+	require.NoError(t, err)
+	tableName := tableOutput.TableDescription.TableName
+	for _, key := range [][2]string{{"a", "1"}, {"a", "2"}, {"a", "3"}, {"a", "x"}, {"b", "1"}} {
+		_, err := db.PutItem(&dynamodb.PutItemInput{
+			Item: map[string]*dynamodb.AttributeValue{
+				"Foo":   {S: ptr(key[0])},
+				"Bar":   {S: ptr(key[1])},
+				"Other": {S: ptr(key[0] + key[1])},
+			},
+			TableName: tableName,
+		})
+		require.NoError(t, err)
+	}
+	return db, tableName
+}
+
+func queryBars(items []map[string]*dynamodb.AttributeValue) []string {
+	var bars []string
+	for _, item := range items {
+		bars = append(bars, val(item["Bar"].S))
+	}
+	return bars
+}
+
+func TestDB_Query_KeyConditions(t *testing.T) {
+	t.Parallel()
+	db, tableName := makeQueryTestTable(t)
+
+	for _, tc := range []struct {
+		name      string
+		condition string
+		values    map[string]*dynamodb.AttributeValue
+		expected  []string
+	}{
+		{
+			name:      "partition",
+			condition: "Foo = :foo",
+			values:    map[string]*dynamodb.AttributeValue{":foo": {S: ptr("a")}},
+			expected:  []string{"1", "2", "3", "x"},
+		},
+		{
+			name:      "begins_with",
+			condition: "Foo = :foo AND begins_with(Bar, :prefix)",
+			values:    map[string]*dynamodb.AttributeValue{":foo": {S: ptr("a")}, ":prefix": {S: ptr("x")}},
+			expected:  []string{"x"},
+		},
+		{
+			name:      "between, partition key last",
+			condition: "Bar BETWEEN :lo AND :hi AND Foo = :foo",
+			values: map[string]*dynamodb.AttributeValue{
+				":foo": {S: ptr("a")}, ":lo": {S: ptr("2")}, ":hi": {S: ptr("3")},
+			},
+			expected: []string{"2", "3"},
+		},
+		{
+			name:      "sort key equality first",
+			condition: "Bar = :bar AND Foo = :foo",
+			values:    map[string]*dynamodb.AttributeValue{":foo": {S: ptr("b")}, ":bar": {S: ptr("1")}},
+			expected:  []string{"1"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			output, err := db.Query(&dynamodb.QueryInput{
+				ExpressionAttributeValues: tc.values,
+				KeyConditionExpression:    &tc.condition,
+				TableName:                 tableName,
+			})
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, queryBars(output.Items))
+			assert.Equal(t, int64(len(tc.expected)), val(output.Count))
+		})
+	}
+}
+
+func TestDB_Query_ProjectsAttributes(t *testing.T) {
+	t.Parallel()
+	db, tableName := makeQueryTestTable(t)
+	output, err := db.Query(&dynamodb.QueryInput{
+		ExpressionAttributeNames:  map[string]*string{"#foo": ptr("Foo")},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{":foo": {S: ptr("b")}},
+		KeyConditionExpression:    ptr("#foo = :foo"),
+		ProjectionExpression:      ptr("Bar, Other, Missing"),
+		TableName:                 tableName,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []map[string]*dynamodb.AttributeValue{
+		{"Bar": {S: ptr("1")}, "Other": {S: ptr("b1")}},
+	}, output.Items)
+}
+
+func TestDB_QueryPages_Backwards(t *testing.T) {
+	t.Parallel()
+	db, tableName := makeQueryTestTable(t)
+	var pages [][]string
+	err := db.QueryPages(&dynamodb.QueryInput{
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{":foo": {S: ptr("a")}},
+		KeyConditionExpression:    ptr("Foo = :foo"),
+		Limit:                     ptr[int64](3),
+		ScanIndexForward:          ptr(false),
+		TableName:                 tableName,
+	}, func(output *dynamodb.QueryOutput, _ bool) bool {
+		pages = append(pages, queryBars(output.Items))
+		return true
+	})
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"x", "3", "2"}, {"1"}}, pages)
+}
+
+func TestDB_Query_RequiresPartitionKeyEquality(t *testing.T) {
+	t.Parallel()
+	db, tableName := makeQueryTestTable(t)
+	_, err := db.Query(&dynamodb.QueryInput{
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{":bar": {S: ptr("1")}},
+		KeyConditionExpression:    ptr("Bar = :bar"),
+		TableName:                 tableName,
+	})
+	assertErrorContains(t, err, "ValidationException", "missed key schema element: Foo")
+
+	_, err = db.Query(&dynamodb.QueryInput{
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{":foo": {N: ptr("1")}},
+		KeyConditionExpression:    ptr("Foo = :foo"),
+		TableName:                 tableName,
+	})
+	assertErrorContains(t, err, "ValidationException", "Foo")
+}
+
+func TestDB_Query_StartKeyDeletedBetweenPages(t *testing.T) {
+	t.Parallel()
+	for _, forward := range []bool{true, false} {
+		t.Run(fmt.Sprint(forward), func(t *testing.T) {
+			t.Parallel()
+			db, tableName := makeQueryTestTable(t)
+			input := &dynamodb.QueryInput{
+				ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{":foo": {S: ptr("a")}},
+				KeyConditionExpression:    ptr("Foo = :foo"),
+				Limit:                     ptr[int64](2),
+				ScanIndexForward:          ptr(forward),
+				TableName:                 tableName,
+			}
+			first, err := db.Query(input)
+			require.NoError(t, err)
+			require.NotNil(t, first.LastEvaluatedKey)
+			_, err = db.DeleteItem(&dynamodb.DeleteItemInput{Key: first.LastEvaluatedKey, TableName: tableName})
+			require.NoError(t, err)
+
+			input.ExclusiveStartKey = first.LastEvaluatedKey
+			second, err := db.Query(input)
+			require.NoError(t, err)
+			expected := []string{"3", "x"}
+			if !forward {
+				expected = []string{"2", "1"}
+			}
+			assert.Equal(t, expected, queryBars(second.Items))
+		})
+	}
+}
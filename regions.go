@@ -0,0 +1,321 @@
+package fakedynamo
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/google/btree"
+)
+
+const (
+	// globalTableVersion2017 identifies global tables managed with
+	// CreateGlobalTable and UpdateGlobalTable.
+	globalTableVersion2017 = "2017.11.29"
+	// globalTableVersion2019 identifies global tables managed with
+	// UpdateTable's ReplicaUpdates.
+	globalTableVersion2019 = "2019.11.21"
+)
+
+// Regions links several [DB]s, each modelling a single AWS region, so that
+// they can host global tables. Writes to a global table in one region are
+// replicated asynchronously to the other regions' replicas, and conflicting
+// writes are resolved by last-writer-wins.
+//
+// Replication is delayed by [Regions.SetReplicationDelay], and can be paused
+// entirely. Tests which need to control the interleaving of writes can pause
+// replication and call [Regions.Flush] to deliver pending writes.
+type Regions struct {
+	// mu guards the fields below. It MUST NOT be held while acquiring a DB's
+	// lock, since DBs acquire mu while holding their own lock.
+	mu  sync.Mutex
+	dbs map[string]*DB
+	// globalTables is ordered by name, so that ListGlobalTables can paginate.
+	globalTables *btree.BTreeG[*globalTable]
+
+	delay   time.Duration
+	paused  bool
+	pending []replicationEvent
+	// clock measures the replication delay. It's the DBs' clock: see
+	// [WithClock].
+	clock func() time.Time
+}
+
+// globalTable tracks the regions that hold replicas of a global table.
+type globalTable struct {
+	name      string
+	version   string
+	createdAt time.Time
+	regions   []string
+}
+
+func globalTableLess(a, b *globalTable) bool {
+	return cmp.Less(a.name, b.name)
+}
+
+// replicationEvent is a write waiting to be applied to a replica.
+type replicationEvent struct {
+	region    string
+	tableName string
+	// key identifies the item; item is its new value, or nil if the item was
+	// deleted.
+	key       avmap
+	item      avmap
+	meta      replicationMeta
+	deliverAt time.Time
+}
+
+// NewRegions creates a [DB] for each of the named regions, linked together so
+// that they can host global tables. The given options are applied to every
+// DB.
+func NewRegions(regionNames []string, opts ...Option) *Regions {
+	r := newRegions()
+	for _, name := range regionNames {
+		dbOpts := append(slices.Clone(opts), WithRegion(name), withRegions(r))
+		r.dbs[name] = NewDB(dbOpts...)
+		// The DBs are created with the same options, so share a clock.
+		r.clock = r.dbs[name].clock
+	}
+	return r
+}
+
+func newRegions() *Regions {
+	return &Regions{
+		mu:           sync.Mutex{},
+		dbs:          map[string]*DB{},
+		globalTables: btree.NewG(2, globalTableLess),
+		delay:        0,
+		paused:       false,
+		pending:      nil,
+		clock:        time.Now,
+	}
+}
+
+// withRegions is used by [NewRegions] to link each DB to its siblings.
+func withRegions(r *Regions) Option {
+	return func(d *DB) {
+		d.regions = r
+	}
+}
+
+// DB returns the DB modelling the named region, or nil if there is none.
+func (r *Regions) DB(region string) *DB {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.dbs[region]
+}
+
+// SetReplicationDelay sets how long writes take to reach other replicas.
+// Writes already in flight are unaffected. The delay is measured by the DBs'
+// clock, set by [WithClock]. A fake clock doesn't wake pending writes as it
+// advances: call [Regions.ResumeReplication] afterwards to deliver those which
+// are due.
+func (r *Regions) SetReplicationDelay(delay time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.delay = delay
+}
+
+// PauseReplication stops writes from being replicated until
+// [Regions.ResumeReplication] or [Regions.Flush] is called.
+func (r *Regions) PauseReplication() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.paused = true
+}
+
+// ResumeReplication undoes [Regions.PauseReplication]. Pending writes are
+// delivered once their replication delay has elapsed.
+func (r *Regions) ResumeReplication() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.paused = false
+	now := r.clock()
+	for _, event := range r.pending {
+		time.AfterFunc(max(0, event.deliverAt.Sub(now)), r.deliverDue)
+	}
+}
+
+// PendingReplication returns the number of writes waiting to be replicated.
+func (r *Regions) PendingReplication() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.pending)
+}
+
+// Flush immediately applies every pending write to its replica, regardless
+// of the replication delay or whether replication is paused.
+func (r *Regions) Flush() {
+	r.mu.Lock()
+	events := r.pending
+	r.pending = nil
+	r.mu.Unlock()
+	r.deliver(events)
+}
+
+func (r *Regions) deliverDue() {
+	r.mu.Lock()
+	if r.paused {
+		r.mu.Unlock()
+		return
+	}
+	now := r.clock()
+	var due []replicationEvent
+	r.pending = slices.DeleteFunc(r.pending, func(event replicationEvent) bool {
+		if event.deliverAt.After(now) {
+			return false
+		}
+		due = append(due, event)
+		return true
+	})
+	r.mu.Unlock()
+	r.deliver(due)
+}
+
+func (r *Regions) deliver(events []replicationEvent) {
+	for _, event := range events {
+		if db := r.DB(event.region); db != nil {
+			db.applyReplicatedWrite(event)
+		}
+	}
+}
+
+// globalTable returns the global table with the given name, or nil. The
+// result is a copy, safe to use without holding mu.
+func (r *Regions) globalTable(name string) *globalTable {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	global, exists := r.globalTables.Get(&globalTable{name: name}) //nolint:exhaustruct
+	if !exists {
+		return nil
+	}
+	clone := *global
+	clone.regions = slices.Clone(global.regions)
+	return &clone
+}
+
+// replicate queues a write to a global table for delivery to the other
+// replicas. The caller MUST hold d's write lock.
+func (d *DB) replicate(t *table, global *globalTable, key, item avmap, at time.Time) {
+	meta := replicationMeta{updatedAt: at, region: d.region}
+	t.replication[t.itemKeyString(key)] = meta
+
+	r := d.regions
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	deliverAt := d.now().Add(r.delay)
+	for _, region := range global.regions {
+		if region == d.region {
+			continue
+		}
+		r.pending = append(r.pending, replicationEvent{
+			region:    region,
+			tableName: global.name,
+			key:       key,
+			item:      item,
+			meta:      meta,
+			deliverAt: deliverAt,
+		})
+		if !r.paused {
+			time.AfterFunc(r.delay, r.deliverDue)
+		}
+	}
+}
+
+// applyReplicatedWrite applies a write made in another region, unless it
+// has been superseded by a later write.
+func (d *DB) applyReplicatedWrite(event replicationEvent) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	t, exists := d.tables.Get(tableKey(event.tableName))
+	if !exists {
+		return
+	}
+
+	key := t.itemKeyString(event.key)
+	if existing, seen := t.replication[key]; seen && !event.meta.supersedes(existing) {
+		return
+	}
+	if err := d.logItemWrite(t, event.key, event.item); err != nil {
+		// Like a write lost in transit, the write will be replicated again
+		// when the item next changes.
+		return
+	}
+	t.replication[key] = event.meta
+
+	if event.item == nil {
+		if previous, existed, err := t.items.Delete(event.key); err == nil && existed {
+			d.recordWrite(t, previous, nil)
+		}
+	} else if previous, _, err := t.items.Put(event.item); err == nil {
+		d.recordWrite(t, previous, event.item)
+	}
+}
+
+// registerGlobalTable records that the named table is replicated across the
+// given regions, replacing any previous record.
+func (r *Regions) registerGlobalTable(global *globalTable) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	slices.Sort(global.regions)
+	global.regions = slices.Compact(global.regions)
+	r.globalTables.ReplaceOrInsert(global)
+}
+
+// createGlobalTable registers a new global table, failing if one with the
+// same name already exists.
+func (r *Regions) createGlobalTable(global *globalTable) error {
+	r.mu.Lock()
+	exists := r.globalTables.Has(global)
+	r.mu.Unlock()
+	if exists {
+		return &dynamodb.GlobalTableAlreadyExistsException{}
+	}
+	r.registerGlobalTable(global)
+	return nil
+}
+
+// removeReplica removes a region from the named global table, if it is a
+// replica. The global table is forgotten once it has no replicas left.
+func (r *Regions) removeReplica(name, region string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	global, exists := r.globalTables.Get(&globalTable{name: name}) //nolint:exhaustruct
+	if !exists {
+		return
+	}
+	global.regions = slices.DeleteFunc(global.regions, func(other string) bool {
+		return other == region
+	})
+	if len(global.regions) == 0 {
+		r.globalTables.Delete(global)
+	}
+}
+
+// unregisterGlobalTable forgets the named global table.
+func (r *Regions) unregisterGlobalTable(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.globalTables.Delete(&globalTable{name: name}) //nolint:exhaustruct
+}
+
+// regionNames lists the regions, sorted by name.
+func (r *Regions) regionNames() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Sorted(maps.Keys(r.dbs))
+}
+
+func (r *Regions) globalTableArn(accountID, name string) string {
+	return fmt.Sprintf("arn:aws:dynamodb::%s:global-table/%s", accountID, name)
+}
@@ -0,0 +1,95 @@
+package fakedynamo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/client/metadata"
+	"github.com/aws/aws-sdk-go/aws/defaults"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// requestEndpoint is the endpoint of requests built by the XxxRequest
+// methods. Nothing listens on it: their Send handler calls the DB directly.
+const requestEndpoint = "http://fakedynamo.invalid"
+
+// sendHandlerName names the Send handler of requests built by the
+// XxxRequest methods, so that callers can find it in the handler list.
+const sendHandlerName = "fakedynamo.SendHandler"
+
+// newRequest builds the request returned by an XxxRequest method. It has the
+// SDK's default handlers, so that handlers added by the caller run as they
+// would for a real client, except that the Send handler runs op against the
+// DB instead of making an HTTP request. The request's output is filled in by
+// a successful Send.
+func newRequest[I, O any](d *DB, name string, input *I, op func(*I) (*O, error)) (*request.Request, *O) {
+	return newContextRequest(d, name, input, func(_ aws.Context, input *I) (*O, error) {
+		return op(input)
+	})
+}
+
+// newContextRequest is like newRequest, for operations which take the
+// request's context, to cut injected latency short or to check it as they go.
+func newContextRequest[I, O any](d *DB, name string, input *I, op func(aws.Context, *I) (*O, error)) (*request.Request, *O) {
+	if input == nil {
+		input = new(I)
+	}
+	output := new(O)
+
+	handlers := defaults.Handlers()
+	handlers.Send.Clear()
+	handlers.Send.PushBackNamed(request.NamedHandler{
+		Name: sendHandlerName,
+		Fn: func(r *request.Request) {
+			if err := r.Context().Err(); err != nil {
+				r.Error = canceledError(err)
+				return
+			}
+			result, err := op(r.Context(), r.Params.(*I)) //nolint:forcetypeassert
+			r.HTTPResponse = &http.Response{              //nolint:exhaustruct
+				StatusCode: http.StatusOK,
+				Header:     http.Header{},
+				Body:       http.NoBody,
+			}
+			if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+				r.Error = canceledError(err)
+				return
+			}
+			if err != nil {
+				r.HTTPResponse.StatusCode = errorStatusCode(err)
+				r.Error = err
+				return
+			}
+			*r.Data.(*O) = *result //nolint:forcetypeassert
+		},
+	})
+
+	clientInfo := metadata.ClientInfo{ //nolint:exhaustruct
+		ServiceName:   dynamodb.ServiceName,
+		ServiceID:     dynamodb.ServiceID,
+		APIVersion:    "2012-08-10",
+		PartitionID:   "aws",
+		Endpoint:      requestEndpoint,
+		SigningName:   dynamodb.EndpointsID,
+		SigningRegion: d.region,
+		JSONVersion:   "1.0",
+		TargetPrefix:  "DynamoDB_20120810",
+	}
+	operation := &request.Operation{ //nolint:exhaustruct
+		Name:       name,
+		HTTPMethod: http.MethodPost,
+		HTTPPath:   "/",
+	}
+	cfg := defaults.Config().WithRegion(d.region)
+	return request.New(*cfg, clientInfo, handlers, nil, operation, input, output), output
+}
+
+// canceledError reports that a request's context was canceled or timed out,
+// as the SDK does when the context ends during an HTTP request.
+func canceledError(err error) error {
+	return awserr.New(request.CanceledErrorCode, "request context canceled", err)
+}
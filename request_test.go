@@ -0,0 +1,149 @@
+package fakedynamo_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DMRobertson/fakedynamo"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDB_Request_RunsHandlersAndFillsOutput(t *testing.T) {
+	t.Parallel()
+	db := makeTestDB(t)
+	input := exampleCreateTableInputSimplePrimaryKey()
+	_, err := db.CreateTable(input)
+	require.NoError(t, err)
+	item := map[string]*dynamodb.AttributeValue{"Foo": {S: ptr("foo")}}
+	_, err = db.PutItem(&dynamodb.PutItemInput{Item: item, TableName: input.TableName})
+	require.NoError(t, err)
+
+	var calls []string
+	req, output := db.GetItemRequest(&dynamodb.GetItemInput{Key: item, TableName: input.TableName})
+	req.Handlers.Send.PushFront(func(r *request.Request) {
+		calls = append(calls, "send:"+r.Operation.Name)
+	})
+	req.Handlers.Complete.PushBack(func(r *request.Request) {
+		calls = append(calls, "complete")
+		assert.NoError(t, r.Error)
+	})
+	require.NoError(t, req.Send())
+	assert.Equal(t, []string{"send:GetItem", "complete"}, calls)
+	assert.Equal(t, item, output.Item)
+	assert.Same(t, output, req.Data)
+}
+
+func TestDB_Request_SetsError(t *testing.T) {
+	t.Parallel()
+	db := makeTestDB(t)
+	req, _ := db.DescribeTableRequest(&dynamodb.DescribeTableInput{TableName: ptr("missing-" + nonce())})
+	var statusCode int
+	req.Handlers.Complete.PushBack(func(r *request.Request) {
+		statusCode = r.HTTPResponse.StatusCode
+	})
+	err := req.Send()
+	var notFound *dynamodb.ResourceNotFoundException
+	require.ErrorAs(t, err, &notFound)
+	assert.Equal(t, err, req.Error)
+	assert.Equal(t, 400, statusCode)
+}
+
+func TestDB_Request_EarlyHandlerErrorsSkipTheOperation(t *testing.T) {
+	t.Parallel()
+	db := makeTestDB(t)
+	input := exampleCreateTableInputSimplePrimaryKey()
+	req, _ := db.CreateTableRequest(input)
+	req.Handlers.Build.PushBack(func(r *request.Request) {
+		r.Error = awserr.New("Refused", "not today", nil)
+	})
+	err := req.Send()
+	assertErrorContains(t, err, "Refused")
+
+	_, err = db.DescribeTable(&dynamodb.DescribeTableInput{TableName: input.TableName})
+	var notFound *dynamodb.ResourceNotFoundException
+	require.ErrorAs(t, err, &notFound)
+}
+
+func TestDB_WithContext_ChecksContext(t *testing.T) {
+	t.Parallel()
+	db := makeTestDB(t)
+	input := exampleCreateTableInputSimplePrimaryKey()
+	_, err := db.CreateTable(input)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	item := map[string]*dynamodb.AttributeValue{"Foo": {S: ptr("foo")}}
+	_, err = db.PutItemWithContext(ctx, &dynamodb.PutItemInput{Item: item, TableName: input.TableName})
+	var awsErr awserr.Error
+	require.ErrorAs(t, err, &awsErr)
+	assert.Equal(t, request.CanceledErrorCode, awsErr.Code())
+
+	got, err := db.GetItem(&dynamodb.GetItemInput{Key: item, TableName: input.TableName})
+	require.NoError(t, err)
+	assert.Nil(t, got.Item)
+}
+
+func TestDB_WithContext_AppliesOptions(t *testing.T) {
+	t.Parallel()
+	db := makeTestDB(t)
+	var operations []string
+	option := func(r *request.Request) {
+		operations = append(operations, r.Operation.Name)
+	}
+	_, err := db.ListTablesWithContext(context.Background(), &dynamodb.ListTablesInput{}, option)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ListTables"}, operations)
+}
+
+func TestDB_PagesWithContext_StopsWhenContextEnds(t *testing.T) {
+	t.Parallel()
+	db := fakedynamo.NewDB()
+	for range 3 {
+		_, err := db.CreateTable(exampleCreateTableInputSimplePrimaryKey())
+		require.NoError(t, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	pages := 0
+	err := db.ListTablesPagesWithContext(ctx, &dynamodb.ListTablesInput{Limit: ptr[int64](1)}, func(*dynamodb.ListTablesOutput, bool) bool {
+		pages++
+		cancel()
+		return true
+	})
+	var awsErr awserr.Error
+	require.ErrorAs(t, err, &awsErr)
+	assert.Equal(t, request.CanceledErrorCode, awsErr.Code())
+	assert.Equal(t, 1, pages)
+}
+
+func TestDB_WaitUntilTableExists(t *testing.T) {
+	t.Parallel()
+	db := fakedynamo.NewDB(fakedynamo.WithTransientStatusDelay(50 * time.Millisecond))
+	input := exampleCreateTableInputSimplePrimaryKey()
+	_, err := db.CreateTable(input)
+	require.NoError(t, err)
+
+	// The context ends before the table is ACTIVE.
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	err = db.WaitUntilTableExistsWithContext(ctx, &dynamodb.DescribeTableInput{TableName: input.TableName})
+	var awsErr awserr.Error
+	require.ErrorAs(t, err, &awsErr)
+	assert.Equal(t, request.CanceledErrorCode, awsErr.Code())
+
+	require.NoError(t, db.WaitUntilTableExists(&dynamodb.DescribeTableInput{TableName: input.TableName}))
+	described, err := db.DescribeTable(&dynamodb.DescribeTableInput{TableName: input.TableName})
+	require.NoError(t, err)
+	assert.Equal(t, ptr(dynamodb.TableStatusActive), described.Table.TableStatus)
+
+	_, err = db.DeleteTable(&dynamodb.DeleteTableInput{TableName: input.TableName})
+	require.NoError(t, err)
+	require.NoError(t, db.WaitUntilTableNotExists(&dynamodb.DescribeTableInput{TableName: input.TableName}))
+}
@@ -0,0 +1,297 @@
+package fakedynamo
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+const (
+	// maxResourcePolicyBytes is the largest policy document we accept.
+	maxResourcePolicyBytes = 20 * 1024
+	// noPolicyRevisionID can be given as an ExpectedRevisionId to require
+	// that the resource has no policy.
+	noPolicyRevisionID = "NO_POLICY"
+)
+
+// resourcePolicy is a resource-based policy attached to a table or stream.
+type resourcePolicy struct {
+	document   string
+	revisionID string
+}
+
+// policyStatement captures the parts of an IAM policy statement that we
+// validate. See
+// https://docs.aws.amazon.com/IAM/latest/UserGuide/reference_policies_elements.html
+type policyStatement struct {
+	Effect       string          `json:"Effect"`
+	Principal    json.RawMessage `json:"Principal"`
+	NotPrincipal json.RawMessage `json:"NotPrincipal"`
+	Action       json.RawMessage `json:"Action"`
+	NotAction    json.RawMessage `json:"NotAction"`
+}
+
+var validPolicyVersions = []string{"2008-10-17", "2012-10-17"}
+
+// validateResourcePolicy checks that the policy is a structurally valid IAM
+// policy document. We don't check that its actions, principals or resources
+// make sense.
+func validateResourcePolicy(policy string) error {
+	if len(policy) > maxResourcePolicyBytes {
+		return newValidationError("Policy must be at most 20 KB")
+	}
+
+	var document struct {
+		Version   *string         `json:"Version"`
+		Statement json.RawMessage `json:"Statement"`
+	}
+	if err := json.Unmarshal([]byte(policy), &document); err != nil {
+		return newValidationErrorf("Policy is not valid JSON: %s", err)
+	}
+	if document.Version != nil && !slices.Contains(validPolicyVersions, *document.Version) {
+		return newValidationErrorf("Policy has unsupported Version %q", *document.Version)
+	}
+	if len(document.Statement) == 0 {
+		return newValidationError("Policy must contain a Statement")
+	}
+
+	var statements []policyStatement
+	if err := json.Unmarshal(document.Statement, &statements); err != nil {
+		var statement policyStatement
+		if err := json.Unmarshal(document.Statement, &statement); err != nil {
+			return newValidationError("Policy Statement must be an object or a list of objects")
+		}
+		statements = []policyStatement{statement}
+	}
+	if len(statements) == 0 {
+		return newValidationError("Policy must contain a Statement")
+	}
+
+	var errs []error
+	for i, statement := range statements {
+		if statement.Effect != "Allow" && statement.Effect != "Deny" {
+			errs = append(errs, newValidationErrorf("Policy Statement[%d].Effect must be Allow or Deny", i))
+		}
+		if (statement.Principal == nil) == (statement.NotPrincipal == nil) {
+			errs = append(errs, newValidationErrorf("Policy Statement[%d] must contain exactly one of Principal and NotPrincipal", i))
+		}
+		if (statement.Action == nil) == (statement.NotAction == nil) {
+			errs = append(errs, newValidationErrorf("Policy Statement[%d] must contain exactly one of Action and NotAction", i))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// newPolicyRevisionID returns a revision ID which differs from previous.
+func newPolicyRevisionID(previous *resourcePolicy) string {
+	revision := time.Now().UnixMicro()
+	if previous != nil {
+		if prev, err := strconv.ParseInt(previous.revisionID, 10, 64); err == nil && prev >= revision {
+			revision = prev + 1
+		}
+	}
+	return strconv.FormatInt(revision, 10)
+}
+
+// resourcePolicyTable finds the table which the given ARN, of a table or its
+// stream, belongs to. It reports whether the ARN is the stream's. The caller
+// MUST ensure that mu is Locked or RLocked appropriately.
+func (d *DB) resourcePolicyTable(arn string) (*table, bool, error) {
+	name, ok := d.tableNameFromArn(arn)
+	if !ok {
+		return nil, false, &dynamodb.ResourceNotFoundException{}
+	}
+	t, exists := d.tables.Get(tableKey(name))
+	if !exists {
+		return nil, false, &dynamodb.ResourceNotFoundException{}
+	}
+
+	if arn == d.tableArn(name) {
+		return t, false, nil
+	}
+	if streamArn, ok := d.streamArn(t); ok && arn == streamArn {
+		return t, true, nil
+	}
+	return nil, false, &dynamodb.ResourceNotFoundException{}
+}
+
+// policySlot returns where the policy of the table, or of its stream, is
+// stored.
+func (t *table) policySlot(stream bool) **resourcePolicy {
+	if stream {
+		return &t.streamPolicy
+	}
+	return &t.policy
+}
+
+// checkExpectedRevisionID implements optimistic concurrency control for
+// resource policies.
+func checkExpectedRevisionID(expected *string, current *resourcePolicy) error {
+	switch {
+	case expected == nil:
+		return nil
+	case *expected == noPolicyRevisionID && current == nil:
+		return nil
+	case current != nil && *expected == current.revisionID:
+		return nil
+	}
+	return &dynamodb.PolicyNotFoundException{
+		Message_: aws.String("the resource's policy revision does not match ExpectedRevisionId"),
+	}
+}
+
+func (d *DB) PutResourcePolicy(input *dynamodb.PutResourcePolicyInput) (*dynamodb.PutResourcePolicyOutput, error) {
+	return d.putResourcePolicyContext(aws.BackgroundContext(), input)
+}
+
+func (d *DB) putResourcePolicyContext(ctx aws.Context, input *dynamodb.PutResourcePolicyInput) (*dynamodb.PutResourcePolicyOutput, error) {
+	if err := d.injectFault(ctx, "PutResourcePolicy", input); err != nil {
+		return nil, err
+	}
+
+	var errs []error
+	if input.ResourceArn == nil {
+		errs = append(errs, newValidationError("ResourceArn is a required field"))
+	}
+	if input.Policy == nil {
+		errs = append(errs, newValidationError("Policy is a required field"))
+	} else {
+		errs = append(errs, validateResourcePolicy(*input.Policy))
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	t, stream, err := d.resourcePolicyTable(*input.ResourceArn)
+	if err != nil {
+		return nil, err
+	}
+	previous := *t.policySlot(stream)
+	if err := checkExpectedRevisionID(input.ExpectedRevisionId, previous); err != nil {
+		return nil, err
+	}
+
+	policy := &resourcePolicy{
+		document:   *input.Policy,
+		revisionID: newPolicyRevisionID(previous),
+	}
+	err = d.changeTable(t, func(updated *table) error {
+		*updated.policySlot(stream) = policy
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &dynamodb.PutResourcePolicyOutput{
+		RevisionId: ptr(policy.revisionID),
+	}, nil
+}
+
+func (d *DB) PutResourcePolicyWithContext(ctx aws.Context, input *dynamodb.PutResourcePolicyInput, opts ...request.Option) (*dynamodb.PutResourcePolicyOutput, error) {
+	req, output := d.PutResourcePolicyRequest(input)
+	req.SetContext(ctx)
+	req.ApplyOptions(opts...)
+	return output, req.Send()
+}
+
+func (d *DB) PutResourcePolicyRequest(input *dynamodb.PutResourcePolicyInput) (*request.Request, *dynamodb.PutResourcePolicyOutput) {
+	return newContextRequest(d, "PutResourcePolicy", input, d.putResourcePolicyContext)
+}
+
+func (d *DB) GetResourcePolicy(input *dynamodb.GetResourcePolicyInput) (*dynamodb.GetResourcePolicyOutput, error) {
+	return d.getResourcePolicyContext(aws.BackgroundContext(), input)
+}
+
+func (d *DB) getResourcePolicyContext(ctx aws.Context, input *dynamodb.GetResourcePolicyInput) (*dynamodb.GetResourcePolicyOutput, error) {
+	if err := d.injectFault(ctx, "GetResourcePolicy", input); err != nil {
+		return nil, err
+	}
+
+	if input.ResourceArn == nil {
+		return nil, newValidationError("ResourceArn is a required field")
+	}
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	t, stream, err := d.resourcePolicyTable(*input.ResourceArn)
+	if err != nil {
+		return nil, err
+	}
+	policy := *t.policySlot(stream)
+	if policy == nil {
+		return nil, &dynamodb.PolicyNotFoundException{}
+	}
+	return &dynamodb.GetResourcePolicyOutput{
+		Policy:     ptr(policy.document),
+		RevisionId: ptr(policy.revisionID),
+	}, nil
+}
+
+func (d *DB) GetResourcePolicyWithContext(ctx aws.Context, input *dynamodb.GetResourcePolicyInput, opts ...request.Option) (*dynamodb.GetResourcePolicyOutput, error) {
+	req, output := d.GetResourcePolicyRequest(input)
+	req.SetContext(ctx)
+	req.ApplyOptions(opts...)
+	return output, req.Send()
+}
+
+func (d *DB) GetResourcePolicyRequest(input *dynamodb.GetResourcePolicyInput) (*request.Request, *dynamodb.GetResourcePolicyOutput) {
+	return newContextRequest(d, "GetResourcePolicy", input, d.getResourcePolicyContext)
+}
+
+func (d *DB) DeleteResourcePolicy(input *dynamodb.DeleteResourcePolicyInput) (*dynamodb.DeleteResourcePolicyOutput, error) {
+	return d.deleteResourcePolicyContext(aws.BackgroundContext(), input)
+}
+
+func (d *DB) deleteResourcePolicyContext(ctx aws.Context, input *dynamodb.DeleteResourcePolicyInput) (*dynamodb.DeleteResourcePolicyOutput, error) {
+	if err := d.injectFault(ctx, "DeleteResourcePolicy", input); err != nil {
+		return nil, err
+	}
+
+	if input.ResourceArn == nil {
+		return nil, newValidationError("ResourceArn is a required field")
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	t, stream, err := d.resourcePolicyTable(*input.ResourceArn)
+	if err != nil {
+		return nil, err
+	}
+	policy := *t.policySlot(stream)
+	if policy == nil {
+		return nil, &dynamodb.PolicyNotFoundException{}
+	}
+	if err := checkExpectedRevisionID(input.ExpectedRevisionId, policy); err != nil {
+		return nil, err
+	}
+
+	err = d.changeTable(t, func(updated *table) error {
+		*updated.policySlot(stream) = nil
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &dynamodb.DeleteResourcePolicyOutput{
+		RevisionId: ptr(policy.revisionID),
+	}, nil
+}
+
+func (d *DB) DeleteResourcePolicyWithContext(ctx aws.Context, input *dynamodb.DeleteResourcePolicyInput, opts ...request.Option) (*dynamodb.DeleteResourcePolicyOutput, error) {
+	req, output := d.DeleteResourcePolicyRequest(input)
+	req.SetContext(ctx)
+	req.ApplyOptions(opts...)
+	return output, req.Send()
+}
+
+func (d *DB) DeleteResourcePolicyRequest(input *dynamodb.DeleteResourcePolicyInput) (*request.Request, *dynamodb.DeleteResourcePolicyOutput) {
+	return newContextRequest(d, "DeleteResourcePolicy", input, d.deleteResourcePolicyContext)
+}
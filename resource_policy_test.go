@@ -0,0 +1,154 @@
+package fakedynamo_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const examplePolicy = `{
+  "Version": "2012-10-17",
+  "Statement": [{
+    "Effect": "Allow",
+    "Principal": {"AWS": "arn:aws:iam::111122223333:root"},
+    "Action": "dynamodb:GetItem",
+    "Resource": "*"
+  }]
+}`
+
+func TestDB_PutResourcePolicy_RevisionIDs(t *testing.T) {
+	t.Parallel()
+	if dynamodbSession != nil {
+		t.Skip("DynamoDB Local doesn't support resource-based policies")
+	}
+	db := makeTestDB(t)
+	created, err := db.CreateTable(exampleCreateTableInputSimplePrimaryKey())
+	require.NoError(t, err)
+	arn := created.TableDescription.TableArn
+
+	_, err = db.GetResourcePolicy(&dynamodb.GetResourcePolicyInput{ResourceArn: arn})
+	var notFoundErr *dynamodb.PolicyNotFoundException
+	require.ErrorAs(t, err, &notFoundErr)
+
+	put, err := db.PutResourcePolicy(&dynamodb.PutResourcePolicyInput{
+		ExpectedRevisionId: ptr("NO_POLICY"),
+		Policy:             ptr(examplePolicy),
+		ResourceArn:        arn,
+	})
+	require.NoError(t, err)
+	firstRevision := val(put.RevisionId)
+	assert.NotEmpty(t, firstRevision)
+
+	got, err := db.GetResourcePolicy(&dynamodb.GetResourcePolicyInput{ResourceArn: arn})
+	require.NoError(t, err)
+	assert.Equal(t, examplePolicy, val(got.Policy))
+	assert.Equal(t, firstRevision, val(got.RevisionId))
+
+	// A stale revision is rejected.
+	_, err = db.PutResourcePolicy(&dynamodb.PutResourcePolicyInput{
+		ExpectedRevisionId: ptr("NO_POLICY"),
+		Policy:             ptr(examplePolicy),
+		ResourceArn:        arn,
+	})
+	require.ErrorAs(t, err, &notFoundErr)
+
+	put, err = db.PutResourcePolicy(&dynamodb.PutResourcePolicyInput{
+		ExpectedRevisionId: &firstRevision,
+		Policy:             ptr(examplePolicy),
+		ResourceArn:        arn,
+	})
+	require.NoError(t, err)
+	secondRevision := val(put.RevisionId)
+	assert.NotEqual(t, firstRevision, secondRevision)
+
+	_, err = db.DeleteResourcePolicy(&dynamodb.DeleteResourcePolicyInput{
+		ExpectedRevisionId: &firstRevision,
+		ResourceArn:        arn,
+	})
+	require.ErrorAs(t, err, &notFoundErr)
+
+	deleted, err := db.DeleteResourcePolicy(&dynamodb.DeleteResourcePolicyInput{
+		ExpectedRevisionId: &secondRevision,
+		ResourceArn:        arn,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, secondRevision, val(deleted.RevisionId))
+
+	_, err = db.DeleteResourcePolicy(&dynamodb.DeleteResourcePolicyInput{ResourceArn: arn})
+	require.ErrorAs(t, err, &notFoundErr)
+}
+
+func TestDB_PutResourcePolicy_StreamsAndCreateTable(t *testing.T) {
+	t.Parallel()
+	if dynamodbSession != nil {
+		t.Skip("DynamoDB Local doesn't support resource-based policies")
+	}
+	db := makeTestDB(t)
+	input := exampleCreateTableInputSimplePrimaryKey()
+	input.ResourcePolicy = ptr(examplePolicy)
+	input.StreamSpecification = &dynamodb.StreamSpecification{
+		StreamEnabled:  ptr(true),
+		StreamViewType: ptr(dynamodb.StreamViewTypeNewAndOldImages),
+	}
+	created, err := db.CreateTable(input)
+	require.NoError(t, err)
+	require.NotNil(t, created.TableDescription.LatestStreamArn)
+
+	got, err := db.GetResourcePolicy(&dynamodb.GetResourcePolicyInput{ResourceArn: created.TableDescription.TableArn})
+	require.NoError(t, err)
+	assert.Equal(t, examplePolicy, val(got.Policy))
+
+	streamArn := created.TableDescription.LatestStreamArn
+	_, err = db.GetResourcePolicy(&dynamodb.GetResourcePolicyInput{ResourceArn: streamArn})
+	var notFoundErr *dynamodb.PolicyNotFoundException
+	require.ErrorAs(t, err, &notFoundErr)
+
+	_, err = db.PutResourcePolicy(&dynamodb.PutResourcePolicyInput{
+		Policy:      ptr(examplePolicy),
+		ResourceArn: streamArn,
+	})
+	require.NoError(t, err)
+	got, err = db.GetResourcePolicy(&dynamodb.GetResourcePolicyInput{ResourceArn: streamArn})
+	require.NoError(t, err)
+	assert.Equal(t, examplePolicy, val(got.Policy))
+
+	_, err = db.GetResourcePolicy(&dynamodb.GetResourcePolicyInput{ResourceArn: ptr(*streamArn + "0")})
+	var resourceErr *dynamodb.ResourceNotFoundException
+	require.ErrorAs(t, err, &resourceErr)
+}
+
+func TestDB_PutResourcePolicy_ValidationErrors(t *testing.T) {
+	t.Parallel()
+	if dynamodbSession != nil {
+		t.Skip("DynamoDB Local doesn't support resource-based policies")
+	}
+	db := makeTestDB(t)
+	created, err := db.CreateTable(exampleCreateTableInputSimplePrimaryKey())
+	require.NoError(t, err)
+
+	testCases := map[string]struct {
+		Policy              string
+		ExpectErrorMessages []string
+	}{
+		"invalid JSON":       {`{`, []string{"JSON"}},
+		"missing Statement":  {`{"Version": "2012-10-17"}`, []string{"Statement"}},
+		"bad Version":        {`{"Version": "2020-01-01", "Statement": []}`, []string{"Version"}},
+		"bad Effect":         {`{"Statement": {"Effect": "Maybe", "Principal": "*", "Action": "*"}}`, []string{"Effect"}},
+		"missing Principal":  {`{"Statement": [{"Effect": "Allow", "Action": "*"}]}`, []string{"Principal"}},
+		"missing Action":     {`{"Statement": [{"Effect": "Deny", "Principal": "*"}]}`, []string{"Action"}},
+		"oversized document": {`{"Sid": "` + strings.Repeat("x", 20*1024) + `"}`, []string{"20 KB"}},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			_, err := db.PutResourcePolicy(&dynamodb.PutResourcePolicyInput{
+				Policy:      ptr(tc.Policy),
+				ResourceArn: created.TableDescription.TableArn,
+			})
+			assertErrorContains(t, err, append([]string{"ValidationException"}, tc.ExpectErrorMessages...)...)
+		})
+	}
+}
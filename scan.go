@@ -12,18 +12,25 @@ func (d *DB) Scan(input *dynamodb.ScanInput) (*dynamodb.ScanOutput, error) {
 	return nil, errors.New("not implemented lol")
 }
 
-func (d *DB) ScanWithContext(_ aws.Context, input *dynamodb.ScanInput, _ ...request.Option) (*dynamodb.ScanOutput, error) {
-	return d.Scan(input)
+func (d *DB) ScanWithContext(ctx aws.Context, input *dynamodb.ScanInput, opts ...request.Option) (*dynamodb.ScanOutput, error) {
+	req, output := d.ScanRequest(input)
+	req.SetContext(ctx)
+	req.ApplyOptions(opts...)
+	return output, req.Send()
 }
 
-func (d *DB) ScanRequest(_ *dynamodb.ScanInput) (*request.Request, *dynamodb.ScanOutput) {
-	panic("not implemented: ScanRequest")
+func (d *DB) ScanRequest(input *dynamodb.ScanInput) (*request.Request, *dynamodb.ScanOutput) {
+	return newRequest(d, "Scan", input, d.Scan)
 }
 
 func (d *DB) ScanPages(input *dynamodb.ScanInput, processPage func(*dynamodb.ScanOutput, bool) bool) error {
+	return d.ScanPagesWithContext(aws.BackgroundContext(), input, processPage)
+}
+
+func (d *DB) ScanPagesWithContext(ctx aws.Context, input *dynamodb.ScanInput, processPage func(*dynamodb.ScanOutput, bool) bool, opts ...request.Option) error {
 	input = shallowCopy(input)
 	for {
-		output, err := d.Scan(input)
+		output, err := d.ScanWithContext(ctx, input, opts...)
 		if err != nil {
 			return err
 		}
@@ -36,7 +43,3 @@ func (d *DB) ScanPages(input *dynamodb.ScanInput, processPage func(*dynamodb.Sca
 	}
 	return nil
 }
-
-func (d *DB) ScanPagesWithContext(_ aws.Context, input *dynamodb.ScanInput, f func(*dynamodb.ScanOutput, bool) bool, _ ...request.Option) error {
-	return d.ScanPages(input, f)
-}
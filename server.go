@@ -0,0 +1,189 @@
+package fakedynamo
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+)
+
+const (
+	// targetPrefix prefixes the operation name in the X-Amz-Target header.
+	targetPrefix = "DynamoDB_20120810."
+	// errorTypePrefix prefixes error codes in the __type field of error
+	// responses.
+	errorTypePrefix = "com.amazonaws.dynamodb.v20120810#"
+	// amzJSONContentType is the content type of requests and responses.
+	amzJSONContentType = "application/x-amz-json-1.0"
+)
+
+// NewHandler returns an HTTP handler which serves the DynamoDB JSON wire
+// protocol, so that any AWS SDK or the AWS CLI can use api by pointing its
+// endpoint at the handler. Typically api is a [DB].
+//
+// Requests are not authenticated: signatures are ignored.
+func NewHandler(api dynamodbiface.DynamoDBAPI) http.Handler {
+	return &handler{api: reflect.ValueOf(api)}
+}
+
+type handler struct {
+	api reflect.Value
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("X-Amzn-Requestid", newRequestID())
+	if r.Method != http.MethodPost {
+		writeErrorResponse(w, http.StatusMethodNotAllowed, "UnknownOperationException", "DynamoDB requests must be POSTed")
+		return
+	}
+
+	op, found := strings.CutPrefix(r.Header.Get("X-Amz-Target"), targetPrefix)
+	method, ok := h.operation(op)
+	if !found || !ok {
+		writeErrorResponse(w, http.StatusBadRequest, "UnknownOperationException", "")
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "SerializationException", err.Error())
+		return
+	}
+	input := reflect.New(method.Type().In(0).Elem())
+	if len(bytes.TrimSpace(body)) > 0 {
+		if err := unmarshalDynamoDBJSON(body, input.Interface()); err != nil {
+			writeErrorResponse(w, http.StatusBadRequest, "SerializationException", err.Error())
+			return
+		}
+	}
+
+	output, err := callOperation(method, input)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	encoded, err := marshalDynamoDBJSON(output)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, "InternalServerError", err.Error())
+		return
+	}
+	writeResponse(w, http.StatusOK, encoded)
+}
+
+// operation finds the method implementing the named DynamoDB operation. We
+// only consider methods shaped like operations, i.e. func(*XInput)
+// (*XOutput, error), so that clients can't call arbitrary methods.
+func (h *handler) operation(op string) (reflect.Value, bool) {
+	if op == "" {
+		return reflect.Value{}, false
+	}
+	method := h.api.MethodByName(op)
+	if !method.IsValid() {
+		return reflect.Value{}, false
+	}
+	t := method.Type()
+	if t.NumIn() != 1 || t.NumOut() != 2 ||
+		t.In(0).Kind() != reflect.Pointer || t.In(0).Elem().Name() != op+"Input" ||
+		t.Out(0).Kind() != reflect.Pointer || t.Out(0).Elem().Name() != op+"Output" {
+		return reflect.Value{}, false
+	}
+	return method, true
+}
+
+// callOperation invokes the operation, converting panics from unimplemented
+// operations into errors.
+func callOperation(method, input reflect.Value) (output any, err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			output, err = nil, fmt.Errorf("%v", recovered)
+		}
+	}()
+	results := method.Call([]reflect.Value{input})
+	if errValue := results[1].Interface(); errValue != nil {
+		return nil, errValue.(error)
+	}
+	return results[0].Interface(), nil
+}
+
+// writeError encodes an error like DynamoDB does. Errors which aren't AWS
+// errors, like those for unimplemented features, are reported as internal
+// server errors.
+func writeError(w http.ResponseWriter, err error) {
+	var awsErr awserr.Error
+	if !errors.As(err, &awsErr) {
+		writeErrorResponse(w, http.StatusInternalServerError, "InternalServerError", err.Error())
+		return
+	}
+
+	status := errorStatusCode(err)
+
+	// Several validation errors may have been joined together; report them
+	// all in a single message.
+	message := awsErr.Message()
+	if awsErr != err { //nolint:errorlint
+		message = err.Error()
+	}
+
+	// SDK exceptions may carry extra fields, such as the Item for a
+	// ConditionalCheckFailedException. Encode them from the exception struct.
+	fields := map[string]json.RawMessage{}
+	if reflect.TypeOf(awsErr).Kind() == reflect.Pointer {
+		if encoded, encodeErr := marshalDynamoDBJSON(awsErr); encodeErr == nil {
+			_ = json.Unmarshal(encoded, &fields)
+		}
+	}
+	fields["__type"], _ = json.Marshal(errorTypePrefix + awsErr.Code())
+	fields["message"], _ = json.Marshal(message)
+	encoded, _ := json.Marshal(fields)
+	writeResponse(w, status, encoded)
+}
+
+// errorStatusCode is the HTTP status code DynamoDB would respond with for err:
+// that of the error if it has one, otherwise 400 for AWS errors and 500 for
+// anything else.
+func errorStatusCode(err error) int {
+	var failure awserr.RequestFailure
+	if errors.As(err, &failure) && failure.StatusCode() != 0 {
+		return failure.StatusCode()
+	}
+	var awsErr awserr.Error
+	if errors.As(err, &awsErr) {
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+func writeErrorResponse(w http.ResponseWriter, status int, code, message string) {
+	encoded, _ := json.Marshal(map[string]string{
+		"__type":  errorTypePrefix + code,
+		"message": message,
+	})
+	writeResponse(w, status, encoded)
+}
+
+// writeResponse writes a response body, with the CRC32 checksum which the
+// SDKs use to verify it.
+func writeResponse(w http.ResponseWriter, status int, body []byte) {
+	w.Header().Set("Content-Type", amzJSONContentType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	w.Header().Set("X-Amz-Crc32", strconv.FormatUint(uint64(crc32.ChecksumIEEE(body)), 10))
+	w.WriteHeader(status)
+	_, _ = w.Write(body)
+}
+
+func newRequestID() string {
+	var id [16]byte
+	_, _ = rand.Read(id[:])
+	return strings.ToUpper(hex.EncodeToString(id[:]))
+}
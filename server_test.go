@@ -0,0 +1,117 @@
+package fakedynamo_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DMRobertson/fakedynamo"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler_WireProtocolErrors(t *testing.T) {
+	t.Parallel()
+	handler := fakedynamo.NewHandler(fakedynamo.NewDB())
+
+	type testCase struct {
+		Name   string
+		Target string
+		Body   string
+
+		ExpectStatus int
+		ExpectType   string
+	}
+	testCases := []testCase{
+		{
+			Name:         "Unknown operation",
+			Target:       "DynamoDB_20120810.NoSuchOperation",
+			Body:         "{}",
+			ExpectStatus: http.StatusBadRequest,
+			ExpectType:   "com.amazonaws.dynamodb.v20120810#UnknownOperationException",
+		},
+		{
+			Name:         "Methods which aren't operations",
+			Target:       "DynamoDB_20120810.TopContributors",
+			Body:         "{}",
+			ExpectStatus: http.StatusBadRequest,
+			ExpectType:   "com.amazonaws.dynamodb.v20120810#UnknownOperationException",
+		},
+		{
+			Name:         "Malformed body",
+			Target:       "DynamoDB_20120810.ListTables",
+			Body:         "{",
+			ExpectStatus: http.StatusBadRequest,
+			ExpectType:   "com.amazonaws.dynamodb.v20120810#SerializationException",
+		},
+		{
+			Name:         "Missing table",
+			Target:       "DynamoDB_20120810.DescribeTable",
+			Body:         `{"TableName": "missing"}`,
+			ExpectStatus: http.StatusBadRequest,
+			ExpectType:   "com.amazonaws.dynamodb.v20120810#ResourceNotFoundException",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tc.Body))
+			req.Header.Set("X-Amz-Target", tc.Target)
+			req.Header.Set("Content-Type", "application/x-amz-json-1.0")
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+
+			assert.Equal(t, tc.ExpectStatus, recorder.Code)
+			assert.Equal(t, "application/x-amz-json-1.0", recorder.Header().Get("Content-Type"))
+			var body map[string]any
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+			assert.Equal(t, tc.ExpectType, body["__type"])
+		})
+	}
+}
+
+func TestHandler_RoundTrip(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(fakedynamo.NewHandler(fakedynamo.NewDB()))
+	t.Cleanup(server.Close)
+	sess, err := session.NewSession(&aws.Config{
+		Credentials: credentials.NewStaticCredentials("key", "secret", ""),
+		Endpoint:    ptr(server.URL),
+		Region:      ptr("us-east-1"),
+	})
+	require.NoError(t, err)
+	client := dynamodb.New(sess)
+
+	before := time.Now().Truncate(time.Second)
+	input := exampleCreateTableInputSimplePrimaryKey()
+	input.TableName = ptr("round-trip")
+	_, err = client.CreateTable(input)
+	require.NoError(t, err)
+	described, err := client.DescribeTable(&dynamodb.DescribeTableInput{TableName: input.TableName})
+	require.NoError(t, err)
+	assert.WithinRange(t, *described.Table.CreationDateTime, before, time.Now().Add(time.Second))
+
+	item := map[string]*dynamodb.AttributeValue{
+		"Foo":    {S: ptr("<key> & \"quotes\"")},
+		"Blob":   {B: []byte{0, 1, 2, 255}},
+		"Blobs":  {BS: [][]byte{{1}, {2}}},
+		"Number": {N: ptr("1.5")},
+		"Empty":  {L: []*dynamodb.AttributeValue{}},
+		"Nested": {M: map[string]*dynamodb.AttributeValue{"Null": {NULL: ptr(true)}}},
+	}
+	_, err = client.PutItem(&dynamodb.PutItemInput{TableName: input.TableName, Item: item})
+	require.NoError(t, err)
+	got, err := client.GetItem(&dynamodb.GetItemInput{
+		TableName: input.TableName,
+		Key:       map[string]*dynamodb.AttributeValue{"Foo": item["Foo"]},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, item, got.Item)
+}
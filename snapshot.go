@@ -0,0 +1,263 @@
+package fakedynamo
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// Snapshot format
+//
+// A snapshot is a sequence of JSON values, one per line. The first is a
+// header:
+//
+//	{"Format":"fakedynamo-snapshot","Version":1}
+//
+// Then, for each table in name order, a table record followed by one item
+// record for each of the table's items:
+//
+//	{"Table":{"Spec":{...},"CreatedAt":"...","Tags":{...},"ResourcePolicy":"...","ResourcePolicyRevisionID":"...","StreamPolicy":"...","StreamPolicyRevisionID":"...","TimeToLiveAttribute":"...","ItemCount":2}}
+//	{"Item":{...}}
+//	{"Item":{...}}
+//
+// Spec is the CreateTableInput which created the table, and items are
+// attribute maps. Both are in DynamoDB JSON, as sent over the wire, so
+// attribute values carry their types, e.g. {"S": "hello"}. CreatedAt is in
+// RFC 3339 format, and ResourcePolicy and ResourcePolicyRevisionID are
+// omitted if the table has no policy; likewise StreamPolicy and
+// StreamPolicyRevisionID for the policy of the table's stream. A policy
+// without a revision ID is given a new one when loaded.
+// TimeToLiveAttribute names the attribute holding items' expiry times, and is
+// omitted unless Time to Live is enabled.
+// Items are written in order of partition key, then sort key.
+//
+// Readers must reject snapshots with a Version they don't know. Adding
+// fields to records doesn't change the version; readers ignore fields they
+// don't know.
+
+const (
+	snapshotFormat  = "fakedynamo-snapshot"
+	snapshotVersion = 1
+)
+
+type snapshotHeader struct {
+	Format  string
+	Version int
+}
+
+type snapshotTable struct {
+	Spec                     json.RawMessage
+	CreatedAt                time.Time
+	Tags                     map[string]string
+	ResourcePolicy           *string `json:",omitempty"`
+	ResourcePolicyRevisionID string  `json:",omitempty"`
+	StreamPolicy             *string `json:",omitempty"`
+	StreamPolicyRevisionID   string  `json:",omitempty"`
+	TimeToLiveAttribute      string  `json:",omitempty"`
+	ItemCount                int
+}
+
+type snapshotRecord struct {
+	Table *snapshotTable
+}
+
+// SaveSnapshot writes every table to w: its spec, creation time, tags, the
+// resource policies of the table and its stream, Time to Live settings and
+// items. Load the snapshot into another DB with [DB.LoadSnapshot]. Tables
+// which are being deleted are left out.
+//
+// Other state, such as point-in-time recovery history, global table
+// replication, Contributor Insights, exports and imports, isn't saved.
+func (d *DB) SaveSnapshot(w io.Writer) error {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.writeSnapshot(w)
+}
+
+// writeSnapshot implements SaveSnapshot. The caller MUST ensure that mu is
+// Locked or RLocked.
+func (d *DB) writeSnapshot(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	if err := enc.Encode(snapshotHeader{Format: snapshotFormat, Version: snapshotVersion}); err != nil {
+		return fmt.Errorf("error writing snapshot: %w", err)
+	}
+	var err error
+	d.tables.Ascend(func(t *table) bool {
+		if !t.deleting {
+			err = t.saveSnapshot(bw, enc)
+		}
+		return err == nil
+	})
+	if err == nil {
+		err = bw.Flush()
+	}
+	if err != nil {
+		return fmt.Errorf("error writing snapshot: %w", err)
+	}
+	return nil
+}
+
+func (t *table) saveSnapshot(w *bufio.Writer, enc *json.Encoder) error {
+	record, err := t.snapshotRecord()
+	if err != nil {
+		return err
+	}
+	var items []avmap
+	err = t.items.Ascend(func(item avmap) bool {
+		items = append(items, item)
+		return true
+	})
+	if err != nil {
+		return err
+	}
+
+	record.ItemCount = len(items)
+	if err := enc.Encode(snapshotRecord{Table: record}); err != nil {
+		return err
+	}
+	for _, item := range items {
+		line, err := marshalDynamoDBJSON(struct{ Item avmap }{item})
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(append(line, '\n')); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// snapshotRecord describes the table, without its items.
+func (t *table) snapshotRecord() (*snapshotTable, error) {
+	spec, err := marshalDynamoDBJSON(t.spec)
+	if err != nil {
+		return nil, err
+	}
+	record := &snapshotTable{
+		Spec:                     spec,
+		CreatedAt:                t.createdAt,
+		Tags:                     t.tags,
+		ResourcePolicy:           nil,
+		ResourcePolicyRevisionID: "",
+		StreamPolicy:             nil,
+		StreamPolicyRevisionID:   "",
+		TimeToLiveAttribute:      t.ttlAttribute,
+		ItemCount:                0,
+	}
+	if t.policy != nil {
+		record.ResourcePolicy = &t.policy.document
+		record.ResourcePolicyRevisionID = t.policy.revisionID
+	}
+	if t.streamPolicy != nil {
+		record.StreamPolicy = &t.streamPolicy.document
+		record.StreamPolicyRevisionID = t.streamPolicy.revisionID
+	}
+	return record, nil
+}
+
+// LoadSnapshot creates the tables saved by [DB.SaveSnapshot], with their
+// items. It fails with a ResourceInUseException if the DB already has a table
+// with the same name as one in the snapshot. If LoadSnapshot fails, the
+// tables loaded before the failure are kept.
+func (d *DB) LoadSnapshot(r io.Reader) error {
+	dec := json.NewDecoder(bufio.NewReader(r))
+	var header snapshotHeader
+	if err := dec.Decode(&header); err != nil {
+		return fmt.Errorf("error reading snapshot header: %w", err)
+	}
+	if header.Format != snapshotFormat {
+		return errors.New("not a fakedynamo snapshot")
+	}
+	if header.Version != snapshotVersion {
+		return fmt.Errorf("unsupported snapshot version %d", header.Version)
+	}
+
+	for {
+		var record snapshotRecord
+		err := dec.Decode(&record)
+		if errors.Is(err, io.EOF) {
+			return d.compactLoadedSnapshot()
+		}
+		if err != nil {
+			return fmt.Errorf("error reading snapshot: %w", err)
+		}
+		if record.Table == nil {
+			return errors.New("error reading snapshot: expected a table record")
+		}
+		if err := d.loadSnapshotTable(dec, record.Table); err != nil {
+			return err
+		}
+	}
+}
+
+// compactLoadedSnapshot saves the state of a DB opened with [OpenDB] after
+// loading a snapshot, since the items loaded aren't logged.
+func (d *DB) compactLoadedSnapshot() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.wal == nil {
+		return nil
+	}
+	return d.compactWAL()
+}
+
+func (d *DB) loadSnapshotTable(dec *json.Decoder, record *snapshotTable) error {
+	var spec dynamodb.CreateTableInput
+	if err := unmarshalDynamoDBJSON(record.Spec, &spec); err != nil {
+		return fmt.Errorf("error reading snapshot: %w", err)
+	}
+	if spec.TableName == nil {
+		return errors.New("error reading snapshot: table has no TableName")
+	}
+	if _, err := d.createTable(&spec); err != nil {
+		return fmt.Errorf("error loading table %s from snapshot: %w", *spec.TableName, err)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	t, _ := d.tables.Get(tableKey(*spec.TableName))
+	t.createdAt = record.CreatedAt
+	t.tags = record.Tags
+	if t.tags == nil {
+		t.tags = map[string]string{}
+	}
+	t.ttlAttribute = record.TimeToLiveAttribute
+	t.policy = loadResourcePolicy(record.ResourcePolicy, record.ResourcePolicyRevisionID)
+	t.streamPolicy = loadResourcePolicy(record.StreamPolicy, record.StreamPolicyRevisionID)
+
+	for range record.ItemCount {
+		var line json.RawMessage
+		if err := dec.Decode(&line); err != nil {
+			return fmt.Errorf("error reading items of table %s from snapshot: %w", *spec.TableName, err)
+		}
+		var item struct{ Item avmap }
+		if err := unmarshalDynamoDBJSON(line, &item); err != nil {
+			return fmt.Errorf("error reading items of table %s from snapshot: %w", *spec.TableName, err)
+		}
+		if _, err := validateAvmapMatchesSchema(item.Item, t, "Item"); err != nil {
+			return fmt.Errorf("error loading items of table %s from snapshot: %w", *spec.TableName, err)
+		}
+		if _, _, err := t.items.Put(item.Item); err != nil {
+			return fmt.Errorf("error loading items of table %s from snapshot: %w", *spec.TableName, err)
+		}
+	}
+	return nil
+}
+
+// loadResourcePolicy restores a policy saved in a snapshot, giving it a new
+// revision ID if none was saved. It returns nil if document is nil.
+func loadResourcePolicy(document *string, revisionID string) *resourcePolicy {
+	if document == nil {
+		return nil
+	}
+	if revisionID == "" {
+		revisionID = newPolicyRevisionID(nil)
+	}
+	return &resourcePolicy{document: *document, revisionID: revisionID}
+}
@@ -0,0 +1,132 @@
+package fakedynamo_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/DMRobertson/fakedynamo"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDB_SaveSnapshot_RoundTrip(t *testing.T) {
+	t.Parallel()
+	db := fakedynamo.NewDB()
+	input := exampleCreateTableInputCompositePrimaryKey()
+	input.Tags = []*dynamodb.Tag{{Key: ptr("team"), Value: ptr("storage")}}
+	input.StreamSpecification = &dynamodb.StreamSpecification{
+		StreamEnabled:  ptr(true),
+		StreamViewType: ptr(dynamodb.StreamViewTypeKeysOnly),
+	}
+	created, err := db.CreateTable(input)
+	require.NoError(t, err)
+	_, err = db.TagResource(&dynamodb.TagResourceInput{
+		ResourceArn: created.TableDescription.TableArn,
+		Tags:        []*dynamodb.Tag{{Key: ptr("env"), Value: ptr("test")}},
+	})
+	require.NoError(t, err)
+	_, err = db.UpdateTimeToLive(&dynamodb.UpdateTimeToLiveInput{
+		TableName: input.TableName,
+		TimeToLiveSpecification: &dynamodb.TimeToLiveSpecification{
+			AttributeName: ptr("ExpiresAt"),
+			Enabled:       ptr(true),
+		},
+	})
+	require.NoError(t, err)
+	_, err = db.PutResourcePolicy(&dynamodb.PutResourcePolicyInput{
+		Policy:      ptr(examplePolicy),
+		ResourceArn: created.TableDescription.TableArn,
+	})
+	require.NoError(t, err)
+	_, err = db.PutResourcePolicy(&dynamodb.PutResourcePolicyInput{
+		Policy:      ptr(examplePolicy),
+		ResourceArn: created.TableDescription.LatestStreamArn,
+	})
+	require.NoError(t, err)
+	other := exampleCreateTableInputSimplePrimaryKey()
+	_, err = db.CreateTable(other)
+	require.NoError(t, err)
+
+	items := []map[string]*dynamodb.AttributeValue{
+		{"Foo": {S: ptr("a")}, "Bar": {S: ptr("1")}, "Baz": {N: ptr("1.5")}},
+		{"Foo": {S: ptr("a")}, "Bar": {S: ptr("2")}, "Baz": {L: []*dynamodb.AttributeValue{{B: []byte("x")}}}},
+		{"Foo": {S: ptr("b")}, "Bar": {S: ptr("1")}, "Baz": {SS: []*string{ptr("x"), ptr("y")}}},
+	}
+	for _, item := range items {
+		_, err = db.PutItem(&dynamodb.PutItemInput{Item: item, TableName: input.TableName})
+		require.NoError(t, err)
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, db.SaveSnapshot(&buf))
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Len(t, lines, 1+2+len(items))
+	assert.JSONEq(t, `{"Format":"fakedynamo-snapshot","Version":1}`, lines[0])
+
+	loaded := fakedynamo.NewDB()
+	require.NoError(t, loaded.LoadSnapshot(bytes.NewReader(buf.Bytes())))
+
+	for _, name := range []*string{input.TableName, other.TableName} {
+		want, err := db.DescribeTable(&dynamodb.DescribeTableInput{TableName: name})
+		require.NoError(t, err)
+		got, err := loaded.DescribeTable(&dynamodb.DescribeTableInput{TableName: name})
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+	}
+	assert.Equal(t,
+		map[string]string{"team": "storage", "env": "test"},
+		listAllTags(t, loaded, created.TableDescription.TableArn),
+	)
+	for _, name := range []*string{input.TableName, other.TableName} {
+		want, err := db.DescribeTimeToLive(&dynamodb.DescribeTimeToLiveInput{TableName: name})
+		require.NoError(t, err)
+		got, err := loaded.DescribeTimeToLive(&dynamodb.DescribeTimeToLiveInput{TableName: name})
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+	}
+	for _, arn := range []*string{created.TableDescription.TableArn, created.TableDescription.LatestStreamArn} {
+		want, err := db.GetResourcePolicy(&dynamodb.GetResourcePolicyInput{ResourceArn: arn})
+		require.NoError(t, err)
+		got, err := loaded.GetResourcePolicy(&dynamodb.GetResourcePolicyInput{ResourceArn: arn})
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+	}
+	for _, item := range items {
+		got, err := loaded.GetItem(&dynamodb.GetItemInput{
+			Key:       map[string]*dynamodb.AttributeValue{"Foo": item["Foo"], "Bar": item["Bar"]},
+			TableName: input.TableName,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, item, got.Item)
+	}
+
+	// Saving the loaded DB gives the same snapshot.
+	var again bytes.Buffer
+	require.NoError(t, loaded.SaveSnapshot(&again))
+	assert.Equal(t, buf.String(), again.String())
+}
+
+func TestDB_LoadSnapshot_Errors(t *testing.T) {
+	t.Parallel()
+	db := fakedynamo.NewDB()
+	input := exampleCreateTableInputSimplePrimaryKey()
+	_, err := db.CreateTable(input)
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	require.NoError(t, db.SaveSnapshot(&buf))
+
+	var inUse *dynamodb.ResourceInUseException
+	require.ErrorAs(t, db.LoadSnapshot(bytes.NewReader(buf.Bytes())), &inUse)
+
+	err = fakedynamo.NewDB().LoadSnapshot(strings.NewReader(`{"Format":"fakedynamo-snapshot","Version":99}`))
+	assertErrorContains(t, err, "unsupported snapshot version 99")
+
+	err = fakedynamo.NewDB().LoadSnapshot(strings.NewReader(`{"Item":{}}`))
+	assertErrorContains(t, err, "not a fakedynamo snapshot")
+
+	truncated := buf.String()[:buf.Len()-10]
+	err = fakedynamo.NewDB().LoadSnapshot(strings.NewReader(truncated))
+	require.Error(t, err)
+}
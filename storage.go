@@ -0,0 +1,245 @@
+package fakedynamo
+
+import (
+	"bytes"
+	"cmp"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/google/btree"
+)
+
+// storage holds the items of a table.
+//
+// DynamoDB's records are conceptually stored in two ways.
+//
+//   - A simple table (no sort key) is a key-value map from partition keys to
+//     records.
+//   - A composite table is a map from partition key to a list of records
+//     sorted by their sort key values.
+//
+// For simplicity, storage treats a simple table as a composite table where
+// all the sort keys are identical. Items are grouped into partitions, and
+// sorted within each partition by their sort key.
+//
+// Methods which take a key find the item with the same primary key; the key
+// may have other attributes too. The caller MUST hold the DB's lock: the
+// write lock to call Put, Delete or Snapshot, otherwise the read lock.
+type storage interface {
+	// Get returns the item with the given key.
+	Get(key avmap) (avmap, bool, error)
+	// Put stores an item, returning the item it replaced, if any.
+	Put(item avmap) (avmap, bool, error)
+	// Delete removes the item with the given key, returning it if it
+	// existed.
+	Delete(key avmap) (avmap, bool, error)
+	// AscendPartition calls f for the items in the partition with the given
+	// partition key value, in sort key order, until f returns false. If from
+	// is non-nil, iteration starts at the first item whose sort key isn't
+	// before from's. f mustn't modify the storage.
+	AscendPartition(partitionKey *dynamodb.AttributeValue, from avmap, f func(avmap) bool) error
+	// Ascend calls f for every item until f returns false, one partition at a
+	// time. Partitions are visited in the order of [keyValueString] of their
+	// partition key. f mustn't modify the storage.
+	Ascend(f func(avmap) bool) error
+	// Len counts the items.
+	Len() int
+	// Snapshot returns a copy of the storage. Later changes to either don't
+	// affect the other.
+	Snapshot() (storage, error)
+	// Close releases the storage's resources. It mustn't be used afterwards.
+	Close() error
+}
+
+// newStorage returns empty storage for a table with the given name and
+// schema, in memory or in a file as configured by [WithFileStorage].
+func (d *DB) newStorage(tableName string, schema tableSchema) (storage, error) {
+	if d.storageDir != "" {
+		return newFileStorage(d.storageDir, tableName, schema)
+	}
+	return newMemoryStorage(schema), nil
+}
+
+// memoryStorage keeps items in memory, with a B-tree for each partition.
+//
+// We store each partition separately, which more closely mimics Dynamo's
+// implementation. We previously considered storing partitions in the same
+// BTree, using a lexicographic sort on the pair (partition key, sort key).
+// But this makes it harder to implement a parallel scan.
+//
+// The partitions are themselves held in a B-tree, so that Snapshot can share
+// everything with the copy. B-trees copy their nodes lazily, as they are
+// written to. Partitions are copied when they are first written to after a
+// Snapshot: see [memoryStorage.writablePartition].
+type memoryStorage struct {
+	schema     tableSchema
+	partitions *btree.BTreeG[*memoryPartition]
+	// owner marks the partitions which this storage may write to in place.
+	// Snapshot gives both copies a new owner, as their existing partitions
+	// are now shared.
+	owner *memoryOwner
+	// cloneMu is shared by a storage and its snapshots. It serializes copying
+	// shared partitions, since cloning a B-tree writes to it, and the storages
+	// may be written to concurrently.
+	cloneMu *sync.Mutex
+}
+
+type memoryPartition struct {
+	// key is the [keyValueString] of the partition key.
+	key   string
+	items *btree.BTreeG[avmap]
+	owner *memoryOwner
+}
+
+// memoryOwner identifies a memoryStorage. It has a field so that each owner
+// is a distinct pointer.
+type memoryOwner struct{ _ byte }
+
+func newMemoryStorage(schema tableSchema) *memoryStorage {
+	return &memoryStorage{
+		schema: schema,
+		partitions: btree.NewG(4, func(a, b *memoryPartition) bool {
+			return a.key < b.key
+		}),
+		owner:   &memoryOwner{},
+		cloneMu: &sync.Mutex{},
+	}
+}
+
+// memoryPartitionKey returns a memoryPartition for looking up the partition
+// with the given partition key.
+func memoryPartitionKey(pval *dynamodb.AttributeValue) *memoryPartition {
+	return &memoryPartition{key: keyValueString(pval), items: nil, owner: nil}
+}
+
+func (s *memoryStorage) partition(pval *dynamodb.AttributeValue) *btree.BTreeG[avmap] {
+	partition, exists := s.partitions.Get(memoryPartitionKey(pval))
+	if !exists {
+		return nil
+	}
+	return partition.items
+}
+
+// writablePartition returns the partition with the given partition key,
+// creating it if create is set, and copying it if it's shared with another
+// storage. It returns nil if the partition doesn't exist and create isn't
+// set.
+func (s *memoryStorage) writablePartition(pval *dynamodb.AttributeValue, create bool) *btree.BTreeG[avmap] {
+	partition, exists := s.partitions.Get(memoryPartitionKey(pval))
+	switch {
+	case exists && partition.owner == s.owner:
+		return partition.items
+	case exists:
+		s.cloneMu.Lock()
+		items := partition.items.Clone()
+		s.cloneMu.Unlock()
+		partition = &memoryPartition{key: partition.key, items: items, owner: s.owner}
+	case create:
+		partition = &memoryPartition{
+			key:   keyValueString(pval),
+			items: btree.NewG[avmap](4, makePartitionLess(s.schema)),
+			owner: s.owner,
+		}
+	default:
+		return nil
+	}
+	s.partitions.ReplaceOrInsert(partition)
+	return partition.items
+}
+
+func (s *memoryStorage) Get(key avmap) (avmap, bool, error) {
+	partition := s.partition(key[s.schema.partition])
+	if partition == nil {
+		return nil, false, nil
+	}
+	item, exists := partition.Get(key)
+	return item, exists, nil
+}
+
+func (s *memoryStorage) Put(item avmap) (avmap, bool, error) {
+	partition := s.writablePartition(item[s.schema.partition], true)
+	previous, replaced := partition.ReplaceOrInsert(item)
+	return previous, replaced, nil
+}
+
+func (s *memoryStorage) Delete(key avmap) (avmap, bool, error) {
+	partition := s.writablePartition(key[s.schema.partition], false)
+	if partition == nil {
+		return nil, false, nil
+	}
+	previous, existed := partition.Delete(key)
+	return previous, existed, nil
+}
+
+func (s *memoryStorage) AscendPartition(pval *dynamodb.AttributeValue, from avmap, f func(avmap) bool) error {
+	partition := s.partition(pval)
+	switch {
+	case partition == nil:
+	case from == nil:
+		partition.Ascend(f)
+	default:
+		partition.AscendGreaterOrEqual(from, f)
+	}
+	return nil
+}
+
+func (s *memoryStorage) Ascend(f func(avmap) bool) error {
+	more := true
+	s.partitions.Ascend(func(partition *memoryPartition) bool {
+		partition.items.Ascend(func(item avmap) bool {
+			more = f(item)
+			return more
+		})
+		return more
+	})
+	return nil
+}
+
+func (s *memoryStorage) Len() int {
+	n := 0
+	s.partitions.Ascend(func(partition *memoryPartition) bool {
+		n += partition.items.Len()
+		return true
+	})
+	return n
+}
+
+// Snapshot takes constant time. The storage and its copy share their
+// partitions until they are written to.
+func (s *memoryStorage) Snapshot() (storage, error) {
+	s.owner = &memoryOwner{}
+	return &memoryStorage{
+		schema:     s.schema,
+		partitions: s.partitions.Clone(),
+		owner:      &memoryOwner{},
+		cloneMu:    s.cloneMu,
+	}, nil
+}
+
+func (s *memoryStorage) Close() error {
+	return nil
+}
+
+func makePartitionLess(schema tableSchema) btree.LessFunc[avmap] {
+	if schema.sort == "" {
+		return func(a, b avmap) bool {
+			return false
+		}
+	}
+
+	switch schema.types[schema.sort] {
+	case dynamodb.ScalarAttributeTypeS:
+		return func(a, b avmap) bool {
+			return cmp.Less(*a[schema.sort].S, *b[schema.sort].S)
+		}
+	case dynamodb.ScalarAttributeTypeN:
+		return func(a, b avmap) bool {
+			return cmp.Less(*a[schema.sort].N, *b[schema.sort].N)
+		}
+	case dynamodb.ScalarAttributeTypeB:
+		return func(a, b avmap) bool {
+			return bytes.Compare(a[schema.sort].B, b[schema.sort].B) < 0
+		}
+	}
+	panic("unreachable")
+}
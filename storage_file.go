@@ -0,0 +1,237 @@
+package fakedynamo
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	bolt "go.etcd.io/bbolt"
+)
+
+// errStopIteration ends an iteration over a bbolt bucket early.
+var errStopIteration = errors.New("stop iteration")
+
+// fileStorage keeps items in a bbolt file, so that they needn't fit in
+// memory. The file is temporary: it is deleted when the storage is closed.
+// For state which survives restarts, see [OpenDB].
+//
+// Each partition is a bucket, named "p" followed by [keyValueString] of the
+// partition key. Items are stored in DynamoDB JSON, keyed by "k" followed by
+// their sort key, so that bbolt's byte order matches [makePartitionLess].
+type fileStorage struct {
+	schema    tableSchema
+	tableName string
+	db        *bolt.DB
+	path      string
+	// count is the number of items. Counting on demand is slow.
+	count int
+}
+
+func newFileStorage(dir, tableName string, schema tableSchema) (*fileStorage, error) {
+	path, err := tempFilePath(dir, tableName)
+	if err != nil {
+		return nil, err
+	}
+	return openFileStorage(path, tableName, schema, 0)
+}
+
+func tempFilePath(dir, tableName string) (string, error) {
+	f, err := os.CreateTemp(dir, tableName+"-*.db")
+	if err != nil {
+		return "", fmt.Errorf("error creating storage file: %w", err)
+	}
+	return f.Name(), f.Close()
+}
+
+func openFileStorage(path, tableName string, schema tableSchema, count int) (*fileStorage, error) {
+	// Syncing would only slow writes down, since the file doesn't outlive
+	// the storage.
+	db, err := bolt.Open(path, 0o600, &bolt.Options{NoSync: true, NoFreelistSync: true}) //nolint:exhaustruct
+	if err != nil {
+		_ = os.Remove(path)
+		return nil, fmt.Errorf("error opening storage file: %w", err)
+	}
+	return &fileStorage{schema: schema, tableName: tableName, db: db, path: path, count: count}, nil
+}
+
+func partitionBucketKey(pval *dynamodb.AttributeValue) []byte {
+	return []byte("p" + keyValueString(pval))
+}
+
+func (s *fileStorage) itemKey(item avmap) []byte {
+	key := []byte("k")
+	if s.schema.sort == "" {
+		return key
+	}
+	switch v := item[s.schema.sort]; {
+	case v.S != nil:
+		return append(key, *v.S...)
+	case v.N != nil:
+		return append(key, *v.N...)
+	default:
+		return append(key, v.B...)
+	}
+}
+
+func encodeStoredItem(item avmap) ([]byte, error) {
+	return marshalDynamoDBJSON(struct{ Item avmap }{item})
+}
+
+func decodeStoredItem(encoded []byte) (avmap, error) {
+	var stored struct{ Item avmap }
+	if err := unmarshalDynamoDBJSON(encoded, &stored); err != nil {
+		return nil, err
+	}
+	return stored.Item, nil
+}
+
+func (s *fileStorage) Get(key avmap) (avmap, bool, error) {
+	var item avmap
+	err := s.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(partitionBucketKey(key[s.schema.partition]))
+		if bucket == nil {
+			return nil
+		}
+		encoded := bucket.Get(s.itemKey(key))
+		if encoded == nil {
+			return nil
+		}
+		var err error
+		item, err = decodeStoredItem(encoded)
+		return err
+	})
+	return item, item != nil, err
+}
+
+func (s *fileStorage) Put(item avmap) (avmap, bool, error) {
+	encoded, err := encodeStoredItem(item)
+	if err != nil {
+		return nil, false, err
+	}
+	var previous avmap
+	err = s.db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists(partitionBucketKey(item[s.schema.partition]))
+		if err != nil {
+			return err
+		}
+		key := s.itemKey(item)
+		if old := bucket.Get(key); old != nil {
+			if previous, err = decodeStoredItem(old); err != nil {
+				return err
+			}
+		}
+		return bucket.Put(key, encoded)
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	if previous == nil {
+		s.count++
+	}
+	return previous, previous != nil, nil
+}
+
+func (s *fileStorage) Delete(key avmap) (avmap, bool, error) {
+	var previous avmap
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(partitionBucketKey(key[s.schema.partition]))
+		if bucket == nil {
+			return nil
+		}
+		k := s.itemKey(key)
+		old := bucket.Get(k)
+		if old == nil {
+			return nil
+		}
+		var err error
+		if previous, err = decodeStoredItem(old); err != nil {
+			return err
+		}
+		return bucket.Delete(k)
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	if previous != nil {
+		s.count--
+	}
+	return previous, previous != nil, nil
+}
+
+func (s *fileStorage) AscendPartition(pval *dynamodb.AttributeValue, from avmap, f func(avmap) bool) error {
+	err := s.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(partitionBucketKey(pval))
+		if bucket == nil {
+			return nil
+		}
+		return ascendBucket(bucket, from, s, f)
+	})
+	if errors.Is(err, errStopIteration) {
+		return nil
+	}
+	return err
+}
+
+func (s *fileStorage) Ascend(f func(avmap) bool) error {
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.ForEach(func(_ []byte, bucket *bolt.Bucket) error {
+			return ascendBucket(bucket, nil, s, f)
+		})
+	})
+	if errors.Is(err, errStopIteration) {
+		return nil
+	}
+	return err
+}
+
+// ascendBucket calls f for the items in a partition's bucket, starting from
+// the given item if non-nil. It returns errStopIteration if f returns false.
+func ascendBucket(bucket *bolt.Bucket, from avmap, s *fileStorage, f func(avmap) bool) error {
+	cursor := bucket.Cursor()
+	var encoded []byte
+	if from == nil {
+		_, encoded = cursor.First()
+	} else {
+		_, encoded = cursor.Seek(s.itemKey(from))
+	}
+	for ; encoded != nil; _, encoded = cursor.Next() {
+		item, err := decodeStoredItem(encoded)
+		if err != nil {
+			return err
+		}
+		if !f(item) {
+			return errStopIteration
+		}
+	}
+	return nil
+}
+
+func (s *fileStorage) Len() int {
+	return s.count
+}
+
+// Snapshot copies the file.
+func (s *fileStorage) Snapshot() (storage, error) {
+	path, err := tempFilePath(filepath.Dir(s.path), s.tableName)
+	if err != nil {
+		return nil, err
+	}
+	err = s.db.View(func(tx *bolt.Tx) error {
+		return tx.CopyFile(path, 0o600)
+	})
+	if err != nil {
+		_ = os.Remove(path)
+		return nil, fmt.Errorf("error copying storage file: %w", err)
+	}
+	return openFileStorage(path, s.tableName, s.schema, s.count)
+}
+
+func (s *fileStorage) Close() error {
+	err := s.db.Close()
+	if removeErr := os.Remove(s.path); err == nil {
+		err = removeErr
+	}
+	return err
+}
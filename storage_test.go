@@ -0,0 +1,102 @@
+package fakedynamo_test
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/DMRobertson/fakedynamo"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithFileStorage_ItemOperations(t *testing.T) {
+	t.Parallel()
+	db := fakedynamo.NewDB(fakedynamo.WithFileStorage(t.TempDir()))
+	defer db.Close()
+	input := exampleCreateTableInputCompositePrimaryKey()
+	_, err := db.CreateTable(input)
+	require.NoError(t, err)
+
+	item := map[string]*dynamodb.AttributeValue{
+		"Foo": {S: ptr("a")},
+		"Bar": {S: ptr("1")},
+		"Baz": {M: map[string]*dynamodb.AttributeValue{"Qux": {N: ptr("2")}}},
+	}
+	key := map[string]*dynamodb.AttributeValue{"Foo": item["Foo"], "Bar": item["Bar"]}
+	_, err = db.PutItem(&dynamodb.PutItemInput{Item: item, TableName: input.TableName})
+	require.NoError(t, err)
+
+	got, err := db.GetItem(&dynamodb.GetItemInput{Key: key, TableName: input.TableName})
+	require.NoError(t, err)
+	assert.Equal(t, item, got.Item)
+
+	deleted, err := db.DeleteItem(&dynamodb.DeleteItemInput{
+		Key:          key,
+		ReturnValues: ptr(dynamodb.ReturnValueAllOld),
+		TableName:    input.TableName,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, item, deleted.Attributes)
+
+	got, err = db.GetItem(&dynamodb.GetItemInput{Key: key, TableName: input.TableName})
+	require.NoError(t, err)
+	assert.Nil(t, got.Item)
+}
+
+func TestWithFileStorage_MatchesMemoryStorage(t *testing.T) {
+	t.Parallel()
+	memory := fakedynamo.NewDB()
+	input := exampleCreateTableInputCompositePrimaryKey()
+	_, err := memory.CreateTable(input)
+	require.NoError(t, err)
+	for _, item := range []map[string]*dynamodb.AttributeValue{
+		{"Foo": {S: ptr("b")}, "Bar": {S: ptr("2")}},
+		{"Foo": {S: ptr("a")}, "Bar": {S: ptr("3")}},
+		{"Foo": {S: ptr("b")}, "Bar": {S: ptr("1")}},
+		{"Foo": {S: ptr("a")}, "Bar": {S: ptr("10")}},
+	} {
+		_, err = memory.PutItem(&dynamodb.PutItemInput{Item: item, TableName: input.TableName})
+		require.NoError(t, err)
+	}
+	var want bytes.Buffer
+	require.NoError(t, memory.SaveSnapshot(&want))
+
+	// Snapshots list items in storage order, so they only match if both
+	// engines order items the same way.
+	file := fakedynamo.NewDB(fakedynamo.WithFileStorage(t.TempDir()))
+	defer file.Close()
+	require.NoError(t, file.LoadSnapshot(bytes.NewReader(want.Bytes())))
+	var got bytes.Buffer
+	require.NoError(t, file.SaveSnapshot(&got))
+	assert.Equal(t, want.String(), got.String())
+}
+
+func TestWithFileStorage_RemovesFiles(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	db := fakedynamo.NewDB(fakedynamo.WithFileStorage(dir))
+	kept := exampleCreateTableInputSimplePrimaryKey()
+	_, err := db.CreateTable(kept)
+	require.NoError(t, err)
+	dropped := exampleCreateTableInputSimplePrimaryKey()
+	_, err = db.CreateTable(dropped)
+	require.NoError(t, err)
+	putFoo(t, db, kept.TableName, "a")
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+
+	_, err = db.DeleteTable(&dynamodb.DeleteTableInput{TableName: dropped.TableName})
+	require.NoError(t, err)
+	entries, err = os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	require.NoError(t, db.Close())
+	entries, err = os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
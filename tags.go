@@ -0,0 +1,250 @@
+package fakedynamo
+
+import (
+	"errors"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+const (
+	// maxTagsPerResource is the number of tags a single resource may have.
+	maxTagsPerResource = 50
+	// tagsPageSize is the number of tags returned by each ListTagsOfResource
+	// call. DynamoDB doesn't document its page size; we choose a small value
+	// so that callers' pagination gets exercised.
+	tagsPageSize = 10
+)
+
+// tagCharacters matches the characters permitted in tag keys and values, see
+// https://docs.aws.amazon.com/amazondynamodb/latest/developerguide/Tagging.html#TaggingRestrictions
+var tagCharacters = regexp.MustCompile(`^[\p{L}\p{Z}\p{N}_.:/=+\-@]*$`)
+
+func validateTags(tags []*dynamodb.Tag, fieldName string) error {
+	var errs []error
+	for i, tag := range tags {
+		if tag == nil {
+			errs = append(errs, newValidationErrorf("%s[%d] is nil", fieldName, i))
+			continue
+		}
+		errs = append(errs, validateTagKey(tag.Key, fieldName, i))
+
+		if tag.Value == nil {
+			errs = append(errs, newValidationErrorf("%s[%d].Value is a required field", fieldName, i))
+		} else if len([]rune(*tag.Value)) > 256 {
+			errs = append(errs, newValidationErrorf("%s[%d].Value must be at most 256 characters", fieldName, i))
+		} else if !tagCharacters.MatchString(*tag.Value) {
+			errs = append(errs, newValidationErrorf("%s[%d].Value contains invalid characters", fieldName, i))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func validateTagKey(key *string, fieldName string, index int) error {
+	switch {
+	case key == nil:
+		return newValidationErrorf("%s[%d].Key is a required field", fieldName, index)
+	case len([]rune(*key)) < 1 || len([]rune(*key)) > 128:
+		return newValidationErrorf("%s[%d].Key must be between 1 and 128 characters", fieldName, index)
+	case !tagCharacters.MatchString(*key):
+		return newValidationErrorf("%s[%d].Key contains invalid characters", fieldName, index)
+	case strings.HasPrefix(strings.ToLower(*key), "aws:"):
+		return newValidationErrorf("%s[%d].Key uses the reserved prefix aws:", fieldName, index)
+	}
+	return nil
+}
+
+// applyTags merges the given tags into existing, enforcing the per-resource
+// limit. existing is only modified if the limit is respected.
+func applyTags(existing map[string]string, tags []*dynamodb.Tag) error {
+	merged := make(map[string]string, len(existing)+len(tags))
+	for key, value := range existing {
+		merged[key] = value
+	}
+	for _, tag := range tags {
+		merged[*tag.Key] = *tag.Value
+	}
+	if len(merged) > maxTagsPerResource {
+		return &dynamodb.LimitExceededException{
+			Message_: aws.String("a resource may have at most 50 tags"),
+		}
+	}
+
+	for key, value := range merged {
+		existing[key] = value
+	}
+	return nil
+}
+
+// tableFromArn finds the table with the given ARN. The caller MUST ensure
+// that mu is Locked or RLocked appropriately.
+func (d *DB) tableFromArn(arn string) (*table, bool) {
+	name, ok := d.tableNameFromArn(arn)
+	if !ok || d.tableArn(name) != arn {
+		return nil, false
+	}
+	return d.tables.Get(tableKey(name))
+}
+
+func (d *DB) TagResource(input *dynamodb.TagResourceInput) (*dynamodb.TagResourceOutput, error) {
+	return d.tagResourceContext(aws.BackgroundContext(), input)
+}
+
+func (d *DB) tagResourceContext(ctx aws.Context, input *dynamodb.TagResourceInput) (*dynamodb.TagResourceOutput, error) {
+	if err := d.injectFault(ctx, "TagResource", input); err != nil {
+		return nil, err
+	}
+
+	var errs []error
+	if input.ResourceArn == nil {
+		errs = append(errs, newValidationError("ResourceArn is a required field"))
+	}
+	if input.Tags == nil {
+		errs = append(errs, newValidationError("Tags is a required field"))
+	}
+	errs = append(errs, validateTags(input.Tags, "Tags"))
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	t, exists := d.tableFromArn(*input.ResourceArn)
+	if !exists {
+		return nil, &dynamodb.ResourceNotFoundException{}
+	}
+	err := d.changeTable(t, func(updated *table) error {
+		updated.tags = maps.Clone(t.tags)
+		return applyTags(updated.tags, input.Tags)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &dynamodb.TagResourceOutput{}, nil
+}
+
+func (d *DB) TagResourceWithContext(ctx aws.Context, input *dynamodb.TagResourceInput, opts ...request.Option) (*dynamodb.TagResourceOutput, error) {
+	req, output := d.TagResourceRequest(input)
+	req.SetContext(ctx)
+	req.ApplyOptions(opts...)
+	return output, req.Send()
+}
+
+func (d *DB) TagResourceRequest(input *dynamodb.TagResourceInput) (*request.Request, *dynamodb.TagResourceOutput) {
+	return newContextRequest(d, "TagResource", input, d.tagResourceContext)
+}
+
+func (d *DB) UntagResource(input *dynamodb.UntagResourceInput) (*dynamodb.UntagResourceOutput, error) {
+	return d.untagResourceContext(aws.BackgroundContext(), input)
+}
+
+func (d *DB) untagResourceContext(ctx aws.Context, input *dynamodb.UntagResourceInput) (*dynamodb.UntagResourceOutput, error) {
+	if err := d.injectFault(ctx, "UntagResource", input); err != nil {
+		return nil, err
+	}
+
+	var errs []error
+	if input.ResourceArn == nil {
+		errs = append(errs, newValidationError("ResourceArn is a required field"))
+	}
+	if input.TagKeys == nil {
+		errs = append(errs, newValidationError("TagKeys is a required field"))
+	}
+	for i, key := range input.TagKeys {
+		errs = append(errs, validateTagKey(key, "TagKeys", i))
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	t, exists := d.tableFromArn(*input.ResourceArn)
+	if !exists {
+		return nil, &dynamodb.ResourceNotFoundException{}
+	}
+	err := d.changeTable(t, func(updated *table) error {
+		updated.tags = maps.Clone(t.tags)
+		for _, key := range input.TagKeys {
+			delete(updated.tags, *key)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &dynamodb.UntagResourceOutput{}, nil
+}
+
+func (d *DB) UntagResourceWithContext(ctx aws.Context, input *dynamodb.UntagResourceInput, opts ...request.Option) (*dynamodb.UntagResourceOutput, error) {
+	req, output := d.UntagResourceRequest(input)
+	req.SetContext(ctx)
+	req.ApplyOptions(opts...)
+	return output, req.Send()
+}
+
+func (d *DB) UntagResourceRequest(input *dynamodb.UntagResourceInput) (*request.Request, *dynamodb.UntagResourceOutput) {
+	return newContextRequest(d, "UntagResource", input, d.untagResourceContext)
+}
+
+// ListTagsOfResource returns the resource's tags sorted by key, in pages of
+// at most 10 tags.
+func (d *DB) ListTagsOfResource(input *dynamodb.ListTagsOfResourceInput) (*dynamodb.ListTagsOfResourceOutput, error) {
+	return d.listTagsOfResourceContext(aws.BackgroundContext(), input)
+}
+
+func (d *DB) listTagsOfResourceContext(ctx aws.Context, input *dynamodb.ListTagsOfResourceInput) (*dynamodb.ListTagsOfResourceOutput, error) {
+	if err := d.injectFault(ctx, "ListTagsOfResource", input); err != nil {
+		return nil, err
+	}
+
+	if input.ResourceArn == nil {
+		return nil, newValidationError("ResourceArn is a required field")
+	}
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	t, exists := d.tableFromArn(*input.ResourceArn)
+	if !exists {
+		return nil, &dynamodb.ResourceNotFoundException{}
+	}
+
+	keys := make([]string, 0, len(t.tags))
+	for key := range t.tags {
+		if input.NextToken == nil || key > *input.NextToken {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+
+	output := &dynamodb.ListTagsOfResourceOutput{
+		Tags: []*dynamodb.Tag{},
+	}
+	if len(keys) > tagsPageSize {
+		keys = keys[:tagsPageSize]
+		output.NextToken = ptr(keys[len(keys)-1])
+	}
+	for _, key := range keys {
+		output.Tags = append(output.Tags, &dynamodb.Tag{
+			Key:   ptr(key),
+			Value: ptr(t.tags[key]),
+		})
+	}
+	return output, nil
+}
+
+func (d *DB) ListTagsOfResourceWithContext(ctx aws.Context, input *dynamodb.ListTagsOfResourceInput, opts ...request.Option) (*dynamodb.ListTagsOfResourceOutput, error) {
+	req, output := d.ListTagsOfResourceRequest(input)
+	req.SetContext(ctx)
+	req.ApplyOptions(opts...)
+	return output, req.Send()
+}
+
+func (d *DB) ListTagsOfResourceRequest(input *dynamodb.ListTagsOfResourceInput) (*request.Request, *dynamodb.ListTagsOfResourceOutput) {
+	return newContextRequest(d, "ListTagsOfResource", input, d.listTagsOfResourceContext)
+}
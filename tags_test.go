@@ -0,0 +1,105 @@
+package fakedynamo_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func listAllTags(t *testing.T, db dynamodbiface.DynamoDBAPI, arn *string) map[string]string {
+	t.Helper()
+	tags := map[string]string{}
+	input := &dynamodb.ListTagsOfResourceInput{ResourceArn: arn}
+	for {
+		output, err := db.ListTagsOfResource(input)
+		require.NoError(t, err)
+		for _, tag := range output.Tags {
+			tags[val(tag.Key)] = val(tag.Value)
+		}
+		if output.NextToken == nil {
+			return tags
+		}
+		input.NextToken = output.NextToken
+	}
+}
+
+func TestDB_TagResource_HappyPath(t *testing.T) {
+	t.Parallel()
+	db := makeTestDB(t)
+	input := exampleCreateTableInputSimplePrimaryKey()
+	input.Tags = []*dynamodb.Tag{{Key: ptr("team"), Value: ptr("storage")}}
+	created, err := db.CreateTable(input)
+	require.NoError(t, err)
+	arn := created.TableDescription.TableArn
+
+	assert.Equal(t, map[string]string{"team": "storage"}, listAllTags(t, db, arn))
+
+	var tags []*dynamodb.Tag
+	expected := map[string]string{"team": "storage"}
+	for i := range 25 {
+		key, value := fmt.Sprintf("key-%02d", i), fmt.Sprintf("value %d", i)
+		tags = append(tags, &dynamodb.Tag{Key: &key, Value: &value})
+		expected[key] = value
+	}
+	_, err = db.TagResource(&dynamodb.TagResourceInput{ResourceArn: arn, Tags: tags})
+	require.NoError(t, err)
+	assert.Equal(t, expected, listAllTags(t, db, arn))
+
+	_, err = db.UntagResource(&dynamodb.UntagResourceInput{
+		ResourceArn: arn,
+		TagKeys:     []*string{ptr("team"), ptr("not-present")},
+	})
+	require.NoError(t, err)
+	delete(expected, "team")
+	assert.Equal(t, expected, listAllTags(t, db, arn))
+}
+
+func TestDB_TagResource_Errors(t *testing.T) {
+	t.Parallel()
+	if dynamodbSession != nil {
+		t.Skip("DynamoDB Local doesn't enforce tag restrictions")
+	}
+	db := makeTestDB(t)
+	created, err := db.CreateTable(exampleCreateTableInputSimplePrimaryKey())
+	require.NoError(t, err)
+	arn := created.TableDescription.TableArn
+
+	_, err = db.TagResource(&dynamodb.TagResourceInput{
+		ResourceArn: ptr(*arn + "-missing"),
+		Tags:        []*dynamodb.Tag{{Key: ptr("a"), Value: ptr("b")}},
+	})
+	var notFoundErr *dynamodb.ResourceNotFoundException
+	require.ErrorAs(t, err, &notFoundErr)
+
+	_, err = db.ListTagsOfResource(&dynamodb.ListTagsOfResourceInput{ResourceArn: ptr(*arn + "-missing")})
+	require.ErrorAs(t, err, &notFoundErr)
+
+	_, err = db.TagResource(&dynamodb.TagResourceInput{
+		ResourceArn: arn,
+		Tags:        []*dynamodb.Tag{{Key: ptr("AWS:reserved"), Value: ptr("b")}},
+	})
+	assertErrorContains(t, err, "ValidationException", "aws:")
+
+	_, err = db.TagResource(&dynamodb.TagResourceInput{
+		ResourceArn: arn,
+		Tags: []*dynamodb.Tag{
+			{Key: ptr(strings.Repeat("k", 129)), Value: ptr("b")},
+			{Key: ptr("k"), Value: ptr(strings.Repeat("v", 257))},
+		},
+	})
+	assertErrorContains(t, err, "ValidationException", "128", "256")
+
+	var tags []*dynamodb.Tag
+	for i := range 51 {
+		tags = append(tags, &dynamodb.Tag{Key: ptr(fmt.Sprintf("key-%d", i)), Value: ptr("")})
+	}
+	_, err = db.TagResource(&dynamodb.TagResourceInput{ResourceArn: arn, Tags: tags})
+	var limitErr *dynamodb.LimitExceededException
+	require.ErrorAs(t, err, &limitErr)
+	assert.Empty(t, listAllTags(t, db, arn))
+}
@@ -0,0 +1,610 @@
+package fakedynamo
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/ext/dynblock"
+	"github.com/hashicorp/hcl/v2/ext/typeexpr"
+	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+	"github.com/zclconf/go-cty/cty/function"
+	"github.com/zclconf/go-cty/cty/function/stdlib"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+)
+
+// Terraform configurations
+//
+// The Terraform loaders create a table for each aws_dynamodb_table resource,
+// translating its key schema, attributes, billing mode and capacity, global
+// and local secondary indexes, stream, server-side encryption, table class,
+// deletion protection, tags, TTL and point-in-time recovery. Other
+// attributes, and other resources, are ignored.
+//
+// In configuration files, expressions may refer to input variables, locals,
+// count.index and each.key/each.value, and call common functions like format
+// and lower. References to other resources, data sources and modules aren't
+// supported. Dynamic blocks are expanded.
+
+// TerraformOptions configures the Terraform loaders.
+type TerraformOptions struct {
+	// Variables sets input variables, overriding their defaults. Values are
+	// converted as they would be from JSON, so may be strings, numbers,
+	// booleans, slices or maps.
+	Variables map[string]any
+}
+
+const tfTableType = "aws_dynamodb_table"
+
+// LoadTerraform creates the tables declared by the Terraform configuration
+// at path, which is a directory of .tf and .tf.json files, a single such file,
+// or a JSON file written by "terraform show -json", of either a state or a
+// plan. It returns a map from the resources' addresses, such as
+// aws_dynamodb_table.orders, to their tables' names.
+//
+// Errors in configuration files are *[FixtureError]s. Loading stops at the
+// first error, keeping the tables created before it.
+func (d *DB) LoadTerraform(path string, opts TerraformOptions) (map[string]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	switch {
+	case info.IsDir():
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			if isTerraformFile(entry.Name()) && !entry.IsDir() {
+				paths = append(paths, filepath.Join(path, entry.Name()))
+			}
+		}
+	case isTerraformFile(path):
+		paths = []string{path}
+	default:
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		return d.LoadTerraformShow(path, f)
+	}
+
+	parser := hclparse.NewParser()
+	var files []*hcl.File
+	for _, path := range paths {
+		var file *hcl.File
+		var diags hcl.Diagnostics
+		if strings.HasSuffix(path, ".json") {
+			file, diags = parser.ParseJSONFile(path)
+		} else {
+			file, diags = parser.ParseHCLFile(path)
+		}
+		if diags.HasErrors() {
+			return nil, diagnosticsError(diags)
+		}
+		files = append(files, file)
+	}
+	return d.loadTerraformFiles(files, opts)
+}
+
+func isTerraformFile(path string) bool {
+	return strings.HasSuffix(path, ".tf") || strings.HasSuffix(path, ".tf.json")
+}
+
+// LoadTerraformConfig creates the tables declared by a single Terraform
+// configuration file, in native syntax or, if name ends in .json, in JSON
+// syntax. See [DB.LoadTerraform].
+func (d *DB) LoadTerraformConfig(name string, src []byte, opts TerraformOptions) (map[string]string, error) {
+	parser := hclparse.NewParser()
+	var file *hcl.File
+	var diags hcl.Diagnostics
+	if strings.HasSuffix(name, ".json") {
+		file, diags = parser.ParseJSON(src, name)
+	} else {
+		file, diags = parser.ParseHCL(src, name)
+	}
+	if diags.HasErrors() {
+		return nil, diagnosticsError(diags)
+	}
+	return d.loadTerraformFiles([]*hcl.File{file}, opts)
+}
+
+// diagnosticsError converts the first error in diags to a [FixtureError].
+func diagnosticsError(diags hcl.Diagnostics) error {
+	for _, diag := range diags {
+		if diag.Severity != hcl.DiagError {
+			continue
+		}
+		message := diag.Summary
+		if diag.Detail != "" {
+			message += ": " + diag.Detail
+		}
+		if diag.Subject == nil {
+			return errors.New(message)
+		}
+		return &FixtureError{
+			Name:   diag.Subject.Filename,
+			Line:   diag.Subject.Start.Line,
+			Column: diag.Subject.Start.Column,
+			Err:    errors.New(message),
+		}
+	}
+	return nil
+}
+
+func rangeError(r hcl.Range, err error) error {
+	return &FixtureError{Name: r.Filename, Line: r.Start.Line, Column: r.Start.Column, Err: err}
+}
+
+var tfFileSchema = &hcl.BodySchema{
+	Attributes: nil,
+	Blocks: []hcl.BlockHeaderSchema{
+		{Type: "resource", LabelNames: []string{"type", "name"}},
+		{Type: "variable", LabelNames: []string{"name"}},
+		{Type: "locals", LabelNames: nil},
+	},
+}
+
+// tfFunctions are the Terraform functions available to expressions.
+var tfFunctions = map[string]function.Function{
+	"coalesce":   stdlib.CoalesceFunc,
+	"concat":     stdlib.ConcatFunc,
+	"format":     stdlib.FormatFunc,
+	"join":       stdlib.JoinFunc,
+	"lookup":     stdlib.LookupFunc,
+	"lower":      stdlib.LowerFunc,
+	"merge":      stdlib.MergeFunc,
+	"replace":    stdlib.ReplaceFunc,
+	"split":      stdlib.SplitFunc,
+	"title":      stdlib.TitleFunc,
+	"tomap":      stdlib.MakeToFunc(cty.Map(cty.DynamicPseudoType)),
+	"tonumber":   stdlib.MakeToFunc(cty.Number),
+	"toset":      stdlib.MakeToFunc(cty.Set(cty.DynamicPseudoType)),
+	"tostring":   stdlib.MakeToFunc(cty.String),
+	"trimprefix": stdlib.TrimPrefixFunc,
+	"trimsuffix": stdlib.TrimSuffixFunc,
+	"trimspace":  stdlib.TrimSpaceFunc,
+	"upper":      stdlib.UpperFunc,
+}
+
+func (d *DB) loadTerraformFiles(files []*hcl.File, opts TerraformOptions) (map[string]string, error) {
+	var resources, variables []*hcl.Block
+	var locals []*hcl.Attribute
+	for _, file := range files {
+		content, _, diags := file.Body.PartialContent(tfFileSchema)
+		if diags.HasErrors() {
+			return nil, diagnosticsError(diags)
+		}
+		for _, block := range content.Blocks {
+			switch block.Type {
+			case "resource":
+				if block.Labels[0] == tfTableType {
+					resources = append(resources, block)
+				}
+			case "variable":
+				variables = append(variables, block)
+			case "locals":
+				attrs, diags := block.Body.JustAttributes()
+				if diags.HasErrors() {
+					return nil, diagnosticsError(diags)
+				}
+				// Keep the locals in order, so that errors are reported
+				// consistently.
+				sorted := slices.SortedFunc(maps.Values(attrs), func(a, b *hcl.Attribute) int {
+					return cmp.Compare(a.Range.Start.Byte, b.Range.Start.Byte)
+				})
+				locals = append(locals, sorted...)
+			}
+		}
+	}
+
+	ctx, err := tfEvalContext(variables, locals, opts)
+	if err != nil {
+		return nil, err
+	}
+	tables := map[string]string{}
+	for _, block := range resources {
+		if err := d.loadTerraformResource(ctx, block, tables); err != nil {
+			return nil, err
+		}
+	}
+	return tables, nil
+}
+
+// tfEvalContext builds the context for evaluating resources, holding the
+// input variables and locals.
+func tfEvalContext(variables []*hcl.Block, locals []*hcl.Attribute, opts TerraformOptions) (*hcl.EvalContext, error) {
+	var err error
+	vars := map[string]cty.Value{}
+	types := map[string]cty.Type{}
+	for _, block := range variables {
+		name := block.Labels[0]
+		content, _, diags := block.Body.PartialContent(&hcl.BodySchema{
+			Attributes: []hcl.AttributeSchema{
+				{Name: "default", Required: false},
+				{Name: "type", Required: false},
+			},
+			Blocks: nil,
+		})
+		if diags.HasErrors() {
+			return nil, diagnosticsError(diags)
+		}
+		types[name] = cty.DynamicPseudoType
+		if typ, ok := content.Attributes["type"]; ok {
+			if types[name], diags = typeexpr.TypeConstraint(typ.Expr); diags.HasErrors() {
+				return nil, diagnosticsError(diags)
+			}
+		}
+		if def, ok := content.Attributes["default"]; ok {
+			value, diags := def.Expr.Value(nil)
+			if diags.HasErrors() {
+				return nil, diagnosticsError(diags)
+			}
+			if vars[name], err = convert.Convert(value, types[name]); err != nil {
+				return nil, rangeError(def.Range, err)
+			}
+		}
+	}
+	for name, v := range opts.Variables {
+		value, err := ctyFromGo(v)
+		if err == nil {
+			value, err = convert.Convert(value, cmp.Or(types[name], cty.DynamicPseudoType))
+		}
+		if err != nil {
+			return nil, fmt.Errorf("variable %s: %w", name, err)
+		}
+		vars[name] = value
+	}
+
+	ctx := &hcl.EvalContext{
+		Variables: map[string]cty.Value{
+			"var":   cty.ObjectVal(vars),
+			"local": cty.EmptyObjectVal,
+		},
+		Functions: tfFunctions,
+	}
+
+	// Locals may refer to each other, so evaluate them in rounds until none
+	// are left, or none can be evaluated.
+	values := map[string]cty.Value{}
+	for len(locals) > 0 {
+		var pending []*hcl.Attribute
+		var firstDiags hcl.Diagnostics
+		for _, attr := range locals {
+			value, diags := attr.Expr.Value(ctx)
+			if diags.HasErrors() {
+				pending = append(pending, attr)
+				if firstDiags == nil {
+					firstDiags = diags
+				}
+				continue
+			}
+			values[attr.Name] = value
+		}
+		if len(pending) == len(locals) {
+			return nil, diagnosticsError(firstDiags)
+		}
+		ctx.Variables["local"] = cty.ObjectVal(values)
+		locals = pending
+	}
+	return ctx, nil
+}
+
+// ctyFromGo converts a value as if it had been decoded from JSON.
+func ctyFromGo(v any) (cty.Value, error) {
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		return cty.NilVal, err
+	}
+	typ, err := ctyjson.ImpliedType(encoded)
+	if err != nil {
+		return cty.NilVal, err
+	}
+	return ctyjson.Unmarshal(encoded, typ)
+}
+
+// tfTable holds the attributes of an aws_dynamodb_table which we translate,
+// as they appear in configuration files and in "terraform show -json".
+type tfTable struct {
+	Name                      string            `hcl:"name"                                json:"name"`
+	HashKey                   string            `hcl:"hash_key"                            json:"hash_key"`
+	RangeKey                  string            `hcl:"range_key,optional"                  json:"range_key"`
+	BillingMode               string            `hcl:"billing_mode,optional"               json:"billing_mode"`
+	ReadCapacity              int64             `hcl:"read_capacity,optional"              json:"read_capacity"`
+	WriteCapacity             int64             `hcl:"write_capacity,optional"             json:"write_capacity"`
+	Attributes                []tfAttribute     `hcl:"attribute,block"                     json:"attribute"`
+	GlobalSecondaryIndexes    []tfIndex         `hcl:"global_secondary_index,block"        json:"global_secondary_index"`
+	LocalSecondaryIndexes     []tfIndex         `hcl:"local_secondary_index,block"         json:"local_secondary_index"`
+	TTL                       []tfTTL           `hcl:"ttl,block"                           json:"ttl"`
+	StreamEnabled             bool              `hcl:"stream_enabled,optional"             json:"stream_enabled"`
+	StreamViewType            string            `hcl:"stream_view_type,optional"           json:"stream_view_type"`
+	PointInTimeRecovery       []tfEnabled       `hcl:"point_in_time_recovery,block"        json:"point_in_time_recovery"`
+	ServerSideEncryption      []tfEncryption    `hcl:"server_side_encryption,block"        json:"server_side_encryption"`
+	TableClass                string            `hcl:"table_class,optional"                json:"table_class"`
+	DeletionProtectionEnabled bool              `hcl:"deletion_protection_enabled,optional" json:"deletion_protection_enabled"`
+	Tags                      map[string]string `hcl:"tags,optional"                       json:"tags"`
+	Remain                    hcl.Body          `hcl:",remain"                             json:"-"`
+}
+
+type tfAttribute struct {
+	Name   string   `hcl:"name"    json:"name"`
+	Type   string   `hcl:"type"    json:"type"`
+	Remain hcl.Body `hcl:",remain" json:"-"`
+}
+
+type tfIndex struct {
+	Name             string   `hcl:"name"                        json:"name"`
+	HashKey          string   `hcl:"hash_key,optional"           json:"hash_key"`
+	RangeKey         string   `hcl:"range_key,optional"          json:"range_key"`
+	ProjectionType   string   `hcl:"projection_type"             json:"projection_type"`
+	NonKeyAttributes []string `hcl:"non_key_attributes,optional" json:"non_key_attributes"`
+	ReadCapacity     int64    `hcl:"read_capacity,optional"      json:"read_capacity"`
+	WriteCapacity    int64    `hcl:"write_capacity,optional"     json:"write_capacity"`
+	Remain           hcl.Body `hcl:",remain"                     json:"-"`
+}
+
+type tfTTL struct {
+	AttributeName string   `hcl:"attribute_name,optional" json:"attribute_name"`
+	Enabled       bool     `hcl:"enabled,optional"        json:"enabled"`
+	Remain        hcl.Body `hcl:",remain"                 json:"-"`
+}
+
+type tfEnabled struct {
+	Enabled bool     `hcl:"enabled"  json:"enabled"`
+	Remain  hcl.Body `hcl:",remain" json:"-"`
+}
+
+type tfEncryption struct {
+	Enabled   bool     `hcl:"enabled"              json:"enabled"`
+	KMSKeyARN string   `hcl:"kms_key_arn,optional" json:"kms_key_arn"`
+	Remain    hcl.Body `hcl:",remain"              json:"-"`
+}
+
+// loadTerraformResource creates the tables for each instance of an
+// aws_dynamodb_table resource, recording their names in tables.
+func (d *DB) loadTerraformResource(ctx *hcl.EvalContext, block *hcl.Block, tables map[string]string) error {
+	content, body, diags := block.Body.PartialContent(&hcl.BodySchema{
+		Attributes: []hcl.AttributeSchema{
+			{Name: "count", Required: false},
+			{Name: "for_each", Required: false},
+		},
+		Blocks: nil,
+	})
+	if diags.HasErrors() {
+		return diagnosticsError(diags)
+	}
+	address := block.Labels[0] + "." + block.Labels[1]
+
+	// instances maps each instance's address to its context.
+	instances := map[string]*hcl.EvalContext{}
+	var addresses []string
+	addInstance := func(address string, name string, value cty.Value) {
+		child := ctx.NewChild()
+		child.Variables = map[string]cty.Value{name: value}
+		instances[address] = child
+		addresses = append(addresses, address)
+	}
+	switch {
+	case content.Attributes["count"] != nil:
+		var count int
+		if diags := gohcl.DecodeExpression(content.Attributes["count"].Expr, ctx, &count); diags.HasErrors() {
+			return diagnosticsError(diags)
+		}
+		for i := range count {
+			addInstance(fmt.Sprintf("%s[%d]", address, i), "count",
+				cty.ObjectVal(map[string]cty.Value{"index": cty.NumberIntVal(int64(i))}))
+		}
+	case content.Attributes["for_each"] != nil:
+		expr := content.Attributes["for_each"].Expr
+		forEach, diags := expr.Value(ctx)
+		if diags.HasErrors() {
+			return diagnosticsError(diags)
+		}
+		if !forEach.CanIterateElements() {
+			return rangeError(expr.Range(), errors.New("for_each must be a map or set"))
+		}
+		for it := forEach.ElementIterator(); it.Next(); {
+			key, value := it.Element()
+			if forEach.Type().IsSetType() {
+				key = value
+			}
+			if key.Type() != cty.String {
+				return rangeError(expr.Range(), errors.New("for_each must be a map, or a set of strings"))
+			}
+			addInstance(fmt.Sprintf("%s[%q]", address, key.AsString()), "each",
+				cty.ObjectVal(map[string]cty.Value{"key": key, "value": value}))
+		}
+	default:
+		instances[address] = ctx
+		addresses = append(addresses, address)
+	}
+
+	for _, instance := range addresses {
+		instanceCtx := instances[instance]
+		var t tfTable
+		expanded := dynblock.Expand(body, instanceCtx)
+		if diags := gohcl.DecodeBody(expanded, instanceCtx, &t); diags.HasErrors() {
+			return diagnosticsError(diags)
+		}
+		if err := d.createTerraformTable(&t); err != nil {
+			return rangeError(block.DefRange, err)
+		}
+		tables[instance] = t.Name
+	}
+	return nil
+}
+
+// LoadTerraformShow creates the tables in the JSON written by "terraform show
+// -json", from either a state or a plan. See [DB.LoadTerraform].
+func (d *DB) LoadTerraformShow(name string, r io.Reader) (map[string]string, error) {
+	var show struct {
+		Values        *tfShowValues `json:"values"`
+		PlannedValues *tfShowValues `json:"planned_values"`
+	}
+	if err := json.NewDecoder(r).Decode(&show); err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	values := show.Values
+	if values == nil {
+		values = show.PlannedValues
+	}
+	if values == nil {
+		return nil, fmt.Errorf("%s: neither values nor planned_values are present", name)
+	}
+
+	tables := map[string]string{}
+	modules := []*tfShowModule{&values.RootModule}
+	for len(modules) > 0 {
+		module := modules[0]
+		modules = append(modules[1:], module.ChildModules...)
+		for _, resource := range module.Resources {
+			if resource.Mode != "managed" || resource.Type != tfTableType {
+				continue
+			}
+			var t tfTable
+			if err := json.Unmarshal(resource.Values, &t); err != nil {
+				return nil, fmt.Errorf("%s: %s: %w", name, resource.Address, err)
+			}
+			if err := d.createTerraformTable(&t); err != nil {
+				return nil, fmt.Errorf("%s: %s: %w", name, resource.Address, err)
+			}
+			tables[resource.Address] = t.Name
+		}
+	}
+	return tables, nil
+}
+
+type tfShowValues struct {
+	RootModule tfShowModule `json:"root_module"`
+}
+
+type tfShowModule struct {
+	Resources []struct {
+		Address string          `json:"address"`
+		Mode    string          `json:"mode"`
+		Type    string          `json:"type"`
+		Values  json.RawMessage `json:"values"`
+	} `json:"resources"`
+	ChildModules []*tfShowModule `json:"child_modules"`
+}
+
+func (d *DB) createTerraformTable(t *tfTable) error {
+	_, err := d.CreateTable(t.createTableInput())
+	if err != nil {
+		return err
+	}
+	var ttlAttribute string
+	if len(t.TTL) > 0 && t.TTL[0].Enabled {
+		ttlAttribute = t.TTL[0].AttributeName
+	}
+	pitr := len(t.PointInTimeRecovery) > 0 && t.PointInTimeRecovery[0].Enabled
+	return d.enableTableSettings(t.Name, ttlAttribute, pitr)
+}
+
+func (t *tfTable) createTableInput() *dynamodb.CreateTableInput {
+	billingMode := cmp.Or(t.BillingMode, dynamodb.BillingModeProvisioned)
+	provisioned := billingMode == dynamodb.BillingModeProvisioned
+	input := &dynamodb.CreateTableInput{ //nolint:exhaustruct
+		BillingMode: &billingMode,
+		KeySchema:   tfKeySchema(t.HashKey, t.RangeKey),
+		TableName:   &t.Name,
+	}
+	for _, attr := range t.Attributes {
+		input.AttributeDefinitions = append(input.AttributeDefinitions, &dynamodb.AttributeDefinition{
+			AttributeName: ptr(attr.Name),
+			AttributeType: ptr(attr.Type),
+		})
+	}
+	if provisioned {
+		input.ProvisionedThroughput = &dynamodb.ProvisionedThroughput{
+			ReadCapacityUnits:  ptr(t.ReadCapacity),
+			WriteCapacityUnits: ptr(t.WriteCapacity),
+		}
+	}
+	for _, index := range t.GlobalSecondaryIndexes {
+		gsi := &dynamodb.GlobalSecondaryIndex{ //nolint:exhaustruct
+			IndexName:  ptr(index.Name),
+			KeySchema:  tfKeySchema(index.HashKey, index.RangeKey),
+			Projection: index.projection(),
+		}
+		if provisioned {
+			gsi.ProvisionedThroughput = &dynamodb.ProvisionedThroughput{
+				ReadCapacityUnits:  ptr(index.ReadCapacity),
+				WriteCapacityUnits: ptr(index.WriteCapacity),
+			}
+		}
+		input.GlobalSecondaryIndexes = append(input.GlobalSecondaryIndexes, gsi)
+	}
+	for _, index := range t.LocalSecondaryIndexes {
+		input.LocalSecondaryIndexes = append(input.LocalSecondaryIndexes, &dynamodb.LocalSecondaryIndex{
+			IndexName:  ptr(index.Name),
+			KeySchema:  tfKeySchema(t.HashKey, index.RangeKey),
+			Projection: index.projection(),
+		})
+	}
+	if t.StreamEnabled {
+		input.StreamSpecification = &dynamodb.StreamSpecification{
+			StreamEnabled:  ptr(true),
+			StreamViewType: ptr(t.StreamViewType),
+		}
+	}
+	if len(t.ServerSideEncryption) > 0 && t.ServerSideEncryption[0].Enabled {
+		sse := &dynamodb.SSESpecification{ //nolint:exhaustruct
+			Enabled: ptr(true),
+			SSEType: ptr(dynamodb.SSETypeKms),
+		}
+		if arn := t.ServerSideEncryption[0].KMSKeyARN; arn != "" {
+			sse.KMSMasterKeyId = &arn
+		}
+		input.SSESpecification = sse
+	}
+	if t.TableClass != "" {
+		input.TableClass = &t.TableClass
+	}
+	if t.DeletionProtectionEnabled {
+		input.DeletionProtectionEnabled = ptr(true)
+	}
+	for _, key := range slices.Sorted(maps.Keys(t.Tags)) {
+		input.Tags = append(input.Tags, &dynamodb.Tag{Key: ptr(key), Value: ptr(t.Tags[key])})
+	}
+	return input
+}
+
+func tfKeySchema(hashKey, rangeKey string) []*dynamodb.KeySchemaElement {
+	schema := []*dynamodb.KeySchemaElement{{
+		AttributeName: ptr(hashKey),
+		KeyType:       ptr(dynamodb.KeyTypeHash),
+	}}
+	if rangeKey != "" {
+		schema = append(schema, &dynamodb.KeySchemaElement{
+			AttributeName: ptr(rangeKey),
+			KeyType:       ptr(dynamodb.KeyTypeRange),
+		})
+	}
+	return schema
+}
+
+func (index *tfIndex) projection() *dynamodb.Projection {
+	projection := &dynamodb.Projection{ //nolint:exhaustruct
+		ProjectionType: ptr(index.ProjectionType),
+	}
+	for _, attr := range index.NonKeyAttributes {
+		projection.NonKeyAttributes = append(projection.NonKeyAttributes, ptr(attr))
+	}
+	return projection
+}
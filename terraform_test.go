@@ -0,0 +1,247 @@
+package fakedynamo_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/DMRobertson/fakedynamo"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const exampleTerraform = `
+variable "env" {
+  type    = string
+  default = "dev"
+}
+
+variable "regions" {
+  type    = set(string)
+  default = []
+}
+
+locals {
+  prefix = "${var.env}-app"
+  ttl    = "expires"
+}
+
+resource "aws_dynamodb_table" "orders" {
+  name         = "${local.prefix}-orders"
+  billing_mode = "PROVISIONED"
+  read_capacity  = 5
+  write_capacity = 2
+  hash_key     = "pk"
+  range_key    = "sk"
+
+  dynamic "attribute" {
+    for_each = ["pk", "sk", "customer", "placed"]
+    content {
+      name = attribute.value
+      type = "S"
+    }
+  }
+
+  global_secondary_index {
+    name               = "ByCustomer"
+    hash_key           = "customer"
+    projection_type    = "INCLUDE"
+    non_key_attributes = ["total"]
+    read_capacity      = 1
+    write_capacity     = 1
+  }
+
+  local_secondary_index {
+    name            = "ByPlaced"
+    range_key       = "placed"
+    projection_type = "KEYS_ONLY"
+  }
+
+  ttl {
+    attribute_name = local.ttl
+    enabled        = true
+  }
+
+  point_in_time_recovery {
+    enabled = true
+  }
+
+  stream_enabled   = true
+  stream_view_type = "NEW_IMAGE"
+
+  tags = {
+    Env = var.env
+  }
+
+  lifecycle {
+    prevent_destroy = true
+  }
+}
+
+resource "aws_dynamodb_table" "shards" {
+  count    = 2
+  name     = format("%s-shard-%d", local.prefix, count.index)
+  hash_key = "id"
+  billing_mode = "PAY_PER_REQUEST"
+
+  attribute {
+    name = "id"
+    type = "S"
+  }
+}
+
+resource "aws_dynamodb_table" "regional" {
+  for_each     = var.regions
+  name         = "regional-${each.key}"
+  hash_key     = "id"
+  billing_mode = "PAY_PER_REQUEST"
+
+  attribute {
+    name = "id"
+    type = "S"
+  }
+}
+
+resource "aws_sqs_queue" "ignored" {
+  name = "queue"
+}
+`
+
+func TestDB_LoadTerraformConfig(t *testing.T) {
+	t.Parallel()
+	db := fakedynamo.NewDB()
+	tables, err := db.LoadTerraformConfig("main.tf", []byte(exampleTerraform), fakedynamo.TerraformOptions{
+		Variables: map[string]any{"env": "test", "regions": []string{"eu"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"aws_dynamodb_table.orders":         "test-app-orders",
+		"aws_dynamodb_table.shards[0]":      "test-app-shard-0",
+		"aws_dynamodb_table.shards[1]":      "test-app-shard-1",
+		`aws_dynamodb_table.regional["eu"]`: "regional-eu",
+	}, tables)
+
+	described, err := db.DescribeTable(&dynamodb.DescribeTableInput{TableName: ptr("test-app-orders")})
+	require.NoError(t, err)
+	table := described.Table
+	assert.Len(t, table.AttributeDefinitions, 4)
+	assert.Len(t, table.KeySchema, 2)
+	assert.Equal(t, int64(5), val(table.ProvisionedThroughput.ReadCapacityUnits))
+	require.Len(t, table.GlobalSecondaryIndexes, 1)
+	assert.Equal(t, []*string{ptr("total")}, table.GlobalSecondaryIndexes[0].Projection.NonKeyAttributes)
+	assert.Equal(t, dynamodb.StreamViewTypeNewImage, val(table.StreamSpecification.StreamViewType))
+	assert.Equal(t, map[string]string{"Env": "test"}, listAllTags(t, db, table.TableArn))
+
+	ttl, err := db.DescribeTimeToLive(&dynamodb.DescribeTimeToLiveInput{TableName: ptr("test-app-orders")})
+	require.NoError(t, err)
+	assert.Equal(t, "expires", val(ttl.TimeToLiveDescription.AttributeName))
+	backups, err := db.DescribeContinuousBackups(&dynamodb.DescribeContinuousBackupsInput{TableName: ptr("test-app-orders")})
+	require.NoError(t, err)
+	assert.Equal(t, dynamodb.PointInTimeRecoveryStatusEnabled,
+		val(backups.ContinuousBackupsDescription.PointInTimeRecoveryDescription.PointInTimeRecoveryStatus))
+}
+
+func TestDB_LoadTerraform_Directory(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "variables.tf"), []byte(`variable "name" {}`), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.tf"), []byte(`
+resource "aws_dynamodb_table" "t" {
+  name         = var.name
+  hash_key     = "id"
+  billing_mode = "PAY_PER_REQUEST"
+  attribute {
+    name = "id"
+    type = "S"
+  }
+}`), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("not terraform"), 0o600))
+
+	tables, err := fakedynamo.NewDB().LoadTerraform(dir, fakedynamo.TerraformOptions{
+		Variables: map[string]any{"name": "from-variable"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"aws_dynamodb_table.t": "from-variable"}, tables)
+}
+
+func TestDB_LoadTerraform_ShowJSON(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "plan.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{
+  "format_version": "1.2",
+  "planned_values": {
+    "root_module": {
+      "resources": [{
+        "address": "aws_dynamodb_table.users",
+        "mode": "managed",
+        "type": "aws_dynamodb_table",
+        "name": "users",
+        "values": {
+          "name": "users",
+          "hash_key": "id",
+          "range_key": null,
+          "billing_mode": "PAY_PER_REQUEST",
+          "read_capacity": 0,
+          "write_capacity": 0,
+          "attribute": [{"name": "id", "type": "S"}],
+          "global_secondary_index": [],
+          "ttl": [{"attribute_name": "", "enabled": false}],
+          "stream_enabled": false,
+          "stream_view_type": "",
+          "tags": null
+        }
+      }],
+      "child_modules": [{
+        "resources": [{
+          "address": "module.audit.aws_dynamodb_table.log",
+          "mode": "managed",
+          "type": "aws_dynamodb_table",
+          "values": {
+            "name": "audit-log",
+            "hash_key": "id",
+            "range_key": "at",
+            "billing_mode": "PAY_PER_REQUEST",
+            "attribute": [{"name": "id", "type": "S"}, {"name": "at", "type": "N"}]
+          }
+        }]
+      }]
+    }
+  }
+}`), 0o600))
+
+	db := fakedynamo.NewDB()
+	tables, err := db.LoadTerraform(path, fakedynamo.TerraformOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"aws_dynamodb_table.users":            "users",
+		"module.audit.aws_dynamodb_table.log": "audit-log",
+	}, tables)
+	described, err := db.DescribeTable(&dynamodb.DescribeTableInput{TableName: ptr("audit-log")})
+	require.NoError(t, err)
+	assert.Len(t, described.Table.KeySchema, 2)
+}
+
+func TestDB_LoadTerraformConfig_Errors(t *testing.T) {
+	t.Parallel()
+	for _, test := range []struct {
+		name    string
+		config  string
+		line    int
+		message string
+	}{
+		{"syntax", "resource \"aws_dynamodb_table\" \"t\" {\n  name = \n}", 2, "Invalid expression"},
+		{"unset variable", "variable \"v\" {}\nresource \"aws_dynamodb_table\" \"t\" {\n  name = var.v\n  hash_key = \"id\"\n}", 3, "Unsupported attribute"},
+		{"invalid table", "resource \"aws_dynamodb_table\" \"t\" {\n  name = \"t\"\n  hash_key = \"id\"\n}", 1, "AttributeDefinitions"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := fakedynamo.NewDB().LoadTerraformConfig("main.tf", []byte(test.config), fakedynamo.TerraformOptions{})
+			var fixtureErr *fakedynamo.FixtureError
+			require.ErrorAs(t, err, &fixtureErr)
+			assert.Equal(t, "main.tf", fixtureErr.Name)
+			assert.Equal(t, test.line, fixtureErr.Line)
+			assertErrorContains(t, err, test.message)
+		})
+	}
+}
@@ -0,0 +1,478 @@
+package fakedynamo
+
+import (
+	"maps"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+const (
+	// readUnitBytes and writeUnitBytes are how much data one read or write
+	// capacity unit covers.
+	readUnitBytes  = 4096
+	writeUnitBytes = 1024
+	// burstWindow is how long DynamoDB keeps unused provisioned capacity for
+	// bursts of activity.
+	burstWindow = 300 * time.Second
+	// partitionReadUnits and partitionWriteUnits are the most capacity a
+	// single partition can serve each second, whatever the table's capacity.
+	partitionReadUnits  = 3000
+	partitionWriteUnits = 1000
+	// maxIdlePartitions is how many partitions' capacity is tracked before
+	// partitions which have fully recovered are forgotten.
+	maxIdlePartitions = 1024
+)
+
+const (
+	provisionedTableMessage = "The level of configured provisioned throughput for the table was exceeded. Consider increasing your provisioning level with the UpdateTable API."
+	provisionedIndexMessage = "The level of configured provisioned throughput for one or more global secondary indexes of the table was exceeded. Consider increasing your provisioning level for the under-provisioned global secondary indexes with the UpdateTable API."
+	onDemandTableMessage    = "Throughput exceeds the maximum OnDemandThroughput configured on the table."
+	onDemandIndexMessage    = "Throughput exceeds the maximum OnDemandThroughput configured on one or more global secondary indexes of the table."
+	partitionMessage        = "Throughput exceeds the current capacity of your table or index. DynamoDB is automatically scaling your table or index so please try again shortly. If exceptions persist, check if you have a hot key: https://docs.aws.amazon.com/amazondynamodb/latest/developerguide/bp-partition-key-design.html"
+)
+
+// tokenBucket models one kind of capacity. Tokens accrue at rate units per
+// second, up to window's worth. Requests proceed while the bucket isn't empty
+// and may overdraw it, so a large request is let through and the requests
+// after it are throttled until the bucket refills.
+type tokenBucket struct {
+	rate    float64
+	window  time.Duration
+	tokens  float64
+	updated time.Time
+}
+
+// newTokenBucket returns a full bucket.
+func newTokenBucket(rate int64, window time.Duration, now time.Time) *tokenBucket {
+	b := &tokenBucket{rate: float64(rate), window: window, tokens: 0, updated: now}
+	b.tokens = b.capacity()
+	return b
+}
+
+func (b *tokenBucket) capacity() float64 {
+	return b.rate * b.window.Seconds()
+}
+
+func (b *tokenBucket) refill(now time.Time) {
+	if elapsed := now.Sub(b.updated); elapsed > 0 {
+		b.tokens = min(b.capacity(), b.tokens+b.rate*elapsed.Seconds())
+		b.updated = now
+	}
+}
+
+// setRate changes the bucket's rate, keeping what it has accrued so far.
+func (b *tokenBucket) setRate(rate int64, now time.Time) {
+	b.refill(now)
+	b.rate = float64(rate)
+	b.tokens = min(b.capacity(), b.tokens)
+}
+
+// buckets holds read and write capacity by name.
+type buckets struct {
+	reads  map[string]*tokenBucket
+	writes map[string]*tokenBucket
+}
+
+func newBuckets() buckets {
+	return buckets{reads: map[string]*tokenBucket{}, writes: map[string]*tokenBucket{}}
+}
+
+func (b buckets) get(write bool) map[string]*tokenBucket {
+	if write {
+		return b.writes
+	}
+	return b.reads
+}
+
+// throughput limits the rate at which a table and its global secondary
+// indexes can be read and written.
+type throughput struct {
+	// mu guards the fields below, which are updated by reads holding the
+	// DB's read lock.
+	mu sync.Mutex
+	// provisioned is the capacity provisioned for the table, keyed by "", and
+	// its global secondary indexes, keyed by index name. Empty if the table is
+	// on-demand.
+	provisioned buckets
+	// maximum is the OnDemandThroughput of an on-demand table and its
+	// indexes, keyed likewise. It has no burst capacity.
+	maximum buckets
+	// partitions caps the throughput of each partition, keyed by partition
+	// key value. Partitions which have been split by adaptive capacity are
+	// instead capped per sort key, keyed by partitionBucketName.
+	partitions buckets
+	// split records the partitions which adaptive capacity has split.
+	split map[string]bool
+}
+
+// newThroughput returns the throughput limits of a table created by spec.
+func newThroughput(spec *dynamodb.CreateTableInput, now time.Time) *throughput {
+	tp := &throughput{
+		mu:          sync.Mutex{},
+		provisioned: newBuckets(),
+		maximum:     newBuckets(),
+		partitions:  newBuckets(),
+		split:       map[string]bool{},
+	}
+	tp.update(spec, now)
+	return tp
+}
+
+// clone returns a copy of the throughput limits, with the same accrued
+// capacity.
+func (tp *throughput) clone() *throughput {
+	tp.mu.Lock()
+	defer tp.mu.Unlock()
+	return &throughput{
+		mu:          sync.Mutex{},
+		provisioned: tp.provisioned.clone(),
+		maximum:     tp.maximum.clone(),
+		partitions:  tp.partitions.clone(),
+		split:       maps.Clone(tp.split),
+	}
+}
+
+func (b buckets) clone() buckets {
+	copied := newBuckets()
+	for name, bucket := range b.reads {
+		copied.reads[name] = shallowCopy(bucket)
+	}
+	for name, bucket := range b.writes {
+		copied.writes[name] = shallowCopy(bucket)
+	}
+	return copied
+}
+
+// update applies the capacity provisioned by spec, or its maximum on-demand
+// throughput. Existing buckets keep their accrued capacity.
+func (tp *throughput) update(spec *dynamodb.CreateTableInput, now time.Time) {
+	provisioned := valOr(spec.BillingMode, dynamodb.BillingModeProvisioned) == dynamodb.BillingModeProvisioned
+	reads, writes := map[string]int64{}, map[string]int64{}
+	maxReads, maxWrites := map[string]int64{}, map[string]int64{}
+	addRates := func(name string, p *dynamodb.ProvisionedThroughput, o *dynamodb.OnDemandThroughput) {
+		switch {
+		case provisioned && p != nil:
+			reads[name] = val(p.ReadCapacityUnits)
+			writes[name] = val(p.WriteCapacityUnits)
+		case !provisioned && o != nil:
+			// -1 means there is no maximum.
+			if units := val(o.MaxReadRequestUnits); units > 0 {
+				maxReads[name] = units
+			}
+			if units := val(o.MaxWriteRequestUnits); units > 0 {
+				maxWrites[name] = units
+			}
+		}
+	}
+	addRates("", spec.ProvisionedThroughput, spec.OnDemandThroughput)
+	for _, gsi := range spec.GlobalSecondaryIndexes {
+		addRates(*gsi.IndexName, gsi.ProvisionedThroughput, gsi.OnDemandThroughput)
+	}
+
+	tp.mu.Lock()
+	defer tp.mu.Unlock()
+	setRates(tp.provisioned.reads, reads, burstWindow, now)
+	setRates(tp.provisioned.writes, writes, burstWindow, now)
+	setRates(tp.maximum.reads, maxReads, time.Second, now)
+	setRates(tp.maximum.writes, maxWrites, time.Second, now)
+}
+
+// setRates updates buckets to have the given rates, adding and removing
+// buckets as needed.
+func setRates(buckets map[string]*tokenBucket, rates map[string]int64, window time.Duration, now time.Time) {
+	for name := range buckets {
+		if _, ok := rates[name]; !ok {
+			delete(buckets, name)
+		}
+	}
+	for name, rate := range rates {
+		if b := buckets[name]; b != nil {
+			b.setRate(rate, now)
+		} else {
+			buckets[name] = newTokenBucket(rate, window, now)
+		}
+	}
+}
+
+// consumption is the capacity used by a request, in capacity units, keyed by
+// "" for the table and by index name.
+type consumption map[string]float64
+
+// charge is an amount to take from a bucket, and the message to throttle
+// the request with if the bucket is empty.
+type charge struct {
+	bucket  *tokenBucket
+	units   float64
+	message string
+}
+
+// consume charges a request for the given item against the table's
+// throughput limits. If the table, any of the indexes charged or the item's
+// partition is out of capacity, nothing is charged and a
+// ProvisionedThroughputExceededException is returned.
+func (d *DB) consume(t *table, write bool, item avmap, c consumption) error {
+	tp := t.throughput
+	tp.mu.Lock()
+	defer tp.mu.Unlock()
+	now := d.now()
+
+	var charges []charge
+	// Sorting puts the table, named "", before its indexes.
+	for _, name := range slices.Sorted(maps.Keys(c)) {
+		if b := tp.provisioned.get(write)[name]; b != nil {
+			charges = append(charges, charge{b, c[name], tableOrIndex(name, provisionedTableMessage, provisionedIndexMessage)})
+		}
+		if b := tp.maximum.get(write)[name]; b != nil {
+			charges = append(charges, charge{b, c[name], tableOrIndex(name, onDemandTableMessage, onDemandIndexMessage)})
+		}
+	}
+	partitionKey := keyValueString(item[t.schema.partition])
+	partition := tp.partitionBucket(t, write, item, now)
+	charges = append(charges, charge{partition, c[""], partitionMessage})
+
+	for _, ch := range charges {
+		ch.bucket.refill(now)
+		if ch.bucket.tokens > 0 {
+			continue
+		}
+		if ch.bucket == partition && d.adaptiveCapacity && t.schema.sort != "" {
+			// Split the hot partition, so that its items are capped
+			// separately from now on. The item which was throttled stays
+			// throttled.
+			tp.split[partitionKey] = true
+			itemKey := partitionBucketName(partitionKey, keyValueString(item[t.schema.sort]))
+			tp.partitions.get(write)[itemKey] = partition
+		}
+		return &dynamodb.ProvisionedThroughputExceededException{Message_: ptr(ch.message)}
+	}
+	for _, ch := range charges {
+		ch.bucket.tokens -= ch.units
+	}
+	return nil
+}
+func tableOrIndex(name, tableMessage, indexMessage string) string {
+	if name == "" {
+		return tableMessage
+	}
+	return indexMessage
+}
+
+// partitionBucket returns the bucket capping the throughput of the item's
+// partition, or of the item itself if adaptive capacity has split its
+// partition.
+func (tp *throughput) partitionBucket(t *table, write bool, item avmap, now time.Time) *tokenBucket {
+	name := keyValueString(item[t.schema.partition])
+	if tp.split[name] {
+		name = partitionBucketName(name, keyValueString(item[t.schema.sort]))
+	}
+	buckets := tp.partitions.get(write)
+	if b := buckets[name]; b != nil {
+		return b
+	}
+
+	if len(buckets) >= maxIdlePartitions {
+		// A full bucket is no different from a new one.
+		for other, b := range buckets {
+			if b.refill(now); b.tokens >= b.capacity() {
+				delete(buckets, other)
+			}
+		}
+	}
+	rate := int64(partitionReadUnits)
+	if write {
+		rate = partitionWriteUnits
+	}
+	b := newTokenBucket(rate, time.Second, now)
+	buckets[name] = b
+	return b
+}
+
+// partitionBucketName names the bucket for one sort key of a split partition.
+func partitionBucketName(partitionKey, sortKey string) string {
+	return partitionKey + "\x00" + sortKey
+}
+
+// readConsumption is the capacity used to read an item of the given size.
+// Items which don't exist cost as much as the smallest item.
+func readConsumption(size int, consistent bool) consumption {
+	units := max(1, math.Ceil(float64(size)/readUnitBytes))
+	if !consistent {
+		units /= 2
+	}
+	return consumption{"": units}
+}
+
+// writeConsumption is the capacity used to replace the item before with
+// after, either of which may be nil. Each global secondary index which the
+// write affects is charged for the index entries written or removed.
+func (t *table) writeConsumption(before, after avmap) consumption {
+	c := consumption{"": writeUnits(max(itemSize(before), itemSize(after)))}
+	for _, gsi := range t.spec.GlobalSecondaryIndexes {
+		oldEntry := t.indexEntry(gsi, before)
+		newEntry := t.indexEntry(gsi, after)
+		var units float64
+		switch {
+		case oldEntry != nil && newEntry != nil && sameIndexKey(gsi, oldEntry, newEntry):
+			units = writeUnits(max(itemSize(oldEntry), itemSize(newEntry)))
+		default:
+			if oldEntry != nil {
+				units += writeUnits(itemSize(oldEntry))
+			}
+			if newEntry != nil {
+				units += writeUnits(itemSize(newEntry))
+			}
+		}
+		if units > 0 {
+			c[*gsi.IndexName] = units
+		}
+	}
+	return c
+}
+
+func writeUnits(size int) float64 {
+	return max(1, math.Ceil(float64(size)/writeUnitBytes))
+}
+
+// indexEntry projects an item into a global secondary index, returning nil
+// if the item lacks the index's key attributes.
+func (t *table) indexEntry(gsi *dynamodb.GlobalSecondaryIndex, item avmap) avmap {
+	if item == nil {
+		return nil
+	}
+	for _, key := range gsi.KeySchema {
+		if item[*key.AttributeName] == nil {
+			return nil
+		}
+	}
+	projection := gsi.Projection
+	if projection == nil || val(projection.ProjectionType) == dynamodb.ProjectionTypeAll {
+		return item
+	}
+
+	names := []string{t.schema.partition, t.schema.sort}
+	for _, key := range gsi.KeySchema {
+		names = append(names, *key.AttributeName)
+	}
+	if val(projection.ProjectionType) == dynamodb.ProjectionTypeInclude {
+		for _, name := range projection.NonKeyAttributes {
+			names = append(names, *name)
+		}
+	}
+	entry := avmap{}
+	for _, name := range names {
+		if v := item[name]; v != nil {
+			entry[name] = v
+		}
+	}
+	return entry
+}
+
+func sameIndexKey(gsi *dynamodb.GlobalSecondaryIndex, a, b avmap) bool {
+	for _, key := range gsi.KeySchema {
+		if keyValueString(a[*key.AttributeName]) != keyValueString(b[*key.AttributeName]) {
+			return false
+		}
+	}
+	return true
+}
+
+// consumedCapacity reports a request's consumption, if the request asked for
+// it with ReturnConsumedCapacity.
+func consumedCapacity(tableName string, returnConsumedCapacity *string, write bool, c consumption) *dynamodb.ConsumedCapacity {
+	mode := valOr(returnConsumedCapacity, dynamodb.ReturnConsumedCapacityNone)
+	if mode == dynamodb.ReturnConsumedCapacityNone {
+		return nil
+	}
+
+	capacity := func(units float64) *dynamodb.Capacity {
+		result := &dynamodb.Capacity{CapacityUnits: ptr(units)}
+		if write {
+			result.WriteCapacityUnits = ptr(units)
+		} else {
+			result.ReadCapacityUnits = ptr(units)
+		}
+		return result
+	}
+	var total float64
+	for _, units := range c {
+		total += units
+	}
+	consumed := &dynamodb.ConsumedCapacity{
+		CapacityUnits: ptr(total),
+		TableName:     &tableName,
+	}
+	if mode == dynamodb.ReturnConsumedCapacityIndexes {
+		consumed.Table = capacity(c[""])
+		for name, units := range c {
+			if name == "" {
+				continue
+			}
+			if consumed.GlobalSecondaryIndexes == nil {
+				consumed.GlobalSecondaryIndexes = map[string]*dynamodb.Capacity{}
+			}
+			consumed.GlobalSecondaryIndexes[name] = capacity(units)
+		}
+	}
+	return consumed
+}
+
+// itemSize approximates the size of an item as DynamoDB measures it for
+// capacity units: the lengths of its attribute names plus the sizes of their
+// values.
+func itemSize(item avmap) int {
+	size := 0
+	for name, v := range item {
+		size += len(name) + attributeValueSize(v)
+	}
+	return size
+}
+
+func attributeValueSize(v *dynamodb.AttributeValue) int {
+	size := 0
+	switch {
+	case v == nil:
+	case v.S != nil:
+		size = len(*v.S)
+	case v.N != nil:
+		size = numberSize(*v.N)
+	case v.B != nil:
+		size = len(v.B)
+	case v.BOOL != nil, v.NULL != nil:
+		size = 1
+	case v.SS != nil:
+		for _, s := range v.SS {
+			size += len(*s)
+		}
+	case v.NS != nil:
+		for _, n := range v.NS {
+			size += numberSize(*n)
+		}
+	case v.BS != nil:
+		for _, b := range v.BS {
+			size += len(b)
+		}
+	case v.L != nil:
+		size = 3
+		for _, element := range v.L {
+			size += 1 + attributeValueSize(element)
+		}
+	case v.M != nil:
+		size = 3
+		for name, element := range v.M {
+			size += len(name) + 1 + attributeValueSize(element)
+		}
+	}
+	return size
+}
+
+// numberSize is one byte per two significant digits, plus one.
+func numberSize(n string) int {
+	mantissa, _, _ := strings.Cut(strings.ToLower(strings.TrimLeft(n, "+-")), "e")
+	digits := strings.Trim(strings.ReplaceAll(mantissa, ".", ""), "0")
+	return (len(digits)+1)/2 + 1
+}
@@ -0,0 +1,313 @@
+package fakedynamo_test
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DMRobertson/fakedynamo"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClock is a clock for [fakedynamo.WithClock] which only moves when
+// advanced.
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{mu: sync.Mutex{}, now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func TestDB_GetItem_ThrottledOnceBurstCapacityIsUsed(t *testing.T) {
+	t.Parallel()
+	clock := newFakeClock()
+	db := fakedynamo.NewDB(fakedynamo.WithClock(clock.Now))
+	input := exampleCreateTableInputSimplePrimaryKey()
+	_, err := db.CreateTable(input)
+	require.NoError(t, err)
+
+	get := func() error {
+		_, err := db.GetItem(&dynamodb.GetItemInput{
+			ConsistentRead: ptr(true),
+			Key:            map[string]*dynamodb.AttributeValue{"Foo": {S: ptr("foo")}},
+			TableName:      input.TableName,
+		})
+		return err
+	}
+	// One read capacity unit accrues 300 seconds of burst capacity.
+	for range 300 {
+		require.NoError(t, get())
+	}
+	var throttled *dynamodb.ProvisionedThroughputExceededException
+	require.ErrorAs(t, get(), &throttled)
+	assertErrorContains(t, throttled, "for the table was exceeded")
+
+	clock.Advance(time.Second)
+	require.NoError(t, get())
+	require.ErrorAs(t, get(), &throttled)
+}
+
+func TestDB_PutItem_ThrottledByGlobalSecondaryIndex(t *testing.T) {
+	t.Parallel()
+	clock := newFakeClock()
+	db := fakedynamo.NewDB(fakedynamo.WithClock(clock.Now))
+	input := exampleCreateTableInputSimplePrimaryKey()
+	input.ProvisionedThroughput.WriteCapacityUnits = ptr[int64](1000)
+	input.AttributeDefinitions = append(input.AttributeDefinitions, &dynamodb.AttributeDefinition{
+		AttributeName: ptr("Bar"),
+		AttributeType: ptr(dynamodb.ScalarAttributeTypeS),
+	})
+	input.GlobalSecondaryIndexes = []*dynamodb.GlobalSecondaryIndex{{
+		IndexName:  ptr("by-bar"),
+		KeySchema:  []*dynamodb.KeySchemaElement{{AttributeName: ptr("Bar"), KeyType: ptr(dynamodb.KeyTypeHash)}},
+		Projection: &dynamodb.Projection{ProjectionType: ptr(dynamodb.ProjectionTypeKeysOnly)},
+		ProvisionedThroughput: &dynamodb.ProvisionedThroughput{
+			ReadCapacityUnits:  ptr[int64](1),
+			WriteCapacityUnits: ptr[int64](1),
+		},
+	}}
+	_, err := db.CreateTable(input)
+	require.NoError(t, err)
+
+	put := func(foo, bar string) error {
+		item := map[string]*dynamodb.AttributeValue{"Foo": {S: ptr(foo)}}
+		if bar != "" {
+			item["Bar"] = &dynamodb.AttributeValue{S: ptr(bar)}
+		}
+		_, err := db.PutItem(&dynamodb.PutItemInput{Item: item, TableName: input.TableName})
+		return err
+	}
+	for i := range 300 {
+		require.NoError(t, put(strings.Repeat("x", i+1), "bar"))
+	}
+	var throttled *dynamodb.ProvisionedThroughputExceededException
+	require.ErrorAs(t, put("new", "bar"), &throttled)
+	assertErrorContains(t, throttled, "global secondary indexes")
+
+	// Writes which don't touch the index aren't held back by it.
+	require.NoError(t, put("unindexed", ""))
+}
+
+func TestDB_PutItem_ReturnsConsumedCapacity(t *testing.T) {
+	t.Parallel()
+	db := fakedynamo.NewDB()
+	input := exampleCreateTableInputSimplePrimaryKey()
+	_, err := db.CreateTable(input)
+	require.NoError(t, err)
+
+	key := map[string]*dynamodb.AttributeValue{"Foo": {S: ptr("foo")}}
+	item := map[string]*dynamodb.AttributeValue{
+		"Foo": {S: ptr("foo")},
+		"Bar": {S: ptr(strings.Repeat("x", 3000))},
+	}
+	put, err := db.PutItem(&dynamodb.PutItemInput{
+		Item:                   item,
+		ReturnConsumedCapacity: ptr(dynamodb.ReturnConsumedCapacityTotal),
+		TableName:              input.TableName,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, &dynamodb.ConsumedCapacity{
+		CapacityUnits: ptr(3.0),
+		TableName:     input.TableName,
+	}, put.ConsumedCapacity)
+
+	get, err := db.GetItem(&dynamodb.GetItemInput{
+		Key:                    key,
+		ReturnConsumedCapacity: ptr(dynamodb.ReturnConsumedCapacityIndexes),
+		TableName:              input.TableName,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, &dynamodb.ConsumedCapacity{
+		CapacityUnits: ptr(0.5),
+		Table:         &dynamodb.Capacity{CapacityUnits: ptr(0.5), ReadCapacityUnits: ptr(0.5)},
+		TableName:     input.TableName,
+	}, get.ConsumedCapacity)
+
+	deleted, err := db.DeleteItem(&dynamodb.DeleteItemInput{
+		Key:                    key,
+		ReturnConsumedCapacity: ptr(dynamodb.ReturnConsumedCapacityTotal),
+		TableName:              input.TableName,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, ptr(3.0), deleted.ConsumedCapacity.CapacityUnits)
+
+	get, err = db.GetItem(&dynamodb.GetItemInput{Key: key, TableName: input.TableName})
+	require.NoError(t, err)
+	assert.Nil(t, get.ConsumedCapacity)
+}
+
+func TestDB_UpdateTable_ChangesProvisionedThroughput(t *testing.T) {
+	t.Parallel()
+	clock := newFakeClock()
+	db := fakedynamo.NewDB(fakedynamo.WithClock(clock.Now))
+	input := exampleCreateTableInputSimplePrimaryKey()
+	_, err := db.CreateTable(input)
+	require.NoError(t, err)
+
+	described, err := db.DescribeTable(&dynamodb.DescribeTableInput{TableName: input.TableName})
+	require.NoError(t, err)
+	assert.Equal(t, ptr[int64](1), described.Table.ProvisionedThroughput.ReadCapacityUnits)
+	assert.Equal(t, ptr[int64](1), described.Table.ProvisionedThroughput.WriteCapacityUnits)
+
+	put := func() error {
+		_, err := db.PutItem(&dynamodb.PutItemInput{
+			Item:      map[string]*dynamodb.AttributeValue{"Foo": {S: ptr("foo")}},
+			TableName: input.TableName,
+		})
+		return err
+	}
+	for range 300 {
+		require.NoError(t, put())
+	}
+	var throttled *dynamodb.ProvisionedThroughputExceededException
+	require.ErrorAs(t, put(), &throttled)
+
+	_, err = db.UpdateTable(&dynamodb.UpdateTableInput{
+		ProvisionedThroughput: &dynamodb.ProvisionedThroughput{
+			ReadCapacityUnits:  ptr[int64](1),
+			WriteCapacityUnits: ptr[int64](10),
+		},
+		TableName: input.TableName,
+	})
+	require.NoError(t, err)
+	clock.Advance(time.Second)
+	for range 10 {
+		require.NoError(t, put())
+	}
+	require.ErrorAs(t, put(), &throttled)
+
+	// On-demand tables have no provisioned capacity to use up.
+	_, err = db.UpdateTable(&dynamodb.UpdateTableInput{
+		BillingMode: ptr(dynamodb.BillingModePayPerRequest),
+		TableName:   input.TableName,
+	})
+	require.NoError(t, err)
+	require.NoError(t, put())
+	described, err = db.DescribeTable(&dynamodb.DescribeTableInput{TableName: input.TableName})
+	require.NoError(t, err)
+	assert.Equal(t, ptr[int64](0), described.Table.ProvisionedThroughput.WriteCapacityUnits)
+}
+
+func TestDB_GetItem_ThrottlesCountedByContributorInsights(t *testing.T) {
+	t.Parallel()
+	clock := newFakeClock()
+	db := fakedynamo.NewDB(fakedynamo.WithClock(clock.Now))
+	input := exampleCreateTableInputSimplePrimaryKey()
+	_, err := db.CreateTable(input)
+	require.NoError(t, err)
+	_, err = db.UpdateContributorInsights(&dynamodb.UpdateContributorInsightsInput{
+		ContributorInsightsAction: ptr(dynamodb.ContributorInsightsActionEnable),
+		TableName:                 input.TableName,
+	})
+	require.NoError(t, err)
+
+	key := map[string]*dynamodb.AttributeValue{"Foo": {S: ptr("hot")}}
+	for range 602 {
+		_, _ = db.GetItem(&dynamodb.GetItemInput{Key: key, TableName: input.TableName})
+	}
+	throttled, err := db.TopContributors(*input.TableName, "", fakedynamo.MostThrottledPartitionKeys, time.Time{})
+	require.NoError(t, err)
+	assert.Equal(t, []fakedynamo.Contributor{{Key: key, Count: 2}}, throttled)
+}
+
+func TestDB_PutItem_ThrottledByOnDemandMaximum(t *testing.T) {
+	t.Parallel()
+	clock := newFakeClock()
+	db := fakedynamo.NewDB(fakedynamo.WithClock(clock.Now))
+	input := exampleCreateTableInputSimplePrimaryKey()
+	input.BillingMode = ptr(dynamodb.BillingModePayPerRequest)
+	input.ProvisionedThroughput = nil
+	input.OnDemandThroughput = &dynamodb.OnDemandThroughput{
+		MaxReadRequestUnits:  ptr[int64](-1),
+		MaxWriteRequestUnits: ptr[int64](5),
+	}
+	_, err := db.CreateTable(input)
+	require.NoError(t, err)
+
+	put := func(foo string) error {
+		_, err := db.PutItem(&dynamodb.PutItemInput{
+			Item:      map[string]*dynamodb.AttributeValue{"Foo": {S: ptr(foo)}},
+			TableName: input.TableName,
+		})
+		return err
+	}
+	for i := range 5 {
+		require.NoError(t, put(strings.Repeat("x", i+1)))
+	}
+	var throttled *dynamodb.ProvisionedThroughputExceededException
+	require.ErrorAs(t, put("y"), &throttled)
+	assertErrorContains(t, throttled, "maximum OnDemandThroughput configured on the table")
+
+	// The maximum has no burst capacity.
+	clock.Advance(time.Hour)
+	for range 5 {
+		require.NoError(t, put("y"))
+	}
+	require.ErrorAs(t, put("y"), &throttled)
+
+	// Reads are unlimited.
+	for range 100 {
+		_, err = db.GetItem(&dynamodb.GetItemInput{
+			Key:       map[string]*dynamodb.AttributeValue{"Foo": {S: ptr("y")}},
+			TableName: input.TableName,
+		})
+		require.NoError(t, err)
+	}
+}
+
+func TestDB_PutItem_ThrottledByPartitionCap(t *testing.T) {
+	t.Parallel()
+	for _, adaptive := range []bool{false, true} {
+		opts := []fakedynamo.Option{fakedynamo.WithClock(newFakeClock().Now)}
+		if adaptive {
+			opts = append(opts, fakedynamo.WithAdaptiveCapacity())
+		}
+		db := fakedynamo.NewDB(opts...)
+		input := exampleCreateTableInputCompositePrimaryKey()
+		input.BillingMode = ptr(dynamodb.BillingModePayPerRequest)
+		input.ProvisionedThroughput = nil
+		_, err := db.CreateTable(input)
+		require.NoError(t, err)
+
+		put := func(foo, bar string) error {
+			_, err := db.PutItem(&dynamodb.PutItemInput{
+				Item:      map[string]*dynamodb.AttributeValue{"Foo": {S: ptr(foo)}, "Bar": {S: ptr(bar)}},
+				TableName: input.TableName,
+			})
+			return err
+		}
+		// A partition takes at most 1000 write capacity units a second.
+		for range 1000 {
+			require.NoError(t, put("hot", "1"))
+		}
+		var throttled *dynamodb.ProvisionedThroughputExceededException
+		require.ErrorAs(t, put("hot", "1"), &throttled)
+		assertErrorContains(t, throttled, "hot key")
+		require.NoError(t, put("cold", "1"))
+
+		// Adaptive capacity splits the hot partition, but not the hot item.
+		if adaptive {
+			require.NoError(t, put("hot", "2"))
+		} else {
+			require.ErrorAs(t, put("hot", "2"), &throttled)
+		}
+		require.ErrorAs(t, put("hot", "1"), &throttled)
+	}
+}
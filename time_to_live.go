@@ -0,0 +1,126 @@
+package fakedynamo
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// DescribeTimeToLive reports whether Time to Live is enabled for a table.
+func (d *DB) DescribeTimeToLive(input *dynamodb.DescribeTimeToLiveInput) (*dynamodb.DescribeTimeToLiveOutput, error) {
+	return d.describeTimeToLiveContext(aws.BackgroundContext(), input)
+}
+
+func (d *DB) describeTimeToLiveContext(ctx aws.Context, input *dynamodb.DescribeTimeToLiveInput) (*dynamodb.DescribeTimeToLiveOutput, error) {
+	if err := d.injectFault(ctx, "DescribeTimeToLive", input); err != nil {
+		return nil, err
+	}
+
+	if input.TableName == nil {
+		return nil, newValidationError("TableName is a required field")
+	}
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	t, exists := d.tables.Get(tableKey(*input.TableName))
+	if !exists {
+		return nil, &dynamodb.ResourceNotFoundException{}
+	}
+	return &dynamodb.DescribeTimeToLiveOutput{
+		TimeToLiveDescription: t.describeTimeToLive(),
+	}, nil
+}
+
+func (t *table) describeTimeToLive() *dynamodb.TimeToLiveDescription {
+	if t.ttlAttribute == "" {
+		return &dynamodb.TimeToLiveDescription{ //nolint:exhaustruct
+			TimeToLiveStatus: ptr(dynamodb.TimeToLiveStatusDisabled),
+		}
+	}
+	return &dynamodb.TimeToLiveDescription{
+		AttributeName:    ptr(t.ttlAttribute),
+		TimeToLiveStatus: ptr(dynamodb.TimeToLiveStatusEnabled),
+	}
+}
+
+func (d *DB) DescribeTimeToLiveWithContext(ctx aws.Context, input *dynamodb.DescribeTimeToLiveInput, opts ...request.Option) (*dynamodb.DescribeTimeToLiveOutput, error) {
+	req, output := d.DescribeTimeToLiveRequest(input)
+	req.SetContext(ctx)
+	req.ApplyOptions(opts...)
+	return output, req.Send()
+}
+
+func (d *DB) DescribeTimeToLiveRequest(input *dynamodb.DescribeTimeToLiveInput) (*request.Request, *dynamodb.DescribeTimeToLiveOutput) {
+	return newContextRequest(d, "DescribeTimeToLive", input, d.describeTimeToLiveContext)
+}
+
+// UpdateTimeToLive enables or disables Time to Live for a table. The change
+// takes effect immediately, rather than passing through the ENABLING and
+// DISABLING states. Expired items aren't deleted: DynamoDB makes no promise
+// about when it deletes them, so callers can't rely on it anyway.
+func (d *DB) UpdateTimeToLive(input *dynamodb.UpdateTimeToLiveInput) (*dynamodb.UpdateTimeToLiveOutput, error) {
+	return d.updateTimeToLiveContext(aws.BackgroundContext(), input)
+}
+
+func (d *DB) updateTimeToLiveContext(ctx aws.Context, input *dynamodb.UpdateTimeToLiveInput) (*dynamodb.UpdateTimeToLiveOutput, error) {
+	if err := d.injectFault(ctx, "UpdateTimeToLive", input); err != nil {
+		return nil, err
+	}
+
+	if input.TableName == nil {
+		return nil, newValidationError("TableName is a required field")
+	}
+	spec := input.TimeToLiveSpecification
+	if spec == nil {
+		return nil, newValidationError("TimeToLiveSpecification is a required field")
+	}
+	switch {
+	case spec.AttributeName == nil:
+		return nil, newValidationError("TimeToLiveSpecification.AttributeName is a required field")
+	case len(*spec.AttributeName) < 1 || len(*spec.AttributeName) > 255:
+		return nil, newValidationError("TimeToLiveSpecification.AttributeName must be between 1 and 255 characters")
+	case spec.Enabled == nil:
+		return nil, newValidationError("TimeToLiveSpecification.Enabled is a required field")
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	t, exists := d.tables.Get(tableKey(*input.TableName))
+	if !exists {
+		return nil, &dynamodb.ResourceNotFoundException{}
+	}
+
+	switch {
+	case *spec.Enabled && t.ttlAttribute != "":
+		return nil, newValidationError("TimeToLive is already enabled")
+	case !*spec.Enabled && t.ttlAttribute == "":
+		return nil, newValidationError("TimeToLive is already disabled")
+	case !*spec.Enabled && t.ttlAttribute != *spec.AttributeName:
+		return nil, newValidationErrorf("TimeToLive is enabled on attribute %s", t.ttlAttribute)
+	}
+	err := d.changeTable(t, func(updated *table) error {
+		updated.ttlAttribute = ""
+		if *spec.Enabled {
+			updated.ttlAttribute = *spec.AttributeName
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &dynamodb.UpdateTimeToLiveOutput{
+		TimeToLiveSpecification: spec,
+	}, nil
+}
+
+func (d *DB) UpdateTimeToLiveWithContext(ctx aws.Context, input *dynamodb.UpdateTimeToLiveInput, opts ...request.Option) (*dynamodb.UpdateTimeToLiveOutput, error) {
+	req, output := d.UpdateTimeToLiveRequest(input)
+	req.SetContext(ctx)
+	req.ApplyOptions(opts...)
+	return output, req.Send()
+}
+
+func (d *DB) UpdateTimeToLiveRequest(input *dynamodb.UpdateTimeToLiveInput) (*request.Request, *dynamodb.UpdateTimeToLiveOutput) {
+	return newContextRequest(d, "UpdateTimeToLive", input, d.updateTimeToLiveContext)
+}
@@ -0,0 +1,64 @@
+package fakedynamo_test
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDB_UpdateTimeToLive_ErrorsIfTableMissing(t *testing.T) {
+	t.Parallel()
+	db := makeTestDB(t)
+	_, err := db.UpdateTimeToLive(&dynamodb.UpdateTimeToLiveInput{
+		TableName: ptr("does-not-exist"),
+		TimeToLiveSpecification: &dynamodb.TimeToLiveSpecification{
+			AttributeName: ptr("Expires"),
+			Enabled:       ptr(true),
+		},
+	})
+	var expectedErr *dynamodb.ResourceNotFoundException
+	assert.ErrorAs(t, err, &expectedErr)
+}
+
+func TestDB_UpdateTimeToLive_TogglesTimeToLive(t *testing.T) {
+	t.Parallel()
+	db := makeTestDB(t)
+	input := exampleCreateTableInputSimplePrimaryKey()
+	_, err := db.CreateTable(input)
+	require.NoError(t, err)
+
+	described, err := db.DescribeTimeToLive(&dynamodb.DescribeTimeToLiveInput{TableName: input.TableName})
+	require.NoError(t, err)
+	assert.Equal(t, &dynamodb.TimeToLiveDescription{
+		TimeToLiveStatus: ptr(dynamodb.TimeToLiveStatusDisabled),
+	}, described.TimeToLiveDescription)
+
+	update := func(attribute string, enabled bool) error {
+		_, err := db.UpdateTimeToLive(&dynamodb.UpdateTimeToLiveInput{
+			TableName: input.TableName,
+			TimeToLiveSpecification: &dynamodb.TimeToLiveSpecification{
+				AttributeName: ptr(attribute),
+				Enabled:       ptr(enabled),
+			},
+		})
+		return err
+	}
+	assertErrorContains(t, update("Expires", false), "already disabled")
+	require.NoError(t, update("Expires", true))
+	assertErrorContains(t, update("Other", true), "already enabled")
+
+	described, err = db.DescribeTimeToLive(&dynamodb.DescribeTimeToLiveInput{TableName: input.TableName})
+	require.NoError(t, err)
+	assert.Equal(t, &dynamodb.TimeToLiveDescription{
+		AttributeName:    ptr("Expires"),
+		TimeToLiveStatus: ptr(dynamodb.TimeToLiveStatusEnabled),
+	}, described.TimeToLiveDescription)
+
+	assertErrorContains(t, update("Other", false), "enabled on attribute Expires")
+	require.NoError(t, update("Expires", false))
+	described, err = db.DescribeTimeToLive(&dynamodb.DescribeTimeToLiveInput{TableName: input.TableName})
+	require.NoError(t, err)
+	assert.Equal(t, dynamodb.TimeToLiveStatusDisabled, val(described.TimeToLiveDescription.TimeToLiveStatus))
+}
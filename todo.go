@@ -11,14 +11,15 @@ func (d *DB) BatchExecuteStatement(input *dynamodb.BatchExecuteStatementInput) (
 	panic("implement me")
 }
 
-func (d *DB) BatchExecuteStatementWithContext(context aws.Context, input *dynamodb.BatchExecuteStatementInput, option ...request.Option) (*dynamodb.BatchExecuteStatementOutput, error) {
-	// TODO implement me
-	panic("implement me")
+func (d *DB) BatchExecuteStatementWithContext(ctx aws.Context, input *dynamodb.BatchExecuteStatementInput, opts ...request.Option) (*dynamodb.BatchExecuteStatementOutput, error) {
+	req, output := d.BatchExecuteStatementRequest(input)
+	req.SetContext(ctx)
+	req.ApplyOptions(opts...)
+	return output, req.Send()
 }
 
 func (d *DB) BatchExecuteStatementRequest(input *dynamodb.BatchExecuteStatementInput) (*request.Request, *dynamodb.BatchExecuteStatementOutput) {
-	// TODO implement me
-	panic("implement me")
+	return newRequest(d, "BatchExecuteStatement", input, d.BatchExecuteStatement)
 }
 
 func (d *DB) BatchWriteItem(input *dynamodb.BatchWriteItemInput) (*dynamodb.BatchWriteItemOutput, error) {
@@ -26,14 +27,15 @@ func (d *DB) BatchWriteItem(input *dynamodb.BatchWriteItemInput) (*dynamodb.Batc
 	panic("implement me")
 }
 
-func (d *DB) BatchWriteItemWithContext(context aws.Context, input *dynamodb.BatchWriteItemInput, option ...request.Option) (*dynamodb.BatchWriteItemOutput, error) {
-	// TODO implement me
-	panic("implement me")
+func (d *DB) BatchWriteItemWithContext(ctx aws.Context, input *dynamodb.BatchWriteItemInput, opts ...request.Option) (*dynamodb.BatchWriteItemOutput, error) {
+	req, output := d.BatchWriteItemRequest(input)
+	req.SetContext(ctx)
+	req.ApplyOptions(opts...)
+	return output, req.Send()
 }
 
 func (d *DB) BatchWriteItemRequest(input *dynamodb.BatchWriteItemInput) (*request.Request, *dynamodb.BatchWriteItemOutput) {
-	// TODO implement me
-	panic("implement me")
+	return newRequest(d, "BatchWriteItem", input, d.BatchWriteItem)
 }
 
 func (d *DB) CreateBackup(input *dynamodb.CreateBackupInput) (*dynamodb.CreateBackupOutput, error) {
@@ -41,29 +43,15 @@ func (d *DB) CreateBackup(input *dynamodb.CreateBackupInput) (*dynamodb.CreateBa
 	panic("implement me")
 }
 
-func (d *DB) CreateBackupWithContext(context aws.Context, input *dynamodb.CreateBackupInput, option ...request.Option) (*dynamodb.CreateBackupOutput, error) {
-	// TODO implement me
-	panic("implement me")
+func (d *DB) CreateBackupWithContext(ctx aws.Context, input *dynamodb.CreateBackupInput, opts ...request.Option) (*dynamodb.CreateBackupOutput, error) {
+	req, output := d.CreateBackupRequest(input)
+	req.SetContext(ctx)
+	req.ApplyOptions(opts...)
+	return output, req.Send()
 }
 
 func (d *DB) CreateBackupRequest(input *dynamodb.CreateBackupInput) (*request.Request, *dynamodb.CreateBackupOutput) {
-	// TODO implement me
-	panic("implement me")
-}
-
-func (d *DB) CreateGlobalTable(input *dynamodb.CreateGlobalTableInput) (*dynamodb.CreateGlobalTableOutput, error) {
-	// TODO implement me
-	panic("implement me")
-}
-
-func (d *DB) CreateGlobalTableWithContext(context aws.Context, input *dynamodb.CreateGlobalTableInput, option ...request.Option) (*dynamodb.CreateGlobalTableOutput, error) {
-	// TODO implement me
-	panic("implement me")
-}
-
-func (d *DB) CreateGlobalTableRequest(input *dynamodb.CreateGlobalTableInput) (*request.Request, *dynamodb.CreateGlobalTableOutput) {
-	// TODO implement me
-	panic("implement me")
+	return newRequest(d, "CreateBackup", input, d.CreateBackup)
 }
 
 func (d *DB) DeleteBackup(input *dynamodb.DeleteBackupInput) (*dynamodb.DeleteBackupOutput, error) {
@@ -71,29 +59,15 @@ func (d *DB) DeleteBackup(input *dynamodb.DeleteBackupInput) (*dynamodb.DeleteBa
 	panic("implement me")
 }
 
-func (d *DB) DeleteBackupWithContext(context aws.Context, input *dynamodb.DeleteBackupInput, option ...request.Option) (*dynamodb.DeleteBackupOutput, error) {
-	// TODO implement me
-	panic("implement me")
+func (d *DB) DeleteBackupWithContext(ctx aws.Context, input *dynamodb.DeleteBackupInput, opts ...request.Option) (*dynamodb.DeleteBackupOutput, error) {
+	req, output := d.DeleteBackupRequest(input)
+	req.SetContext(ctx)
+	req.ApplyOptions(opts...)
+	return output, req.Send()
 }
 
 func (d *DB) DeleteBackupRequest(input *dynamodb.DeleteBackupInput) (*request.Request, *dynamodb.DeleteBackupOutput) {
-	// TODO implement me
-	panic("implement me")
-}
-
-func (d *DB) DeleteResourcePolicy(input *dynamodb.DeleteResourcePolicyInput) (*dynamodb.DeleteResourcePolicyOutput, error) {
-	// TODO implement me
-	panic("implement me")
-}
-
-func (d *DB) DeleteResourcePolicyWithContext(context aws.Context, input *dynamodb.DeleteResourcePolicyInput, option ...request.Option) (*dynamodb.DeleteResourcePolicyOutput, error) {
-	// TODO implement me
-	panic("implement me")
-}
-
-func (d *DB) DeleteResourcePolicyRequest(input *dynamodb.DeleteResourcePolicyInput) (*request.Request, *dynamodb.DeleteResourcePolicyOutput) {
-	// TODO implement me
-	panic("implement me")
+	return newRequest(d, "DeleteBackup", input, d.DeleteBackup)
 }
 
 func (d *DB) DescribeBackup(input *dynamodb.DescribeBackupInput) (*dynamodb.DescribeBackupOutput, error) {
@@ -101,44 +75,15 @@ func (d *DB) DescribeBackup(input *dynamodb.DescribeBackupInput) (*dynamodb.Desc
 	panic("implement me")
 }
 
-func (d *DB) DescribeBackupWithContext(context aws.Context, input *dynamodb.DescribeBackupInput, option ...request.Option) (*dynamodb.DescribeBackupOutput, error) {
-	// TODO implement me
-	panic("implement me")
+func (d *DB) DescribeBackupWithContext(ctx aws.Context, input *dynamodb.DescribeBackupInput, opts ...request.Option) (*dynamodb.DescribeBackupOutput, error) {
+	req, output := d.DescribeBackupRequest(input)
+	req.SetContext(ctx)
+	req.ApplyOptions(opts...)
+	return output, req.Send()
 }
 
 func (d *DB) DescribeBackupRequest(input *dynamodb.DescribeBackupInput) (*request.Request, *dynamodb.DescribeBackupOutput) {
-	// TODO implement me
-	panic("implement me")
-}
-
-func (d *DB) DescribeContinuousBackups(input *dynamodb.DescribeContinuousBackupsInput) (*dynamodb.DescribeContinuousBackupsOutput, error) {
-	// TODO implement me
-	panic("implement me")
-}
-
-func (d *DB) DescribeContinuousBackupsWithContext(context aws.Context, input *dynamodb.DescribeContinuousBackupsInput, option ...request.Option) (*dynamodb.DescribeContinuousBackupsOutput, error) {
-	// TODO implement me
-	panic("implement me")
-}
-
-func (d *DB) DescribeContinuousBackupsRequest(input *dynamodb.DescribeContinuousBackupsInput) (*request.Request, *dynamodb.DescribeContinuousBackupsOutput) {
-	// TODO implement me
-	panic("implement me")
-}
-
-func (d *DB) DescribeContributorInsights(input *dynamodb.DescribeContributorInsightsInput) (*dynamodb.DescribeContributorInsightsOutput, error) {
-	// TODO implement me
-	panic("implement me")
-}
-
-func (d *DB) DescribeContributorInsightsWithContext(context aws.Context, input *dynamodb.DescribeContributorInsightsInput, option ...request.Option) (*dynamodb.DescribeContributorInsightsOutput, error) {
-	// TODO implement me
-	panic("implement me")
-}
-
-func (d *DB) DescribeContributorInsightsRequest(input *dynamodb.DescribeContributorInsightsInput) (*request.Request, *dynamodb.DescribeContributorInsightsOutput) {
-	// TODO implement me
-	panic("implement me")
+	return newRequest(d, "DescribeBackup", input, d.DescribeBackup)
 }
 
 func (d *DB) DescribeEndpoints(input *dynamodb.DescribeEndpointsInput) (*dynamodb.DescribeEndpointsOutput, error) {
@@ -146,89 +91,15 @@ func (d *DB) DescribeEndpoints(input *dynamodb.DescribeEndpointsInput) (*dynamod
 	panic("implement me")
 }
 
-func (d *DB) DescribeEndpointsWithContext(context aws.Context, input *dynamodb.DescribeEndpointsInput, option ...request.Option) (*dynamodb.DescribeEndpointsOutput, error) {
-	// TODO implement me
-	panic("implement me")
+func (d *DB) DescribeEndpointsWithContext(ctx aws.Context, input *dynamodb.DescribeEndpointsInput, opts ...request.Option) (*dynamodb.DescribeEndpointsOutput, error) {
+	req, output := d.DescribeEndpointsRequest(input)
+	req.SetContext(ctx)
+	req.ApplyOptions(opts...)
+	return output, req.Send()
 }
 
 func (d *DB) DescribeEndpointsRequest(input *dynamodb.DescribeEndpointsInput) (*request.Request, *dynamodb.DescribeEndpointsOutput) {
-	// TODO implement me
-	panic("implement me")
-}
-
-func (d *DB) DescribeExport(input *dynamodb.DescribeExportInput) (*dynamodb.DescribeExportOutput, error) {
-	// TODO implement me
-	panic("implement me")
-}
-
-func (d *DB) DescribeExportWithContext(context aws.Context, input *dynamodb.DescribeExportInput, option ...request.Option) (*dynamodb.DescribeExportOutput, error) {
-	// TODO implement me
-	panic("implement me")
-}
-
-func (d *DB) DescribeExportRequest(input *dynamodb.DescribeExportInput) (*request.Request, *dynamodb.DescribeExportOutput) {
-	// TODO implement me
-	panic("implement me")
-}
-
-func (d *DB) DescribeGlobalTable(input *dynamodb.DescribeGlobalTableInput) (*dynamodb.DescribeGlobalTableOutput, error) {
-	// TODO implement me
-	panic("implement me")
-}
-
-func (d *DB) DescribeGlobalTableWithContext(context aws.Context, input *dynamodb.DescribeGlobalTableInput, option ...request.Option) (*dynamodb.DescribeGlobalTableOutput, error) {
-	// TODO implement me
-	panic("implement me")
-}
-
-func (d *DB) DescribeGlobalTableRequest(input *dynamodb.DescribeGlobalTableInput) (*request.Request, *dynamodb.DescribeGlobalTableOutput) {
-	// TODO implement me
-	panic("implement me")
-}
-
-func (d *DB) DescribeGlobalTableSettings(input *dynamodb.DescribeGlobalTableSettingsInput) (*dynamodb.DescribeGlobalTableSettingsOutput, error) {
-	// TODO implement me
-	panic("implement me")
-}
-
-func (d *DB) DescribeGlobalTableSettingsWithContext(context aws.Context, input *dynamodb.DescribeGlobalTableSettingsInput, option ...request.Option) (*dynamodb.DescribeGlobalTableSettingsOutput, error) {
-	// TODO implement me
-	panic("implement me")
-}
-
-func (d *DB) DescribeGlobalTableSettingsRequest(input *dynamodb.DescribeGlobalTableSettingsInput) (*request.Request, *dynamodb.DescribeGlobalTableSettingsOutput) {
-	// TODO implement me
-	panic("implement me")
-}
-
-func (d *DB) DescribeImport(input *dynamodb.DescribeImportInput) (*dynamodb.DescribeImportOutput, error) {
-	// TODO implement me
-	panic("implement me")
-}
-
-func (d *DB) DescribeImportWithContext(context aws.Context, input *dynamodb.DescribeImportInput, option ...request.Option) (*dynamodb.DescribeImportOutput, error) {
-	// TODO implement me
-	panic("implement me")
-}
-
-func (d *DB) DescribeImportRequest(input *dynamodb.DescribeImportInput) (*request.Request, *dynamodb.DescribeImportOutput) {
-	// TODO implement me
-	panic("implement me")
-}
-
-func (d *DB) DescribeKinesisStreamingDestination(input *dynamodb.DescribeKinesisStreamingDestinationInput) (*dynamodb.DescribeKinesisStreamingDestinationOutput, error) {
-	// TODO implement me
-	panic("implement me")
-}
-
-func (d *DB) DescribeKinesisStreamingDestinationWithContext(context aws.Context, input *dynamodb.DescribeKinesisStreamingDestinationInput, option ...request.Option) (*dynamodb.DescribeKinesisStreamingDestinationOutput, error) {
-	// TODO implement me
-	panic("implement me")
-}
-
-func (d *DB) DescribeKinesisStreamingDestinationRequest(input *dynamodb.DescribeKinesisStreamingDestinationInput) (*request.Request, *dynamodb.DescribeKinesisStreamingDestinationOutput) {
-	// TODO implement me
-	panic("implement me")
+	return newRequest(d, "DescribeEndpoints", input, d.DescribeEndpoints)
 }
 
 func (d *DB) DescribeLimits(input *dynamodb.DescribeLimitsInput) (*dynamodb.DescribeLimitsOutput, error) {
@@ -236,14 +107,15 @@ func (d *DB) DescribeLimits(input *dynamodb.DescribeLimitsInput) (*dynamodb.Desc
 	panic("implement me")
 }
 
-func (d *DB) DescribeLimitsWithContext(context aws.Context, input *dynamodb.DescribeLimitsInput, option ...request.Option) (*dynamodb.DescribeLimitsOutput, error) {
-	// TODO implement me
-	panic("implement me")
+func (d *DB) DescribeLimitsWithContext(ctx aws.Context, input *dynamodb.DescribeLimitsInput, opts ...request.Option) (*dynamodb.DescribeLimitsOutput, error) {
+	req, output := d.DescribeLimitsRequest(input)
+	req.SetContext(ctx)
+	req.ApplyOptions(opts...)
+	return output, req.Send()
 }
 
 func (d *DB) DescribeLimitsRequest(input *dynamodb.DescribeLimitsInput) (*request.Request, *dynamodb.DescribeLimitsOutput) {
-	// TODO implement me
-	panic("implement me")
+	return newRequest(d, "DescribeLimits", input, d.DescribeLimits)
 }
 
 func (d *DB) DescribeTableReplicaAutoScaling(input *dynamodb.DescribeTableReplicaAutoScalingInput) (*dynamodb.DescribeTableReplicaAutoScalingOutput, error) {
@@ -251,59 +123,15 @@ func (d *DB) DescribeTableReplicaAutoScaling(input *dynamodb.DescribeTableReplic
 	panic("implement me")
 }
 
-func (d *DB) DescribeTableReplicaAutoScalingWithContext(context aws.Context, input *dynamodb.DescribeTableReplicaAutoScalingInput, option ...request.Option) (*dynamodb.DescribeTableReplicaAutoScalingOutput, error) {
-	// TODO implement me
-	panic("implement me")
+func (d *DB) DescribeTableReplicaAutoScalingWithContext(ctx aws.Context, input *dynamodb.DescribeTableReplicaAutoScalingInput, opts ...request.Option) (*dynamodb.DescribeTableReplicaAutoScalingOutput, error) {
+	req, output := d.DescribeTableReplicaAutoScalingRequest(input)
+	req.SetContext(ctx)
+	req.ApplyOptions(opts...)
+	return output, req.Send()
 }
 
 func (d *DB) DescribeTableReplicaAutoScalingRequest(input *dynamodb.DescribeTableReplicaAutoScalingInput) (*request.Request, *dynamodb.DescribeTableReplicaAutoScalingOutput) {
-	// TODO implement me
-	panic("implement me")
-}
-
-func (d *DB) DescribeTimeToLive(input *dynamodb.DescribeTimeToLiveInput) (*dynamodb.DescribeTimeToLiveOutput, error) {
-	// TODO implement me
-	panic("implement me")
-}
-
-func (d *DB) DescribeTimeToLiveWithContext(context aws.Context, input *dynamodb.DescribeTimeToLiveInput, option ...request.Option) (*dynamodb.DescribeTimeToLiveOutput, error) {
-	// TODO implement me
-	panic("implement me")
-}
-
-func (d *DB) DescribeTimeToLiveRequest(input *dynamodb.DescribeTimeToLiveInput) (*request.Request, *dynamodb.DescribeTimeToLiveOutput) {
-	// TODO implement me
-	panic("implement me")
-}
-
-func (d *DB) DisableKinesisStreamingDestination(input *dynamodb.DisableKinesisStreamingDestinationInput) (*dynamodb.DisableKinesisStreamingDestinationOutput, error) {
-	// TODO implement me
-	panic("implement me")
-}
-
-func (d *DB) DisableKinesisStreamingDestinationWithContext(context aws.Context, input *dynamodb.DisableKinesisStreamingDestinationInput, option ...request.Option) (*dynamodb.DisableKinesisStreamingDestinationOutput, error) {
-	// TODO implement me
-	panic("implement me")
-}
-
-func (d *DB) DisableKinesisStreamingDestinationRequest(input *dynamodb.DisableKinesisStreamingDestinationInput) (*request.Request, *dynamodb.DisableKinesisStreamingDestinationOutput) {
-	// TODO implement me
-	panic("implement me")
-}
-
-func (d *DB) EnableKinesisStreamingDestination(input *dynamodb.EnableKinesisStreamingDestinationInput) (*dynamodb.EnableKinesisStreamingDestinationOutput, error) {
-	// TODO implement me
-	panic("implement me")
-}
-
-func (d *DB) EnableKinesisStreamingDestinationWithContext(context aws.Context, input *dynamodb.EnableKinesisStreamingDestinationInput, option ...request.Option) (*dynamodb.EnableKinesisStreamingDestinationOutput, error) {
-	// TODO implement me
-	panic("implement me")
-}
-
-func (d *DB) EnableKinesisStreamingDestinationRequest(input *dynamodb.EnableKinesisStreamingDestinationInput) (*request.Request, *dynamodb.EnableKinesisStreamingDestinationOutput) {
-	// TODO implement me
-	panic("implement me")
+	return newRequest(d, "DescribeTableReplicaAutoScaling", input, d.DescribeTableReplicaAutoScaling)
 }
 
 func (d *DB) ExecuteStatement(input *dynamodb.ExecuteStatementInput) (*dynamodb.ExecuteStatementOutput, error) {
@@ -311,14 +139,15 @@ func (d *DB) ExecuteStatement(input *dynamodb.ExecuteStatementInput) (*dynamodb.
 	panic("implement me")
 }
 
-func (d *DB) ExecuteStatementWithContext(context aws.Context, input *dynamodb.ExecuteStatementInput, option ...request.Option) (*dynamodb.ExecuteStatementOutput, error) {
-	// TODO implement me
-	panic("implement me")
+func (d *DB) ExecuteStatementWithContext(ctx aws.Context, input *dynamodb.ExecuteStatementInput, opts ...request.Option) (*dynamodb.ExecuteStatementOutput, error) {
+	req, output := d.ExecuteStatementRequest(input)
+	req.SetContext(ctx)
+	req.ApplyOptions(opts...)
+	return output, req.Send()
 }
 
 func (d *DB) ExecuteStatementRequest(input *dynamodb.ExecuteStatementInput) (*request.Request, *dynamodb.ExecuteStatementOutput) {
-	// TODO implement me
-	panic("implement me")
+	return newRequest(d, "ExecuteStatement", input, d.ExecuteStatement)
 }
 
 func (d *DB) ExecuteTransaction(input *dynamodb.ExecuteTransactionInput) (*dynamodb.ExecuteTransactionOutput, error) {
@@ -326,59 +155,15 @@ func (d *DB) ExecuteTransaction(input *dynamodb.ExecuteTransactionInput) (*dynam
 	panic("implement me")
 }
 
-func (d *DB) ExecuteTransactionWithContext(context aws.Context, input *dynamodb.ExecuteTransactionInput, option ...request.Option) (*dynamodb.ExecuteTransactionOutput, error) {
-	// TODO implement me
-	panic("implement me")
+func (d *DB) ExecuteTransactionWithContext(ctx aws.Context, input *dynamodb.ExecuteTransactionInput, opts ...request.Option) (*dynamodb.ExecuteTransactionOutput, error) {
+	req, output := d.ExecuteTransactionRequest(input)
+	req.SetContext(ctx)
+	req.ApplyOptions(opts...)
+	return output, req.Send()
 }
 
 func (d *DB) ExecuteTransactionRequest(input *dynamodb.ExecuteTransactionInput) (*request.Request, *dynamodb.ExecuteTransactionOutput) {
-	// TODO implement me
-	panic("implement me")
-}
-
-func (d *DB) ExportTableToPointInTime(input *dynamodb.ExportTableToPointInTimeInput) (*dynamodb.ExportTableToPointInTimeOutput, error) {
-	// TODO implement me
-	panic("implement me")
-}
-
-func (d *DB) ExportTableToPointInTimeWithContext(context aws.Context, input *dynamodb.ExportTableToPointInTimeInput, option ...request.Option) (*dynamodb.ExportTableToPointInTimeOutput, error) {
-	// TODO implement me
-	panic("implement me")
-}
-
-func (d *DB) ExportTableToPointInTimeRequest(input *dynamodb.ExportTableToPointInTimeInput) (*request.Request, *dynamodb.ExportTableToPointInTimeOutput) {
-	// TODO implement me
-	panic("implement me")
-}
-
-func (d *DB) GetResourcePolicy(input *dynamodb.GetResourcePolicyInput) (*dynamodb.GetResourcePolicyOutput, error) {
-	// TODO implement me
-	panic("implement me")
-}
-
-func (d *DB) GetResourcePolicyWithContext(context aws.Context, input *dynamodb.GetResourcePolicyInput, option ...request.Option) (*dynamodb.GetResourcePolicyOutput, error) {
-	// TODO implement me
-	panic("implement me")
-}
-
-func (d *DB) GetResourcePolicyRequest(input *dynamodb.GetResourcePolicyInput) (*request.Request, *dynamodb.GetResourcePolicyOutput) {
-	// TODO implement me
-	panic("implement me")
-}
-
-func (d *DB) ImportTable(input *dynamodb.ImportTableInput) (*dynamodb.ImportTableOutput, error) {
-	// TODO implement me
-	panic("implement me")
-}
-
-func (d *DB) ImportTableWithContext(context aws.Context, input *dynamodb.ImportTableInput, option ...request.Option) (*dynamodb.ImportTableOutput, error) {
-	// TODO implement me
-	panic("implement me")
-}
-
-func (d *DB) ImportTableRequest(input *dynamodb.ImportTableInput) (*request.Request, *dynamodb.ImportTableOutput) {
-	// TODO implement me
-	panic("implement me")
+	return newRequest(d, "ExecuteTransaction", input, d.ExecuteTransaction)
 }
 
 func (d *DB) ListBackups(input *dynamodb.ListBackupsInput) (*dynamodb.ListBackupsOutput, error) {
@@ -386,134 +171,15 @@ func (d *DB) ListBackups(input *dynamodb.ListBackupsInput) (*dynamodb.ListBackup
 	panic("implement me")
 }
 
-func (d *DB) ListBackupsWithContext(context aws.Context, input *dynamodb.ListBackupsInput, option ...request.Option) (*dynamodb.ListBackupsOutput, error) {
-	// TODO implement me
-	panic("implement me")
+func (d *DB) ListBackupsWithContext(ctx aws.Context, input *dynamodb.ListBackupsInput, opts ...request.Option) (*dynamodb.ListBackupsOutput, error) {
+	req, output := d.ListBackupsRequest(input)
+	req.SetContext(ctx)
+	req.ApplyOptions(opts...)
+	return output, req.Send()
 }
 
 func (d *DB) ListBackupsRequest(input *dynamodb.ListBackupsInput) (*request.Request, *dynamodb.ListBackupsOutput) {
-	// TODO implement me
-	panic("implement me")
-}
-
-func (d *DB) ListContributorInsights(input *dynamodb.ListContributorInsightsInput) (*dynamodb.ListContributorInsightsOutput, error) {
-	// TODO implement me
-	panic("implement me")
-}
-
-func (d *DB) ListContributorInsightsWithContext(context aws.Context, input *dynamodb.ListContributorInsightsInput, option ...request.Option) (*dynamodb.ListContributorInsightsOutput, error) {
-	// TODO implement me
-	panic("implement me")
-}
-
-func (d *DB) ListContributorInsightsRequest(input *dynamodb.ListContributorInsightsInput) (*request.Request, *dynamodb.ListContributorInsightsOutput) {
-	// TODO implement me
-	panic("implement me")
-}
-
-func (d *DB) ListContributorInsightsPages(input *dynamodb.ListContributorInsightsInput, f func(*dynamodb.ListContributorInsightsOutput, bool) bool) error {
-	// TODO implement me
-	panic("implement me")
-}
-
-func (d *DB) ListContributorInsightsPagesWithContext(context aws.Context, input *dynamodb.ListContributorInsightsInput, f func(*dynamodb.ListContributorInsightsOutput, bool) bool, option ...request.Option) error {
-	// TODO implement me
-	panic("implement me")
-}
-
-func (d *DB) ListExports(input *dynamodb.ListExportsInput) (*dynamodb.ListExportsOutput, error) {
-	// TODO implement me
-	panic("implement me")
-}
-
-func (d *DB) ListExportsWithContext(context aws.Context, input *dynamodb.ListExportsInput, option ...request.Option) (*dynamodb.ListExportsOutput, error) {
-	// TODO implement me
-	panic("implement me")
-}
-
-func (d *DB) ListExportsRequest(input *dynamodb.ListExportsInput) (*request.Request, *dynamodb.ListExportsOutput) {
-	// TODO implement me
-	panic("implement me")
-}
-
-func (d *DB) ListExportsPages(input *dynamodb.ListExportsInput, f func(*dynamodb.ListExportsOutput, bool) bool) error {
-	// TODO implement me
-	panic("implement me")
-}
-
-func (d *DB) ListExportsPagesWithContext(context aws.Context, input *dynamodb.ListExportsInput, f func(*dynamodb.ListExportsOutput, bool) bool, option ...request.Option) error {
-	// TODO implement me
-	panic("implement me")
-}
-
-func (d *DB) ListGlobalTables(input *dynamodb.ListGlobalTablesInput) (*dynamodb.ListGlobalTablesOutput, error) {
-	// TODO implement me
-	panic("implement me")
-}
-
-func (d *DB) ListGlobalTablesWithContext(context aws.Context, input *dynamodb.ListGlobalTablesInput, option ...request.Option) (*dynamodb.ListGlobalTablesOutput, error) {
-	// TODO implement me
-	panic("implement me")
-}
-
-func (d *DB) ListGlobalTablesRequest(input *dynamodb.ListGlobalTablesInput) (*request.Request, *dynamodb.ListGlobalTablesOutput) {
-	// TODO implement me
-	panic("implement me")
-}
-
-func (d *DB) ListImports(input *dynamodb.ListImportsInput) (*dynamodb.ListImportsOutput, error) {
-	// TODO implement me
-	panic("implement me")
-}
-
-func (d *DB) ListImportsWithContext(context aws.Context, input *dynamodb.ListImportsInput, option ...request.Option) (*dynamodb.ListImportsOutput, error) {
-	// TODO implement me
-	panic("implement me")
-}
-
-func (d *DB) ListImportsRequest(input *dynamodb.ListImportsInput) (*request.Request, *dynamodb.ListImportsOutput) {
-	// TODO implement me
-	panic("implement me")
-}
-
-func (d *DB) ListImportsPages(input *dynamodb.ListImportsInput, f func(*dynamodb.ListImportsOutput, bool) bool) error {
-	// TODO implement me
-	panic("implement me")
-}
-
-func (d *DB) ListImportsPagesWithContext(context aws.Context, input *dynamodb.ListImportsInput, f func(*dynamodb.ListImportsOutput, bool) bool, option ...request.Option) error {
-	// TODO implement me
-	panic("implement me")
-}
-
-func (d *DB) ListTagsOfResource(input *dynamodb.ListTagsOfResourceInput) (*dynamodb.ListTagsOfResourceOutput, error) {
-	// TODO implement me
-	panic("implement me")
-}
-
-func (d *DB) ListTagsOfResourceWithContext(context aws.Context, input *dynamodb.ListTagsOfResourceInput, option ...request.Option) (*dynamodb.ListTagsOfResourceOutput, error) {
-	// TODO implement me
-	panic("implement me")
-}
-
-func (d *DB) ListTagsOfResourceRequest(input *dynamodb.ListTagsOfResourceInput) (*request.Request, *dynamodb.ListTagsOfResourceOutput) {
-	// TODO implement me
-	panic("implement me")
-}
-
-func (d *DB) PutResourcePolicy(input *dynamodb.PutResourcePolicyInput) (*dynamodb.PutResourcePolicyOutput, error) {
-	// TODO implement me
-	panic("implement me")
-}
-
-func (d *DB) PutResourcePolicyWithContext(context aws.Context, input *dynamodb.PutResourcePolicyInput, option ...request.Option) (*dynamodb.PutResourcePolicyOutput, error) {
-	// TODO implement me
-	panic("implement me")
-}
-
-func (d *DB) PutResourcePolicyRequest(input *dynamodb.PutResourcePolicyInput) (*request.Request, *dynamodb.PutResourcePolicyOutput) {
-	// TODO implement me
-	panic("implement me")
+	return newRequest(d, "ListBackups", input, d.ListBackups)
 }
 
 func (d *DB) RestoreTableFromBackup(input *dynamodb.RestoreTableFromBackupInput) (*dynamodb.RestoreTableFromBackupOutput, error) {
@@ -521,14 +187,15 @@ func (d *DB) RestoreTableFromBackup(input *dynamodb.RestoreTableFromBackupInput)
 	panic("implement me")
 }
 
-func (d *DB) RestoreTableFromBackupWithContext(context aws.Context, input *dynamodb.RestoreTableFromBackupInput, option ...request.Option) (*dynamodb.RestoreTableFromBackupOutput, error) {
-	// TODO implement me
-	panic("implement me")
+func (d *DB) RestoreTableFromBackupWithContext(ctx aws.Context, input *dynamodb.RestoreTableFromBackupInput, opts ...request.Option) (*dynamodb.RestoreTableFromBackupOutput, error) {
+	req, output := d.RestoreTableFromBackupRequest(input)
+	req.SetContext(ctx)
+	req.ApplyOptions(opts...)
+	return output, req.Send()
 }
 
 func (d *DB) RestoreTableFromBackupRequest(input *dynamodb.RestoreTableFromBackupInput) (*request.Request, *dynamodb.RestoreTableFromBackupOutput) {
-	// TODO implement me
-	panic("implement me")
+	return newRequest(d, "RestoreTableFromBackup", input, d.RestoreTableFromBackup)
 }
 
 func (d *DB) RestoreTableToPointInTime(input *dynamodb.RestoreTableToPointInTimeInput) (*dynamodb.RestoreTableToPointInTimeOutput, error) {
@@ -536,119 +203,15 @@ func (d *DB) RestoreTableToPointInTime(input *dynamodb.RestoreTableToPointInTime
 	panic("implement me")
 }
 
-func (d *DB) RestoreTableToPointInTimeWithContext(context aws.Context, input *dynamodb.RestoreTableToPointInTimeInput, option ...request.Option) (*dynamodb.RestoreTableToPointInTimeOutput, error) {
-	// TODO implement me
-	panic("implement me")
+func (d *DB) RestoreTableToPointInTimeWithContext(ctx aws.Context, input *dynamodb.RestoreTableToPointInTimeInput, opts ...request.Option) (*dynamodb.RestoreTableToPointInTimeOutput, error) {
+	req, output := d.RestoreTableToPointInTimeRequest(input)
+	req.SetContext(ctx)
+	req.ApplyOptions(opts...)
+	return output, req.Send()
 }
 
 func (d *DB) RestoreTableToPointInTimeRequest(input *dynamodb.RestoreTableToPointInTimeInput) (*request.Request, *dynamodb.RestoreTableToPointInTimeOutput) {
-	// TODO implement me
-	panic("implement me")
-}
-
-func (d *DB) TagResource(input *dynamodb.TagResourceInput) (*dynamodb.TagResourceOutput, error) {
-	// TODO implement me
-	panic("implement me")
-}
-
-func (d *DB) TagResourceWithContext(context aws.Context, input *dynamodb.TagResourceInput, option ...request.Option) (*dynamodb.TagResourceOutput, error) {
-	// TODO implement me
-	panic("implement me")
-}
-
-func (d *DB) TagResourceRequest(input *dynamodb.TagResourceInput) (*request.Request, *dynamodb.TagResourceOutput) {
-	// TODO implement me
-	panic("implement me")
-}
-
-func (d *DB) UntagResource(input *dynamodb.UntagResourceInput) (*dynamodb.UntagResourceOutput, error) {
-	// TODO implement me
-	panic("implement me")
-}
-
-func (d *DB) UntagResourceWithContext(context aws.Context, input *dynamodb.UntagResourceInput, option ...request.Option) (*dynamodb.UntagResourceOutput, error) {
-	// TODO implement me
-	panic("implement me")
-}
-
-func (d *DB) UntagResourceRequest(input *dynamodb.UntagResourceInput) (*request.Request, *dynamodb.UntagResourceOutput) {
-	// TODO implement me
-	panic("implement me")
-}
-
-func (d *DB) UpdateContinuousBackups(input *dynamodb.UpdateContinuousBackupsInput) (*dynamodb.UpdateContinuousBackupsOutput, error) {
-	// TODO implement me
-	panic("implement me")
-}
-
-func (d *DB) UpdateContinuousBackupsWithContext(context aws.Context, input *dynamodb.UpdateContinuousBackupsInput, option ...request.Option) (*dynamodb.UpdateContinuousBackupsOutput, error) {
-	// TODO implement me
-	panic("implement me")
-}
-
-func (d *DB) UpdateContinuousBackupsRequest(input *dynamodb.UpdateContinuousBackupsInput) (*request.Request, *dynamodb.UpdateContinuousBackupsOutput) {
-	// TODO implement me
-	panic("implement me")
-}
-
-func (d *DB) UpdateContributorInsights(input *dynamodb.UpdateContributorInsightsInput) (*dynamodb.UpdateContributorInsightsOutput, error) {
-	// TODO implement me
-	panic("implement me")
-}
-
-func (d *DB) UpdateContributorInsightsWithContext(context aws.Context, input *dynamodb.UpdateContributorInsightsInput, option ...request.Option) (*dynamodb.UpdateContributorInsightsOutput, error) {
-	// TODO implement me
-	panic("implement me")
-}
-
-func (d *DB) UpdateContributorInsightsRequest(input *dynamodb.UpdateContributorInsightsInput) (*request.Request, *dynamodb.UpdateContributorInsightsOutput) {
-	// TODO implement me
-	panic("implement me")
-}
-
-func (d *DB) UpdateGlobalTable(input *dynamodb.UpdateGlobalTableInput) (*dynamodb.UpdateGlobalTableOutput, error) {
-	// TODO implement me
-	panic("implement me")
-}
-
-func (d *DB) UpdateGlobalTableWithContext(context aws.Context, input *dynamodb.UpdateGlobalTableInput, option ...request.Option) (*dynamodb.UpdateGlobalTableOutput, error) {
-	// TODO implement me
-	panic("implement me")
-}
-
-func (d *DB) UpdateGlobalTableRequest(input *dynamodb.UpdateGlobalTableInput) (*request.Request, *dynamodb.UpdateGlobalTableOutput) {
-	// TODO implement me
-	panic("implement me")
-}
-
-func (d *DB) UpdateGlobalTableSettings(input *dynamodb.UpdateGlobalTableSettingsInput) (*dynamodb.UpdateGlobalTableSettingsOutput, error) {
-	// TODO implement me
-	panic("implement me")
-}
-
-func (d *DB) UpdateGlobalTableSettingsWithContext(context aws.Context, input *dynamodb.UpdateGlobalTableSettingsInput, option ...request.Option) (*dynamodb.UpdateGlobalTableSettingsOutput, error) {
-	// TODO implement me
-	panic("implement me")
-}
-
-func (d *DB) UpdateGlobalTableSettingsRequest(input *dynamodb.UpdateGlobalTableSettingsInput) (*request.Request, *dynamodb.UpdateGlobalTableSettingsOutput) {
-	// TODO implement me
-	panic("implement me")
-}
-
-func (d *DB) UpdateKinesisStreamingDestination(input *dynamodb.UpdateKinesisStreamingDestinationInput) (*dynamodb.UpdateKinesisStreamingDestinationOutput, error) {
-	// TODO implement me
-	panic("implement me")
-}
-
-func (d *DB) UpdateKinesisStreamingDestinationWithContext(context aws.Context, input *dynamodb.UpdateKinesisStreamingDestinationInput, option ...request.Option) (*dynamodb.UpdateKinesisStreamingDestinationOutput, error) {
-	// TODO implement me
-	panic("implement me")
-}
-
-func (d *DB) UpdateKinesisStreamingDestinationRequest(input *dynamodb.UpdateKinesisStreamingDestinationInput) (*request.Request, *dynamodb.UpdateKinesisStreamingDestinationOutput) {
-	// TODO implement me
-	panic("implement me")
+	return newRequest(d, "RestoreTableToPointInTime", input, d.RestoreTableToPointInTime)
 }
 
 func (d *DB) UpdateTableReplicaAutoScaling(input *dynamodb.UpdateTableReplicaAutoScalingInput) (*dynamodb.UpdateTableReplicaAutoScalingOutput, error) {
@@ -656,47 +219,13 @@ func (d *DB) UpdateTableReplicaAutoScaling(input *dynamodb.UpdateTableReplicaAut
 	panic("implement me")
 }
 
-func (d *DB) UpdateTableReplicaAutoScalingWithContext(context aws.Context, input *dynamodb.UpdateTableReplicaAutoScalingInput, option ...request.Option) (*dynamodb.UpdateTableReplicaAutoScalingOutput, error) {
-	// TODO implement me
-	panic("implement me")
+func (d *DB) UpdateTableReplicaAutoScalingWithContext(ctx aws.Context, input *dynamodb.UpdateTableReplicaAutoScalingInput, opts ...request.Option) (*dynamodb.UpdateTableReplicaAutoScalingOutput, error) {
+	req, output := d.UpdateTableReplicaAutoScalingRequest(input)
+	req.SetContext(ctx)
+	req.ApplyOptions(opts...)
+	return output, req.Send()
 }
 
 func (d *DB) UpdateTableReplicaAutoScalingRequest(input *dynamodb.UpdateTableReplicaAutoScalingInput) (*request.Request, *dynamodb.UpdateTableReplicaAutoScalingOutput) {
-	// TODO implement me
-	panic("implement me")
-}
-
-func (d *DB) UpdateTimeToLive(input *dynamodb.UpdateTimeToLiveInput) (*dynamodb.UpdateTimeToLiveOutput, error) {
-	// TODO implement me
-	panic("implement me")
-}
-
-func (d *DB) UpdateTimeToLiveWithContext(context aws.Context, input *dynamodb.UpdateTimeToLiveInput, option ...request.Option) (*dynamodb.UpdateTimeToLiveOutput, error) {
-	// TODO implement me
-	panic("implement me")
-}
-
-func (d *DB) UpdateTimeToLiveRequest(input *dynamodb.UpdateTimeToLiveInput) (*request.Request, *dynamodb.UpdateTimeToLiveOutput) {
-	// TODO implement me
-	panic("implement me")
-}
-
-func (d *DB) WaitUntilTableExists(input *dynamodb.DescribeTableInput) error {
-	// TODO implement me
-	panic("implement me")
-}
-
-func (d *DB) WaitUntilTableExistsWithContext(context aws.Context, input *dynamodb.DescribeTableInput, option ...request.WaiterOption) error {
-	// TODO implement me
-	panic("implement me")
-}
-
-func (d *DB) WaitUntilTableNotExists(input *dynamodb.DescribeTableInput) error {
-	// TODO implement me
-	panic("implement me")
-}
-
-func (d *DB) WaitUntilTableNotExistsWithContext(context aws.Context, input *dynamodb.DescribeTableInput, option ...request.WaiterOption) error {
-	// TODO implement me
-	panic("implement me")
+	return newRequest(d, "UpdateTableReplicaAutoScaling", input, d.UpdateTableReplicaAutoScaling)
 }
@@ -11,10 +11,13 @@ func (d *DB) TransactWriteItems(input *dynamodb.TransactWriteItemsInput) (*dynam
 	panic("implement me")
 }
 
-func (d *DB) TransactWriteItemsWithContext(_ aws.Context, input *dynamodb.TransactWriteItemsInput, _ ...request.Option) (*dynamodb.TransactWriteItemsOutput, error) {
-	return d.TransactWriteItems(input)
+func (d *DB) TransactWriteItemsWithContext(ctx aws.Context, input *dynamodb.TransactWriteItemsInput, opts ...request.Option) (*dynamodb.TransactWriteItemsOutput, error) {
+	req, output := d.TransactWriteItemsRequest(input)
+	req.SetContext(ctx)
+	req.ApplyOptions(opts...)
+	return output, req.Send()
 }
 
-func (d *DB) TransactWriteItemsRequest(_ *dynamodb.TransactWriteItemsInput) (*request.Request, *dynamodb.TransactWriteItemsOutput) {
-	panic("not implemented: TransactWriteItemsRequest")
+func (d *DB) TransactWriteItemsRequest(input *dynamodb.TransactWriteItemsInput) (*request.Request, *dynamodb.TransactWriteItemsOutput) {
+	return newRequest(d, "TransactWriteItems", input, d.TransactWriteItems)
 }
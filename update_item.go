@@ -11,12 +11,13 @@ func (d *DB) UpdateItem(input *dynamodb.UpdateItemInput) (*dynamodb.UpdateItemOu
 	panic("implement me")
 }
 
-func (d *DB) UpdateItemWithContext(context aws.Context, input *dynamodb.UpdateItemInput, option ...request.Option) (*dynamodb.UpdateItemOutput, error) {
-	// TODO implement me
-	panic("implement me")
+func (d *DB) UpdateItemWithContext(ctx aws.Context, input *dynamodb.UpdateItemInput, opts ...request.Option) (*dynamodb.UpdateItemOutput, error) {
+	req, output := d.UpdateItemRequest(input)
+	req.SetContext(ctx)
+	req.ApplyOptions(opts...)
+	return output, req.Send()
 }
 
 func (d *DB) UpdateItemRequest(input *dynamodb.UpdateItemInput) (*request.Request, *dynamodb.UpdateItemOutput) {
-	// TODO implement me
-	panic("implement me")
+	return newRequest(d, "UpdateItem", input, d.UpdateItem)
 }
@@ -1,22 +1,268 @@
 package fakedynamo
 
 import (
+	"errors"
+	"slices"
+	"time"
+
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 )
 
 func (d *DB) UpdateTable(input *dynamodb.UpdateTableInput) (*dynamodb.UpdateTableOutput, error) {
-	// TODO implement me
-	panic("implement me")
+	return d.updateTableContext(aws.BackgroundContext(), input)
+}
+
+func (d *DB) updateTableContext(ctx aws.Context, input *dynamodb.UpdateTableInput) (*dynamodb.UpdateTableOutput, error) {
+	if err := d.injectFault(ctx, "UpdateTable", input); err != nil {
+		return nil, err
+	}
+	return d.updateTable(input)
+}
+
+// updateTable implements UpdateTable. Other operations which update tables use it
+// directly, so that faults injected into UpdateTable don't affect them.
+func (d *DB) updateTable(input *dynamodb.UpdateTableInput) (*dynamodb.UpdateTableOutput, error) {
+	var errs []error
+	if input.TableName == nil {
+		errs = append(errs, newValidationError("TableName is a required field"))
+	}
+	if input.AttributeDefinitions != nil {
+		errs = append(errs, errors.New("not implemented: UpdateTableInput.AttributeDefinitions"))
+	}
+	if input.GlobalSecondaryIndexUpdates != nil {
+		errs = append(errs, errors.New("not implemented: UpdateTableInput.GlobalSecondaryIndexUpdates"))
+	}
+	if input.SSESpecification != nil {
+		errs = append(errs, errors.New("not implemented: UpdateTableInput.SSESpecification"))
+	}
+	if input.TableClass != nil {
+		errs = append(errs, errors.New("not implemented: UpdateTableInput.TableClass"))
+	}
+	if input.BillingMode != nil && !slices.Contains(dynamodb.BillingMode_Values(), *input.BillingMode) {
+		errs = append(errs, newValidationErrorf("BillingMode must be one of %v", dynamodb.BillingMode_Values()))
+	}
+	if input.ReplicaUpdates != nil && len(input.ReplicaUpdates) == 0 {
+		errs = append(errs, newValidationError("ReplicaUpdates must contain at least 1 element"))
+	}
+	for i, update := range input.ReplicaUpdates {
+		errs = append(errs, validateReplicationGroupUpdate(update, i))
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	if err := d.updateTableSpec(input); err != nil {
+		return nil, err
+	}
+	for _, update := range input.ReplicaUpdates {
+		var err error
+		if update.Create != nil {
+			err = d.createReplica(*input.TableName, *update.Create.RegionName)
+		} else {
+			err = d.deleteReplica(*input.TableName, *update.Delete.RegionName)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	desc := d.describeTable(*input.TableName)
+	if desc == nil {
+		return nil, &dynamodb.ResourceNotFoundException{}
+	}
+	return &dynamodb.UpdateTableOutput{
+		TableDescription: desc,
+	}, nil
+}
+
+func validateReplicationGroupUpdate(update *dynamodb.ReplicationGroupUpdate, i int) error {
+	switch {
+	case update == nil || toInt(update.Create != nil)+toInt(update.Delete != nil)+toInt(update.Update != nil) != 1:
+		return newValidationErrorf("ReplicaUpdates[%d] must specify exactly one of Create, Update and Delete", i)
+	case update.Update != nil:
+		return errors.New("not implemented: ReplicationGroupUpdate.Update")
+	case update.Create != nil && update.Create.RegionName == nil:
+		return newValidationErrorf("ReplicaUpdates[%d].Create.RegionName is a required field", i)
+	case update.Delete != nil && update.Delete.RegionName == nil:
+		return newValidationErrorf("ReplicaUpdates[%d].Delete.RegionName is a required field", i)
+	}
+	return nil
+}
+
+// updateTableSpec applies the parts of an UpdateTable request which only
+// affect this region's table.
+func (d *DB) updateTableSpec(input *dynamodb.UpdateTableInput) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	t, exists := d.tables.Get(tableKey(*input.TableName))
+	if !exists {
+		return &dynamodb.ResourceNotFoundException{}
+	}
+
+	// The spec may be shared with the CreateTableInput which created the table,
+	// so we mustn't modify it in place.
+	spec := shallowCopy(t.spec)
+	if input.BillingMode != nil {
+		spec.BillingMode = input.BillingMode
+	}
+	if input.DeletionProtectionEnabled != nil {
+		spec.DeletionProtectionEnabled = input.DeletionProtectionEnabled
+	}
+	if input.OnDemandThroughput != nil {
+		spec.OnDemandThroughput = input.OnDemandThroughput
+	}
+	if input.ProvisionedThroughput != nil {
+		spec.ProvisionedThroughput = input.ProvisionedThroughput
+	}
+	if input.StreamSpecification != nil {
+		spec.StreamSpecification = input.StreamSpecification
+	}
+	err := d.changeTable(t, func(updated *table) error {
+		updated.spec = spec
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	t.throughput.update(spec, d.now())
+	return nil
+}
+
+// createReplica adds a replica of the named table in the given region,
+// making the table a version 2019.11.21 global table if it isn't already one.
+// The replica is created from this region's table, and backfilled with its
+// items.
+func (d *DB) createReplica(name, region string) error {
+	if d.regions == nil {
+		return errNoRegions
+	}
+	target := d.regions.DB(region)
+	if target == nil {
+		return newValidationErrorf("unknown region %q", region)
+	}
+
+	global := d.regions.globalTable(name)
+	switch {
+	case global == nil:
+		global = &globalTable{
+			name:      name,
+			version:   globalTableVersion2019,
+			createdAt: time.Now().UTC(),
+			regions:   []string{d.region},
+		}
+	case global.version != globalTableVersion2019:
+		return newValidationErrorf("table %s belongs to a version %s global table; use UpdateGlobalTable instead", name, global.version)
+	case slices.Contains(global.regions, region):
+		return newValidationErrorf("table %s already has a replica in region %s", name, region)
+	}
+
+	d.mu.RLock()
+	t, exists := d.tables.Get(tableKey(name))
+	var spec *dynamodb.CreateTableInput
+	if exists {
+		spec = t.spec
+	}
+	d.mu.RUnlock()
+	if !exists {
+		return &dynamodb.ResourceNotFoundException{}
+	}
+
+	_, err := target.createTable(&dynamodb.CreateTableInput{
+		AttributeDefinitions:      spec.AttributeDefinitions,
+		BillingMode:               spec.BillingMode,
+		DeletionProtectionEnabled: nil,
+		GlobalSecondaryIndexes:    spec.GlobalSecondaryIndexes,
+		KeySchema:                 spec.KeySchema,
+		LocalSecondaryIndexes:     spec.LocalSecondaryIndexes,
+		OnDemandThroughput:        spec.OnDemandThroughput,
+		ProvisionedThroughput:     spec.ProvisionedThroughput,
+		ResourcePolicy:            nil,
+		SSESpecification:          spec.SSESpecification,
+		StreamSpecification:       spec.StreamSpecification,
+		TableClass:                spec.TableClass,
+		TableName:                 spec.TableName,
+		Tags:                      nil,
+	})
+	if err != nil {
+		return err
+	}
+
+	// Once the global table is registered, every new write is replicated to
+	// the new region. Anything written before then is copied by the backfill.
+	// Items written in both ways are reconciled by last-writer-wins.
+	global.regions = append(global.regions, region)
+	d.regions.registerGlobalTable(global)
+	events, err := d.backfillEvents(name, region)
+	for _, event := range events {
+		target.applyReplicatedWrite(event)
+	}
+	return err
+}
+
+// backfillEvents lists every item in the named table, as replication events
+// for the given region. Items which haven't been written since the table
+// became global are given a zero timestamp, so that any later write wins.
+func (d *DB) backfillEvents(name, region string) ([]replicationEvent, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	t, exists := d.tables.Get(tableKey(name))
+	if !exists {
+		return nil, nil
+	}
+
+	var events []replicationEvent
+	err := t.items.Ascend(func(item avmap) bool {
+		meta, seen := t.replication[t.itemKeyString(item)]
+		if !seen {
+			meta = replicationMeta{updatedAt: time.Time{}, region: d.region}
+		}
+		events = append(events, replicationEvent{
+			region:    region,
+			tableName: name,
+			key:       t.extractKeys(item),
+			item:      item,
+			meta:      meta,
+			deliverAt: time.Time{},
+		})
+		return true
+	})
+	return events, err
+}
+
+// deleteReplica removes the named table's replica in the given region, and
+// deletes the table there.
+func (d *DB) deleteReplica(name, region string) error {
+	if d.regions == nil {
+		return errNoRegions
+	}
+	global := d.regions.globalTable(name)
+	if global == nil || global.version != globalTableVersion2019 || !slices.Contains(global.regions, region) {
+		return newValidationErrorf("table %s has no replica in region %s", name, region)
+	}
+	if region == d.region {
+		return newValidationErrorf("cannot delete the replica in %s from its own region", region)
+	}
+
+	d.regions.removeReplica(name, region)
+	target := d.regions.DB(region)
+	if target == nil {
+		return nil
+	}
+	_, err := target.deleteTable(&dynamodb.DeleteTableInput{TableName: &name})
+	return err
 }
 
-func (d *DB) UpdateTableWithContext(context aws.Context, input *dynamodb.UpdateTableInput, option ...request.Option) (*dynamodb.UpdateTableOutput, error) {
-	// TODO implement me
-	panic("implement me")
+func (d *DB) UpdateTableWithContext(ctx aws.Context, input *dynamodb.UpdateTableInput, opts ...request.Option) (*dynamodb.UpdateTableOutput, error) {
+	req, output := d.UpdateTableRequest(input)
+	req.SetContext(ctx)
+	req.ApplyOptions(opts...)
+	return output, req.Send()
 }
 
 func (d *DB) UpdateTableRequest(input *dynamodb.UpdateTableInput) (*request.Request, *dynamodb.UpdateTableOutput) {
-	// TODO implement me
-	panic("implement me")
+	return newContextRequest(d, "UpdateTable", input, d.updateTableContext)
 }
@@ -73,6 +73,10 @@ func (d dummyAwsError) RequestID() string {
 	return ""
 }
 
+func (d dummyAwsError) StatusCode() int {
+	return d.httpStatusCode
+}
+
 // The DynamoDB service doesn't explicitly define a ValidationException error, so it doesn't show up in the SDK.
 // https://github.com/aws/aws-sdk/issues/47 and https://github.com/aws/aws-sdk-go-v2/issues/3040
 func newValidationError(message string) error {
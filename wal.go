@@ -0,0 +1,387 @@
+package fakedynamo
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// Durable DBs
+//
+// A DB opened with [OpenDB] keeps its state in a directory holding two files:
+//
+//   - "snapshot", in the format written by [DB.SaveSnapshot], and
+//   - "wal", a write-ahead log of the changes made since the snapshot.
+//
+// The log is a sequence of records. Each record is a 4-byte big-endian
+// payload length, a 4-byte big-endian CRC-32C checksum of the payload, and the
+// payload: a walRecord encoded as JSON. Items are in DynamoDB JSON.
+//
+// Once compactEvery records have been logged, the DB's state is written to a
+// new snapshot and the log is emptied. Records only set state, so replaying
+// the log on top of a snapshot which already includes it does no harm, in
+// case the DB stops between the two steps.
+
+const (
+	snapshotFileName = "snapshot"
+	walFileName      = "wal"
+	// maxWALRecordSize guards against reading a corrupt length.
+	maxWALRecordSize = 64 << 20
+	// defaultCompactEvery is how many records are logged between snapshots,
+	// unless configured with [WithCompactEvery].
+	defaultCompactEvery = 10000
+)
+
+var crc32c = crc32.MakeTable(crc32.Castagnoli)
+
+// walRecord is a change to a durable DB. Exactly one field is set.
+type walRecord struct {
+	// Table creates the table or replaces its metadata.
+	Table *snapshotTable `json:",omitempty"`
+	// DeleteTable deletes the named table.
+	DeleteTable string `json:",omitempty"`
+	// PutItem stores an item, in the form {"TableName": ..., "Item": ...}.
+	PutItem json.RawMessage `json:",omitempty"`
+	// DeleteItem deletes an item, in the form {"TableName": ..., "Key": ...}.
+	DeleteItem json.RawMessage `json:",omitempty"`
+}
+
+// wal is the write-ahead log of a durable DB. It is guarded by the DB's mu:
+// only goroutines holding the write lock may append to it.
+type wal struct {
+	dir  string
+	file *os.File
+	// size is the length of the log's valid records.
+	size int64
+	// records counts the records logged since the last compaction.
+	records int
+}
+
+// OpenDB returns a DB which keeps its state in the given directory, so that
+// it survives restarts. The directory is created if it doesn't exist. State
+// left by a previous DB in the directory is loaded; if the previous DB
+// stopped part way through logging a change, the incomplete change is
+// discarded. OpenDB fails if the state is damaged in any other way.
+//
+// Every change is written and synced to disk before the operation making it
+// returns. If the change can't be written, the operation fails without
+// making it. Only the state saved by [DB.SaveSnapshot] is kept: tables, with
+// their tags, the resource policies of the tables and their streams, and Time
+// to Live settings, and items. Call [DB.Close] when done with the DB.
+func OpenDB(dir string, opts ...Option) (*DB, error) {
+	d := NewDB(opts...)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	snapshot, err := os.Open(filepath.Join(dir, snapshotFileName))
+	switch {
+	case err == nil:
+		err = d.LoadSnapshot(snapshot)
+		_ = snapshot.Close()
+		if err != nil {
+			return nil, err
+		}
+	case !errors.Is(err, fs.ErrNotExist):
+		return nil, err
+	}
+
+	file, err := os.OpenFile(filepath.Join(dir, walFileName), os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	size, records, err := d.replayWAL(file)
+	if err == nil {
+		// Discard any torn record at the end of the log.
+		err = file.Truncate(size)
+	}
+	if err == nil {
+		err = file.Sync()
+	}
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+
+	d.wal = &wal{
+		dir:     dir,
+		file:    file,
+		size:    size,
+		records: records,
+	}
+	return d, nil
+}
+
+// replayWAL applies the valid records in the log, returning their total size
+// and number. Reading stops at an incomplete or corrupt record at the end of
+// the log, which was torn by a crash part way through writing it. A corrupt
+// record followed by more data means the log itself is damaged, which is an
+// error.
+func (d *DB) replayWAL(file *os.File) (int64, int, error) {
+	r := bufio.NewReader(file)
+	var size int64
+	var records int
+	for {
+		var header [8]byte
+		if _, err := io.ReadFull(r, header[:]); err != nil {
+			return size, records, ignoreTornWrite(err)
+		}
+		length := binary.BigEndian.Uint32(header[0:4])
+		if length == 0 || length > maxWALRecordSize {
+			// Without a length, we can't tell where the record ends. A
+			// crash while extending the file can leave it padded with
+			// zeros; anything else is damage.
+			rest, err := io.ReadAll(r)
+			if err == nil && isZeros(header[:]) && isZeros(rest) {
+				return size, records, nil
+			}
+			return 0, 0, fmt.Errorf("error reading write-ahead log: corrupt record length at offset %d", size)
+		}
+		payload := make([]byte, length)
+		if _, err := io.ReadFull(r, payload); err != nil {
+			return size, records, ignoreTornWrite(err)
+		}
+		if crc32.Checksum(payload, crc32c) != binary.BigEndian.Uint32(header[4:8]) {
+			if _, err := r.Peek(1); errors.Is(err, io.EOF) {
+				return size, records, nil
+			}
+			return 0, 0, fmt.Errorf("error reading write-ahead log: corrupt record at offset %d", size)
+		}
+
+		var record walRecord
+		if err := json.Unmarshal(payload, &record); err != nil {
+			return 0, 0, fmt.Errorf("error reading write-ahead log: %w", err)
+		}
+		if err := d.applyWALRecord(record); err != nil {
+			return 0, 0, fmt.Errorf("error replaying write-ahead log: %w", err)
+		}
+		size += int64(len(header) + len(payload))
+		records++
+	}
+}
+
+func ignoreTornWrite(err error) error {
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil
+	}
+	return err
+}
+
+func isZeros(b []byte) bool {
+	return !slices.ContainsFunc(b, func(c byte) bool { return c != 0 })
+}
+
+func (d *DB) applyWALRecord(record walRecord) error {
+	switch {
+	case record.Table != nil:
+		return d.applyTableRecord(record.Table)
+	case record.DeleteTable != "":
+		if t, exists := d.tables.Get(tableKey(record.DeleteTable)); exists {
+			d.removeTable(t)
+		}
+		return nil
+	case record.PutItem != nil:
+		var input struct {
+			TableName *string
+			Item      avmap
+		}
+		if err := unmarshalDynamoDBJSON(record.PutItem, &input); err != nil {
+			return err
+		}
+		t, exists := d.tables.Get(tableKey(val(input.TableName)))
+		if !exists {
+			return fmt.Errorf("item written to unknown table %s", val(input.TableName))
+		}
+		_, _, err := t.items.Put(input.Item)
+		return err
+	case record.DeleteItem != nil:
+		var input struct {
+			TableName *string
+			Key       avmap
+		}
+		if err := unmarshalDynamoDBJSON(record.DeleteItem, &input); err != nil {
+			return err
+		}
+		t, exists := d.tables.Get(tableKey(val(input.TableName)))
+		if !exists {
+			return fmt.Errorf("item deleted from unknown table %s", val(input.TableName))
+		}
+		_, _, err := t.items.Delete(input.Key)
+		return err
+	}
+	return errors.New("empty record")
+}
+
+// applyTableRecord creates a table, or updates the metadata of an existing
+// table.
+func (d *DB) applyTableRecord(record *snapshotTable) error {
+	var spec struct{ TableName string }
+	if err := json.Unmarshal(record.Spec, &spec); err != nil {
+		return err
+	}
+	t, exists := d.tables.Get(tableKey(spec.TableName))
+	if !exists {
+		return d.loadSnapshotTable(nil, record)
+	}
+
+	var updated dynamodb.CreateTableInput
+	if err := unmarshalDynamoDBJSON(record.Spec, &updated); err != nil {
+		return err
+	}
+	t.spec = &updated
+	t.throughput.update(t.spec, d.now())
+	t.createdAt = record.CreatedAt
+	t.tags = record.Tags
+	t.ttlAttribute = record.TimeToLiveAttribute
+	t.policy = loadResourcePolicy(record.ResourcePolicy, record.ResourcePolicyRevisionID)
+	t.streamPolicy = loadResourcePolicy(record.StreamPolicy, record.StreamPolicyRevisionID)
+	return nil
+}
+
+// changeTable changes a table's metadata. change is applied to a copy of the
+// table, which is logged before it replaces the table, so that a change which
+// can't be logged isn't made. change mustn't modify the maps or other values
+// which the copy shares with the table; it should replace them instead. The
+// caller MUST hold the write lock.
+func (d *DB) changeTable(t *table, change func(updated *table) error) error {
+	updated := *t
+	if err := change(&updated); err != nil {
+		return err
+	}
+	if err := d.logTable(&updated); err != nil {
+		return err
+	}
+	*t = updated
+	return nil
+}
+
+// logTable records a change to a table's metadata in the write-ahead log, if
+// the DB has one. The caller MUST hold the write lock.
+func (d *DB) logTable(t *table) error {
+	if d.wal == nil {
+		return nil
+	}
+	record, err := t.snapshotRecord()
+	if err != nil {
+		return err
+	}
+	return d.appendWAL(walRecord{Table: record, DeleteTable: "", PutItem: nil, DeleteItem: nil})
+}
+
+// logItemWrite records that an item was stored, or if item is nil that the
+// item with the given key was deleted, in the write-ahead log, if the DB has
+// one. The caller MUST hold the write lock.
+func (d *DB) logItemWrite(t *table, key, item avmap) error {
+	if d.wal == nil {
+		return nil
+	}
+	var record walRecord
+	var err error
+	if item != nil {
+		record.PutItem, err = marshalDynamoDBJSON(struct {
+			TableName *string
+			Item      avmap
+		}{t.spec.TableName, item})
+	} else {
+		record.DeleteItem, err = marshalDynamoDBJSON(struct {
+			TableName *string
+			Key       avmap
+		}{t.spec.TableName, t.extractKeys(key)})
+	}
+	if err != nil {
+		return err
+	}
+	return d.appendWAL(record)
+}
+
+// appendWAL writes a record to the log and syncs it to disk, compacting the
+// log first if it is due. The caller MUST hold the write lock.
+func (d *DB) appendWAL(record walRecord) error {
+	w := d.wal
+	if w == nil {
+		return nil
+	}
+	if w.records >= d.compactEvery {
+		if err := d.compactWAL(); err != nil {
+			return err
+		}
+	}
+
+	payload, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	frame := make([]byte, 8, 8+len(payload))
+	binary.BigEndian.PutUint32(frame[0:4], uint32(len(payload))) //nolint:gosec
+	binary.BigEndian.PutUint32(frame[4:8], crc32.Checksum(payload, crc32c))
+	frame = append(frame, payload...)
+
+	_, err = w.file.WriteAt(frame, w.size)
+	if err == nil {
+		err = w.file.Sync()
+	}
+	if err != nil {
+		// Don't leave part of the record behind for later records to follow.
+		_ = w.file.Truncate(w.size)
+		return fmt.Errorf("error writing to write-ahead log: %w", err)
+	}
+	w.size += int64(len(frame))
+	w.records++
+	return nil
+}
+
+// compactWAL replaces the snapshot with the DB's current state, then empties
+// the log. The caller MUST hold the write lock.
+func (d *DB) compactWAL() error {
+	w := d.wal
+	tmpPath := filepath.Join(w.dir, snapshotFileName+".tmp")
+	tmp, err := os.Create(tmpPath)
+	if err != nil {
+		return fmt.Errorf("error compacting write-ahead log: %w", err)
+	}
+	err = d.writeSnapshot(tmp)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmpPath, filepath.Join(w.dir, snapshotFileName))
+	}
+	if err == nil {
+		err = syncDir(w.dir)
+	}
+	if err == nil {
+		err = w.file.Truncate(0)
+	}
+	if err == nil {
+		err = w.file.Sync()
+	}
+	if err != nil {
+		return fmt.Errorf("error compacting write-ahead log: %w", err)
+	}
+	w.size = 0
+	w.records = 0
+	return nil
+}
+
+// syncDir makes a rename within the directory durable.
+func syncDir(dir string) error {
+	f, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return f.Sync()
+}
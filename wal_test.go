@@ -0,0 +1,259 @@
+package fakedynamo_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/DMRobertson/fakedynamo"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func putFoo(t *testing.T, db *fakedynamo.DB, tableName *string, foo string) {
+	t.Helper()
+	_, err := db.PutItem(&dynamodb.PutItemInput{
+		Item:      map[string]*dynamodb.AttributeValue{"Foo": {S: ptr(foo)}},
+		TableName: tableName,
+	})
+	require.NoError(t, err)
+}
+
+func hasFoo(t *testing.T, db *fakedynamo.DB, tableName *string, foo string) bool {
+	t.Helper()
+	got, err := db.GetItem(&dynamodb.GetItemInput{
+		Key:       map[string]*dynamodb.AttributeValue{"Foo": {S: ptr(foo)}},
+		TableName: tableName,
+	})
+	require.NoError(t, err)
+	return got.Item != nil
+}
+
+func TestOpenDB_SurvivesRestart(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	db, err := fakedynamo.OpenDB(dir)
+	require.NoError(t, err)
+	input := exampleCreateTableInputSimplePrimaryKey()
+	input.StreamSpecification = &dynamodb.StreamSpecification{
+		StreamEnabled:  ptr(true),
+		StreamViewType: ptr(dynamodb.StreamViewTypeNewAndOldImages),
+	}
+	created, err := db.CreateTable(input)
+	require.NoError(t, err)
+	policy, err := db.PutResourcePolicy(&dynamodb.PutResourcePolicyInput{
+		Policy:      ptr(examplePolicy),
+		ResourceArn: created.TableDescription.TableArn,
+	})
+	require.NoError(t, err)
+	streamPolicy, err := db.PutResourcePolicy(&dynamodb.PutResourcePolicyInput{
+		Policy:      ptr(examplePolicy),
+		ResourceArn: created.TableDescription.LatestStreamArn,
+	})
+	require.NoError(t, err)
+	_, err = db.TagResource(&dynamodb.TagResourceInput{
+		ResourceArn: created.TableDescription.TableArn,
+		Tags:        []*dynamodb.Tag{{Key: ptr("team"), Value: ptr("storage")}},
+	})
+	require.NoError(t, err)
+	_, err = db.UpdateTimeToLive(&dynamodb.UpdateTimeToLiveInput{
+		TableName: input.TableName,
+		TimeToLiveSpecification: &dynamodb.TimeToLiveSpecification{
+			AttributeName: ptr("ExpiresAt"),
+			Enabled:       ptr(true),
+		},
+	})
+	require.NoError(t, err)
+	putFoo(t, db, input.TableName, "kept")
+	putFoo(t, db, input.TableName, "deleted")
+	_, err = db.DeleteItem(&dynamodb.DeleteItemInput{
+		Key:       map[string]*dynamodb.AttributeValue{"Foo": {S: ptr("deleted")}},
+		TableName: input.TableName,
+	})
+	require.NoError(t, err)
+	dropped := exampleCreateTableInputSimplePrimaryKey()
+	_, err = db.CreateTable(dropped)
+	require.NoError(t, err)
+	_, err = db.DeleteTable(&dynamodb.DeleteTableInput{TableName: dropped.TableName})
+	require.NoError(t, err)
+
+	// Reopen without closing, as if the process had died.
+	reopened, err := fakedynamo.OpenDB(dir)
+	require.NoError(t, err)
+	defer reopened.Close()
+	assert.True(t, hasFoo(t, reopened, input.TableName, "kept"))
+	assert.False(t, hasFoo(t, reopened, input.TableName, "deleted"))
+	assert.Equal(t, map[string]string{"team": "storage"}, listAllTags(t, reopened, created.TableDescription.TableArn))
+	ttl, err := reopened.DescribeTimeToLive(&dynamodb.DescribeTimeToLiveInput{TableName: input.TableName})
+	require.NoError(t, err)
+	assert.Equal(t, ptr("ExpiresAt"), ttl.TimeToLiveDescription.AttributeName)
+	// Clients can carry on using the revision ID they were given.
+	_, err = reopened.DeleteResourcePolicy(&dynamodb.DeleteResourcePolicyInput{
+		ExpectedRevisionId: policy.RevisionId,
+		ResourceArn:        created.TableDescription.TableArn,
+	})
+	require.NoError(t, err)
+	_, err = reopened.DeleteResourcePolicy(&dynamodb.DeleteResourcePolicyInput{
+		ExpectedRevisionId: streamPolicy.RevisionId,
+		ResourceArn:        created.TableDescription.LatestStreamArn,
+	})
+	require.NoError(t, err)
+	listed, err := reopened.ListTables(&dynamodb.ListTablesInput{})
+	require.NoError(t, err)
+	assert.Equal(t, []*string{input.TableName}, listed.TableNames)
+}
+
+func TestOpenDB_DiscardsTornWrite(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	db, err := fakedynamo.OpenDB(dir)
+	require.NoError(t, err)
+	input := exampleCreateTableInputSimplePrimaryKey()
+	_, err = db.CreateTable(input)
+	require.NoError(t, err)
+	putFoo(t, db, input.TableName, "first")
+	putFoo(t, db, input.TableName, "second")
+
+	// Cut the last record short.
+	walPath := filepath.Join(dir, "wal")
+	info, err := os.Stat(walPath)
+	require.NoError(t, err)
+	require.NoError(t, os.Truncate(walPath, info.Size()-3))
+
+	reopened, err := fakedynamo.OpenDB(dir)
+	require.NoError(t, err)
+	assert.True(t, hasFoo(t, reopened, input.TableName, "first"))
+	assert.False(t, hasFoo(t, reopened, input.TableName, "second"))
+
+	// Writes after the torn record are kept.
+	putFoo(t, reopened, input.TableName, "third")
+	reopened, err = fakedynamo.OpenDB(dir)
+	require.NoError(t, err)
+	assert.True(t, hasFoo(t, reopened, input.TableName, "third"))
+}
+
+func TestOpenDB_RejectsCorruptLog(t *testing.T) {
+	t.Parallel()
+	for _, corrupted := range []string{"first", "second"} {
+		t.Run(corrupted, func(t *testing.T) {
+			t.Parallel()
+			dir := t.TempDir()
+			db, err := fakedynamo.OpenDB(dir)
+			require.NoError(t, err)
+			input := exampleCreateTableInputSimplePrimaryKey()
+			_, err = db.CreateTable(input)
+			require.NoError(t, err)
+			putFoo(t, db, input.TableName, "first")
+			putFoo(t, db, input.TableName, "second")
+
+			walPath := filepath.Join(dir, "wal")
+			contents, err := os.ReadFile(walPath)
+			require.NoError(t, err)
+			i := bytes.Index(contents, []byte(corrupted))
+			require.Positive(t, i)
+			contents[i] = 'X'
+			require.NoError(t, os.WriteFile(walPath, contents, 0o600))
+
+			reopened, err := fakedynamo.OpenDB(dir)
+			if corrupted == "first" {
+				// A valid record follows, so the corrupt one wasn't torn.
+				assert.ErrorContains(t, err, "corrupt record")
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, hasFoo(t, reopened, input.TableName, "first"))
+			assert.False(t, hasFoo(t, reopened, input.TableName, "second"))
+		})
+	}
+}
+
+func TestOpenDB_CompactsLog(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	db, err := fakedynamo.OpenDB(dir, fakedynamo.WithCompactEvery(3))
+	require.NoError(t, err)
+	input := exampleCreateTableInputSimplePrimaryKey()
+	_, err = db.CreateTable(input)
+	require.NoError(t, err)
+	for _, foo := range []string{"a", "b", "c", "d"} {
+		putFoo(t, db, input.TableName, foo)
+	}
+
+	_, err = os.Stat(filepath.Join(dir, "snapshot"))
+	require.NoError(t, err)
+	reopened, err := fakedynamo.OpenDB(dir)
+	require.NoError(t, err)
+	for _, foo := range []string{"a", "b", "c", "d"} {
+		assert.True(t, hasFoo(t, reopened, input.TableName, foo))
+	}
+
+	require.NoError(t, reopened.Close())
+	info, err := os.Stat(filepath.Join(dir, "wal"))
+	require.NoError(t, err)
+	assert.Zero(t, info.Size())
+}
+
+func TestOpenDB_ChangesWhichCantBeLoggedArentMade(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	db, err := fakedynamo.OpenDB(dir, fakedynamo.WithCompactEvery(1))
+	require.NoError(t, err)
+	input := exampleCreateTableInputSimplePrimaryKey()
+	input.ResourcePolicy = ptr(examplePolicy)
+	input.Tags = []*dynamodb.Tag{{Key: ptr("team"), Value: ptr("storage")}}
+	created, err := db.CreateTable(input)
+	require.NoError(t, err)
+	arn := created.TableDescription.TableArn
+	before, err := db.DescribeTable(&dynamodb.DescribeTableInput{TableName: input.TableName})
+	require.NoError(t, err)
+	policyBefore, err := db.GetResourcePolicy(&dynamodb.GetResourcePolicyInput{ResourceArn: arn})
+	require.NoError(t, err)
+
+	// The next change compacts the log first, which fails since the snapshot
+	// can't be written.
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "snapshot.tmp"), 0o750))
+	_, err = db.TagResource(&dynamodb.TagResourceInput{
+		ResourceArn: arn,
+		Tags:        []*dynamodb.Tag{{Key: ptr("env"), Value: ptr("test")}},
+	})
+	require.Error(t, err)
+	_, err = db.UntagResource(&dynamodb.UntagResourceInput{ResourceArn: arn, TagKeys: []*string{ptr("team")}})
+	require.Error(t, err)
+	_, err = db.PutResourcePolicy(&dynamodb.PutResourcePolicyInput{
+		Policy:      ptr(strings.Replace(examplePolicy, "GetItem", "PutItem", 1)),
+		ResourceArn: arn,
+	})
+	require.Error(t, err)
+	_, err = db.DeleteResourcePolicy(&dynamodb.DeleteResourcePolicyInput{ResourceArn: arn})
+	require.Error(t, err)
+	_, err = db.UpdateTimeToLive(&dynamodb.UpdateTimeToLiveInput{
+		TableName: input.TableName,
+		TimeToLiveSpecification: &dynamodb.TimeToLiveSpecification{
+			AttributeName: ptr("ExpiresAt"),
+			Enabled:       ptr(true),
+		},
+	})
+	require.Error(t, err)
+	_, err = db.UpdateTable(&dynamodb.UpdateTableInput{
+		DeletionProtectionEnabled: ptr(true),
+		TableName:                 input.TableName,
+	})
+	require.Error(t, err)
+
+	assert.Equal(t, map[string]string{"team": "storage"}, listAllTags(t, db, arn))
+	policyAfter, err := db.GetResourcePolicy(&dynamodb.GetResourcePolicyInput{ResourceArn: arn})
+	require.NoError(t, err)
+	assert.Equal(t, policyBefore, policyAfter)
+	ttl, err := db.DescribeTimeToLive(&dynamodb.DescribeTimeToLiveInput{TableName: input.TableName})
+	require.NoError(t, err)
+	assert.Equal(t, dynamodb.TimeToLiveStatusDisabled, val(ttl.TimeToLiveDescription.TimeToLiveStatus))
+	after, err := db.DescribeTable(&dynamodb.DescribeTableInput{TableName: input.TableName})
+	require.NoError(t, err)
+	assert.Equal(t, before, after)
+
+	require.NoError(t, os.Remove(filepath.Join(dir, "snapshot.tmp")))
+	require.NoError(t, db.Close())
+}
@@ -0,0 +1,262 @@
+package fakedynamo
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"gopkg.in/yaml.v3"
+)
+
+// WorkbenchModel is a data model exported from NoSQL Workbench, loaded by
+// [DB.LoadWorkbenchModel].
+type WorkbenchModel struct {
+	Name string
+	// AccessPatterns holds the facets of the model's tables, in order.
+	AccessPatterns []*AccessPattern
+}
+
+// AccessPattern returns the access pattern with the given name, or nil if
+// there isn't one. If tables have facets with the same name, the first is
+// returned.
+func (m *WorkbenchModel) AccessPattern(name string) *AccessPattern {
+	for _, p := range m.AccessPatterns {
+		if p.Name == name {
+			return p
+		}
+	}
+	return nil
+}
+
+// AccessPattern is a named way of reading a table's items, defined by a NoSQL
+// Workbench facet. Running its queries checks that the items written by an
+// implementation match the model.
+type AccessPattern struct {
+	// Name is the facet's name.
+	Name      string
+	TableName string
+	// PartitionKey and SortKey name the table's key attributes. SortKey is
+	// empty if the table doesn't have a sort key.
+	PartitionKey string
+	SortKey      string
+	// PartitionKeyAlias and SortKeyAlias are the facet's names for the key
+	// attributes.
+	PartitionKeyAlias string
+	SortKeyAlias      string
+	// Attributes names the non-key attributes which the facet shows.
+	Attributes []string
+}
+
+// QueryInput returns a Query reading the facet's items with the given
+// partition key value, and if sortKey is non-nil, the given sort key value.
+// The Query projects the key attributes and the facet's attributes.
+func (p *AccessPattern) QueryInput(partitionKey, sortKey *dynamodb.AttributeValue) *dynamodb.QueryInput {
+	names := map[string]*string{"#pk": ptr(p.PartitionKey)}
+	values := map[string]*dynamodb.AttributeValue{":pk": partitionKey}
+	condition := "#pk = :pk"
+	projection := "#pk"
+	if p.SortKey != "" {
+		names["#sk"] = ptr(p.SortKey)
+		projection += ", #sk"
+		if sortKey != nil {
+			values[":sk"] = sortKey
+			condition += " AND #sk = :sk"
+		}
+	}
+	for i, attr := range p.Attributes {
+		placeholder := fmt.Sprintf("#a%d", i)
+		names[placeholder] = ptr(attr)
+		projection += ", " + placeholder
+	}
+	return &dynamodb.QueryInput{ //nolint:exhaustruct
+		ExpressionAttributeNames:  names,
+		ExpressionAttributeValues: values,
+		KeyConditionExpression:    &condition,
+		ProjectionExpression:      &projection,
+		TableName:                 &p.TableName,
+	}
+}
+
+// LoadWorkbenchModelFile loads the NoSQL Workbench data model in the file at
+// path. See [DB.LoadWorkbenchModel].
+func (d *DB) LoadWorkbenchModelFile(path string) (*WorkbenchModel, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return d.LoadWorkbenchModel(path, f)
+}
+
+// LoadWorkbenchModel reads a data model exported from NoSQL Workbench as JSON,
+// creating its tables with their global secondary indexes, and putting their
+// sample data. The model's facets are returned as access patterns.
+//
+// name identifies the model in errors, which are *[FixtureError]s unless the
+// model can't be parsed at all. Loading stops at the first error, keeping
+// the tables and items created before it.
+func (d *DB) LoadWorkbenchModel(name string, r io.Reader) (*WorkbenchModel, error) {
+	var doc yaml.Node
+	if err := yaml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	l := &fixtureLoader{d: d, name: name}
+	root := resolveAlias(doc.Content[0])
+	model := &WorkbenchModel{Name: "", AccessPatterns: nil}
+	if modelName := yamlField(root, "ModelName"); modelName != nil {
+		model.Name = modelName.Value
+	}
+	tables := yamlField(root, "DataModel")
+	if tables == nil || tables.Kind != yaml.SequenceNode {
+		return nil, l.errorf(root, "expected a NoSQL Workbench data model, with a DataModel list")
+	}
+	for _, table := range tables.Content {
+		patterns, err := l.loadWorkbenchTable(resolveAlias(table))
+		if err != nil {
+			return nil, err
+		}
+		model.AccessPatterns = append(model.AccessPatterns, patterns...)
+	}
+	return model, nil
+}
+
+type workbenchKeyAttributes struct {
+	PartitionKey *dynamodb.AttributeDefinition
+	SortKey      *dynamodb.AttributeDefinition
+}
+
+type workbenchTable struct {
+	TableName              string
+	KeyAttributes          workbenchKeyAttributes
+	GlobalSecondaryIndexes []struct {
+		IndexName     string
+		KeyAttributes workbenchKeyAttributes
+		Projection    *dynamodb.Projection
+	}
+	TableFacets []struct {
+		FacetName         string
+		KeyAttributeAlias struct {
+			PartitionKeyAlias string
+			SortKeyAlias      string
+		}
+		NonKeyAttributes []string
+	}
+	BillingMode                 string
+	ProvisionedCapacitySettings *struct {
+		ProvisionedThroughput *dynamodb.ProvisionedThroughput
+	}
+}
+
+func (l *fixtureLoader) loadWorkbenchTable(n *yaml.Node) ([]*AccessPattern, error) {
+	plain, err := l.plainValue(n)
+	if err != nil {
+		return nil, err
+	}
+	encoded, err := json.Marshal(plain)
+	if err != nil {
+		return nil, l.wrap(n, err)
+	}
+	var table workbenchTable
+	if err := json.Unmarshal(encoded, &table); err != nil {
+		return nil, l.wrap(n, err)
+	}
+
+	if _, err := l.d.CreateTable(table.createTableInput()); err != nil {
+		return nil, l.wrap(n, err)
+	}
+
+	// Sample data is held by the table, or in older models by its facets.
+	data := []*yaml.Node{yamlField(n, "TableData")}
+	if facets := yamlField(n, "TableFacets"); facets != nil {
+		for _, facet := range facets.Content {
+			data = append(data, yamlField(resolveAlias(facet), "TableData"))
+		}
+	}
+	for _, items := range data {
+		if items == nil {
+			continue
+		}
+		if items.Kind != yaml.SequenceNode {
+			return nil, l.errorf(items, "TableData must be a list")
+		}
+		for _, itemNode := range items.Content {
+			item, err := l.item(resolveAlias(itemNode))
+			if err != nil {
+				return nil, err
+			}
+			_, err = l.d.PutItem(&dynamodb.PutItemInput{ //nolint:exhaustruct
+				Item:      item,
+				TableName: &table.TableName,
+			})
+			if err != nil {
+				return nil, l.wrap(itemNode, err)
+			}
+		}
+	}
+
+	var patterns []*AccessPattern
+	for _, facet := range table.TableFacets {
+		pattern := &AccessPattern{
+			Name:              facet.FacetName,
+			TableName:         table.TableName,
+			PartitionKey:      val(table.KeyAttributes.PartitionKey.AttributeName),
+			SortKey:           "",
+			PartitionKeyAlias: facet.KeyAttributeAlias.PartitionKeyAlias,
+			SortKeyAlias:      facet.KeyAttributeAlias.SortKeyAlias,
+			Attributes:        facet.NonKeyAttributes,
+		}
+		if table.KeyAttributes.SortKey != nil {
+			pattern.SortKey = val(table.KeyAttributes.SortKey.AttributeName)
+		}
+		patterns = append(patterns, pattern)
+	}
+	return patterns, nil
+}
+
+func (t *workbenchTable) createTableInput() *dynamodb.CreateTableInput {
+	input := &dynamodb.CreateTableInput{ //nolint:exhaustruct
+		BillingMode: ptr(cmp.Or(t.BillingMode, dynamodb.BillingModePayPerRequest)),
+		TableName:   &t.TableName,
+	}
+	var throughput *dynamodb.ProvisionedThroughput
+	if *input.BillingMode == dynamodb.BillingModeProvisioned && t.ProvisionedCapacitySettings != nil {
+		throughput = t.ProvisionedCapacitySettings.ProvisionedThroughput
+		input.ProvisionedThroughput = throughput
+	}
+
+	defined := map[string]bool{}
+	keySchema := func(keys workbenchKeyAttributes) []*dynamodb.KeySchemaElement {
+		var schema []*dynamodb.KeySchemaElement
+		for _, key := range []struct {
+			attr    *dynamodb.AttributeDefinition
+			keyType string
+		}{{keys.PartitionKey, dynamodb.KeyTypeHash}, {keys.SortKey, dynamodb.KeyTypeRange}} {
+			if key.attr == nil {
+				continue
+			}
+			schema = append(schema, &dynamodb.KeySchemaElement{
+				AttributeName: key.attr.AttributeName,
+				KeyType:       ptr(key.keyType),
+			})
+			if !defined[val(key.attr.AttributeName)] {
+				defined[val(key.attr.AttributeName)] = true
+				input.AttributeDefinitions = append(input.AttributeDefinitions, key.attr)
+			}
+		}
+		return schema
+	}
+
+	input.KeySchema = keySchema(t.KeyAttributes)
+	for _, index := range t.GlobalSecondaryIndexes {
+		input.GlobalSecondaryIndexes = append(input.GlobalSecondaryIndexes, &dynamodb.GlobalSecondaryIndex{ //nolint:exhaustruct
+			IndexName:             ptr(index.IndexName),
+			KeySchema:             keySchema(index.KeyAttributes),
+			Projection:            index.Projection,
+			ProvisionedThroughput: throughput,
+		})
+	}
+	return input
+}
@@ -0,0 +1,133 @@
+package fakedynamo_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/DMRobertson/fakedynamo"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const exampleWorkbenchModel = `{
+  "ModelName": "Shop",
+  "ModelMetadata": {"Author": "", "Version": "3.0", "AWSService": "Amazon DynamoDB"},
+  "DataModel": [
+    {
+      "TableName": "Shop",
+      "KeyAttributes": {
+        "PartitionKey": {"AttributeName": "PK", "AttributeType": "S"},
+        "SortKey": {"AttributeName": "SK", "AttributeType": "S"}
+      },
+      "NonKeyAttributes": [
+        {"AttributeName": "Name", "AttributeType": "S"},
+        {"AttributeName": "Total", "AttributeType": "N"},
+        {"AttributeName": "GSI1PK", "AttributeType": "S"}
+      ],
+      "TableFacets": [
+        {
+          "FacetName": "Customer",
+          "KeyAttributeAlias": {"PartitionKeyAlias": "CustomerId", "SortKeyAlias": "Profile"},
+          "NonKeyAttributes": ["Name"],
+          "DataAccess": {"MySql": {}}
+        },
+        {
+          "FacetName": "Order",
+          "KeyAttributeAlias": {"PartitionKeyAlias": "CustomerId", "SortKeyAlias": "OrderId"},
+          "NonKeyAttributes": ["Total", "GSI1PK"],
+          "TableData": [
+            {"PK": {"S": "c#1"}, "SK": {"S": "o#2"}, "Total": {"N": "20"}, "GSI1PK": {"S": "o#2"}}
+          ]
+        }
+      ],
+      "GlobalSecondaryIndexes": [
+        {
+          "IndexName": "GSI1",
+          "KeyAttributes": {"PartitionKey": {"AttributeName": "GSI1PK", "AttributeType": "S"}},
+          "Projection": {"ProjectionType": "ALL"}
+        }
+      ],
+      "TableData": [
+        {"PK": {"S": "c#1"}, "SK": {"S": "profile"}, "Name": {"S": "Ada"}}
+      ],
+      "DataAccess": {"MySql": {}},
+      "BillingMode": "PROVISIONED",
+      "ProvisionedCapacitySettings": {
+        "ProvisionedThroughput": {"ReadCapacityUnits": 5, "WriteCapacityUnits": 5}
+      }
+    }
+  ]
+}`
+
+func TestDB_LoadWorkbenchModel(t *testing.T) {
+	t.Parallel()
+	db := fakedynamo.NewDB()
+	model, err := db.LoadWorkbenchModel("shop.json", strings.NewReader(exampleWorkbenchModel))
+	require.NoError(t, err)
+	assert.Equal(t, "Shop", model.Name)
+
+	described, err := db.DescribeTable(&dynamodb.DescribeTableInput{TableName: ptr("Shop")})
+	require.NoError(t, err)
+	assert.Len(t, described.Table.AttributeDefinitions, 3)
+	assert.Equal(t, int64(5), val(described.Table.ProvisionedThroughput.ReadCapacityUnits))
+	require.Len(t, described.Table.GlobalSecondaryIndexes, 1)
+	assert.Equal(t, "GSI1", val(described.Table.GlobalSecondaryIndexes[0].IndexName))
+
+	for _, key := range []string{"profile", "o#2"} {
+		got, err := db.GetItem(&dynamodb.GetItemInput{
+			Key: map[string]*dynamodb.AttributeValue{
+				"PK": {S: ptr("c#1")},
+				"SK": {S: ptr(key)},
+			},
+			TableName: ptr("Shop"),
+		})
+		require.NoError(t, err)
+		assert.NotNil(t, got.Item, key)
+	}
+
+	require.Len(t, model.AccessPatterns, 2)
+	order := model.AccessPattern("Order")
+	require.NotNil(t, order)
+	assert.Equal(t, &fakedynamo.AccessPattern{
+		Name:              "Order",
+		TableName:         "Shop",
+		PartitionKey:      "PK",
+		SortKey:           "SK",
+		PartitionKeyAlias: "CustomerId",
+		SortKeyAlias:      "OrderId",
+		Attributes:        []string{"Total", "GSI1PK"},
+	}, order)
+	assert.Nil(t, model.AccessPattern("Missing"))
+
+	query := order.QueryInput(&dynamodb.AttributeValue{S: ptr("c#1")}, &dynamodb.AttributeValue{S: ptr("o#2")})
+	require.NoError(t, query.Validate())
+	assert.Equal(t, "#pk = :pk AND #sk = :sk", val(query.KeyConditionExpression))
+	assert.Equal(t, "#pk, #sk, #a0, #a1", val(query.ProjectionExpression))
+	assert.Equal(t, map[string]*string{
+		"#pk": ptr("PK"), "#sk": ptr("SK"), "#a0": ptr("Total"), "#a1": ptr("GSI1PK"),
+	}, query.ExpressionAttributeNames)
+
+	queried, err := db.Query(query)
+	require.NoError(t, err)
+	assert.Equal(t, []map[string]*dynamodb.AttributeValue{{
+		"PK": {S: ptr("c#1")}, "SK": {S: ptr("o#2")}, "Total": {N: ptr("20")}, "GSI1PK": {S: ptr("o#2")},
+	}}, queried.Items)
+}
+
+func TestDB_LoadWorkbenchModel_ReportsPositions(t *testing.T) {
+	t.Parallel()
+	_, err := fakedynamo.NewDB().LoadWorkbenchModel("model.json", strings.NewReader(`{
+  "DataModel": [{
+    "TableName": "Things",
+    "KeyAttributes": {"PartitionKey": {"AttributeName": "PK", "AttributeType": "S"}},
+    "TableData": [
+      {"PK": {"S": "a"}},
+      {"Other": {"S": "b"}}
+    ]
+  }]
+}`))
+	var fixtureErr *fakedynamo.FixtureError
+	require.ErrorAs(t, err, &fixtureErr)
+	assert.Equal(t, 7, fixtureErr.Line)
+}